@@ -0,0 +1,47 @@
+package main
+
+import (
+	"JavaCode/internal/grpcserver"
+	"JavaCode/internal/service"
+	"JavaCode/utils"
+	"database/sql"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"net"
+	"time"
+)
+
+// grpcHealthInterval is how often the gRPC health status is refreshed.
+const grpcHealthInterval = 10 * time.Second
+
+// startGRPCServer serves the gRPC API on addr in the background. Its health
+// service follows the readiness checks of /readyz.
+func startGRPCServer(addr string, wallets *grpcserver.WalletServer, schemaVersion int64) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server, healthServer := grpcserver.New(wallets)
+	go watchGRPCHealth(healthServer, wallets.DB, schemaVersion)
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			utils.Logger.WithError(err).Error("gRPC server stopped")
+		}
+	}()
+	return nil
+}
+
+// watchGRPCHealth reports the overall server as NOT_SERVING while the
+// database is unreachable or behind the expected schema version.
+func watchGRPCHealth(healthServer *health.Server, dbConn *sql.DB, schemaVersion int64) {
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if _, err := service.ReadinessService(dbConn, schemaVersion); err != nil {
+			utils.Logger.WithError(err).Warn("gRPC health check failed")
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		healthServer.SetServingStatus("", status)
+		time.Sleep(grpcHealthInterval)
+	}
+}
@@ -0,0 +1,262 @@
+// Command loadtest generates load on a running wallet API and checks
+// afterwards that no money was lost or created.
+//
+// It creates and funds fixture wallets (or uses --wallet-ids), runs a
+// scenario closed loop with --concurrency workers or open loop at a
+// constant --rate, reports latency percentiles per operation and finally
+// compares the total balance with the deposits and withdrawals that were
+// applied and verifies every wallet against its ledger.
+package main
+
+import (
+	"JavaCode/internal/loadtest"
+	"JavaCode/pkg/walletclient"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: loadtest [flags]
+
+Scenarios:
+%s
+Flags:
+%s`
+
+// errInconsistent makes loadtest exit non-zero when the consistency check fails.
+var errInconsistent = errors.New("consistency check failed")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "loadtest:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run executes a load test and writes the report to out.
+func run(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	target := fs.String("target", envOr("LOADTEST_TARGET", "http://localhost:8080"), "base URL of the wallet API")
+	scenarioName := fs.String("scenario", "read-heavy", "load profile, see the list above")
+	duration := fs.Duration("duration", 30*time.Second, "how long load is generated")
+	rate := fs.Float64("rate", 0, "requests per second started regardless of responses (open loop); 0 runs closed loop")
+	concurrency := fs.Int("concurrency", 10, "workers, or the limit of requests in flight with --rate")
+	wallets := fs.Int("wallets", 0, "fixture wallets to create; 0 uses the scenario's default")
+	walletIDs := fs.String("wallet-ids", "", "comma-separated existing wallets to use instead of creating fixtures")
+	currency := fs.String("currency", "RUB", "currency of the fixture wallets")
+	balance := fs.Int64("balance", 1_000_000, "opening balance of each fixture wallet, in minor units")
+	maxAmount := fs.Int64("max-amount", 1000, "largest amount of an operation, in minor units")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of each request")
+	seed := fs.Uint64("seed", 0, "seed of the operation sequence; 0 picks one")
+	hdrFile := fs.String("hdr", "", "write the latency distribution in HdrHistogram format to this file")
+	format := fs.String("format", "text", "report format: text or json")
+	skipCheck := fs.Bool("no-check", false, "skip the final consistency check")
+	fs.Usage = func() {
+		var scenarios, flags strings.Builder
+		for _, name := range loadtest.ScenarioNames() {
+			fmt.Fprintf(&scenarios, "  %-14s %s\n", name, loadtest.Scenarios[name].Description)
+		}
+		fs.SetOutput(&flags)
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, usage, scenarios.String(), flags.String())
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || (*format != "text" && *format != "json") {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if *rate < 0 || *duration <= 0 || *concurrency < 1 {
+		return errors.New("--rate must not be negative, --duration and --concurrency must be positive")
+	}
+
+	scenario, err := loadtest.LookupScenario(*scenarioName)
+	if err != nil {
+		return err
+	}
+
+	// One connection per request in flight, instead of reconnecting once the
+	// two idle connections of the default transport are in use.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = *concurrency
+	httpClient := &http.Client{Transport: transport}
+
+	// Requests of the load are not retried, so that failures are reported;
+	// those of the fixtures and the check are.
+	load, err := walletclient.New(*target, walletclient.WithHTTPClient(httpClient),
+		walletclient.WithRetries(0), walletclient.WithUserAgent("loadtest"))
+	if err != nil {
+		return err
+	}
+	setup, err := walletclient.New(*target, walletclient.WithHTTPClient(httpClient), walletclient.WithUserAgent("loadtest"))
+	if err != nil {
+		return err
+	}
+
+	cfg := loadtest.Config{
+		Scenario:    scenario,
+		MaxAmount:   *maxAmount,
+		Duration:    *duration,
+		Rate:        *rate,
+		Concurrency: *concurrency,
+		Timeout:     *timeout,
+		Seed:        *seed,
+	}
+	if *walletIDs != "" {
+		for _, id := range strings.Split(*walletIDs, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				cfg.Wallets = append(cfg.Wallets, id)
+			}
+		}
+	} else {
+		count := *wallets
+		if count == 0 {
+			count = scenario.Wallets
+		}
+		fixtures := loadtest.Fixtures{
+			Count:       count,
+			Currency:    *currency,
+			Balance:     *balance,
+			RunID:       time.Now().UTC().Format("20060102T150405"),
+			Concurrency: *concurrency,
+		}
+		fmt.Fprintf(os.Stderr, "Creating %d wallets labelled loadtest=%s...\n", count, fixtures.RunID)
+		if cfg.Wallets, err = loadtest.CreateWallets(ctx, setup, fixtures); err != nil {
+			return err
+		}
+	}
+
+	runner, err := loadtest.NewRunner(load, cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Running %s on %d wallets for %v...\n", scenario.Name, len(cfg.Wallets), *duration)
+	report, err := runner.Run(ctx)
+	if err != nil {
+		return err
+	}
+	if !*skipCheck {
+		if _, err := runner.Check(ctx, setup); err != nil {
+			return fmt.Errorf("consistency check: %w", err)
+		}
+	}
+
+	if *hdrFile != "" {
+		if err := writeHistogram(*hdrFile, report.Latency); err != nil {
+			return err
+		}
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = writeReport(out, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if report.Consistency != nil && !report.Consistency.OK {
+		return errInconsistent
+	}
+	return nil
+}
+
+// writeReport writes a human-readable load test report.
+func writeReport(out io.Writer, report *loadtest.Report) error {
+	mode := report.Mode
+	if report.TargetRate > 0 {
+		mode = fmt.Sprintf("%s at %.0f req/s", mode, report.TargetRate)
+	}
+	fmt.Fprintf(out, "Scenario %s, %s, %d wallets\n", report.Scenario, mode, report.Wallets)
+	fmt.Fprintf(out, "%d requests in %v (%.1f req/s)", report.Requests, report.Duration.Round(time.Millisecond), report.Throughput)
+	if report.Saturated > 0 {
+		fmt.Fprintf(out, ", %d delayed waiting for a free connection", report.Saturated)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out)
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "OPERATION\tOK\tREJECTED\tERRORS\tP50\tP90\tP99\tP99.9\tMAX\t")
+	row := func(name string, ok, rejected, errs int64, h *loadtest.Histogram) {
+		p := h.Summary()
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%v\t%v\t%v\t%v\t%v\t\n", name, ok, rejected, errs,
+			roundLatency(p.P50), roundLatency(p.P90), roundLatency(p.P99), roundLatency(p.P999), roundLatency(p.Max))
+	}
+	var ok, rejected, errs int64
+	for _, kind := range []string{loadtest.OpRead, loadtest.OpDeposit, loadtest.OpWithdraw, loadtest.OpTransfer} {
+		stats := report.Operations[kind]
+		if stats.Latency.Count() == 0 {
+			continue
+		}
+		row(kind, stats.OK, stats.Rejected, stats.Errors, stats.Latency)
+		ok, rejected, errs = ok+stats.OK, rejected+stats.Rejected, errs+stats.Errors
+	}
+	row("all", ok, rejected, errs, report.Latency)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Fprintln(out, "\nErrors:")
+		for _, e := range report.Errors {
+			fmt.Fprintln(out, "  "+e)
+		}
+	}
+
+	if c := report.Consistency; c != nil {
+		status := "OK"
+		if !c.OK {
+			status = "FAILED"
+		}
+		fmt.Fprintf(out, "\nConsistency %s: expected total %d, actual %d, difference %+d; %d unknown outcomes resolved, %d unresolved\n",
+			status, c.Expected, c.Actual, c.Difference, c.Resolved, c.Unresolved)
+		for _, v := range c.Inconsistent {
+			fmt.Fprintf(out, "  wallet %s: balance %d, ledger %d\n", v.WalletId, v.Balance, v.LedgerBalance)
+		}
+	}
+	return nil
+}
+
+// writeHistogram writes a latency distribution to a file.
+func writeHistogram(name string, h *loadtest.Histogram) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := h.WritePercentiles(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func roundLatency(d time.Duration) time.Duration {
+	if d >= 10*time.Millisecond {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(10 * time.Microsecond)
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"JavaCode/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newFakeServer serves wallets kept in memory.
+func newFakeServer(t *testing.T) *httptest.Server {
+	var (
+		mu       sync.Mutex
+		balances = map[string]uint64{}
+	)
+	writeError := func(w http.ResponseWriter, status int, code string) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": code, "message": code})
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+		switch {
+		case r.Method == http.MethodPost && path == "wallets":
+			id := fmt.Sprintf("00000000-0000-0000-0000-%012d", len(balances)+1)
+			balances[id] = 0
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(models.BalanceResponse{Uuid: id, Currency: "RUB"})
+		case r.Method == http.MethodPost && path == "wallet":
+			var op models.WalletOperationRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&op))
+			if op.OperationType == models.LedgerWithdraw {
+				if uint64(op.Amount) > balances[op.WalletID] {
+					writeError(w, http.StatusBadRequest, "negative_amount")
+					return
+				}
+				balances[op.WalletID] -= uint64(op.Amount)
+			} else {
+				balances[op.WalletID] += uint64(op.Amount)
+			}
+			_, _ = w.Write([]byte(`{"message":"Operation successful"}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(path, "/verify"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, "admin/wallets/"), "/verify")
+			_ = json.NewEncoder(w).Encode(models.BalanceVerification{
+				WalletId: id, Balance: balances[id], LedgerBalance: int64(balances[id]), Consistent: true,
+			})
+		case r.Method == http.MethodGet && strings.HasPrefix(path, "wallets/"):
+			id := strings.TrimPrefix(path, "wallets/")
+			_ = json.NewEncoder(w).Encode(models.BalanceResponse{Uuid: id, Balance: balances[id], Currency: "RUB"})
+		default:
+			writeError(w, http.StatusNotFound, "not_found")
+		}
+	}))
+}
+
+func TestRun_HotWallet(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	hdr := filepath.Join(t.TempDir(), "latency.hgrm")
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"--target", server.URL, "--scenario", "hot-wallet",
+		"--duration", "100ms", "--concurrency", "4", "--balance", "1000", "--hdr", hdr}, &out)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Scenario hot-wallet, closed-loop, 1 wallets")
+	assert.Contains(t, out.String(), "Consistency OK")
+
+	data, err := os.ReadFile(hdr)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "#[Max")
+}
+
+func TestRun_OpenLoopJSON(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.Close()
+
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"--target", server.URL, "--scenario", "write-heavy", "--wallets", "5",
+		"--rate", "200", "--duration", "100ms", "--format", "json"}, &out)
+	assert.NoError(t, err)
+
+	var report struct {
+		Mode        string
+		Requests    int64
+		Consistency struct{ OK bool }
+	}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, "open-loop", report.Mode)
+	assert.Positive(t, report.Requests)
+	assert.True(t, report.Consistency.OK)
+}
+
+func TestRun_UnknownScenario(t *testing.T) {
+	err := run(context.Background(), []string{"--scenario", "spiky"}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, `unknown scenario "spiky"`)
+}
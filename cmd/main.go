@@ -5,52 +5,236 @@
 //
 // Features:
 //   - PostgreSQL database connection
-//   - Config loading from environment
+//   - Layered config loading (file, environment, flags) with validation
+//   - Embedded schema migrations (wallet-app migrate up|down|status|version)
+//   - Double-entry ledger with balance reconciliation (wallet-app reconcile)
+//   - Replay of recorded API requests (wallet-app replay)
+//   - Scheduled and recurring deposits and withdrawals, run by an elected instance
 //   - REST API with Gin framework
+//   - gRPC API sharing the service layer, with health and reflection
 //   - Middleware-based structured logging
 //   - Swagger documentation support
 //
 // Endpoints:
 //   - GET    /api/v1/wallets/{wallet_uuid} — get wallet balance
+//   - GET    /api/v1/wallets/{wallet_uuid}/stream — balance updates (SSE or WebSocket)
 //   - POST   /api/v1/wallet                — perform deposit or withdrawal
+//   - GET    /healthz, /readyz             — liveness and readiness probes
 
 // @title Wallet API
 // @version 1.0
 // @description API for wallet operation
-// @BasePath /api/v1
+// @BasePath /api
 package main
 
 import (
 	"JavaCode/config"
+	"JavaCode/internal/cache"
+	"JavaCode/internal/controllers"
+	"JavaCode/internal/grpcserver"
 	"JavaCode/internal/routes"
+	"JavaCode/internal/stream"
+	"JavaCode/migrations"
 	"JavaCode/pkg/db"
+	"JavaCode/pkg/migrate"
 	"JavaCode/utils"
+	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	_ "github.com/lib/pq"
+	"os"
 	"time"
 )
 
+const usage = `Usage: wallet-app [flags] [command]
+
+Commands:
+  (none)                          start the API server
+  migrate up|down|status|version  manage the database schema
+  reconcile [--format text|json] [--freeze]
+                                  compare wallet balances with the ledger
+  replay [--target URL] [--rate N | --speed X] FILE
+                                  replay recorded API requests and compare
+                                  the responses with the recording
+
+Run "wallet-app -h" to list the flags.`
+
 func main() {
 	utils.InitLogger()
-	cfg := config.LoadConfig()
+	cfg, opts, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, usage)
+		return
+	}
+	if err != nil {
+		utils.Logger.Error(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if opts.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(opts.Args) > 0 {
+		if err := runCommand(cfg, opts.Args); err != nil {
+			utils.Logger.Error(err)
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	runServer(cfg, opts)
+}
 
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.Db.Host, cfg.Db.Port, cfg.Db.User, cfg.Db.Password, cfg.Db.Db,
-	)
+// runCommand dispatches a subcommand given after the flags.
+func runCommand(cfg *config.Config, args []string) error {
+	switch args[0] {
+	case "migrate":
+		dbConn, err := connectDB(cfg)
+		if err != nil {
+			return err
+		}
+		defer dbConn.Close()
+		return runMigrate(dbConn, args[1:], os.Stdout)
+	case "reconcile":
+		dbConn, err := connectDB(cfg)
+		if err != nil {
+			return err
+		}
+		defer dbConn.Close()
+		return runReconcile(dbConn, args[1:], os.Stdout)
+	case "replay":
+		return runReplay(cfg, args[1:], os.Stdout)
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+	}
+}
+
+// newController builds the API controller on the primary database with the
+// request handling settings of cfg, so that every way of serving the API
+// applies the same rules. The server adds replicas, the cache and streams.
+func newController(cfg *config.Config, dbConn *sql.DB) *controllers.Controller {
+	return &controllers.Controller{DB: dbConn, MaxRateAge: cfg.Rates.MaxAge}
+}
+
+// connectDB connects to the primary database, retrying while it starts.
+func connectDB(cfg *config.Config) (*sql.DB, error) {
+	retry := cfg.Db.Retry()
+	retry.OnRetry = func(attempt int, wait time.Duration, err error) {
+		utils.Logger.Warnf("DataBase is not ready (attempt %d/%d), retrying in %v: %v",
+			attempt, retry.Attempts, wait, err)
+	}
 
-	utils.Logger.Infof("Connect to DataBase: %v", dsn)
-	dbConn, err := db.InitDB(dsn, cfg.Db.Driver)
+	dsn := cfg.Db.DSN()
+	utils.Logger.Infof("Connect to DataBase: %v", db.RedactDSN(dsn))
+	dbConn, err := db.Connect(dsn, cfg.Db.Driver, cfg.Db.Pool(), retry)
 	if err != nil {
-		utils.Logger.Fatalf("Failed to init DB: %v", err)
+		return nil, fmt.Errorf("failed to init DB: %w", err)
+	}
+	utils.Logger.Infof("Luck connect to DataBase: %v", db.RedactDSN(dsn))
+	return dbConn, nil
+}
+
+func runServer(cfg *config.Config, opts config.Options) {
+	dbConn, err := connectDB(cfg)
+	if err != nil {
+		utils.Logger.Fatal(err)
 	}
-	utils.Logger.Infof("Luck connect to DataBase: %v", dsn)
 	defer dbConn.Close()
 
-	dbConn.SetMaxOpenConns(100)
-	dbConn.SetMaxIdleConns(25)
-	dbConn.SetConnMaxLifetime(time.Hour)
+	migrator, err := migrate.New(dbConn, migrations.FS)
+	if err != nil {
+		utils.Logger.Fatalf("Failed to load migrations: %v", err)
+	}
+	if opts.MigrateOnStart {
+		applied, err := migrator.Up()
+		if err != nil {
+			utils.Logger.Fatalf("Failed to migrate DataBase: %v", err)
+		}
+		utils.Logger.Infof("Applied %d migrations, schema version %d", len(applied), migrator.Latest())
+	}
+
+	replicas, err := db.InitReplicas(cfg.Db.ReplicaDSNs, cfg.Db.Driver, cfg.Db.Pool(), cfg.Db.Retry())
+	if err != nil {
+		utils.Logger.Fatalf("Failed to init DB replicas: %v", err)
+	}
+	for _, replica := range replicas {
+		defer replica.Close()
+	}
+	utils.Logger.Infof("Connected to %d DataBase replicas", len(replicas))
+
+	controller := newController(cfg, dbConn)
+	controller.Replicas = replicas
+	controller.SchemaVersion = migrator.Latest()
+	if cfg.Cache.Size > 0 {
+		controller.Cache = cache.NewBalances(cache.NewLRU(cfg.Cache.Size, cfg.Cache.TTL))
+		utils.Logger.Infof("Balance cache enabled: size=%d ttl=%v", cfg.Cache.Size, cfg.Cache.TTL)
+	}
+
+	if cfg.Reconcile.Interval > 0 {
+		startReconcileJob(dbConn, controller.Cache, cfg.Reconcile.Interval, cfg.Reconcile.Freeze)
+		utils.Logger.Infof("Balance reconciliation enabled: interval=%v freeze=%v", cfg.Reconcile.Interval, cfg.Reconcile.Freeze)
+	}
+
+	if cfg.Snapshots.Interval > 0 {
+		startSnapshotJob(dbConn, cfg.Snapshots.Interval, cfg.Snapshots.Lag)
+		utils.Logger.Infof("Balance snapshots enabled: interval=%v lag=%v", cfg.Snapshots.Interval, cfg.Snapshots.Lag)
+	}
+
+	if cfg.Outbox.Sink != "" {
+		sink, err := newOutboxSink(cfg.Outbox)
+		if err != nil {
+			utils.Logger.Fatalf("Failed to init outbox sink: %v", err)
+		}
+		startOutboxDispatcher(dbConn, sink, cfg.Outbox)
+		utils.Logger.Infof("Outbox dispatcher enabled: sink=%s interval=%v", cfg.Outbox.Sink, cfg.Outbox.Interval)
+	}
+
+	if cfg.Outbox.Retention > 0 {
+		startOutboxPruner(dbConn, cfg.Outbox)
+		utils.Logger.Infof("Outbox pruning enabled: retention=%v", cfg.Outbox.Retention)
+	}
+
+	if cfg.Webhooks.Interval > 0 {
+		startWebhookWorker(dbConn, cfg.Webhooks)
+		utils.Logger.Infof("Webhook delivery enabled: interval=%v max_attempts=%d", cfg.Webhooks.Interval, cfg.Webhooks.MaxAttempts)
+	}
+
+	if cfg.Scheduler.Interval > 0 {
+		startScheduler(dbConn, controller.Cache, cfg.Scheduler)
+		utils.Logger.Infof("Scheduled operations enabled: interval=%v batch_size=%d", cfg.Scheduler.Interval, cfg.Scheduler.BatchSize)
+	}
+
+	controller.StreamHeartbeat = cfg.Stream.Heartbeat
+	if cfg.Stream.Listen {
+		hub, err := startStreamHub(cfg)
+		if err != nil {
+			utils.Logger.Fatalf("Failed to listen for wallet events: %v", err)
+		}
+		controller.Events = hub
+		utils.Logger.Infof("Balance streams enabled: listen=%s heartbeat=%v", stream.Channel, cfg.Stream.Heartbeat)
+	} else {
+		controller.Events = stream.Poller{Interval: cfg.Stream.PollInterval}
+		utils.Logger.Infof("Balance streams enabled: poll=%v heartbeat=%v", cfg.Stream.PollInterval, cfg.Stream.Heartbeat)
+	}
+
+	if cfg.Host.GrpcPort != "" {
+		grpcAddr := cfg.Host.ServerHost + ":" + cfg.Host.GrpcPort
+		wallets := &grpcserver.WalletServer{DB: dbConn, Cache: controller.Cache, MaxRateAge: controller.MaxRateAge}
+		if err := startGRPCServer(grpcAddr, wallets, migrator.Latest()); err != nil {
+			utils.Logger.Fatalf("Failed to start gRPC server: %v", err)
+		}
+		utils.Logger.Infof("Start listing gRPC server: %v", grpcAddr)
+	}
 
-	router := routes.SetupRouter(dbConn)
+	router := routes.SetupRouter(controller)
 
 	addr := cfg.Host.ServerHost + ":" + cfg.Host.ServerPort
 
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"JavaCode/migrations"
+	"JavaCode/pkg/migrate"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "usage: wallet-app migrate up|down|status|version"
+
+// runMigrate executes a "migrate" subcommand against the embedded migrations.
+func runMigrate(dbConn *sql.DB, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	migrator, err := migrate.New(dbConn, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Fprintf(out, "OK   %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "no pending migrations")
+		}
+	case "down":
+		m, err := migrator.Down()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "OK   rolled back %d_%s\n", m.Version, m.Name)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "APPLIED AT\tMIGRATION")
+		for _, s := range statuses {
+			appliedAt := "Pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%d_%s\n", appliedAt, s.Migration.Version, s.Migration.Name)
+		}
+		return w.Flush()
+	case "version":
+		version, err := migrate.Version(dbConn)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "version %d (latest embedded %d)\n", version, migrator.Latest())
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"JavaCode/config"
+	"JavaCode/internal/outbox"
+	"JavaCode/internal/repositories"
+	"JavaCode/internal/service"
+	"JavaCode/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// outboxPruneInterval is how often events past their retention are removed.
+const outboxPruneInterval = time.Hour
+
+// newOutboxSink builds the sink selected in the configuration.
+func newOutboxSink(cfg config.Outbox) (outbox.Sink, error) {
+	switch cfg.Sink {
+	case "log":
+		return outbox.LogSink{}, nil
+	case "file":
+		return outbox.NewFileSink(cfg.File)
+	case "webhook":
+		return &outbox.WebhookSink{URL: cfg.WebhookURL, Client: &http.Client{Timeout: cfg.WebhookTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("unknown outbox sink %q", cfg.Sink)
+	}
+}
+
+// startOutboxDispatcher delivers outbox events to sink in the background,
+// polling every interval and draining the backlog a batch at a time.
+//
+// As with the scheduler, replicas elect the dispatching instance with a
+// session-scoped advisory lock held on a dedicated connection, so that no
+// transaction stays open while the sink is slow.
+func startOutboxDispatcher(dbConn *sql.DB, sink outbox.Sink, cfg config.Outbox) {
+	go func() {
+		ctx := context.Background()
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		var leader *sql.Conn
+		for range ticker.C {
+			if leader == nil {
+				if leader = lockLeader(ctx, dbConn, "outbox", repositories.TryLockOutbox); leader == nil {
+					continue
+				}
+				utils.Logger.Info("Outbox lock acquired, dispatching events")
+			} else if err := leader.PingContext(ctx); err != nil {
+				utils.Logger.WithError(err).Warn("outbox dispatcher lost its lock connection")
+				_ = leader.Close()
+				leader = nil
+				continue
+			}
+
+			for {
+				delivered, pending, err := service.DispatchOutboxService(ctx, dbConn, sink,
+					cfg.BatchSize, cfg.MaxBackoff)
+				if err != nil {
+					utils.Logger.WithError(err).Warn("outbox dispatch failed")
+					break
+				}
+				if delivered == 0 || pending < cfg.BatchSize {
+					break
+				}
+			}
+		}
+	}()
+}
+
+// startOutboxPruner removes outbox events past their retention in the
+// background, whether or not a dispatcher delivers them.
+func startOutboxPruner(dbConn *sql.DB, cfg config.Outbox) {
+	go func() {
+		ticker := time.NewTicker(outboxPruneInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			removed, err := service.PruneOutboxService(dbConn, cfg.Retention, cfg.Sink != "")
+			if err != nil {
+				utils.Logger.WithError(err).Warn("outbox prune failed")
+				continue
+			}
+			utils.Logger.Infof("Removed %d outbox events", removed)
+		}
+	}()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"JavaCode/internal/cache"
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/utils"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+const reconcileUsage = "usage: wallet-app reconcile [--format text|json] [--freeze]"
+
+// errDiscrepancies makes "reconcile" exit non-zero when balances do not match.
+var errDiscrepancies = errors.New("balance discrepancies found")
+
+// runReconcile executes the "reconcile" subcommand and writes the report to out.
+func runReconcile(dbConn *sql.DB, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	format := fs.String("format", "text", "report format: text or json")
+	freeze := fs.Bool("freeze", false, "freeze wallets whose balance does not match the ledger")
+	if err := fs.Parse(args); err != nil {
+		return errors.New(reconcileUsage)
+	}
+	if fs.NArg() > 0 || (*format != "text" && *format != "json") {
+		return errors.New(reconcileUsage)
+	}
+
+	report, err := service.ReconcileService(dbConn, nil, *freeze)
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		return errors.New("another instance is reconciling balances, try again later")
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = writeReconcileReport(out, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(report.Discrepancies) > 0 {
+		return fmt.Errorf("%w: %d of %d wallets", errDiscrepancies, len(report.Discrepancies), report.WalletsChecked)
+	}
+	return nil
+}
+
+// writeReconcileReport writes a human-readable reconciliation report.
+func writeReconcileReport(out io.Writer, report *models.ReconcileReport) error {
+	fmt.Fprintf(out, "Checked %d wallets in %v: %d discrepancies\n",
+		report.WalletsChecked, report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond), len(report.Discrepancies))
+	if len(report.Discrepancies) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "WALLET\tCURRENCY\tBALANCE\tLEDGER\tDIFFERENCE\tFROZEN")
+	for _, d := range report.Discrepancies {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%+d\t%v\n", d.WalletId, d.Currency, d.Balance, d.LedgerBalance, d.Difference, d.Frozen)
+	}
+	return w.Flush()
+}
+
+// startReconcileJob reconciles balances every interval in the background,
+// logging each discrepancy. Only one instance reconciles at a time; the
+// others skip the tick.
+func startReconcileJob(dbConn *sql.DB, balances *cache.Balances, interval time.Duration, freeze bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			report, err := service.ReconcileService(dbConn, balances, freeze)
+			if err != nil {
+				utils.Logger.WithError(err).Warn("balance reconciliation failed")
+				continue
+			}
+			if report == nil {
+				continue
+			}
+			for _, d := range report.Discrepancies {
+				utils.Logger.Errorf("wallet %s balance %d does not match the ledger balance %d (frozen: %v)",
+					d.WalletId, d.Balance, d.LedgerBalance, d.Frozen)
+			}
+			utils.Logger.Infof("Reconciled %d wallets: %d discrepancies", report.WalletsChecked, len(report.Discrepancies))
+		}
+	}()
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"JavaCode/config"
+	"JavaCode/internal/replay"
+	"JavaCode/internal/routes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const replayUsage = `usage: wallet-app replay (--target URL | --in-process) [--rate N | --speed X] [--concurrency N]
+                        [--timeout D] [--ignore FIELDS] [--expect-balances FILE]
+                        [--format text|json] FILE`
+
+// errReplayMismatch makes "replay" exit non-zero when responses or balances do not match.
+var errReplayMismatch = errors.New("replay did not match the recording")
+
+// runReplay executes the "replay" subcommand and writes the report to out.
+//
+// With --in-process instead of --target the requests are served in process
+// by the API handlers on the configured database, which they write to.
+func runReplay(cfg *config.Config, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	target := fs.String("target", "", "base URL of the instance to replay against, e.g. http://localhost:8080")
+	inProcess := fs.Bool("in-process", false, "serve the requests in process on the configured database, writing to it")
+	rate := fs.Float64("rate", 0, "requests per second, 0 for as fast as possible")
+	speed := fs.Float64("speed", 0, "replay the recorded timing, sped up this many times")
+	concurrency := fs.Int("concurrency", 1, "requests in flight; above 1 the recorded order is not kept")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout of each request")
+	ignore := fs.String("ignore", strings.Join(replay.DefaultIgnore, ","), "comma-separated response fields not compared")
+	balances := fs.String("expect-balances", "", "JSON Lines file of the final wallet balances, e.g. a wallet export")
+	format := fs.String("format", "text", "report format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return errors.New(replayUsage)
+	}
+	if fs.NArg() != 1 || (*format != "text" && *format != "json") {
+		return errors.New(replayUsage)
+	}
+	if *rate < 0 || *speed < 0 || (*rate > 0 && *speed > 0) {
+		return fmt.Errorf("--rate and --speed must be positive and cannot be combined\n%s", replayUsage)
+	}
+	if (*target == "") == !*inProcess {
+		return fmt.Errorf("exactly one of --target and --in-process is required\n%s", replayUsage)
+	}
+	if *concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1\n%s", replayUsage)
+	}
+
+	in, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	opts := replay.Options{
+		BaseURL:     *target,
+		Rate:        *rate,
+		Speed:       *speed,
+		Concurrency: *concurrency,
+		Timeout:     *timeout,
+	}
+	for _, field := range strings.Split(*ignore, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			opts.Ignore = append(opts.Ignore, field)
+		}
+	}
+	if *format == "text" {
+		opts.OnResult = func(result replay.Result) {
+			if result.Failed() {
+				writeReplayFailure(out, result)
+			}
+		}
+	}
+
+	var replayTarget replay.Target = http.DefaultClient
+	if *inProcess {
+		dbConn, err := connectDB(cfg)
+		if err != nil {
+			return err
+		}
+		defer dbConn.Close()
+
+		gin.SetMode(gin.ReleaseMode)
+		gin.DefaultWriter = io.Discard
+		replayTarget = replay.Handler{Handler: routes.SetupRouter(newController(cfg, dbConn))}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	replayer := replay.New(replayTarget, opts)
+	summary, err := replayer.Run(ctx, in)
+	if err != nil {
+		return err
+	}
+
+	if *balances != "" {
+		expected, err := os.Open(*balances)
+		if err != nil {
+			return err
+		}
+		defer expected.Close()
+		if summary.Balances, err = replayer.CheckBalances(ctx, expected); err != nil {
+			return fmt.Errorf("%s: %w", *balances, err)
+		}
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(summary)
+	} else {
+		err = writeReplaySummary(out, summary)
+	}
+	if err != nil {
+		return err
+	}
+
+	if summary.Failed() {
+		return fmt.Errorf("%w: %d mismatches, %d errors, %d balances", errReplayMismatch,
+			summary.Mismatches, summary.Errors, len(summary.Balances))
+	}
+	return nil
+}
+
+// openInput opens a file, or stdin for "-".
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// writeReplayFailure writes a request that failed or did not match.
+func writeReplayFailure(out io.Writer, result replay.Result) {
+	if result.Error != "" {
+		fmt.Fprintf(out, "line %d: %s %s: %s\n", result.Line, result.Method, result.Path, result.Error)
+		return
+	}
+	for _, diff := range result.Diffs {
+		fmt.Fprintf(out, "line %d: %s %s: %s\n", result.Line, result.Method, result.Path, diff)
+	}
+}
+
+// writeReplaySummary writes a human-readable replay report.
+func writeReplaySummary(out io.Writer, summary *replay.Summary) error {
+	fmt.Fprintf(out, "Replayed %d requests in %v (%.1f req/s): %d mismatches, %d errors\n",
+		summary.Requests, summary.Duration.Round(time.Millisecond), summary.Rate, summary.Mismatches, summary.Errors)
+	fmt.Fprintf(out, "Latency p50=%v p95=%v p99=%v max=%v\n",
+		summary.P50.Round(time.Microsecond), summary.P95.Round(time.Microsecond),
+		summary.P99.Round(time.Microsecond), summary.Max.Round(time.Microsecond))
+	if len(summary.Balances) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(out, "%d wallet balances do not match\n", len(summary.Balances))
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "WALLET\tRECORDED\tBALANCE\tEXPECTED\tERROR")
+	for _, b := range summary.Balances {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", b.Id, b.Recorded, b.Balance, b.Expected, b.Error)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"JavaCode/config"
+	"JavaCode/internal/replay"
+	"JavaCode/internal/routes"
+	"bytes"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunReplay_RequiresTarget(t *testing.T) {
+	var out bytes.Buffer
+	err := runReplay(&config.Config{}, []string{"requests.jsonl"}, &out)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "--in-process")
+	}
+
+	err = runReplay(&config.Config{}, []string{"--target", "http://localhost:8080", "--in-process", "requests.jsonl"}, &out)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "--in-process")
+	}
+}
+
+// TestReplay_StaleRateInProcessAndOverHTTP checks that a transfer on an
+// open-ended rate past the maximum age is rejected the same way whether the
+// requests are replayed in process or against a server.
+func TestReplay_StaleRateInProcessAndOverHTTP(t *testing.T) {
+	const (
+		fromID = "f4c863ec-0300-495d-852d-c115e197390b"
+		toID   = "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"
+	)
+	const recording = `{"method":"POST","path":"/api/v1/transfers","body":{"fromWalletId":"` + fromID + `","toWalletId":"` + toID +
+		`","amount":1000,"currency":"EUR"},"expect":{"status":422,"body":{"error":"stale_rate"}}}` + "\n"
+
+	gin.SetMode(gin.TestMode)
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	walletColumns := []string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}
+	expectTransfer := func() {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+			WithArgs(toID).
+			WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(toID, 0, "USD", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+			WithArgs(fromID).
+			WillReturnRows(sqlmock.NewRows(walletColumns).AddRow(fromID, 5000, "EUR", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+		// The rate has no validTo and took effect two days ago.
+		mock.ExpectQuery("SELECT .* FROM exchange_rates").
+			WithArgs("EUR", "USD", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "base_currency", "quote_currency", "rate_units", "precision",
+				"rounding_mode", "valid_from", "valid_to", "created_at"}).
+				AddRow(7, "EUR", "USD", 10834, 4, "HALF_EVEN", time.Now().Add(-48*time.Hour), nil, time.Now()))
+		mock.ExpectRollback()
+	}
+
+	cfg := &config.Config{Rates: config.Rates{MaxAge: 24 * time.Hour}}
+	router := routes.SetupRouter(newController(cfg, db))
+
+	expectTransfer()
+	inProcess, err := replay.New(replay.Handler{Handler: router}, replay.Options{Concurrency: 1, Timeout: time.Second}).
+		Run(context.Background(), strings.NewReader(recording))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, inProcess.Mismatches+inProcess.Errors, "in process: %+v", inProcess.Failures)
+	}
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+	expectTransfer()
+	overHTTP, err := replay.New(http.DefaultClient, replay.Options{BaseURL: server.URL, Concurrency: 1, Timeout: time.Second}).
+		Run(context.Background(), strings.NewReader(recording))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, overHTTP.Mismatches+overHTTP.Errors, "over HTTP: %+v", overHTTP.Failures)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
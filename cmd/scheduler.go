@@ -0,0 +1,76 @@
+package main
+
+import (
+	"JavaCode/config"
+	"JavaCode/internal/cache"
+	"JavaCode/internal/repositories"
+	"JavaCode/internal/service"
+	"JavaCode/utils"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// startScheduler runs due scheduled operations in the background.
+//
+// Replicas elect a leader with a session-scoped advisory lock held on a
+// dedicated connection: every interval, an instance without the lock tries
+// to take it, and the leader checks its connection and drains the due
+// operations a batch at a time. If the connection breaks, the lock is
+// released by Postgres and another instance takes over. Due operations are
+// leased before they run, and per-occurrence references keep an occurrence
+// from being executed twice when a lease expires or leadership changes hands.
+func startScheduler(dbConn *sql.DB, balances *cache.Balances, cfg config.Scheduler) {
+	go func() {
+		ctx := context.Background()
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		var leader *sql.Conn
+		for range ticker.C {
+			if leader == nil {
+				if leader = lockLeader(ctx, dbConn, "scheduler", repositories.TryLockScheduler); leader == nil {
+					continue
+				}
+				utils.Logger.Info("Scheduler lock acquired, running scheduled operations")
+			} else if err := leader.PingContext(ctx); err != nil {
+				utils.Logger.WithError(err).Warn("scheduler lost its lock connection")
+				_ = leader.Close()
+				leader = nil
+				continue
+			}
+
+			for {
+				_, attempted, err := service.RunScheduledOperationsService(ctx, dbConn, balances, cfg.BatchSize, cfg.MaxBackoff)
+				if err != nil {
+					utils.Logger.WithError(err).Warn("scheduled operations run failed")
+					break
+				}
+				if attempted < cfg.BatchSize {
+					break
+				}
+			}
+		}
+	}()
+}
+
+// lockLeader takes the session-scoped lock of a background job with
+// tryLock on a dedicated connection. It returns the connection holding the
+// lock, or nil if another instance holds it or the attempt failed.
+func lockLeader(ctx context.Context, dbConn *sql.DB, job string,
+	tryLock func(context.Context, *sql.Conn) (bool, error)) *sql.Conn {
+	conn, err := dbConn.Conn(ctx)
+	if err != nil {
+		utils.Logger.WithError(err).Warnf("%s lock connection failed", job)
+		return nil
+	}
+	locked, err := tryLock(ctx, conn)
+	if err != nil {
+		utils.Logger.WithError(err).Warnf("%s lock failed", job)
+	}
+	if !locked {
+		_ = conn.Close()
+		return nil
+	}
+	return conn
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"JavaCode/internal/service"
+	"JavaCode/utils"
+	"database/sql"
+	"time"
+)
+
+// startSnapshotJob snapshots changed wallet balances every interval in the
+// background, keeping point-in-time balance queries fast.
+func startSnapshotJob(dbConn *sql.DB, interval, lag time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			taken, err := service.TakeBalanceSnapshotsService(dbConn, lag)
+			if err != nil {
+				utils.Logger.WithError(err).Error("balance snapshot failed")
+				continue
+			}
+			utils.Logger.Infof("Took %d balance snapshots", taken)
+		}
+	}()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"JavaCode/config"
+	"JavaCode/internal/stream"
+	"JavaCode/utils"
+	"context"
+	"github.com/lib/pq"
+	"time"
+)
+
+// startStreamHub LISTENs for wallet event notifications and returns the
+// hub that wakes the balance streams of this instance.
+func startStreamHub(cfg *config.Config) (*stream.Hub, error) {
+	listener := pq.NewListener(cfg.Db.DSN(), time.Second, time.Minute,
+		func(event pq.ListenerEventType, err error) {
+			switch event {
+			case pq.ListenerEventDisconnected:
+				utils.Logger.WithError(err).Warn("stream listener disconnected")
+			case pq.ListenerEventReconnected:
+				utils.Logger.Info("stream listener reconnected")
+			case pq.ListenerEventConnectionAttemptFailed:
+				utils.Logger.WithError(err).Warn("stream listener connection failed")
+			}
+		})
+	if err := listener.Listen(stream.Channel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	hub := stream.NewHub()
+	go hub.Run(context.Background(), listener)
+	return hub, nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"JavaCode/internal/controllers"
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/pkg/walletclient"
+	"context"
+	"database/sql"
+)
+
+// backend performs the commands, either through the service layer with a
+// direct database connection or through the REST API. Both apply the same
+// checks.
+type backend interface {
+	CreateWallet(ctx context.Context, request models.CreateWalletRequest) (*models.BalanceResponse, error)
+	GetWallet(ctx context.Context, walletID string) (*models.BalanceResponse, error)
+	ListWallets(ctx context.Context, request walletclient.ListWalletsRequest) (*models.WalletListResponse, error)
+	// Inspect returns the latest ledger entries, the ledger check and the
+	// status history of a wallet.
+	Inspect(ctx context.Context, walletID string, entries int) (*inspection, error)
+	FreezeWallet(ctx context.Context, walletID, reason string, blockDeposits bool) (*models.BalanceResponse, error)
+	UnfreezeWallet(ctx context.Context, walletID, reason string) (*models.BalanceResponse, error)
+	// ApplyOperation deposits or withdraws and returns the wallet afterwards.
+	ApplyOperation(ctx context.Context, request models.WalletOperationRequest) (*models.BalanceResponse, error)
+}
+
+// inspection is the output of the "inspect" command.
+type inspection struct {
+	Wallet        *models.BalanceResponse      `json:"wallet"`
+	Verification  *models.BalanceVerification  `json:"verification"`
+	Entries       []models.LedgerEntryResponse `json:"entries"`
+	StatusChanges []models.WalletStatusChange  `json:"statusChanges"`
+}
+
+// dbBackend calls the service layer on the primary database. It has no
+// balance cache, so API instances with BALANCE_CACHE_SIZE set may serve the
+// old balance until BALANCE_CACHE_TTL expires.
+type dbBackend struct {
+	db *sql.DB
+}
+
+func (b *dbBackend) CreateWallet(_ context.Context, request models.CreateWalletRequest) (*models.BalanceResponse, error) {
+	if err := service.ValidateCurrency(request.Currency); err != nil {
+		return nil, err
+	}
+	wallet, err := service.CreateWalletService(b.db, request)
+	if err != nil {
+		return nil, err
+	}
+	return balanceResponse(wallet), nil
+}
+
+func (b *dbBackend) GetWallet(_ context.Context, walletID string) (*models.BalanceResponse, error) {
+	if err := controllers.ValidateUUID(walletID); err != nil {
+		return nil, err
+	}
+	wallet, err := service.GetWalletsService(b.db, walletID)
+	if err != nil {
+		return nil, err
+	}
+	return balanceResponse(wallet), nil
+}
+
+func (b *dbBackend) ListWallets(_ context.Context, request walletclient.ListWalletsRequest) (*models.WalletListResponse, error) {
+	page, err := service.ListWalletsService(b.db, models.WalletFilter{
+		Owner:       request.Owner,
+		Name:        request.Name,
+		Labels:      request.Labels,
+		Status:      request.Status,
+		Currency:    request.Currency,
+		MinBalance:  request.MinBalance,
+		MaxBalance:  request.MaxBalance,
+		CreatedFrom: request.CreatedFrom,
+		CreatedTo:   request.CreatedTo,
+		Sort:        request.Sort,
+		Desc:        request.Desc,
+		Limit:       request.Limit,
+	}, request.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	response := &models.WalletListResponse{
+		Wallets:    make([]models.BalanceResponse, 0, len(page.Wallets)),
+		NextCursor: page.NextCursor,
+	}
+	for i := range page.Wallets {
+		response.Wallets = append(response.Wallets, controllers.NewBalanceResponse(&page.Wallets[i]))
+	}
+	return response, nil
+}
+
+func (b *dbBackend) Inspect(ctx context.Context, walletID string, entries int) (*inspection, error) {
+	wallet, err := b.GetWallet(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	result := &inspection{Wallet: wallet, Entries: []models.LedgerEntryResponse{}}
+	if result.Verification, err = service.VerifyWalletBalanceService(b.db, walletID); err != nil {
+		return nil, err
+	}
+	ledger, err := service.ListLedgerEntriesService(b.db, walletID, "", entries)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ledger {
+		result.Entries = append(result.Entries, controllers.NewLedgerEntryResponse(&ledger[i]))
+	}
+	if result.StatusChanges, err = service.ListWalletStatusChangesService(b.db, walletID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (b *dbBackend) FreezeWallet(_ context.Context, walletID, reason string, blockDeposits bool) (*models.BalanceResponse, error) {
+	if err := controllers.ValidateUUID(walletID); err != nil {
+		return nil, err
+	}
+	wallet, err := service.FreezeWalletService(b.db, nil, walletID, reason, blockDeposits)
+	if err != nil {
+		return nil, err
+	}
+	return balanceResponse(wallet), nil
+}
+
+func (b *dbBackend) UnfreezeWallet(_ context.Context, walletID, reason string) (*models.BalanceResponse, error) {
+	if err := controllers.ValidateUUID(walletID); err != nil {
+		return nil, err
+	}
+	wallet, err := service.UnfreezeWalletService(b.db, nil, walletID, reason)
+	if err != nil {
+		return nil, err
+	}
+	return balanceResponse(wallet), nil
+}
+
+func (b *dbBackend) ApplyOperation(_ context.Context, request models.WalletOperationRequest) (*models.BalanceResponse, error) {
+	if err := service.ValidateOperationRequest(request); err != nil {
+		return nil, err
+	}
+	if err := service.HandleOperationService(b.db, nil, request); err != nil {
+		return nil, err
+	}
+	wallet, err := service.GetWalletsService(b.db, request.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	return balanceResponse(wallet), nil
+}
+
+func balanceResponse(wallet *models.Wallet) *models.BalanceResponse {
+	response := controllers.NewBalanceResponse(wallet)
+	return &response
+}
+
+// apiBackend calls the REST API.
+type apiBackend struct {
+	client *walletclient.Client
+}
+
+func (b *apiBackend) CreateWallet(ctx context.Context, request models.CreateWalletRequest) (*models.BalanceResponse, error) {
+	return b.client.CreateWallet(ctx, request)
+}
+
+func (b *apiBackend) GetWallet(ctx context.Context, walletID string) (*models.BalanceResponse, error) {
+	return b.client.GetBalance(ctx, walletID, walletclient.ReadStrong())
+}
+
+func (b *apiBackend) ListWallets(ctx context.Context, request walletclient.ListWalletsRequest) (*models.WalletListResponse, error) {
+	return b.client.ListWallets(ctx, request)
+}
+
+func (b *apiBackend) Inspect(ctx context.Context, walletID string, entries int) (*inspection, error) {
+	wallet, err := b.GetWallet(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	result := &inspection{Wallet: wallet}
+	if result.Verification, err = b.client.VerifyWalletBalance(ctx, walletID); err != nil {
+		return nil, err
+	}
+	if result.Entries, err = b.client.ListLedgerEntries(ctx, walletID, "", entries); err != nil {
+		return nil, err
+	}
+	if result.StatusChanges, err = b.client.ListWalletStatusChanges(ctx, walletID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (b *apiBackend) FreezeWallet(ctx context.Context, walletID, reason string, blockDeposits bool) (*models.BalanceResponse, error) {
+	return b.client.FreezeWallet(ctx, walletID, models.FreezeWalletRequest{Reason: reason, BlockDeposits: blockDeposits})
+}
+
+func (b *apiBackend) UnfreezeWallet(ctx context.Context, walletID, reason string) (*models.BalanceResponse, error) {
+	return b.client.UnfreezeWallet(ctx, walletID, reason)
+}
+
+func (b *apiBackend) ApplyOperation(ctx context.Context, request models.WalletOperationRequest) (*models.BalanceResponse, error) {
+	result, err := b.client.ApplyOperation(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return b.client.GetBalance(ctx, request.WalletID, walletclient.ReadAfter(result.ConsistencyToken))
+}
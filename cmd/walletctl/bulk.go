@@ -0,0 +1,259 @@
+package main
+
+import (
+	"JavaCode/internal/bulk"
+	"JavaCode/internal/controllers"
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/utils"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxListedFailures caps the failed rows listed in an import summary; a
+// report file receives all of them.
+const maxListedFailures = 100
+
+// exportSummary is the output of an export to a file.
+type exportSummary struct {
+	Kind    string `json:"kind"`
+	Records int    `json:"records"`
+	Output  string `json:"output"`
+}
+
+// importSummary is the output of an import.
+type importSummary struct {
+	Rows     int  `json:"rows"`
+	Imported int  `json:"imported"`
+	Failed   int  `json:"failed"`
+	DryRun   bool `json:"dryRun"`
+	// Failures lists the first failed rows when there is no report file.
+	Failures []models.WalletImportResult `json:"failures,omitempty"`
+}
+
+// errNeedsDatabase is returned by the commands that only work on the database.
+var errNeedsDatabase = errors.New("this command needs a database connection; run it without --api")
+
+// runExport writes all wallets or all their ledger entries, from one
+// snapshot, to a file or to out.
+func runExport(ctx context.Context, b backend, args []string, out io.Writer) (any, error) {
+	fs := newFlagSet("export")
+	csvFormat := fs.Bool("csv", false, "write CSV instead of JSON Lines")
+	output := fs.String("output", "-", "file to write, - for standard output")
+	kind, err := parseArgFlags(fs, args, "of wallets or entries")
+	if err != nil {
+		return nil, err
+	}
+	if kind != "wallets" && kind != "entries" {
+		return nil, errUsage
+	}
+	db, ok := b.(*dbBackend)
+	if !ok {
+		return nil, errNeedsDatabase
+	}
+
+	dest := out
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		dest = file
+	}
+	writer, err := bulk.NewWriter(dest, fileFormat(*csvFormat, *output))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &exportSummary{Kind: kind, Output: *output}
+	if kind == "wallets" {
+		err = service.ExportWalletsService(ctx, db.db, func(wallet *models.Wallet) error {
+			summary.Records++
+			record := bulk.NewWalletExport(wallet)
+			return writer.WriteWallet(&record)
+		})
+	} else {
+		err = service.ExportLedgerEntriesService(ctx, db.db, func(entry *models.LedgerEntry) error {
+			summary.Records++
+			record := controllers.NewLedgerEntryResponse(entry)
+			return writer.WriteLedgerEntry(&record)
+		})
+	}
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if *output == "-" {
+		return nil, nil
+	}
+	return summary, nil
+}
+
+// runImport creates the wallets of an import file with their opening
+// balances, one transaction per row. Rows that fail are reported and
+// skipped; with --dry-run every row is checked and rolled back.
+func runImport(ctx context.Context, b backend, args []string, _ io.Writer) (any, error) {
+	fs := newFlagSet("import")
+	csvFormat := fs.Bool("csv", false, "read CSV; the default is JSON Lines, or CSV for .csv files")
+	dryRun := fs.Bool("dry-run", false, "check every row against the database without importing")
+	reportFile := fs.String("report", "", "file receiving the outcome of every row, in the format of the input")
+	path, err := parseArgFlags(fs, args, "file")
+	if err != nil {
+		return nil, err
+	}
+	db, ok := b.(*dbBackend)
+	if !ok {
+		return nil, errNeedsDatabase
+	}
+
+	format := fileFormat(*csvFormat, path)
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		input = file
+	}
+	reader, err := bulk.NewWalletReader(input, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var report *bulk.Writer
+	if *reportFile != "" {
+		file, err := os.Create(*reportFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		if report, err = bulk.NewWriter(file, format); err != nil {
+			return nil, err
+		}
+	}
+
+	source := filepath.Base(path)
+	if path == "-" {
+		source = "stdin"
+	}
+	summary := &importSummary{DryRun: *dryRun}
+	err = importRows(ctx, db, reader, source, *dryRun, func(result *models.WalletImportResult) error {
+		summary.Rows++
+		switch result.Status {
+		case models.ImportFailed:
+			summary.Failed++
+			if report == nil && len(summary.Failures) < maxListedFailures {
+				summary.Failures = append(summary.Failures, *result)
+			}
+		default:
+			summary.Imported++
+		}
+		if report != nil {
+			return report.WriteImportResult(result)
+		}
+		return nil
+	})
+	if report != nil {
+		if flushErr := report.Flush(); err == nil {
+			err = flushErr
+		}
+	}
+	if err == nil && summary.Failed > 0 {
+		err = fmt.Errorf("%d of %d rows failed", summary.Failed, summary.Rows)
+	}
+	return summary, err
+}
+
+// importRows imports the rows of reader and passes the outcome of each to
+// result. It stops at the first error that is not the row's own.
+//
+// A row repeating the id of a wallet imported, or found valid, on an earlier
+// row fails as an existing wallet. The database would reject it on a real
+// import, but a dry run rolls every row back, so it is caught here in both.
+func importRows(ctx context.Context, db *dbBackend, reader *bulk.WalletReader, file string, dryRun bool,
+	result func(*models.WalletImportResult) error) error {
+	imported := map[string]int{}
+	for {
+		record, line, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var rowErr *bulk.RowError
+		if err != nil && !errors.As(err, &rowErr) {
+			return err
+		}
+
+		outcome := &models.WalletImportResult{Line: line}
+		if rowErr != nil {
+			outcome.Status = models.ImportFailed
+			outcome.Error = utils.NewErrorResponse(utils.ErrInvalidRequest).Error
+			outcome.Message = rowErr.Err.Error()
+		} else if first, ok := imported[walletID(record.Id)]; ok {
+			outcome.Status = models.ImportFailed
+			outcome.Error = utils.NewErrorResponse(utils.ErrWalletExists).Error
+			outcome.Message = fmt.Sprintf("%v: imported on line %d", utils.ErrWalletExists, first)
+			outcome.Id = strings.TrimSpace(record.Id)
+		} else {
+			record.Metadata = importMetadata(record.Metadata, file)
+			wallet, err := service.ImportWalletService(ctx, db.db, record, dryRun)
+			switch {
+			case ctx.Err() != nil:
+				return ctx.Err()
+			case err != nil:
+				outcome.Status = models.ImportFailed
+				outcome.Error = utils.NewErrorResponse(err).Error
+				outcome.Message = err.Error()
+				outcome.Id = strings.TrimSpace(record.Id)
+			default:
+				outcome.Status = models.ImportCreated
+				if dryRun {
+					outcome.Status = models.ImportValid
+				}
+				outcome.Id = wallet.Id
+				imported[wallet.Id] = line
+			}
+		}
+		if err := result(outcome); err != nil {
+			return err
+		}
+	}
+}
+
+// walletID returns the canonical form of a wallet id given in an import
+// file, or "" if there is none or it is not a valid UUID.
+func walletID(id string) string {
+	parsed, err := uuid.Parse(strings.TrimSpace(id))
+	if err != nil {
+		return ""
+	}
+	return parsed.String()
+}
+
+// importMetadata adds the source of an import to the metadata of the
+// opening-balance transaction, keeping the values given in the file.
+func importMetadata(metadata map[string]string, file string) map[string]string {
+	merged := map[string]string{"source": "walletctl import", "operator": operator(), "file": file}
+	for key, value := range metadata {
+		merged[key] = value
+	}
+	return merged
+}
+
+// fileFormat returns CSV if requested or if the file is a .csv file, and
+// JSON Lines otherwise.
+func fileFormat(csvFormat bool, path string) string {
+	if csvFormat || strings.EqualFold(filepath.Ext(path), ".csv") {
+		return bulk.CSV
+	}
+	return bulk.JSONL
+}
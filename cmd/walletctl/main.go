@@ -0,0 +1,415 @@
+// Command walletctl is an administration tool for wallets.
+//
+// It creates, inspects, lists, freezes and unfreezes wallets and credits or
+// debits them with a mandatory reason, either through the service layer on
+// a direct database connection or through the REST API (--api). Either way
+// every operation goes through the same checks as the API and is recorded
+// in the ledger, so there is no need to edit the wallets table by hand.
+package main
+
+import (
+	"JavaCode/config"
+	"JavaCode/internal/models"
+	"JavaCode/pkg/currency"
+	"JavaCode/pkg/db"
+	"JavaCode/pkg/money"
+	"JavaCode/pkg/walletclient"
+	"JavaCode/utils"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const usage = `Usage: walletctl [--api URL] [--config FILE] [--format table|json] [--timeout 30s] command [flags]
+
+Commands:
+  create --currency CODE [--owner ID] [--name NAME] [--label key=value]...
+  get WALLET
+  inspect WALLET [--entries 20]   wallet, ledger check, latest entries and status history
+  list [--owner ID] [--name TEXT] [--status STATUS] [--currency CODE] [--label key=value]...
+       [--min-balance N] [--max-balance N] [--sort [-]createdAt|balance] [--limit N] [--cursor C]
+  freeze WALLET --reason TEXT [--block-deposits]
+  unfreeze WALLET --reason TEXT
+  credit WALLET --amount 12.34 --reason TEXT [--currency CODE] [--reference REF]
+  debit WALLET --amount 12.34 --reason TEXT [--currency CODE] [--reference REF]
+  export wallets|entries [--csv] [--output FILE]
+                                  all wallets, or the ledger entries of all wallets
+  import FILE [--csv] [--dry-run] [--report FILE]
+                                  create wallets with opening balances; - reads stdin
+
+Without --api (or WALLETCTL_API_URL) walletctl connects to the database
+configured as for wallet-app: config.env, environment variables and --config.
+export and import always need the database.`
+
+// errUsage is returned for malformed command lines.
+var errUsage = errors.New(usage)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdout)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		fmt.Fprintln(os.Stderr, usage)
+	case errors.Is(err, errUsage):
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "walletctl:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes a walletctl command line and writes its output to out.
+func run(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("walletctl", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	apiURL := fs.String("api", os.Getenv("WALLETCTL_API_URL"), "base URL of the wallet API; empty uses the database")
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "wallet-app configuration file for database access")
+	format := fs.String("format", "table", "output format: table or json")
+	timeout := fs.Duration("timeout", 30*time.Second, "time limit of the command; imports and exports have none by default")
+	verbose := fs.Bool("verbose", false, "log service layer messages to stderr")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%v\n\n%w", err, errUsage)
+	}
+	if fs.NArg() == 0 || (*format != "table" && *format != "json") {
+		return errUsage
+	}
+	command, args := fs.Arg(0), fs.Args()[1:]
+
+	utils.Logger.SetOutput(io.Discard)
+	if *verbose {
+		utils.Logger.SetOutput(os.Stderr)
+	}
+
+	handler, ok := commands[command]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n\n%w", command, errUsage)
+	}
+
+	// Imports and exports take as long as their files unless limited explicitly.
+	timeoutSet := false
+	fs.Visit(func(f *flag.Flag) { timeoutSet = timeoutSet || f.Name == "timeout" })
+	if *timeout > 0 && (timeoutSet || (command != "import" && command != "export")) {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	backend, closeBackend, err := newBackend(*apiURL, *configFile)
+	if err != nil {
+		return err
+	}
+	defer closeBackend()
+
+	result, err := handler(ctx, backend, args, out)
+	if result != nil {
+		if writeErr := write(out, *format, result); err == nil {
+			err = writeErr
+		}
+	}
+	return err
+}
+
+// newBackend returns the API backend if apiURL is set, the database backend
+// otherwise, and a function releasing it.
+func newBackend(apiURL, configFile string) (backend, func(), error) {
+	if apiURL != "" {
+		client, err := walletclient.New(apiURL, walletclient.WithUserAgent("walletctl"))
+		if err != nil {
+			return nil, nil, err
+		}
+		return &apiBackend{client: client}, func() {}, nil
+	}
+
+	var args []string
+	if configFile != "" {
+		args = []string{"--config", configFile}
+	}
+	cfg, _, err := config.Load(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg.Db.ApplicationName = "walletctl"
+	pool := cfg.Db.Pool()
+	pool.MaxOpenConns, pool.MaxIdleConns = 2, 2
+	dbConn, err := db.Connect(cfg.Db.DSN(), cfg.Db.Driver, pool, db.RetryOptions{Attempts: 1})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to the database: %w", err)
+	}
+	return &dbBackend{db: dbConn}, func() { _ = dbConn.Close() }, nil
+}
+
+// command runs a command with its arguments and returns the value to print.
+// Commands that stream their output write it to out and return nil.
+type command func(ctx context.Context, b backend, args []string, out io.Writer) (any, error)
+
+var commands = map[string]command{
+	"create":   runCreate,
+	"get":      runGet,
+	"inspect":  runInspect,
+	"list":     runList,
+	"freeze":   runFreeze,
+	"unfreeze": runUnfreeze,
+	"credit": func(ctx context.Context, b backend, args []string, _ io.Writer) (any, error) {
+		return runOperation(ctx, b, "credit", models.LedgerDeposit, args)
+	},
+	"debit": func(ctx context.Context, b backend, args []string, _ io.Writer) (any, error) {
+		return runOperation(ctx, b, "debit", models.LedgerWithdraw, args)
+	},
+	"export": runExport,
+	"import": runImport,
+}
+
+func runCreate(ctx context.Context, b backend, args []string, _ io.Writer) (any, error) {
+	fs := newFlagSet("create")
+	request := models.CreateWalletRequest{}
+	fs.StringVar(&request.Currency, "currency", "", "ISO 4217 code of the wallet")
+	fs.StringVar(&request.Owner, "owner", "", "owner id")
+	fs.StringVar(&request.Name, "name", "", "display name")
+	labels := labelsFlag{}
+	fs.Var(labels, "label", "key=value label, may be repeated")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 || request.Currency == "" {
+		return nil, errUsage
+	}
+	if len(labels) > 0 {
+		request.Labels = labels
+	}
+	return b.CreateWallet(ctx, request)
+}
+
+func runGet(ctx context.Context, b backend, args []string, _ io.Writer) (any, error) {
+	walletID, err := parseWalletFlags(newFlagSet("get"), args)
+	if err != nil {
+		return nil, err
+	}
+	return b.GetWallet(ctx, walletID)
+}
+
+func runInspect(ctx context.Context, b backend, args []string, _ io.Writer) (any, error) {
+	fs := newFlagSet("inspect")
+	entries := fs.Int("entries", 20, "number of latest ledger entries")
+	walletID, err := parseWalletFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if *entries <= 0 {
+		return nil, errUsage
+	}
+	return b.Inspect(ctx, walletID, *entries)
+}
+
+func runList(ctx context.Context, b backend, args []string, _ io.Writer) (any, error) {
+	fs := newFlagSet("list")
+	request := walletclient.ListWalletsRequest{}
+	fs.StringVar(&request.Owner, "owner", "", "owner id")
+	fs.StringVar(&request.Name, "name", "", "part of the name")
+	fs.StringVar(&request.Status, "status", "", "ACTIVE, FROZEN or CLOSED")
+	fs.StringVar(&request.Currency, "currency", "", "ISO 4217 code")
+	fs.StringVar(&request.Cursor, "cursor", "", "cursor of the next page")
+	fs.IntVar(&request.Limit, "limit", 0, "page size")
+	labels := labelsFlag{}
+	fs.Var(labels, "label", "key=value label, may be repeated")
+	minBalance := fs.String("min-balance", "", "minimum balance in minor units")
+	maxBalance := fs.String("max-balance", "", "maximum balance in minor units")
+	sort := fs.String("sort", "", "createdAt or balance, - for descending")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 || request.Limit < 0 {
+		return nil, errUsage
+	}
+
+	request.Status = strings.ToUpper(request.Status)
+	request.Currency = currency.Normalize(request.Currency)
+	request.Desc = strings.HasPrefix(*sort, "-")
+	request.Sort = strings.TrimPrefix(*sort, "-")
+	if len(labels) > 0 {
+		request.Labels = labels
+	}
+	var err error
+	if request.MinBalance, err = parseBalance(*minBalance); err != nil {
+		return nil, err
+	}
+	if request.MaxBalance, err = parseBalance(*maxBalance); err != nil {
+		return nil, err
+	}
+	return b.ListWallets(ctx, request)
+}
+
+func runFreeze(ctx context.Context, b backend, args []string, _ io.Writer) (any, error) {
+	fs := newFlagSet("freeze")
+	reason := fs.String("reason", "", "reason recorded in the status history (required)")
+	blockDeposits := fs.Bool("block-deposits", false, "refuse deposits as well as withdrawals")
+	walletID, err := parseWalletFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireReason(*reason); err != nil {
+		return nil, err
+	}
+	return b.FreezeWallet(ctx, walletID, strings.TrimSpace(*reason), *blockDeposits)
+}
+
+func runUnfreeze(ctx context.Context, b backend, args []string, _ io.Writer) (any, error) {
+	fs := newFlagSet("unfreeze")
+	reason := fs.String("reason", "", "reason recorded in the status history (required)")
+	walletID, err := parseWalletFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireReason(*reason); err != nil {
+		return nil, err
+	}
+	return b.UnfreezeWallet(ctx, walletID, strings.TrimSpace(*reason))
+}
+
+// runOperation credits or debits a wallet. The reason becomes the
+// description of the ledger transaction, and the operator and tool are
+// recorded in its metadata.
+func runOperation(ctx context.Context, b backend, name, operationType string, args []string) (any, error) {
+	fs := newFlagSet(name)
+	amount := fs.String("amount", "", "decimal amount, e.g. 12.34")
+	code := fs.String("currency", "", "ISO 4217 code; defaults to the wallet currency")
+	reason := fs.String("reason", "", "reason recorded with the ledger transaction (required)")
+	reference := fs.String("reference", "", "operation reference, unique per wallet; generated if empty")
+	walletID, err := parseWalletFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if *amount == "" {
+		return nil, errUsage
+	}
+	if err := requireReason(*reason); err != nil {
+		return nil, err
+	}
+
+	if *code == "" {
+		wallet, err := b.GetWallet(ctx, walletID)
+		if err != nil {
+			return nil, err
+		}
+		*code = wallet.Currency
+	}
+	value, err := money.ParseAmount(*amount, *code)
+	if err != nil {
+		return nil, err
+	}
+	if !value.IsPositive() {
+		return nil, fmt.Errorf("amount must be positive, got %s", value)
+	}
+	if *reference == "" {
+		*reference = "walletctl-" + uuid.NewString()
+	}
+
+	return b.ApplyOperation(ctx, models.WalletOperationRequest{
+		WalletID:      walletID,
+		OperationType: operationType,
+		Amount:        value.Units,
+		Currency:      value.Currency,
+		OperationDetails: models.OperationDetails{
+			Description: strings.TrimSpace(*reason),
+			Reference:   *reference,
+			Metadata:    map[string]string{"source": "walletctl", "operator": operator()},
+		},
+	})
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%s: %v\n\n%w", fs.Name(), err, errUsage)
+	}
+	return nil
+}
+
+// parseWalletFlags parses the flags of a command that takes a wallet id.
+func parseWalletFlags(fs *flag.FlagSet, args []string) (string, error) {
+	return parseArgFlags(fs, args, "wallet id")
+}
+
+// parseArgFlags parses the flags of a command that takes one argument,
+// which may come before or after the flags.
+func parseArgFlags(fs *flag.FlagSet, args []string, name string) (string, error) {
+	var arg string
+	if len(args) > 0 && (args[0] == "-" || !strings.HasPrefix(args[0], "-")) {
+		arg, args = args[0], args[1:]
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return "", err
+	}
+	if arg == "" && fs.NArg() == 1 {
+		return fs.Arg(0), nil
+	}
+	if arg == "" || fs.NArg() > 0 {
+		return "", fmt.Errorf("%s: expected one %s\n\n%w", fs.Name(), name, errUsage)
+	}
+	return arg, nil
+}
+
+func requireReason(reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("--reason is required")
+	}
+	return nil
+}
+
+func parseBalance(raw string) (*uint64, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseUint(raw, 10, 63)
+	if err != nil {
+		return nil, fmt.Errorf("invalid balance %q", raw)
+	}
+	return &value, nil
+}
+
+// operator names the person running the command, for the ledger metadata.
+func operator() string {
+	for _, name := range []string{"WALLETCTL_OPERATOR", "USER", "USERNAME"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return "unknown"
+}
+
+// labelsFlag collects repeated key=value flags.
+type labelsFlag map[string]string
+
+func (l labelsFlag) String() string {
+	return ""
+}
+
+func (l labelsFlag) Set(value string) error {
+	key, label, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("label %q is not key=value", value)
+	}
+	l[key] = label
+	return nil
+}
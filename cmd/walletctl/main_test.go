@@ -0,0 +1,151 @@
+package main
+
+import (
+	"JavaCode/internal/bulk"
+	"JavaCode/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testWalletID = "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+
+func TestRun_CreditThroughAPI(t *testing.T) {
+	t.Setenv("WALLETCTL_OPERATOR", "alice")
+	balance := uint64(500)
+	var operation models.WalletOperationRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/wallets/"+testWalletID:
+			_ = json.NewEncoder(w).Encode(models.BalanceResponse{
+				Uuid: testWalletID, Balance: balance, Currency: "RUB", Exponent: 2, Status: models.WalletActive,
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/wallet":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&operation))
+			balance += uint64(operation.Amount)
+			_, _ = w.Write([]byte(`{"message":"operation successful"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"--api", server.URL, "credit", testWalletID,
+		"--amount", "12.34", "--reason", "compensation for ticket #81"}, &out)
+	assert.NoError(t, err)
+
+	assert.Equal(t, models.LedgerDeposit, operation.OperationType)
+	assert.Equal(t, int64(1234), operation.Amount)
+	assert.Equal(t, "RUB", operation.Currency)
+	assert.Equal(t, "compensation for ticket #81", operation.Description)
+	assert.Contains(t, operation.Reference, "walletctl-")
+	assert.Equal(t, map[string]string{"source": "walletctl", "operator": "alice"}, operation.Metadata)
+	assert.Contains(t, out.String(), "17.34 RUB")
+}
+
+func TestRun_ReasonRequired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL)
+	}))
+	defer server.Close()
+
+	for _, args := range [][]string{
+		{"debit", testWalletID, "--amount", "1"},
+		{"freeze", testWalletID, "--reason", "  "},
+		{"unfreeze", testWalletID},
+	} {
+		err := run(context.Background(), append([]string{"--api", server.URL}, args...), &bytes.Buffer{})
+		assert.EqualError(t, err, "--reason is required", args)
+	}
+}
+
+func TestRun_ListJSON(t *testing.T) {
+	page := models.WalletListResponse{
+		Wallets:    []models.BalanceResponse{{Uuid: testWalletID, Balance: 100, Currency: "EUR", Status: models.WalletFrozen}},
+		NextCursor: "abc",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "FROZEN", r.URL.Query().Get("status"))
+		assert.Equal(t, []string{"tier:gold"}, r.URL.Query()["label"])
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"--api", server.URL, "--format", "json",
+		"list", "--status", "frozen", "--label", "tier=gold"}, &out)
+	assert.NoError(t, err)
+
+	var got models.WalletListResponse
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	assert.Equal(t, page, got)
+}
+
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"--format", "yaml", "get", testWalletID},
+		{"--api", "http://localhost:8080", "drop"},
+		{"--api", "http://localhost:8080", "get"},
+		{"--api", "http://localhost:8080", "create"},
+	} {
+		err := run(context.Background(), args, &bytes.Buffer{})
+		assert.True(t, errors.Is(err, errUsage), args)
+	}
+}
+
+func TestRun_BulkNeedsDatabase(t *testing.T) {
+	for _, args := range [][]string{
+		{"export", "wallets"},
+		{"import", "wallets.jsonl", "--dry-run"},
+	} {
+		err := run(context.Background(), append([]string{"--api", "http://localhost:8080"}, args...), &bytes.Buffer{})
+		assert.ErrorIs(t, err, errNeedsDatabase, args)
+	}
+}
+
+func TestImportRows_DuplicateID(t *testing.T) {
+	for _, dryRun := range []bool{true, false} {
+		db, mock, _ := sqlmock.New()
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO wallets").
+			WithArgs(testWalletID, "EUR", "legacy-17", "", []byte("{}")).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+		if dryRun {
+			mock.ExpectRollback()
+		} else {
+			mock.ExpectCommit()
+		}
+
+		reader, err := bulk.NewWalletReader(strings.NewReader(
+			`{"id":"`+testWalletID+`","currency":"EUR","owner":"legacy-17"}`+"\n"+
+				`{"id":"`+strings.ToUpper(testWalletID)+`","currency":"EUR","owner":"legacy-18"}`+"\n"), bulk.JSONL)
+		if !assert.NoError(t, err) {
+			return
+		}
+		var results []models.WalletImportResult
+		err = importRows(context.Background(), &dbBackend{db: db}, reader, "wallets.jsonl", dryRun,
+			func(result *models.WalletImportResult) error {
+				results = append(results, *result)
+				return nil
+			})
+		assert.NoError(t, err)
+		if assert.Len(t, results, 2, "dry run %v", dryRun) {
+			assert.NotEqual(t, models.ImportFailed, results[0].Status)
+			assert.Equal(t, models.ImportFailed, results[1].Status)
+			assert.Equal(t, "wallet_exists", results[1].Error)
+			assert.Equal(t, 2, results[1].Line)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/pkg/money"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// write prints a command result as JSON or as a table.
+func write(out io.Writer, format string, result any) error {
+	if format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	switch result := result.(type) {
+	case *models.BalanceResponse:
+		writeWallets(w, []models.BalanceResponse{*result})
+	case *models.WalletListResponse:
+		writeWallets(w, result.Wallets)
+		if result.NextCursor != "" {
+			fmt.Fprintf(w, "\nNext page: --cursor %s\n", result.NextCursor)
+		}
+	case *inspection:
+		writeInspection(w, result)
+	case *exportSummary:
+		fmt.Fprintf(w, "Exported %d %s to %s\n", result.Records, result.Kind, result.Output)
+	case *importSummary:
+		writeImportSummary(w, result)
+	default:
+		return fmt.Errorf("cannot print %T as a table", result)
+	}
+	return w.Flush()
+}
+
+func writeWallets(w io.Writer, wallets []models.BalanceResponse) {
+	fmt.Fprintln(w, "WALLET\tBALANCE\tSTATUS\tOWNER\tNAME\tLABELS\tCREATED")
+	for _, wallet := range wallets {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", wallet.Uuid, formatBalance(wallet.Balance, wallet.Currency),
+			walletStatus(&wallet), wallet.Owner, wallet.Name, formatLabels(wallet.Labels),
+			wallet.CreatedAt.UTC().Format(time.RFC3339))
+	}
+}
+
+func writeInspection(w io.Writer, result *inspection) {
+	wallet := result.Wallet
+	fmt.Fprintf(w, "Wallet:\t%s\n", wallet.Uuid)
+	fmt.Fprintf(w, "Balance:\t%s\n", formatBalance(wallet.Balance, wallet.Currency))
+	fmt.Fprintf(w, "Status:\t%s\n", walletStatus(wallet))
+	fmt.Fprintf(w, "Owner:\t%s\n", wallet.Owner)
+	fmt.Fprintf(w, "Name:\t%s\n", wallet.Name)
+	fmt.Fprintf(w, "Labels:\t%s\n", formatLabels(wallet.Labels))
+	fmt.Fprintf(w, "Created:\t%s\n", wallet.CreatedAt.UTC().Format(time.RFC3339))
+	if v := result.Verification; v != nil {
+		state := "consistent"
+		if !v.Consistent {
+			state = "MISMATCH"
+		}
+		fmt.Fprintf(w, "Ledger:\t%s (ledger balance %s)\n", state, money.New(v.LedgerBalance, v.Currency))
+	}
+
+	fmt.Fprintf(w, "\nLatest ledger entries:\n")
+	fmt.Fprintln(w, "ID\tTIME\tTYPE\tDIRECTION\tAMOUNT\tREFERENCE\tDESCRIPTION")
+	for _, entry := range result.Entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.Id, entry.CreatedAt.UTC().Format(time.RFC3339),
+			entry.TransactionType, entry.Direction, money.New(entry.Amount, entry.Currency), entry.Reference, entry.Description)
+	}
+
+	fmt.Fprintf(w, "\nStatus history:\n")
+	fmt.Fprintln(w, "TIME\tFROM\tTO\tREASON")
+	for _, change := range result.StatusChanges {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.ChangedTime.UTC().Format(time.RFC3339),
+			change.FromStatus, change.ToStatus, change.Reason)
+	}
+}
+
+func writeImportSummary(w io.Writer, summary *importSummary) {
+	fmt.Fprintln(w, "ROWS\tIMPORTED\tFAILED\tDRY RUN")
+	fmt.Fprintf(w, "%d\t%d\t%d\t%v\n", summary.Rows, summary.Imported, summary.Failed, summary.DryRun)
+	if len(summary.Failures) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nFailed rows:\n")
+	fmt.Fprintln(w, "LINE\tID\tERROR\tMESSAGE")
+	for _, failure := range summary.Failures {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", failure.Line, failure.Id, failure.Error, failure.Message)
+	}
+	if summary.Failed > len(summary.Failures) {
+		fmt.Fprintf(w, "... and %d more; use --report to list every row\n", summary.Failed-len(summary.Failures))
+	}
+}
+
+// formatBalance formats a balance in its currency, e.g. "12.34 EUR".
+func formatBalance(balance uint64, code string) string {
+	amount, err := money.FromUnsigned(balance, code)
+	if err != nil {
+		return fmt.Sprintf("%d %s", balance, code)
+	}
+	return amount.String()
+}
+
+func walletStatus(wallet *models.BalanceResponse) string {
+	if wallet.DepositsBlocked {
+		return wallet.Status + " (deposits blocked)"
+	}
+	return wallet.Status
+}
+
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"JavaCode/config"
+	"JavaCode/internal/service"
+	"JavaCode/internal/webhooks"
+	"JavaCode/utils"
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+)
+
+// startWebhookWorker sends due webhook deliveries in the background,
+// polling every interval and draining the backlog a batch at a time.
+// A batch is leased for as long as sending all of it may take.
+func startWebhookWorker(dbConn *sql.DB, cfg config.Webhooks) {
+	sender := &webhooks.Sender{Client: &http.Client{Timeout: cfg.Timeout}}
+	lease := time.Duration(cfg.BatchSize)*cfg.Timeout + time.Minute
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			for {
+				_, attempted, err := service.DeliverWebhooksService(context.Background(), dbConn, sender,
+					cfg.BatchSize, cfg.MaxAttempts, cfg.MaxBackoff, lease)
+				if err != nil {
+					utils.Logger.WithError(err).Warn("webhook delivery failed")
+					break
+				}
+				if attempted < cfg.BatchSize {
+					break
+				}
+			}
+		}
+	}()
+}
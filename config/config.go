@@ -1,63 +1,207 @@
-// Package config provides application configuration loading
-// from environment variables (using config.env support via godotenv).
+// Package config provides layered application configuration loading.
 //
-// It defines the structure for application and database settings,
-// and exposes a LoadConfig function to initialize them.
+// Values are resolved in increasing order of precedence from built-in defaults,
+// an optional YAML or TOML file, environment variables (with config.env support
+// via godotenv) and command-line flags, then validated as a whole.
 package config
 
 import (
-	"github.com/joho/godotenv"
-	"os"
+	"JavaCode/pkg/db"
+	"fmt"
+	"strings"
+	"time"
 )
 
 // Host holds the server's host and port configuration.
 type Host struct {
-	ServerHost string
-	ServerPort string
+	ServerHost string `config:"host" env:"SERVER_HOST" default:"0.0.0.0"`
+	ServerPort string `config:"port" env:"SERVER_PORT" default:"8080"`
+	// GrpcPort is the port of the gRPC API; empty disables it.
+	GrpcPort string `config:"grpc_port" env:"GRPC_PORT"`
 }
 
 // Db holds the database connection configuration.
 type Db struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Db       string
-	Driver   string
-}
+	Host     string `config:"host" env:"DB_HOST" default:"0.0.0.0"`
+	Port     string `config:"port" env:"DB_PORT" default:"5432"`
+	User     string `config:"user" env:"DB_USER" default:"admin"`
+	Password string `config:"password" env:"DB_PASSWORD" secret:"true"`
+	Db       string `config:"name" env:"DB_NAME" default:"wallet"`
+	Driver   string `config:"driver" env:"DRIVER" default:"postgres"`
 
-// Config combines all app configuration sections.
-type Config struct {
-	Host Host
-	Db   Db
+	// URL is a complete connection string (DATABASE_URL).
+	// When set it takes precedence over the individual fields above.
+	URL string `config:"url" env:"DATABASE_URL" secret:"dsn"`
+
+	SSLMode         string        `config:"sslmode" env:"DB_SSLMODE" default:"disable"`
+	SSLRootCert     string        `config:"sslrootcert" env:"DB_SSLROOTCERT"`
+	ConnectTimeout  time.Duration `config:"connect_timeout" env:"DB_CONNECT_TIMEOUT" default:"5s"`
+	ApplicationName string        `config:"application_name" env:"DB_APPLICATION_NAME" default:"wallet-app"`
+
+	MaxOpenConns    int           `config:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"100"`
+	MaxIdleConns    int           `config:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"25"`
+	ConnMaxLifetime time.Duration `config:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"1h"`
+	ConnMaxIdleTime time.Duration `config:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"0s"`
+
+	// ConnectRetries is the number of attempts to reach the database at startup.
+	ConnectRetries int `config:"connect_retries" env:"DB_CONNECT_RETRIES" default:"10"`
+	// ConnectBackoff is the initial wait between attempts; it doubles up to ConnectMaxBackoff.
+	ConnectBackoff    time.Duration `config:"connect_backoff" env:"DB_CONNECT_BACKOFF" default:"500ms"`
+	ConnectMaxBackoff time.Duration `config:"connect_max_backoff" env:"DB_CONNECT_MAX_BACKOFF" default:"10s"`
+
+	// ReplicaDSNs lists optional read-replica connection strings.
+	// Balance reads are routed to them when present.
+	ReplicaDSNs []string `config:"replica_dsns" env:"DB_REPLICA_DSNS" secret:"dsn"`
 }
 
-// LoadConfig loads configuration from environment variables (with config.env fallback).
+// DSN returns the connection string for the primary database.
 //
-// It returns a populated Config struct with default values if variables are missing.
-func LoadConfig() *Config {
-	_ = godotenv.Load("config.env")
-
-	return &Config{
-		Host: Host{
-			ServerHost: getEnv("SERVER_HOST", "0.0.0.0"),
-			ServerPort: getEnv("SERVER_PORT", "8080"),
-		},
-		Db: Db{
-			Host:     getEnv("DB_HOST", "0.0.0.0"),
-			User:     getEnv("DB_USER", "admin"),
-			Password: getEnv("DB_PASSWORD", "password"),
-			Db:       getEnv("DB_NAME", "wrallet"),
-			Port:     getEnv("DB_PORT", "5432"),
-			Driver:   getEnv("DRIVER", "postgres"),
-		},
-	}
-}
-
-// getEnv returns the value of the environment variable or a default if not set.
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
-	}
-	return defaultValue
+// It returns URL as is when set, otherwise a key=value DSN built from the
+// individual fields.
+func (d Db) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
+
+	params := []string{
+		"host=" + db.QuoteDSNValue(d.Host),
+		"port=" + db.QuoteDSNValue(d.Port),
+		"user=" + db.QuoteDSNValue(d.User),
+		"password=" + db.QuoteDSNValue(d.Password),
+		"dbname=" + db.QuoteDSNValue(d.Db),
+	}
+	if d.SSLMode != "" {
+		params = append(params, "sslmode="+db.QuoteDSNValue(d.SSLMode))
+	}
+	if d.SSLRootCert != "" {
+		params = append(params, "sslrootcert="+db.QuoteDSNValue(d.SSLRootCert))
+	}
+	if d.ConnectTimeout > 0 {
+		// libpq takes whole seconds; round up so that sub-second values still apply.
+		params = append(params, fmt.Sprintf("connect_timeout=%d", int((d.ConnectTimeout+time.Second-1)/time.Second)))
+	}
+	if d.ApplicationName != "" {
+		params = append(params, "application_name="+db.QuoteDSNValue(d.ApplicationName))
+	}
+	return strings.Join(params, " ")
+}
+
+// Pool returns the connection pool options for the database.
+func (d Db) Pool() db.PoolOptions {
+	return db.PoolOptions{
+		MaxOpenConns:    d.MaxOpenConns,
+		MaxIdleConns:    d.MaxIdleConns,
+		ConnMaxLifetime: d.ConnMaxLifetime,
+		ConnMaxIdleTime: d.ConnMaxIdleTime,
+	}
+}
+
+// Retry returns the startup connection retry options for the database.
+func (d Db) Retry() db.RetryOptions {
+	return db.RetryOptions{
+		Attempts:       d.ConnectRetries,
+		InitialBackoff: d.ConnectBackoff,
+		MaxBackoff:     d.ConnectMaxBackoff,
+	}
+}
+
+// Cache holds the balance read cache configuration.
+type Cache struct {
+	// Size is the maximum number of cached wallets; 0 disables the cache.
+	Size int `config:"size" env:"BALANCE_CACHE_SIZE" default:"0"`
+	// TTL bounds how long a balance may be served without re-reading it.
+	TTL time.Duration `config:"ttl" env:"BALANCE_CACHE_TTL" default:"5s"`
+}
+
+// Rates holds the configuration of currency conversion in transfers.
+type Rates struct {
+	// MaxAge is how long after its validFrom a rate without validTo may be
+	// used; 0 lets it apply until a newer rate is published.
+	MaxAge time.Duration `config:"max_age" env:"EXCHANGE_RATE_MAX_AGE" default:"24h"`
+}
+
+// Reconcile holds the periodic balance reconciliation configuration.
+type Reconcile struct {
+	// Interval between reconciliation runs in the server; 0 disables the job.
+	Interval time.Duration `config:"interval" env:"RECONCILE_INTERVAL" default:"0s"`
+	// Freeze freezes wallets whose balance does not match the ledger.
+	Freeze bool `config:"freeze" env:"RECONCILE_FREEZE" default:"false"`
+}
+
+// Snapshots holds the balance snapshot configuration used by point-in-time balance queries.
+type Snapshots struct {
+	// Interval between snapshot runs in the server; 0 disables them.
+	Interval time.Duration `config:"interval" env:"BALANCE_SNAPSHOT_INTERVAL" default:"1h"`
+	// Lag is how far behind the current time snapshots are taken; a
+	// transaction still open from before then holds them back further.
+	Lag time.Duration `config:"lag" env:"BALANCE_SNAPSHOT_LAG" default:"1m"`
+}
+
+// Outbox holds the configuration of the dispatcher that delivers outbox events.
+type Outbox struct {
+	// Sink is where events are delivered: log, file or webhook; empty disables the dispatcher.
+	// Events are recorded either way.
+	Sink string `config:"sink" env:"OUTBOX_SINK"`
+	// File is the file the file sink appends events to.
+	File string `config:"file" env:"OUTBOX_FILE"`
+	// WebhookURL is the endpoint the webhook sink POSTs events to.
+	WebhookURL     string        `config:"webhook_url" env:"OUTBOX_WEBHOOK_URL"`
+	WebhookTimeout time.Duration `config:"webhook_timeout" env:"OUTBOX_WEBHOOK_TIMEOUT" default:"10s"`
+	// Interval between polls for new events.
+	Interval time.Duration `config:"interval" env:"OUTBOX_INTERVAL" default:"1s"`
+	// BatchSize is the maximum number of events delivered per poll.
+	BatchSize int `config:"batch_size" env:"OUTBOX_BATCH_SIZE" default:"100"`
+	// MaxBackoff caps the exponentially growing wait before redelivering a failed event.
+	MaxBackoff time.Duration `config:"max_backoff" env:"OUTBOX_MAX_BACKOFF" default:"5m"`
+	// Retention is how long delivered events are kept, or recorded events if
+	// Sink is empty; 0 keeps them forever.
+	Retention time.Duration `config:"retention" env:"OUTBOX_RETENTION" default:"168h"`
+}
+
+// Webhooks holds the webhook delivery worker configuration.
+type Webhooks struct {
+	// Interval between polls for due deliveries; 0 disables the worker.
+	Interval time.Duration `config:"interval" env:"WEBHOOK_INTERVAL" default:"1s"`
+	// BatchSize is the maximum number of deliveries sent per poll.
+	BatchSize int `config:"batch_size" env:"WEBHOOK_BATCH_SIZE" default:"20"`
+	// Timeout bounds each delivery request.
+	Timeout time.Duration `config:"timeout" env:"WEBHOOK_TIMEOUT" default:"10s"`
+	// MaxAttempts is the number of attempts before a delivery is dead-lettered.
+	MaxAttempts int `config:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" default:"10"`
+	// MaxBackoff caps the exponentially growing wait between attempts.
+	MaxBackoff time.Duration `config:"max_backoff" env:"WEBHOOK_MAX_BACKOFF" default:"1h"`
+}
+
+// Stream holds the configuration of the wallet balance streams.
+type Stream struct {
+	// Listen wakes streams with Postgres LISTEN/NOTIFY; when false they poll every PollInterval.
+	Listen       bool          `config:"listen" env:"STREAM_LISTEN" default:"true"`
+	PollInterval time.Duration `config:"poll_interval" env:"STREAM_POLL_INTERVAL" default:"1s"`
+	// Heartbeat is how often an idle stream sends a keep-alive; it also re-checks for missed events.
+	Heartbeat time.Duration `config:"heartbeat" env:"STREAM_HEARTBEAT" default:"15s"`
+}
+
+// Scheduler holds the configuration of the scheduled operations runner.
+type Scheduler struct {
+	// Interval between polls for due operations; 0 disables the scheduler.
+	// Only the instance holding the scheduler lock runs operations.
+	Interval time.Duration `config:"interval" env:"SCHEDULER_INTERVAL" default:"1s"`
+	// BatchSize is the maximum number of operations leased per poll.
+	BatchSize int `config:"batch_size" env:"SCHEDULER_BATCH_SIZE" default:"100"`
+	// MaxBackoff caps the exponentially growing wait between retries of a failed occurrence.
+	MaxBackoff time.Duration `config:"max_backoff" env:"SCHEDULER_MAX_BACKOFF" default:"1h"`
+}
+
+// Config combines all app configuration sections.
+type Config struct {
+	Host      Host      `config:"server"`
+	Db        Db        `config:"db"`
+	Cache     Cache     `config:"cache"`
+	Rates     Rates     `config:"rates"`
+	Reconcile Reconcile `config:"reconcile"`
+	Snapshots Snapshots `config:"snapshots"`
+	Outbox    Outbox    `config:"outbox"`
+	Webhooks  Webhooks  `config:"webhooks"`
+	Stream    Stream    `config:"stream"`
+	Scheduler Scheduler `config:"scheduler"`
 }
@@ -0,0 +1,320 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options are command-line switches that are not part of Config.
+type Options struct {
+	// File is the configuration file given by --config (or CONFIG_FILE).
+	File string
+	// PrintConfig requests dumping the effective configuration and exiting.
+	PrintConfig bool
+	// MigrateOnStart applies pending migrations before serving (or MIGRATE_ON_START=true).
+	MigrateOnStart bool
+	// Args are the positional arguments left after the flags.
+	Args []string
+}
+
+// field describes one configuration value and where it can be set from.
+type field struct {
+	key        string // dotted file key and flag name, e.g. "db.password"
+	env        string
+	defaultVal string
+	secret     string // "", "true" (mask entirely) or "dsn" (redact passwords only)
+	value      reflect.Value
+}
+
+// source is a named set of raw values keyed by field key.
+type source struct {
+	name   string
+	values map[string]string
+}
+
+// LoadError is returned by Load when the configuration cannot be used.
+// It lists every problem found, so they can all be fixed at once.
+type LoadError struct {
+	Problems []string
+}
+
+func (e *LoadError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem)
+	}
+	return b.String()
+}
+
+// Load resolves the configuration from defaults, the configuration file,
+// environment variables (with config.env fallback) and the given
+// command-line arguments, and validates the result.
+//
+// It returns:
+//   - the effective configuration and the parsed command-line options;
+//   - flag.ErrHelp if -h/--help was given;
+//   - a *LoadError listing every problem found otherwise.
+func Load(args []string) (*Config, Options, error) {
+	_ = godotenv.Load("config.env")
+
+	cfg := &Config{}
+	fields := fieldsOf(cfg)
+
+	var opts Options
+	flagValues := map[string]string{}
+	fs := flag.NewFlagSet("wallet-app", flag.ContinueOnError)
+	fs.StringVar(&opts.File, "config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML configuration file")
+	fs.BoolVar(&opts.PrintConfig, "print-config", false, "print the effective configuration with secrets masked and exit")
+	migrateOnStart, _ := strconv.ParseBool(os.Getenv("MIGRATE_ON_START"))
+	fs.BoolVar(&opts.MigrateOnStart, "migrate-on-start", migrateOnStart, "apply pending migrations before starting the server")
+	for _, f := range fields {
+		key := f.key
+		fs.Func(key, fmt.Sprintf("overrides %s (default %q)", f.env, f.defaultVal), func(v string) error {
+			flagValues[key] = v
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, opts, err
+		}
+		return nil, opts, &LoadError{Problems: []string{err.Error()}}
+	}
+	opts.Args = fs.Args()
+
+	var problems []string
+	sources := []source{{name: "default", values: defaultValues(fields)}}
+
+	if opts.File != "" {
+		values, err := readFile(opts.File, fields)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		sources = append(sources, source{name: opts.File, values: values})
+	}
+	sources = append(sources,
+		source{name: "env", values: envValues(fields)},
+		source{name: "flag", values: flagValues},
+	)
+
+	for _, src := range sources {
+		for _, f := range fields {
+			raw, ok := src.values[f.key]
+			if !ok {
+				continue
+			}
+			if err := setValue(f.value, raw); err != nil {
+				problems = append(problems, fmt.Sprintf("%s (%s): %v", describe(f, src.name), src.name, err))
+			}
+		}
+	}
+
+	problems = append(problems, cfg.Validate()...)
+	if len(problems) > 0 {
+		return nil, opts, &LoadError{Problems: problems}
+	}
+	return cfg, opts, nil
+}
+
+// describe names a field the way it was set in the given source.
+func describe(f field, sourceName string) string {
+	switch sourceName {
+	case "env":
+		return f.env
+	case "flag":
+		return "--" + f.key
+	default:
+		return f.key
+	}
+}
+
+// fieldsOf lists the tagged fields of cfg in declaration order.
+func fieldsOf(cfg *Config) []field {
+	var fields []field
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Type().Field(i).Tag.Get("config")
+		sectionValue := root.Field(i)
+		for j := 0; j < sectionValue.NumField(); j++ {
+			sf := sectionValue.Type().Field(j)
+			fields = append(fields, field{
+				key:        section + "." + sf.Tag.Get("config"),
+				env:        sf.Tag.Get("env"),
+				defaultVal: sf.Tag.Get("default"),
+				secret:     sf.Tag.Get("secret"),
+				value:      sectionValue.Field(j),
+			})
+		}
+	}
+	return fields
+}
+
+func defaultValues(fields []field) map[string]string {
+	values := map[string]string{}
+	for _, f := range fields {
+		if f.defaultVal != "" {
+			values[f.key] = f.defaultVal
+		}
+	}
+	return values
+}
+
+func envValues(fields []field) map[string]string {
+	values := map[string]string{}
+	for _, f := range fields {
+		if v, ok := os.LookupEnv(f.env); ok {
+			values[f.key] = v
+		}
+	}
+	return values
+}
+
+// readFile parses a YAML (.yaml, .yml) or TOML (.toml) configuration file.
+// Keys that do not correspond to a configuration field are rejected.
+func readFile(path string, fields []field) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	tree := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.NewDecoder(bytes.NewReader(data)).Decode(&tree)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension %q (want .yaml, .yml or .toml)", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	known := map[string]bool{}
+	for _, f := range fields {
+		known[f.key] = true
+	}
+
+	values := map[string]string{}
+	var problems []string
+	flatten("", tree, func(key string, value any) {
+		if !known[key] {
+			problems = append(problems, fmt.Sprintf("%s: unknown key %q", path, key))
+			return
+		}
+		values[key] = scalarString(value)
+	})
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return values, errors.New(strings.Join(problems, "\n  - "))
+	}
+	return values, nil
+}
+
+// flatten walks nested maps and reports every leaf under its dotted key.
+func flatten(prefix string, node map[string]any, leaf func(key string, value any)) {
+	for k, v := range node {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if child, ok := v.(map[string]any); ok {
+			flatten(key, child, leaf)
+			continue
+		}
+		leaf(key, v)
+	}
+}
+
+// scalarString converts a decoded file value to the textual form used by
+// environment variables; lists become comma-separated.
+func scalarString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, scalarString(item))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// setValue parses raw into the field according to its type.
+func setValue(v reflect.Value, raw string) error {
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		v.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		v.SetBool(b)
+	case time.Duration:
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not a duration (e.g. 500ms, 5s, 1h)", raw)
+		}
+		v.SetInt(int64(d))
+	case []string:
+		v.Set(reflect.ValueOf(splitList(raw)))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// splitList returns the comma-separated values of raw, skipping empty entries.
+func splitList(raw string) []string {
+	var result []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Print writes the configuration as YAML with secrets masked.
+func (c *Config) Print(w io.Writer) error {
+	tree := map[string]map[string]any{}
+	for _, f := range fieldsOf(c) {
+		section, name, _ := strings.Cut(f.key, ".")
+		if tree[section] == nil {
+			tree[section] = map[string]any{}
+		}
+		tree[section][name] = maskedValue(f)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(tree); err != nil {
+		return err
+	}
+	return enc.Close()
+}
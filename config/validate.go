@@ -0,0 +1,212 @@
+package config
+
+import (
+	"JavaCode/pkg/db"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const masked = "********"
+
+// sslModes are the sslmode values accepted by lib/pq.
+var sslModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+// outboxSinks are the supported outbox.sink values.
+var outboxSinks = []string{"log", "file", "webhook"}
+
+// Validate checks the configuration as a whole.
+//
+// It returns a human-readable description of every problem found,
+// or nil if the configuration is usable.
+func (c *Config) Validate() []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if err := validatePort(c.Host.ServerPort); err != nil {
+		add("server.port (SERVER_PORT): %v", err)
+	}
+	if c.Host.GrpcPort != "" {
+		if err := validatePort(c.Host.GrpcPort); err != nil {
+			add("server.grpc_port (GRPC_PORT): %v", err)
+		} else if c.Host.GrpcPort == c.Host.ServerPort {
+			add("server.grpc_port (GRPC_PORT): must differ from server.port")
+		}
+	}
+
+	if c.Db.URL != "" {
+		if u, err := url.Parse(c.Db.URL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			add("db.url (DATABASE_URL): must be a postgres:// or postgresql:// URL")
+		}
+	} else {
+		if err := validatePort(c.Db.Port); err != nil {
+			add("db.port (DB_PORT): %v", err)
+		}
+		for _, required := range []struct{ key, env, value string }{
+			{"db.host", "DB_HOST", c.Db.Host},
+			{"db.user", "DB_USER", c.Db.User},
+			{"db.password", "DB_PASSWORD", c.Db.Password},
+			{"db.name", "DB_NAME", c.Db.Db},
+		} {
+			if required.value == "" {
+				add("%s (%s): required unless db.url (DATABASE_URL) is set", required.key, required.env)
+			}
+		}
+		if !contains(sslModes, c.Db.SSLMode) {
+			add("db.sslmode (DB_SSLMODE): %q is not one of %s", c.Db.SSLMode, strings.Join(sslModes, ", "))
+		}
+		if c.Db.SSLRootCert != "" && c.Db.SSLMode != "verify-ca" && c.Db.SSLMode != "verify-full" {
+			add("db.sslrootcert (DB_SSLROOTCERT): only used with sslmode verify-ca or verify-full")
+		}
+	}
+	if c.Db.Driver == "" {
+		add("db.driver (DRIVER): required")
+	}
+
+	for _, nonNegative := range []struct {
+		key, env string
+		value    int
+	}{
+		{"db.max_open_conns", "DB_MAX_OPEN_CONNS", c.Db.MaxOpenConns},
+		{"db.max_idle_conns", "DB_MAX_IDLE_CONNS", c.Db.MaxIdleConns},
+		{"db.connect_retries", "DB_CONNECT_RETRIES", c.Db.ConnectRetries},
+		{"cache.size", "BALANCE_CACHE_SIZE", c.Cache.Size},
+		{"outbox.batch_size", "OUTBOX_BATCH_SIZE", c.Outbox.BatchSize},
+	} {
+		if nonNegative.value < 0 {
+			add("%s (%s): must not be negative, got %d", nonNegative.key, nonNegative.env, nonNegative.value)
+		}
+	}
+
+	for _, nonNegative := range []struct {
+		key, env string
+		value    time.Duration
+	}{
+		{"db.connect_timeout", "DB_CONNECT_TIMEOUT", c.Db.ConnectTimeout},
+		{"db.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", c.Db.ConnMaxLifetime},
+		{"db.conn_max_idle_time", "DB_CONN_MAX_IDLE_TIME", c.Db.ConnMaxIdleTime},
+		{"db.connect_backoff", "DB_CONNECT_BACKOFF", c.Db.ConnectBackoff},
+		{"db.connect_max_backoff", "DB_CONNECT_MAX_BACKOFF", c.Db.ConnectMaxBackoff},
+		{"cache.ttl", "BALANCE_CACHE_TTL", c.Cache.TTL},
+		{"rates.max_age", "EXCHANGE_RATE_MAX_AGE", c.Rates.MaxAge},
+		{"reconcile.interval", "RECONCILE_INTERVAL", c.Reconcile.Interval},
+		{"snapshots.interval", "BALANCE_SNAPSHOT_INTERVAL", c.Snapshots.Interval},
+		{"snapshots.lag", "BALANCE_SNAPSHOT_LAG", c.Snapshots.Lag},
+		{"outbox.webhook_timeout", "OUTBOX_WEBHOOK_TIMEOUT", c.Outbox.WebhookTimeout},
+		{"outbox.retention", "OUTBOX_RETENTION", c.Outbox.Retention},
+		{"webhooks.interval", "WEBHOOK_INTERVAL", c.Webhooks.Interval},
+		{"webhooks.timeout", "WEBHOOK_TIMEOUT", c.Webhooks.Timeout},
+		{"scheduler.interval", "SCHEDULER_INTERVAL", c.Scheduler.Interval},
+	} {
+		if nonNegative.value < 0 {
+			add("%s (%s): must not be negative, got %v", nonNegative.key, nonNegative.env, nonNegative.value)
+		}
+	}
+
+	if c.Outbox.Sink != "" {
+		if !contains(outboxSinks, c.Outbox.Sink) {
+			add("outbox.sink (OUTBOX_SINK): %q is not one of %s", c.Outbox.Sink, strings.Join(outboxSinks, ", "))
+		}
+		if c.Outbox.Sink == "file" && c.Outbox.File == "" {
+			add("outbox.file (OUTBOX_FILE): required with the file sink")
+		}
+		if c.Outbox.Sink == "webhook" {
+			if u, err := url.Parse(c.Outbox.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				add("outbox.webhook_url (OUTBOX_WEBHOOK_URL): must be an http:// or https:// URL with the webhook sink")
+			}
+		}
+		if c.Outbox.Interval <= 0 {
+			add("outbox.interval (OUTBOX_INTERVAL): must be positive, got %v", c.Outbox.Interval)
+		}
+		if c.Outbox.BatchSize == 0 {
+			add("outbox.batch_size (OUTBOX_BATCH_SIZE): must be positive")
+		}
+		if c.Outbox.MaxBackoff <= 0 {
+			add("outbox.max_backoff (OUTBOX_MAX_BACKOFF): must be positive, got %v", c.Outbox.MaxBackoff)
+		}
+	}
+
+	if !c.Stream.Listen && c.Stream.PollInterval <= 0 {
+		add("stream.poll_interval (STREAM_POLL_INTERVAL): must be positive without stream.listen, got %v", c.Stream.PollInterval)
+	}
+	if c.Stream.Heartbeat <= 0 {
+		add("stream.heartbeat (STREAM_HEARTBEAT): must be positive, got %v", c.Stream.Heartbeat)
+	}
+
+	if c.Webhooks.Interval > 0 {
+		if c.Webhooks.BatchSize <= 0 {
+			add("webhooks.batch_size (WEBHOOK_BATCH_SIZE): must be positive, got %d", c.Webhooks.BatchSize)
+		}
+		if c.Webhooks.MaxAttempts <= 0 {
+			add("webhooks.max_attempts (WEBHOOK_MAX_ATTEMPTS): must be positive, got %d", c.Webhooks.MaxAttempts)
+		}
+		if c.Webhooks.MaxBackoff <= 0 {
+			add("webhooks.max_backoff (WEBHOOK_MAX_BACKOFF): must be positive, got %v", c.Webhooks.MaxBackoff)
+		}
+	}
+
+	if c.Scheduler.Interval > 0 {
+		if c.Scheduler.BatchSize <= 0 {
+			add("scheduler.batch_size (SCHEDULER_BATCH_SIZE): must be positive, got %d", c.Scheduler.BatchSize)
+		}
+		if c.Scheduler.MaxBackoff <= 0 {
+			add("scheduler.max_backoff (SCHEDULER_MAX_BACKOFF): must be positive, got %v", c.Scheduler.MaxBackoff)
+		}
+	}
+
+	return problems
+}
+
+// validatePort checks that port is a number between 1 and 65535.
+func validatePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", port)
+	}
+	if n < 1 || n > 65535 {
+		return fmt.Errorf("%d is out of range 1-65535", n)
+	}
+	return nil
+}
+
+// maskedValue returns the printable value of a field with secrets hidden.
+func maskedValue(f field) any {
+	switch v := f.value.Interface().(type) {
+	case string:
+		switch {
+		case v == "":
+			return v
+		case f.secret == "dsn":
+			return db.RedactDSN(v)
+		case f.secret != "":
+			return masked
+		}
+		return v
+	case []string:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if f.secret != "" {
+				item = db.RedactDSN(item)
+			}
+			items = append(items, item)
+		}
+		return items
+	case time.Duration:
+		return v.String()
+	default:
+		return v
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
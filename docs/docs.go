@@ -15,9 +15,50 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/wallet": {
+        "/v1/admin/rates": {
+            "get": {
+                "description": "Return stored exchange rates, newest first within each currency pair.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List exchange rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency filter",
+                        "name": "base",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency filter",
+                        "name": "quote",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExchangeRateResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Deposit funds to, or withdraw funds from, a wallet.",
+                "description": "Store a conversion rate between two currencies. Transfers use the latest rate whose validity has started.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,44 +66,94 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallet"
+                    "admin"
                 ],
-                "summary": "Perform a wallet operation",
+                "summary": "Publish an exchange rate",
                 "parameters": [
                     {
-                        "description": "Operation parameters",
+                        "description": "Rate parameters",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.WalletOperationRequest"
+                            "$ref": "#/definitions/models.CreateExchangeRateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExchangeRateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request / unsupported currency",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/wallets/{WALLET_UUID}/close": {
+            "post": {
+                "description": "Close an active wallet with a zero balance. A closed wallet refuses every operation and cannot be reopened.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Close a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID wallet",
+                        "name": "WALLET_UUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Operation successful",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.BalanceResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request / negative amount",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Wallet not found",
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "409": {
+                        "description": "Wallet is not active or its balance is not zero",
                         "schema": {
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
@@ -70,13 +161,75 @@ const docTemplate = `{
                 }
             }
         },
-        "/wallets/{WALLET_UUID}": {
+        "/v1/admin/wallets/{WALLET_UUID}/entries": {
             "get": {
-                "description": "Return balance by UUID",
+                "description": "Return the most recent debit and credit entries posted to a wallet account, newest first, with the description, reference and metadata of their operation.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "wallet"
+                    "admin"
                 ],
-                "summary": "Get Balance",
+                "summary": "List ledger entries of a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID wallet",
+                        "name": "WALLET_UUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries of the operation with this reference",
+                        "name": "reference",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LedgerEntryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/wallets/{WALLET_UUID}/freeze": {
+            "post": {
+                "description": "Freeze an active wallet. Withdrawals and outgoing transfers are refused; with blockDeposits, deposits and incoming transfers too.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Freeze a wallet",
                 "parameters": [
                     {
                         "type": "string",
@@ -84,6 +237,15 @@ const docTemplate = `{
                         "name": "WALLET_UUID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Reason and deposit blocking",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FreezeWalletRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -104,40 +266,2375 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/utils.ErrorResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Wallet is not active",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.BalanceResponse": {
-            "type": "object",
-            "properties": {
-                "balance": {
-                    "type": "integer",
-                    "example": 1000
-                },
-                "uuid": {
-                    "type": "string",
-                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+        },
+        "/v1/admin/wallets/{WALLET_UUID}/status-changes": {
+            "get": {
+                "description": "Return the wallet's status history with the recorded reasons, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List status changes of a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID wallet",
+                        "name": "WALLET_UUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WalletStatusChange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "models.WalletOperationRequest": {
-            "type": "object",
-            "properties": {
-                "amount": {
-                    "description": "Amount is the amount of money to deposit or withdraw.\nMust be a positive integer.\nrequired: true\nexample: 500",
-                    "type": "integer",
-                    "example": 1000
-                },
-                "operationType": {
-                    "description": "OperationType defines the type of operation: \"deposit\" or \"withdrawal\".\nrequired: true\nexample: deposit",
-                    "type": "string",
-                    "example": "DEPOSIT"
-                },
-                "walletId": {
-                    "description": "WalletID is the unique identifier of the wallet.\nrequired: true\nexample: abc123",
+        "/v1/admin/wallets/{WALLET_UUID}/unfreeze": {
+            "post": {
+                "description": "Make a frozen wallet active again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Unfreeze a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID wallet",
+                        "name": "WALLET_UUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BalanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Wallet is not frozen",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/wallets/{WALLET_UUID}/verify": {
+            "get": {
+                "description": "Compare the stored balance of a wallet with the sum of its ledger entries.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Verify a wallet balance against the ledger",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID wallet",
+                        "name": "WALLET_UUID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BalanceVerification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/scheduled-operations": {
+            "get": {
+                "description": "Return the scheduled operations, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-operations"
+                ],
+                "summary": "List scheduled operations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only the operations of this wallet",
+                        "name": "walletId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ACTIVE, COMPLETED or CANCELLED",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ScheduledOperationResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Schedule an operation to run once at startAt (now if not given), or on a recurrence: daily, weekly or monthly at the time of startAt, or a five-field cron expression in UTC, until endAt. A failed occurrence, e.g. for insufficient funds, is recorded and retried up to maxRetries times (at most 10) with exponential backoff, then skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-operations"
+                ],
+                "summary": "Schedule a deposit or withdrawal",
+                "parameters": [
+                    {
+                        "description": "Operation and schedule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateScheduledOperationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduledOperationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, schedule or time range",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Wallet not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Wallet closed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Currency mismatch",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/scheduled-operations/{SCHEDULE_ID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-operations"
+                ],
+                "summary": "Get a scheduled operation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Scheduled operation UUID",
+                        "name": "SCHEDULE_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduledOperationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stop an active scheduled operation before its next occurrence. Its runs are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-operations"
+                ],
+                "summary": "Cancel a scheduled operation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Scheduled operation UUID",
+                        "name": "SCHEDULE_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduledOperationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already completed or cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/scheduled-operations/{SCHEDULE_ID}/runs": {
+            "get": {
+                "description": "Return the attempts to execute the operation, newest first, with the error code of failed attempts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-operations"
+                ],
+                "summary": "List the runs of a scheduled operation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Scheduled operation UUID",
+                        "name": "SCHEDULE_ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of runs (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ScheduledRunResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/transfers": {
+            "post": {
+                "description": "Debit one wallet and credit another. If the wallets use different currencies the amount is converted at the current rate, which is recorded on the transfer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfer"
+                ],
+                "summary": "Transfer between wallets",
+                "parameters": [
+                    {
+                        "description": "Transfer parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferResponse"
+                        },
+                        "headers": {
+                            "X-Consistency-Token": {
+                                "type": "string",
+                                "description": "Read-your-writes token (only with replicas)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request / negative amount / unsupported currency",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Wallet not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Currency mismatch / no rate / stale rate",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/transfers/{TRANSFER_ID}": {
+            "get": {
+                "description": "Return a transfer with the rate applied to it, if any.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfer"
+                ],
+                "summary": "Get a transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transfer UUID",
+                        "name": "TRANSFER_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallet": {
+            "post": {
+                "description": "Deposit funds to, or withdraw funds from, a wallet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Perform a wallet operation",
+                "parameters": [
+                    {
+                        "description": "Operation parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletOperationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Operation successful",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "X-Consistency-Token": {
+                                "type": "string",
+                                "description": "Read-your-writes token (only with replicas)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request / negative amount / unsupported currency",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Wallet not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Wallet frozen or closed / duplicate reference",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Currency does not match the wallet / amount overflow",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets": {
+            "get": {
+                "description": "Search wallets by owner, status, currency, balance and creation time. Results are paginated with an opaque cursor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "List wallets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Owner reference",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Part of the name, ignoring case",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Label as key:value; repeat to require several",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ACTIVE, FROZEN or CLOSED",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 code",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum balance in minor units (inclusive)",
+                        "name": "minBalance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum balance in minor units (inclusive)",
+                        "name": "maxBalance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 creation time lower bound (inclusive)",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 creation time upper bound (exclusive)",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "createdAt, -createdAt, balance or -balance (default createdAt)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create an empty wallet in the given currency. The currency cannot be changed later.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Create a wallet",
+                "parameters": [
+                    {
+                        "description": "Wallet parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWalletRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.BalanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request / unsupported currency",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{WALLET_UUID}": {
+            "get": {
+                "description": "Return balance by UUID, optionally as of a past time",
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Get Balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID wallet",
+                        "name": "WALLET_UUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 timestamp to compute the balance at from the ledger",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to \\",
+                        "name": "consistency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token returned by a previous operation",
+                        "name": "X-Consistency-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to \\",
+                        "name": "Cache-Control",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BalanceResponse"
+                        },
+                        "headers": {
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "HIT or MISS (only with the balance cache enabled)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "History unavailable before the wallet's opening balance",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change the owner, name or labels of a wallet. Omitted fields are kept, an empty owner or name clears it, and a null label value removes the label.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Update wallet metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID wallet",
+                        "name": "WALLET_UUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Metadata changes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateWalletRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BalanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{WALLET_UUID}/stream": {
+            "get": {
+                "description": "Push the wallet's events (wallet.balance_changed, wallet.status_changed) as they commit, as server-sent events with the event id and type, or as models.WalletStreamMessage JSON messages when the request upgrades to a WebSocket. A stream without a cursor starts with a \"balance\" event carrying the current balance; a reconnecting client resumes after the Last-Event-ID header or the lastEventId query parameter. Idle streams get a keep-alive every heartbeat.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Stream balance updates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID wallet",
+                        "name": "WALLET_UUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Id of the last event received, for WebSocket clients",
+                        "name": "lastEventId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BalanceChangedEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid uuid or event id",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Wallet not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks": {
+            "get": {
+                "description": "Return the registered webhook endpoints, oldest first, without their secrets.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook endpoints",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only the endpoints of this client",
+                        "name": "clientId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register an endpoint that receives signed JSON POSTs for the selected events: deposit, withdrawal, low_balance (with a threshold) and freeze. The signing secret is only returned here.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register a webhook endpoint",
+                "parameters": [
+                    {
+                        "description": "Endpoint parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Wallet not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{WEBHOOK_ID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "WEBHOOK_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stop deliveries to the endpoint. Pending deliveries are dead-lettered; the delivery log is kept.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Deactivate a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "WEBHOOK_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{WEBHOOK_ID}/deliveries": {
+            "get": {
+                "description": "Return the delivery log of an endpoint, newest first, with the outcome of the last attempt.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "WEBHOOK_ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "PENDING, DELIVERED or DEAD",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of deliveries (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDeliveryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{WEBHOOK_ID}/deliveries/{DELIVERY_ID}/retry": {
+            "post": {
+                "description": "Requeue a delivery that ran out of attempts, with a fresh set of attempts.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Retry a dead-lettered delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "WEBHOOK_ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery id",
+                        "name": "DELIVERY_ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found / no such dead delivery",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/transfers": {
+            "post": {
+                "description": "Debit one wallet and credit another, converting at the current rate if the currencies differ. Amounts are decimal strings with currency.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfer-v2"
+                ],
+                "summary": "Transfer between wallets",
+                "parameters": [
+                    {
+                        "description": "Transfer parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferRequestV2"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferResponseV2"
+                        },
+                        "headers": {
+                            "X-Consistency-Token": {
+                                "type": "string",
+                                "description": "Read-your-writes token (only with replicas)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request / invalid amount / excess precision / unsupported currency",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Wallet not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Currency mismatch / no rate / stale rate / amount overflow",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallet": {
+            "post": {
+                "description": "Deposit funds to, or withdraw funds from, a wallet. The amount is a decimal string with currency, e.g. \"12.34 EUR\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet-v2"
+                ],
+                "summary": "Perform a wallet operation",
+                "parameters": [
+                    {
+                        "description": "Operation parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletOperationRequestV2"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Operation successful",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "X-Consistency-Token": {
+                                "type": "string",
+                                "description": "Read-your-writes token (only with replicas)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request / invalid amount / excess precision / unsupported currency",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Wallet not found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Wallet frozen or closed / duplicate reference",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Currency does not match the wallet / amount overflow",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets": {
+            "post": {
+                "description": "Create an empty wallet in the given currency. The currency cannot be changed later.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet-v2"
+                ],
+                "summary": "Create a wallet",
+                "parameters": [
+                    {
+                        "description": "Wallet parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWalletRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.BalanceResponseV2"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request / unsupported currency",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{WALLET_UUID}": {
+            "get": {
+                "description": "Return balance by UUID as a decimal amount with currency, optionally as of a past time",
+                "tags": [
+                    "wallet-v2"
+                ],
+                "summary": "Get Balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID wallet",
+                        "name": "WALLET_UUID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 timestamp to compute the balance at from the ledger",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to \\",
+                        "name": "consistency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token returned by a previous operation",
+                        "name": "X-Consistency-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to \\",
+                        "name": "Cache-Control",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BalanceResponseV2"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "History unavailable before the wallet's opening balance",
+                        "schema": {
+                            "$ref": "#/definitions/utils.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.BalanceChangedEvent": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "Balance is the wallet balance after the change.",
+                    "type": "integer",
+                    "example": 1500
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "delta": {
+                    "description": "Delta is the signed change of the balance.",
+                    "type": "integer",
+                    "example": 500
+                },
+                "eventId": {
+                    "type": "string",
+                    "example": "0b8f2a4c-4d1e-4b7a-9d3c-6f5e2a1b0c9d"
+                },
+                "occurredAt": {
+                    "type": "string"
+                },
+                "operation": {
+                    "description": "Operation is the ledger transaction type: DEPOSIT, WITHDRAW or TRANSFER.",
+                    "type": "string",
+                    "example": "DEPOSIT"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "order-1042"
+                },
+                "transactionId": {
+                    "type": "string",
+                    "example": "5b0f7b0e-8a53-4b39-a0c4-2f7d4c5e2c11"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "wallet.balance_changed"
+                },
+                "walletId": {
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                }
+            }
+        },
+        "models.BalanceResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-06-10T12:00:00Z"
+                },
+                "currency": {
+                    "description": "Currency is the ISO 4217 code of the wallet.",
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "depositsBlocked": {
+                    "description": "DepositsBlocked is set on frozen wallets that refuse deposits as well.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "exponent": {
+                    "description": "Exponent is the number of decimal places in Balance (2 means Balance is in cents).",
+                    "type": "integer",
+                    "example": 2
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Savings"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "customer-1842"
+                },
+                "status": {
+                    "description": "Status is ACTIVE, FROZEN or CLOSED.",
+                    "type": "string",
+                    "example": "ACTIVE"
+                },
+                "uuid": {
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                }
+            }
+        },
+        "models.BalanceResponseV2": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "string",
+                    "example": "12.34 EUR"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Savings"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "customer-1842"
+                },
+                "status": {
+                    "description": "Status is ACTIVE, FROZEN or CLOSED.",
+                    "type": "string",
+                    "example": "ACTIVE"
+                },
+                "uuid": {
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                }
+            }
+        },
+        "models.BalanceVerification": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "Balance is the stored balance projection.",
+                    "type": "integer",
+                    "example": 1000
+                },
+                "consistent": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "ledgerBalance": {
+                    "description": "LedgerBalance is the wallet account's credits minus debits.",
+                    "type": "integer",
+                    "example": 1000
+                },
+                "walletId": {
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                }
+            }
+        },
+        "models.CreateExchangeRateRequest": {
+            "type": "object",
+            "properties": {
+                "baseCurrency": {
+                    "description": "BaseCurrency is the ISO 4217 code converted from.\nrequired: true",
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "precision": {
+                    "description": "Precision is the number of decimal places the rate is stored with (0-12).\nrequired: true",
+                    "type": "integer",
+                    "example": 4
+                },
+                "quoteCurrency": {
+                    "description": "QuoteCurrency is the ISO 4217 code converted to.\nrequired: true",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "rate": {
+                    "description": "Rate is the decimal number of QuoteCurrency units per BaseCurrency unit.\nrequired: true",
+                    "type": "string",
+                    "example": "1.0834"
+                },
+                "roundingMode": {
+                    "description": "RoundingMode applies to converted amounts: HALF_UP, HALF_EVEN, DOWN or UP.\nrequired: true",
+                    "type": "string",
+                    "example": "HALF_EVEN"
+                },
+                "validFrom": {
+                    "description": "ValidFrom is when the rate starts to apply; defaults to now.",
+                    "type": "string",
+                    "example": "2025-05-10T00:00:00Z"
+                },
+                "validTo": {
+                    "description": "ValidTo is when the rate stops applying; transfers after it are rejected\nas stale until a newer rate is published. If empty, the rate expires the\nserver's maximum rate age after ValidFrom.",
+                    "type": "string",
+                    "example": "2025-05-11T00:00:00Z"
+                }
+            }
+        },
+        "models.CreateScheduledOperationRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount is the amount in minor units of Currency.\nrequired: true",
+                    "type": "integer",
+                    "example": 1000
+                },
+                "currency": {
+                    "description": "Currency must match the wallet currency.\nrequired: true",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "description": {
+                    "description": "Description is recorded on every executed operation.",
+                    "type": "string",
+                    "example": "Monthly subscription"
+                },
+                "endAt": {
+                    "description": "EndAt is the last time an occurrence may fall on.",
+                    "type": "string",
+                    "example": "2026-07-15T09:00:00Z"
+                },
+                "maxRetries": {
+                    "description": "MaxRetries is the number of times a failed occurrence is retried,\ne.g. after insufficient funds, before it is skipped.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "metadata": {
+                    "description": "Metadata is recorded on every executed operation.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "operationType": {
+                    "description": "OperationType is \"DEPOSIT\" or \"WITHDRAW\".\nrequired: true",
+                    "type": "string",
+                    "example": "WITHDRAW"
+                },
+                "schedule": {
+                    "description": "Schedule repeats the operation: daily, weekly or monthly at the time\nof StartAt, or a cron expression in UTC. Empty runs it once.",
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "startAt": {
+                    "description": "StartAt is the first occurrence; now if not given.",
+                    "type": "string",
+                    "example": "2025-07-15T09:00:00Z"
+                },
+                "walletId": {
+                    "description": "WalletID is the unique identifier of the wallet.\nrequired: true",
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                }
+            }
+        },
+        "models.CreateWalletRequest": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "Currency is the ISO 4217 code of the wallet; it cannot be changed later.\nrequired: true\nexample: EUR",
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "labels": {
+                    "description": "Labels are free-form key/value pairs, e.g. {\"tier\": \"gold\"}.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "Name is an optional display name.\nexample: Savings",
+                    "type": "string",
+                    "example": "Savings"
+                },
+                "owner": {
+                    "description": "Owner optionally identifies the wallet's owner, e.g. a customer id.\nexample: customer-1842",
+                    "type": "string",
+                    "example": "customer-1842"
+                }
+            }
+        },
+        "models.CreateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "description": "ClientId identifies the client owning the endpoint.\nrequired: true",
+                    "type": "string",
+                    "example": "billing-service"
+                },
+                "events": {
+                    "description": "Events filters the delivered events: deposit, withdrawal, low_balance, freeze.\nrequired: true",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "deposit",
+                        "low_balance"
+                    ]
+                },
+                "lowBalanceThreshold": {
+                    "description": "LowBalanceThreshold, in minor units, is required with the low_balance event.",
+                    "type": "integer",
+                    "example": 1000
+                },
+                "url": {
+                    "description": "URL receives the deliveries as POST requests.\nrequired: true",
+                    "type": "string",
+                    "example": "https://billing.example.com/hooks/wallet"
+                },
+                "walletId": {
+                    "description": "WalletId limits the endpoint to one wallet.",
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                }
+            }
+        },
+        "models.CreateWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "clientId": {
+                    "type": "string",
+                    "example": "billing-service"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-06-30T09:00:00Z"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "deposit",
+                        "low_balance"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9e4c2f1a-6b7d-4c8e-a0f3-2d5b8c1e7f90"
+                },
+                "lowBalanceThreshold": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "whsec_3f9a1c..."
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://billing.example.com/hooks/wallet"
+                },
+                "walletId": {
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                }
+            }
+        },
+        "models.ExchangeRateResponse": {
+            "type": "object",
+            "properties": {
+                "baseCurrency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "precision": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "quoteCurrency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "1.0834"
+                },
+                "roundingMode": {
+                    "type": "string",
+                    "example": "HALF_EVEN"
+                },
+                "validFrom": {
+                    "type": "string",
+                    "example": "2025-05-10T00:00:00Z"
+                },
+                "validTo": {
+                    "type": "string",
+                    "example": "2025-05-11T00:00:00Z"
+                }
+            }
+        },
+        "models.FreezeWalletRequest": {
+            "type": "object",
+            "properties": {
+                "blockDeposits": {
+                    "description": "BlockDeposits makes the wallet refuse deposits as well as withdrawals.",
+                    "type": "boolean",
+                    "example": false
+                },
+                "reason": {
+                    "description": "Reason is recorded in the wallet's status history.\nrequired: true",
+                    "type": "string",
+                    "example": "AML investigation #4411"
+                }
+            }
+        },
+        "models.LedgerEntryResponse": {
+            "type": "object",
+            "properties": {
+                "accountId": {
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                },
+                "amount": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-05-20T12:00:00Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "description": {
+                    "description": "Description is free text, e.g. shown in statements.",
+                    "type": "string",
+                    "example": "Order #1001"
+                },
+                "direction": {
+                    "type": "string",
+                    "example": "CREDIT"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "metadata": {
+                    "description": "Metadata holds free-form key/value pairs.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "reference": {
+                    "description": "Reference is the client's id for the operation, e.g. an order or\ninvoice number. It must be unique per wallet.",
+                    "type": "string",
+                    "example": "order-1001"
+                },
+                "transactionId": {
+                    "type": "string",
+                    "example": "5b0f7b0e-8a53-4b39-a0c4-2f7d4c5e2c11"
+                },
+                "transactionType": {
+                    "type": "string",
+                    "example": "DEPOSIT"
+                }
+            }
+        },
+        "models.ScheduledOperationResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "attempts": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-07-10T09:00:00Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Monthly subscription"
+                },
+                "endAt": {
+                    "type": "string",
+                    "example": "2026-07-15T09:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7d1e5c2a-3b4f-4a6d-9e8c-1f2a3b4c5d6e"
+                },
+                "maxRetries": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "nextAttemptAt": {
+                    "type": "string",
+                    "example": "2025-08-15T09:00:00Z"
+                },
+                "nextRunAt": {
+                    "type": "string",
+                    "example": "2025-08-15T09:00:00Z"
+                },
+                "operationType": {
+                    "type": "string",
+                    "example": "WITHDRAW"
+                },
+                "schedule": {
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "startAt": {
+                    "type": "string",
+                    "example": "2025-07-15T09:00:00Z"
+                },
+                "status": {
+                    "description": "Status is ACTIVE, COMPLETED or CANCELLED.",
+                    "type": "string",
+                    "example": "ACTIVE"
+                },
+                "walletId": {
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                }
+            }
+        },
+        "models.ScheduledRunResponse": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "error": {
+                    "type": "string",
+                    "example": "negative_amount"
+                },
+                "executedAt": {
+                    "type": "string",
+                    "example": "2025-08-15T09:00:01Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "schedule:7d1e5c2a-3b4f-4a6d-9e8c-1f2a3b4c5d6e:1755248400"
+                },
+                "runAt": {
+                    "type": "string",
+                    "example": "2025-08-15T09:00:00Z"
+                },
+                "status": {
+                    "description": "Status is SUCCEEDED or FAILED.",
+                    "type": "string",
+                    "example": "FAILED"
+                }
+            }
+        },
+        "models.TransferRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount is debited from the source wallet, in minor units of Currency.\nrequired: true",
+                    "type": "integer",
+                    "example": 1000
+                },
+                "currency": {
+                    "description": "Currency must match the source wallet currency. If the destination\nwallet uses another currency the amount is converted at the current rate.\nrequired: true",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "fromWalletId": {
+                    "description": "FromWalletID is the wallet debited.\nrequired: true",
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                },
+                "toWalletId": {
+                    "description": "ToWalletID is the wallet credited.\nrequired: true",
+                    "type": "string",
+                    "example": "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"
+                }
+            }
+        },
+        "models.TransferRequestV2": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount is debited from the source wallet and must be in its currency.\nrequired: true",
+                    "type": "string",
+                    "example": "10.00 EUR"
+                },
+                "fromWalletId": {
+                    "description": "FromWalletID is the wallet debited.\nrequired: true",
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                },
+                "toWalletId": {
+                    "description": "ToWalletID is the wallet credited.\nrequired: true",
+                    "type": "string",
+                    "example": "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"
+                }
+            }
+        },
+        "models.TransferResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-05-10T12:00:00Z"
+                },
+                "fromWalletId": {
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5b0f7b0e-8a53-4b39-a0c4-2f7d4c5e2c11"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "1.0834"
+                },
+                "rateId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "roundingMode": {
+                    "type": "string",
+                    "example": "HALF_EVEN"
+                },
+                "sourceAmount": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "sourceCurrency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "targetAmount": {
+                    "type": "integer",
+                    "example": 1083
+                },
+                "targetCurrency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "toWalletId": {
+                    "type": "string",
+                    "example": "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"
+                }
+            }
+        },
+        "models.TransferResponseV2": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-05-10T12:00:00Z"
+                },
+                "fromWalletId": {
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5b0f7b0e-8a53-4b39-a0c4-2f7d4c5e2c11"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "1.0834"
+                },
+                "rateId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "roundingMode": {
+                    "type": "string",
+                    "example": "HALF_EVEN"
+                },
+                "sourceAmount": {
+                    "type": "string",
+                    "example": "10.00 EUR"
+                },
+                "targetAmount": {
+                    "type": "string",
+                    "example": "10.83 USD"
+                },
+                "toWalletId": {
+                    "type": "string",
+                    "example": "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"
+                }
+            }
+        },
+        "models.UpdateWalletRequest": {
+            "type": "object",
+            "properties": {
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Savings"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "customer-1842"
+                }
+            }
+        },
+        "models.WalletListResponse": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "description": "NextCursor is passed as the cursor parameter to fetch the next page; empty on the last page.",
+                    "type": "string",
+                    "example": "eyJzIjoiY3JlYXRlZEF0In0"
+                },
+                "wallets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BalanceResponse"
+                    }
+                }
+            }
+        },
+        "models.WalletOperationRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount is the amount of money to deposit or withdraw.\nMust be a positive integer.\nrequired: true\nexample: 500",
+                    "type": "integer",
+                    "example": 1000
+                },
+                "currency": {
+                    "description": "Currency is the ISO 4217 code of Amount and must match the wallet's currency.\nAmount is expressed in minor units of this currency (e.g. cents).\nrequired: true\nexample: EUR",
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "description": {
+                    "description": "Description is free text, e.g. shown in statements.",
+                    "type": "string",
+                    "example": "Order #1001"
+                },
+                "metadata": {
+                    "description": "Metadata holds free-form key/value pairs.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "operationType": {
+                    "description": "OperationType defines the type of operation: \"deposit\" or \"withdrawal\".\nrequired: true\nexample: deposit",
+                    "type": "string",
+                    "example": "DEPOSIT"
+                },
+                "reference": {
+                    "description": "Reference is the client's id for the operation, e.g. an order or\ninvoice number. It must be unique per wallet.",
+                    "type": "string",
+                    "example": "order-1001"
+                },
+                "walletId": {
+                    "description": "WalletID is the unique identifier of the wallet.\nrequired: true\nexample: abc123",
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                }
+            }
+        },
+        "models.WalletOperationRequestV2": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount is a positive decimal amount and the wallet's currency.\nIt may not have more decimal places than the currency allows.\nrequired: true",
+                    "type": "string",
+                    "example": "12.34 EUR"
+                },
+                "description": {
+                    "description": "Description is free text, e.g. shown in statements.",
+                    "type": "string",
+                    "example": "Order #1001"
+                },
+                "metadata": {
+                    "description": "Metadata holds free-form key/value pairs.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "operationType": {
+                    "description": "OperationType is \"DEPOSIT\" or \"WITHDRAW\".\nrequired: true",
+                    "type": "string",
+                    "example": "DEPOSIT"
+                },
+                "reference": {
+                    "description": "Reference is the client's id for the operation, e.g. an order or\ninvoice number. It must be unique per wallet.",
+                    "type": "string",
+                    "example": "order-1001"
+                },
+                "walletId": {
+                    "description": "WalletID is the unique identifier of the wallet.\nrequired: true",
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                }
+            }
+        },
+        "models.WalletStatusChange": {
+            "type": "object",
+            "properties": {
+                "changedAt": {
+                    "type": "string",
+                    "example": "2025-06-05T12:00:00Z"
+                },
+                "fromStatus": {
+                    "type": "string",
+                    "example": "ACTIVE"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "AML investigation #4411"
+                },
+                "toStatus": {
+                    "type": "string",
+                    "example": "FROZEN"
+                },
+                "walletId": {
+                    "type": "string",
+                    "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+                }
+            }
+        },
+        "models.WalletStatusRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Reason is recorded in the wallet's status history.\nrequired: true",
+                    "type": "string",
+                    "example": "Investigation closed"
+                }
+            }
+        },
+        "models.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-06-30T09:00:00Z"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string",
+                    "example": "0b8f2a4c-4d1e-4b7a-9d3c-6f5e2a1b0c9d"
+                },
+                "eventType": {
+                    "type": "string",
+                    "example": "deposit"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "lastError": {
+                    "type": "string",
+                    "example": ""
+                },
+                "lastStatusCode": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "DELIVERED"
+                }
+            }
+        },
+        "models.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "clientId": {
+                    "type": "string",
+                    "example": "billing-service"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-06-30T09:00:00Z"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "deposit",
+                        "low_balance"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9e4c2f1a-6b7d-4c8e-a0f3-2d5b8c1e7f90"
+                },
+                "lowBalanceThreshold": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://billing.example.com/hooks/wallet"
+                },
+                "walletId": {
                     "type": "string",
                     "example": "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
                 }
@@ -164,7 +2661,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "",
-	BasePath:         "/api/v1",
+	BasePath:         "/api",
 	Schemes:          []string{},
 	Title:            "Wallet API",
 	Description:      "API for wallet operation",
@@ -0,0 +1,133 @@
+package bulk_test
+
+import (
+	"JavaCode/internal/bulk"
+	"JavaCode/internal/models"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readAll returns the wallets and the row errors of an import file.
+func readAll(t *testing.T, input, format string) ([]models.WalletImport, []int, []int) {
+	t.Helper()
+	reader, err := bulk.NewWalletReader(strings.NewReader(input), format)
+	if err != nil {
+		t.Fatalf("NewWalletReader: %v", err)
+	}
+	var (
+		records   []models.WalletImport
+		lines     []int
+		errorRows []int
+	)
+	for {
+		record, line, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, lines, errorRows
+		}
+		var rowErr *bulk.RowError
+		if errors.As(err, &rowErr) {
+			errorRows = append(errorRows, rowErr.Line)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+}
+
+func TestWalletReader_JSONL(t *testing.T) {
+	input := `{"id":"f4c863ec-0300-495d-852d-c115e197390b","currency":"EUR","balance":1500,"labels":{"tier":"gold"},"reference":"ACC-17"}
+
+{"currency":"RUB","balance":
+{"currency":"USD","createdAt":"2025-06-01T00:00:00Z"}
+`
+	records, lines, errorRows := readAll(t, input, bulk.JSONL)
+
+	want := []models.WalletImport{
+		{
+			Id: "f4c863ec-0300-495d-852d-c115e197390b", Currency: "EUR", Balance: 1500,
+			Labels: map[string]string{"tier": "gold"}, OperationDetails: models.OperationDetails{Reference: "ACC-17"},
+		},
+		{Currency: "USD"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records: got %+v, want %+v", records, want)
+	}
+	if !reflect.DeepEqual(lines, []int{1, 4}) || !reflect.DeepEqual(errorRows, []int{3}) {
+		t.Errorf("lines: got %v and errors %v, want [1 4] and [3]", lines, errorRows)
+	}
+}
+
+func TestWalletReader_CSV(t *testing.T) {
+	input := "\ufeffid,currency,balance,status,depositsBlocked,owner,labels,reference,metadata,createdAt\n" +
+		`,EUR,1500,FROZEN,true,legacy-17,"{""tier"":""gold""}",ACC-17,"{""branch"":""north""}",2025-06-01` + "\n" +
+		",RUB,-5,,,,,,,\n" +
+		",USD\n" +
+		",JPY,0,,,,,,,\n"
+	records, lines, errorRows := readAll(t, input, bulk.CSV)
+
+	want := []models.WalletImport{
+		{
+			Currency: "EUR", Balance: 1500, Status: "FROZEN", DepositsBlocked: true, Owner: "legacy-17",
+			Labels: map[string]string{"tier": "gold"},
+			OperationDetails: models.OperationDetails{
+				Reference: "ACC-17", Metadata: map[string]string{"branch": "north"},
+			},
+		},
+		{Currency: "JPY"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records: got %+v, want %+v", records, want)
+	}
+	if !reflect.DeepEqual(lines, []int{2, 5}) || !reflect.DeepEqual(errorRows, []int{3, 4}) {
+		t.Errorf("lines: got %v and errors %v, want [2 5] and [3 4]", lines, errorRows)
+	}
+}
+
+func TestWalletReader_CSVMissingCurrency(t *testing.T) {
+	reader, _ := bulk.NewWalletReader(strings.NewReader("id,balance\n,1\n"), bulk.CSV)
+	_, _, err := reader.Read()
+	var rowErr *bulk.RowError
+	if err == nil || errors.As(err, &rowErr) {
+		t.Errorf("Read: got %v, want a header error", err)
+	}
+}
+
+func TestWriter_CSV(t *testing.T) {
+	var out bytes.Buffer
+	writer, _ := bulk.NewWriter(&out, bulk.CSV)
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, wallet := range []models.WalletExport{
+		{Id: "f4c863ec-0300-495d-852d-c115e197390b", Currency: "EUR", Balance: 1500, Status: "ACTIVE",
+			Labels: map[string]string{"tier": "gold"}, CreatedAt: created, UpdatedAt: created},
+		{Id: "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c", Currency: "RUB", Status: "CLOSED", Name: "a, b",
+			Labels: map[string]string{}, CreatedAt: created, UpdatedAt: created},
+	} {
+		if err := writer.WriteWallet(&wallet); err != nil {
+			t.Fatalf("WriteWallet: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := "id,currency,balance,status,depositsBlocked,owner,name,labels,createdAt,updatedAt\n" +
+		`f4c863ec-0300-495d-852d-c115e197390b,EUR,1500,ACTIVE,false,,,"{""tier"":""gold""}",2025-06-01T12:00:00Z,2025-06-01T12:00:00Z` + "\n" +
+		`1c63a43f-aacd-47b0-bc3b-535e69c6ed4c,RUB,0,CLOSED,false,,"a, b",{},2025-06-01T12:00:00Z,2025-06-01T12:00:00Z` + "\n"
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	// An export can be imported again.
+	records, _, errorRows := readAll(t, out.String(), bulk.CSV)
+	if len(records) != 2 || len(errorRows) != 0 || records[0].Labels["tier"] != "gold" || records[1].Name != "a, b" {
+		t.Errorf("reimported: got %+v, errors %v", records, errorRows)
+	}
+}
@@ -0,0 +1,7 @@
+// Package bulk reads and writes the files of the wallet import and export:
+// JSON Lines, one object per line, or CSV with a header row.
+//
+// Both are processed one record at a time, so files of any size are
+// handled in constant memory. In CSV files labels and metadata are JSON
+// objects in a single column.
+package bulk
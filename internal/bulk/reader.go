@@ -0,0 +1,175 @@
+package bulk
+
+import (
+	"JavaCode/internal/models"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// File formats.
+const (
+	JSONL = "jsonl"
+	CSV   = "csv"
+)
+
+// maxLineSize caps the length of a JSON line.
+const maxLineSize = 1 << 20
+
+// RowError is a malformed row. Reading can continue with the next row.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// WalletReader reads the wallets of an import file.
+type WalletReader struct {
+	next func() (models.WalletImport, int, error)
+}
+
+// NewWalletReader returns a reader of wallets in the given format.
+func NewWalletReader(r io.Reader, format string) (*WalletReader, error) {
+	switch format {
+	case JSONL:
+		return newJSONLReader(r), nil
+	case CSV:
+		return newCSVReader(r), nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// Read returns the next wallet and its line number.
+//
+// It returns io.EOF after the last wallet, a *RowError for a malformed row,
+// and any other error if the file cannot be read further.
+func (r *WalletReader) Read() (models.WalletImport, int, error) {
+	return r.next()
+}
+
+func newJSONLReader(r io.Reader) *WalletReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	line := 0
+	return &WalletReader{next: func() (models.WalletImport, int, error) {
+		for scanner.Scan() {
+			line++
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+			var record models.WalletImport
+			if err := json.Unmarshal(data, &record); err != nil {
+				return record, line, &RowError{Line: line, Err: err}
+			}
+			return record, line, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return models.WalletImport{}, line + 1, fmt.Errorf("line %d: %w", line+1, err)
+		}
+		return models.WalletImport{}, line, io.EOF
+	}}
+}
+
+func newCSVReader(r io.Reader) *WalletReader {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	var columns map[string]int
+	return &WalletReader{next: func() (models.WalletImport, int, error) {
+		if columns == nil {
+			header, err := reader.Read()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return models.WalletImport{}, 0, io.EOF
+				}
+				return models.WalletImport{}, 1, fmt.Errorf("header: %w", err)
+			}
+			columns = map[string]int{}
+			for i, name := range header {
+				if i == 0 {
+					name = strings.TrimPrefix(name, "\ufeff") // byte order mark written by spreadsheets
+				}
+				columns[strings.TrimSpace(name)] = i
+			}
+			if _, ok := columns["currency"]; !ok {
+				return models.WalletImport{}, 1, errors.New("header: missing the currency column")
+			}
+		}
+
+		row, err := reader.Read()
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return models.WalletImport{}, parseErr.Line, err
+			}
+			return models.WalletImport{}, 0, err
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return models.WalletImport{}, line, &RowError{Line: line, Err: err}
+		}
+		record, err := parseCSVWallet(columns, row)
+		if err != nil {
+			return record, line, &RowError{Line: line, Err: err}
+		}
+		return record, line, nil
+	}}
+}
+
+// parseCSVWallet reads a wallet from a row with the given columns; unknown
+// columns are ignored.
+func parseCSVWallet(columns map[string]int, row []string) (models.WalletImport, error) {
+	value := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	record := models.WalletImport{
+		Id:       value("id"),
+		Currency: value("currency"),
+		Status:   value("status"),
+		Owner:    value("owner"),
+		Name:     value("name"),
+		OperationDetails: models.OperationDetails{
+			Description: value("description"),
+			Reference:   value("reference"),
+		},
+	}
+	var err error
+	if raw := value("balance"); raw != "" {
+		if record.Balance, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			return record, fmt.Errorf("balance: %w", err)
+		}
+	}
+	if raw := value("depositsBlocked"); raw != "" {
+		if record.DepositsBlocked, err = strconv.ParseBool(raw); err != nil {
+			return record, fmt.Errorf("depositsBlocked: %w", err)
+		}
+	}
+	if raw := value("labels"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &record.Labels); err != nil {
+			return record, fmt.Errorf("labels: %w", err)
+		}
+	}
+	if raw := value("metadata"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &record.Metadata); err != nil {
+			return record, fmt.Errorf("metadata: %w", err)
+		}
+	}
+	return record, nil
+}
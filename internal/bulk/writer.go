@@ -0,0 +1,119 @@
+package bulk
+
+import (
+	"JavaCode/internal/models"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Column headers of the CSV files.
+var (
+	walletColumns = []string{"id", "currency", "balance", "status", "depositsBlocked", "owner", "name", "labels",
+		"createdAt", "updatedAt"}
+	entryColumns = []string{"id", "transactionId", "transactionType", "accountId", "direction", "amount", "currency",
+		"createdAt", "description", "reference", "metadata"}
+	resultColumns = []string{"line", "id", "status", "error", "message"}
+)
+
+// Writer writes records of one kind to an export or report file.
+type Writer struct {
+	json   *json.Encoder
+	csv    *csv.Writer
+	header bool
+}
+
+// NewWriter returns a writer of records in the given format. Flush must be
+// called after the last record.
+func NewWriter(w io.Writer, format string) (*Writer, error) {
+	switch format {
+	case JSONL:
+		return &Writer{json: json.NewEncoder(w)}, nil
+	case CSV:
+		return &Writer{csv: csv.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// NewWalletExport converts a wallet to its export record.
+func NewWalletExport(wallet *models.Wallet) models.WalletExport {
+	return models.WalletExport{
+		Id:              wallet.Id,
+		Currency:        wallet.Currency,
+		Balance:         wallet.Balance,
+		Status:          wallet.Status,
+		DepositsBlocked: wallet.DepositsBlocked,
+		Owner:           wallet.Owner,
+		Name:            wallet.Name,
+		Labels:          wallet.Labels,
+		CreatedAt:       wallet.CreatedTime,
+		UpdatedAt:       wallet.UpdatedTime,
+	}
+}
+
+// WriteWallet writes an exported wallet.
+func (w *Writer) WriteWallet(wallet *models.WalletExport) error {
+	if w.json != nil {
+		return w.json.Encode(wallet)
+	}
+	labels, err := json.Marshal(wallet.Labels)
+	if err != nil {
+		return err
+	}
+	return w.writeRow(walletColumns, []string{
+		wallet.Id, wallet.Currency, strconv.FormatUint(wallet.Balance, 10), wallet.Status,
+		strconv.FormatBool(wallet.DepositsBlocked), wallet.Owner, wallet.Name, string(labels),
+		wallet.CreatedAt.UTC().Format(time.RFC3339Nano), wallet.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	})
+}
+
+// WriteLedgerEntry writes an exported ledger entry of a wallet.
+func (w *Writer) WriteLedgerEntry(entry *models.LedgerEntryResponse) error {
+	if w.json != nil {
+		return w.json.Encode(entry)
+	}
+	metadata := []byte("{}")
+	if len(entry.Metadata) > 0 {
+		var err error
+		if metadata, err = json.Marshal(entry.Metadata); err != nil {
+			return err
+		}
+	}
+	return w.writeRow(entryColumns, []string{
+		strconv.FormatInt(entry.Id, 10), entry.TransactionId, entry.TransactionType, entry.AccountId,
+		entry.Direction, strconv.FormatInt(entry.Amount, 10), entry.Currency,
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano), entry.Description, entry.Reference, string(metadata),
+	})
+}
+
+// WriteImportResult writes the outcome of an imported row.
+func (w *Writer) WriteImportResult(result *models.WalletImportResult) error {
+	if w.json != nil {
+		return w.json.Encode(result)
+	}
+	return w.writeRow(resultColumns, []string{
+		strconv.Itoa(result.Line), result.Id, result.Status, result.Error, result.Message,
+	})
+}
+
+// Flush writes any buffered data.
+func (w *Writer) Flush() error {
+	if w.csv == nil {
+		return nil
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *Writer) writeRow(header, row []string) error {
+	if !w.header {
+		w.header = true
+		if err := w.csv.Write(header); err != nil {
+			return err
+		}
+	}
+	return w.csv.Write(row)
+}
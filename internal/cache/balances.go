@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"JavaCode/internal/models"
+	"hash/fnv"
+	"sync"
+)
+
+const stripeCount = 256
+
+type stripe struct {
+	mu         sync.Mutex
+	generation uint64
+}
+
+// Balances guards a Store against serving balances older than a write
+// committed by this process.
+//
+// Every invalidation bumps the generation of the wallet's stripe; a reader
+// may only fill the cache with a value read after taking the current
+// generation, so a read that raced with a commit is never cached.
+//
+// A nil *Balances is valid and disables caching.
+type Balances struct {
+	store   Store
+	stripes [stripeCount]stripe
+}
+
+// NewBalances wraps store with write invalidation tracking.
+func NewBalances(store Store) *Balances {
+	return &Balances{store: store}
+}
+
+// Get returns the cached wallet, if any.
+func (b *Balances) Get(walletID string) (models.Wallet, bool) {
+	if b == nil {
+		return models.Wallet{}, false
+	}
+	return b.store.Get(walletID)
+}
+
+// Generation returns the invalidation generation to pass to Fill.
+// It must be taken before reading the wallet from the database.
+func (b *Balances) Generation(walletID string) uint64 {
+	if b == nil {
+		return 0
+	}
+	s := b.stripe(walletID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generation
+}
+
+// Fill caches a wallet read from the database, unless the wallet's stripe
+// was invalidated since generation was taken.
+func (b *Balances) Fill(wallet models.Wallet, generation uint64) {
+	if b == nil {
+		return
+	}
+	s := b.stripe(wallet.Id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation == generation {
+		b.store.Set(wallet)
+	}
+}
+
+// Invalidate drops the cached wallet after a write and prevents in-flight
+// reads that started before it from filling the cache.
+func (b *Balances) Invalidate(walletID string) {
+	if b == nil {
+		return
+	}
+	s := b.stripe(walletID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	b.store.Delete(walletID)
+}
+
+func (b *Balances) stripe(walletID string) *stripe {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(walletID))
+	return &b.stripes[h.Sum32()%stripeCount]
+}
@@ -0,0 +1,105 @@
+package cache_test
+
+import (
+	"JavaCode/internal/cache"
+	"JavaCode/internal/models"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	t.Run("Test 1: Get after Set", func(t *testing.T) {
+		lru := cache.NewLRU(2, time.Minute)
+		lru.Set(models.Wallet{Id: "a", Balance: 100})
+
+		wallet, ok := lru.Get("a")
+		if !ok || wallet.Balance != 100 {
+			t.Errorf("Get: got (%+v, %v), want balance 100", wallet, ok)
+		}
+	})
+
+	t.Run("Test 2: Evicts least recently used", func(t *testing.T) {
+		lru := cache.NewLRU(2, time.Minute)
+		lru.Set(models.Wallet{Id: "a"})
+		lru.Set(models.Wallet{Id: "b"})
+		lru.Get("a")
+		lru.Set(models.Wallet{Id: "c"})
+
+		if _, ok := lru.Get("b"); ok {
+			t.Error("expected b to be evicted")
+		}
+		if _, ok := lru.Get("a"); !ok {
+			t.Error("expected a to be kept")
+		}
+		if lru.Len() != 2 {
+			t.Errorf("Len: got %d, want 2", lru.Len())
+		}
+	})
+
+	t.Run("Test 3: Entries expire", func(t *testing.T) {
+		lru := cache.NewLRU(2, time.Millisecond)
+		lru.Set(models.Wallet{Id: "a"})
+		time.Sleep(5 * time.Millisecond)
+
+		if _, ok := lru.Get("a"); ok {
+			t.Error("expected expired entry to be missed")
+		}
+	})
+
+	t.Run("Test 4: Delete", func(t *testing.T) {
+		lru := cache.NewLRU(2, time.Minute)
+		lru.Set(models.Wallet{Id: "a"})
+		lru.Delete("a")
+
+		if _, ok := lru.Get("a"); ok {
+			t.Error("expected deleted entry to be missed")
+		}
+	})
+}
+
+func TestBalances(t *testing.T) {
+	t.Run("Test 1: Fill caches wallet", func(t *testing.T) {
+		balances := cache.NewBalances(cache.NewLRU(10, time.Minute))
+
+		generation := balances.Generation("a")
+		balances.Fill(models.Wallet{Id: "a", Balance: 100}, generation)
+
+		if wallet, ok := balances.Get("a"); !ok || wallet.Balance != 100 {
+			t.Errorf("Get: got (%+v, %v), want balance 100", wallet, ok)
+		}
+	})
+
+	t.Run("Test 2: Read racing with a write is not cached", func(t *testing.T) {
+		balances := cache.NewBalances(cache.NewLRU(10, time.Minute))
+
+		generation := balances.Generation("a")
+		balances.Invalidate("a")
+		balances.Fill(models.Wallet{Id: "a", Balance: 100}, generation)
+
+		if _, ok := balances.Get("a"); ok {
+			t.Error("expected stale read not to be cached")
+		}
+	})
+
+	t.Run("Test 3: Invalidate drops cached wallet", func(t *testing.T) {
+		balances := cache.NewBalances(cache.NewLRU(10, time.Minute))
+
+		balances.Fill(models.Wallet{Id: "a", Balance: 100}, balances.Generation("a"))
+		balances.Invalidate("a")
+
+		if _, ok := balances.Get("a"); ok {
+			t.Error("expected invalidated wallet to be missed")
+		}
+	})
+
+	t.Run("Test 4: Nil disables caching", func(t *testing.T) {
+		var balances *cache.Balances
+
+		balances.Fill(models.Wallet{Id: "a"}, balances.Generation("a"))
+		balances.Invalidate("a")
+
+		if _, ok := balances.Get("a"); ok {
+			t.Error("expected nil cache to always miss")
+		}
+	})
+}
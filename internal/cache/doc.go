@@ -0,0 +1,6 @@
+// Package cache provides an optional read cache for wallet balances.
+//
+// It defines a pluggable Store interface (implemented in-process by LRU,
+// and by shared caches elsewhere) and a Balances guard that keeps the cache
+// coherent with writes committed by the same process.
+package cache
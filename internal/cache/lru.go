@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"JavaCode/internal/models"
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Store is a key-value store of wallets keyed by wallet UUID.
+//
+// Implementations must be safe for concurrent use. A shared cache
+// (e.g. Redis) can be plugged in by implementing this interface.
+type Store interface {
+	Get(walletID string) (models.Wallet, bool)
+	Set(wallet models.Wallet)
+	Delete(walletID string)
+}
+
+type lruEntry struct {
+	wallet    models.Wallet
+	expiresAt time.Time
+}
+
+// LRU is an in-process least-recently-used Store with per-entry TTL.
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List
+	items    map[string]*list.Element
+	now      func() time.Time
+}
+
+// NewLRU creates an LRU holding at most capacity wallets, each for at most ttl.
+// A zero ttl keeps entries until they are evicted or deleted.
+func NewLRU(capacity int, ttl time.Duration) *LRU {
+	return &LRU{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Get returns the cached wallet if present and not expired.
+func (l *LRU) Get(walletID string) (models.Wallet, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[walletID]
+	if !ok {
+		return models.Wallet{}, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if l.ttl > 0 && l.now().After(entry.expiresAt) {
+		l.removeElement(elem)
+		return models.Wallet{}, false
+	}
+
+	l.order.MoveToFront(elem)
+	return entry.wallet, true
+}
+
+// Set stores the wallet, evicting the least recently used one if full.
+func (l *LRU) Set(wallet models.Wallet) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.capacity <= 0 {
+		return
+	}
+
+	expiresAt := l.now().Add(l.ttl)
+	if elem, ok := l.items[wallet.Id]; ok {
+		elem.Value = &lruEntry{wallet: wallet, expiresAt: expiresAt}
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.items[wallet.Id] = l.order.PushFront(&lruEntry{wallet: wallet, expiresAt: expiresAt})
+	for l.order.Len() > l.capacity {
+		l.removeElement(l.order.Back())
+	}
+}
+
+// Delete removes the wallet from the cache.
+func (l *LRU) Delete(walletID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.items[walletID]; ok {
+		l.removeElement(elem)
+	}
+}
+
+// Len returns the number of cached wallets, including expired ones not yet removed.
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+func (l *LRU) removeElement(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.items, elem.Value.(*lruEntry).wallet.Id)
+}
@@ -1,9 +1,45 @@
 package controllers
 
 import (
+	"JavaCode/internal/cache"
+	"JavaCode/internal/stream"
 	"database/sql"
+	"sync/atomic"
+	"time"
 )
 
 type Controller struct {
 	DB *sql.DB
+
+	// Replicas are optional read replicas used for balance reads.
+	Replicas []*sql.DB
+
+	// Cache is the optional balance read cache; nil disables it.
+	Cache *cache.Balances
+
+	// Events wakes balance streams when their wallet has new events; nil
+	// makes them poll every second.
+	Events stream.Notifier
+
+	// StreamHeartbeat is the keep-alive period of idle balance streams.
+	StreamHeartbeat time.Duration
+
+	// MaxRateAge is how long a rate without validTo may be used for
+	// transfers after it took effect; 0 means until a newer rate is published.
+	MaxRateAge time.Duration
+
+	// SchemaVersion is the migration version /readyz requires; 0 skips the check.
+	SchemaVersion int64
+
+	next atomic.Uint32
+}
+
+// replica returns the next read replica in round-robin order,
+// or nil if none are configured.
+func (controller *Controller) replica() *sql.DB {
+	if len(controller.Replicas) == 0 {
+		return nil
+	}
+	i := controller.next.Add(1) - 1
+	return controller.Replicas[int(i%uint32(len(controller.Replicas)))]
 }
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/pkg/currency"
+	"JavaCode/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// CreateExchangeRateHandler godoc
+// @Summary      Publish an exchange rate
+// @Description  Store a conversion rate between two currencies. Transfers use the latest rate whose validity has started.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateExchangeRateRequest  true  "Rate parameters"
+// @Success      201      {object}  models.ExchangeRateResponse
+// @Failure      400      {object}  utils.ErrorResponse               "Invalid request / unsupported currency"
+// @Failure      500      {object}  utils.ErrorResponse               "Internal server error"
+// @Router       /v1/admin/rates [post]
+func (controller *Controller) CreateExchangeRateHandler(c *gin.Context) {
+	var request models.CreateExchangeRateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Logger.WithError(err).Warn("bad JSON body")
+		utils.HandleError(c, utils.ErrInvalidRequest)
+		return
+	}
+
+	rate, err := service.CreateExchangeRateService(controller.DB, request)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service CreateExchangeRateService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewExchangeRateResponse(rate))
+}
+
+// ListExchangeRatesHandler godoc
+// @Summary      List exchange rates
+// @Description  Return stored exchange rates, newest first within each currency pair.
+// @Tags         admin
+// @Produce      json
+// @Param        base   query     string  false  "Base currency filter"
+// @Param        quote  query     string  false  "Quote currency filter"
+// @Success      200    {array}   models.ExchangeRateResponse
+// @Failure      500    {object}  utils.ErrorResponse  "Internal server error"
+// @Router       /v1/admin/rates [get]
+func (controller *Controller) ListExchangeRatesHandler(c *gin.Context) {
+	rates, err := service.ListExchangeRatesService(controller.DB, c.Query("base"), c.Query("quote"))
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service ListExchangeRatesService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	response := make([]models.ExchangeRateResponse, 0, len(rates))
+	for i := range rates {
+		response = append(response, NewExchangeRateResponse(&rates[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// NewExchangeRateResponse converts an exchange rate to its API representation.
+func NewExchangeRateResponse(rate *models.ExchangeRate) models.ExchangeRateResponse {
+	return models.ExchangeRateResponse{
+		Id:            rate.Id,
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          currency.FormatRate(rate.RateUnits, rate.Precision),
+		Precision:     rate.Precision,
+		RoundingMode:  rate.RoundingMode,
+		ValidFrom:     rate.ValidFrom,
+		ValidTo:       rate.ValidTo,
+	}
+}
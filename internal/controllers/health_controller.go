@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// LivenessHandler reports that the process is running (GET /healthz).
+func (controller *Controller) LivenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, models.HealthResponse{Status: "ok"})
+}
+
+// ReadinessHandler reports whether the service can take traffic (GET /readyz).
+//
+// It responds 503 if the database is unreachable or its schema is older
+// than the migrations embedded in the binary.
+func (controller *Controller) ReadinessHandler(c *gin.Context) {
+	version, err := service.ReadinessService(controller.DB, controller.SchemaVersion)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("readiness check failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HealthResponse{Status: "ok", SchemaVersion: version})
+}
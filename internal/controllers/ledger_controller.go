@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// ListLedgerEntriesHandler godoc
+// @Summary      List ledger entries of a wallet
+// @Description  Return the most recent debit and credit entries posted to a wallet account, newest first, with the description, reference and metadata of their operation.
+// @Tags         admin
+// @Produce      json
+// @Param        WALLET_UUID  path      string  true   "UUID wallet"
+// @Param        reference    query     string  false  "Only entries of the operation with this reference"
+// @Param        limit        query     int     false  "Maximum number of entries (default 100, max 1000)"
+// @Success      200          {array}   models.LedgerEntryResponse
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Router       /v1/admin/wallets/{WALLET_UUID}/entries [get]
+func (controller *Controller) ListLedgerEntriesHandler(c *gin.Context) {
+	walletUUID := c.Param("WALLET_UUID")
+	if err := ValidateUUID(walletUUID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 {
+			utils.Logger.Warnf("invalid limit: %q", raw)
+			utils.HandleError(c, utils.ErrInvalidRequest)
+			return
+		}
+	}
+
+	entries, err := service.ListLedgerEntriesService(controller.DB, walletUUID, c.Query("reference"), limit)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service ListLedgerEntriesService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	response := make([]models.LedgerEntryResponse, 0, len(entries))
+	for i := range entries {
+		response = append(response, NewLedgerEntryResponse(&entries[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// NewLedgerEntryResponse converts a ledger entry to its API representation.
+func NewLedgerEntryResponse(entry *models.LedgerEntry) models.LedgerEntryResponse {
+	return models.LedgerEntryResponse{
+		Id:               entry.Id,
+		TransactionId:    entry.TransactionId,
+		TransactionType:  entry.TransactionType,
+		AccountId:        entry.AccountId,
+		Direction:        entry.Direction,
+		Amount:           entry.Amount,
+		Currency:         entry.Currency,
+		CreatedAt:        entry.CreatedTime,
+		OperationDetails: entry.Details,
+	}
+}
+
+// VerifyWalletBalanceHandler godoc
+// @Summary      Verify a wallet balance against the ledger
+// @Description  Compare the stored balance of a wallet with the sum of its ledger entries.
+// @Tags         admin
+// @Produce      json
+// @Param        WALLET_UUID  path      string  true  "UUID wallet"
+// @Success      200          {object}  models.BalanceVerification
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Router       /v1/admin/wallets/{WALLET_UUID}/verify [get]
+func (controller *Controller) VerifyWalletBalanceHandler(c *gin.Context) {
+	walletUUID := c.Param("WALLET_UUID")
+	if err := ValidateUUID(walletUUID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	verification, err := service.VerifyWalletBalanceService(controller.DB, walletUUID)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service VerifyWalletBalanceService failed")
+		utils.HandleError(c, err)
+		return
+	}
+	if !verification.Consistent {
+		utils.Logger.Errorf("wallet %s balance %d does not match the ledger balance %d",
+			walletUUID, verification.Balance, verification.LedgerBalance)
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
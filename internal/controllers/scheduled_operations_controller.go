@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// CreateScheduledOperationHandler godoc
+// @Summary      Schedule a deposit or withdrawal
+// @Description  Schedule an operation to run once at startAt (now if not given), or on a recurrence: daily, weekly or monthly at the time of startAt, or a five-field cron expression in UTC, until endAt. A failed occurrence, e.g. for insufficient funds, is recorded and retried up to maxRetries times (at most 10) with exponential backoff, then skipped.
+// @Tags         scheduled-operations
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateScheduledOperationRequest  true  "Operation and schedule"
+// @Success      201      {object}  models.ScheduledOperationResponse
+// @Failure      400      {object}  utils.ErrorResponse  "Invalid request, schedule or time range"
+// @Failure      404      {object}  utils.ErrorResponse  "Wallet not found"
+// @Failure      409      {object}  utils.ErrorResponse  "Wallet closed"
+// @Failure      422      {object}  utils.ErrorResponse  "Currency mismatch"
+// @Failure      500      {object}  utils.ErrorResponse  "Internal server error"
+// @Router       /v1/scheduled-operations [post]
+func (controller *Controller) CreateScheduledOperationHandler(c *gin.Context) {
+	var request models.CreateScheduledOperationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Logger.WithError(err).Warn("bad JSON body")
+		utils.HandleError(c, utils.ErrInvalidRequest)
+		return
+	}
+
+	operation, err := service.CreateScheduledOperationService(controller.DB, request)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service CreateScheduledOperationService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewScheduledOperationResponse(operation))
+}
+
+// ListScheduledOperationsHandler godoc
+// @Summary      List scheduled operations
+// @Description  Return the scheduled operations, oldest first.
+// @Tags         scheduled-operations
+// @Produce      json
+// @Param        walletId  query     string  false  "Only the operations of this wallet"
+// @Param        status    query     string  false  "ACTIVE, COMPLETED or CANCELLED"
+// @Success      200       {array}   models.ScheduledOperationResponse
+// @Failure      400       {object}  utils.ErrorResponse
+// @Failure      500       {object}  utils.ErrorResponse  "Internal server error"
+// @Router       /v1/scheduled-operations [get]
+func (controller *Controller) ListScheduledOperationsHandler(c *gin.Context) {
+	operations, err := service.ListScheduledOperationsService(controller.DB, c.Query("walletId"), c.Query("status"))
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service ListScheduledOperationsService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	response := make([]models.ScheduledOperationResponse, 0, len(operations))
+	for i := range operations {
+		response = append(response, NewScheduledOperationResponse(&operations[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetScheduledOperationHandler godoc
+// @Summary      Get a scheduled operation
+// @Tags         scheduled-operations
+// @Produce      json
+// @Param        SCHEDULE_ID  path      string  true  "Scheduled operation UUID"
+// @Success      200          {object}  models.ScheduledOperationResponse
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Router       /v1/scheduled-operations/{SCHEDULE_ID} [get]
+func (controller *Controller) GetScheduledOperationHandler(c *gin.Context) {
+	scheduleID := c.Param("SCHEDULE_ID")
+	if err := ValidateUUID(scheduleID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	operation, err := service.GetScheduledOperationService(controller.DB, scheduleID)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service GetScheduledOperationService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewScheduledOperationResponse(operation))
+}
+
+// CancelScheduledOperationHandler godoc
+// @Summary      Cancel a scheduled operation
+// @Description  Stop an active scheduled operation before its next occurrence. Its runs are kept.
+// @Tags         scheduled-operations
+// @Produce      json
+// @Param        SCHEDULE_ID  path      string  true  "Scheduled operation UUID"
+// @Success      200          {object}  models.ScheduledOperationResponse
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Failure      409          {object}  utils.ErrorResponse  "Already completed or cancelled"
+// @Router       /v1/scheduled-operations/{SCHEDULE_ID} [delete]
+func (controller *Controller) CancelScheduledOperationHandler(c *gin.Context) {
+	scheduleID := c.Param("SCHEDULE_ID")
+	if err := ValidateUUID(scheduleID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	operation, err := service.CancelScheduledOperationService(controller.DB, scheduleID)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service CancelScheduledOperationService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewScheduledOperationResponse(operation))
+}
+
+// ListScheduledRunsHandler godoc
+// @Summary      List the runs of a scheduled operation
+// @Description  Return the attempts to execute the operation, newest first, with the error code of failed attempts.
+// @Tags         scheduled-operations
+// @Produce      json
+// @Param        SCHEDULE_ID  path      string  true   "Scheduled operation UUID"
+// @Param        limit        query     int     false  "Maximum number of runs (default 100, max 1000)"
+// @Success      200          {array}   models.ScheduledRunResponse
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Router       /v1/scheduled-operations/{SCHEDULE_ID}/runs [get]
+func (controller *Controller) ListScheduledRunsHandler(c *gin.Context) {
+	scheduleID := c.Param("SCHEDULE_ID")
+	if err := ValidateUUID(scheduleID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 {
+			utils.Logger.Warnf("invalid limit: %q", raw)
+			utils.HandleError(c, utils.ErrInvalidRequest)
+			return
+		}
+	}
+
+	runs, err := service.ListScheduledRunsService(controller.DB, scheduleID, limit)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service ListScheduledRunsService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	response := make([]models.ScheduledRunResponse, 0, len(runs))
+	for _, run := range runs {
+		response = append(response, models.ScheduledRunResponse{
+			Id:         run.Id,
+			RunAt:      run.RunTime,
+			Attempt:    run.Attempt,
+			Status:     run.Status,
+			Reference:  run.Reference,
+			Error:      run.Error,
+			ExecutedAt: run.ExecutedTime,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// NewScheduledOperationResponse converts a scheduled operation to its API representation.
+func NewScheduledOperationResponse(operation *models.ScheduledOperation) models.ScheduledOperationResponse {
+	metadata := operation.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	return models.ScheduledOperationResponse{
+		Id:            operation.Id,
+		WalletId:      operation.WalletId,
+		OperationType: operation.OperationType,
+		Amount:        operation.Amount,
+		Currency:      operation.Currency,
+		Description:   operation.Description,
+		Metadata:      metadata,
+		Schedule:      operation.Schedule,
+		StartAt:       operation.StartTime,
+		EndAt:         operation.EndTime,
+		MaxRetries:    operation.MaxRetries,
+		Status:        operation.Status,
+		NextRunAt:     operation.NextRunTime,
+		Attempts:      operation.Attempts,
+		NextAttemptAt: operation.NextAttemptTime,
+		CreatedAt:     operation.CreatedTime,
+	}
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/pkg/currency"
+	"JavaCode/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// TransferHandler godoc
+// @Summary      Transfer between wallets
+// @Description  Debit one wallet and credit another. If the wallets use different currencies the amount is converted at the current rate, which is recorded on the transfer.
+// @Tags         transfer
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.TransferRequest  true  "Transfer parameters"
+// @Success      201      {object}  models.TransferResponse
+// @Header       201      {string}  X-Consistency-Token     "Read-your-writes token (only with replicas)"
+// @Failure      400      {object}  utils.ErrorResponse     "Invalid request / negative amount / unsupported currency"
+// @Failure      404      {object}  utils.ErrorResponse     "Wallet not found"
+// @Failure      422      {object}  utils.ErrorResponse     "Currency mismatch / no rate / stale rate"
+// @Failure      500      {object}  utils.ErrorResponse     "Internal server error"
+// @Router       /v1/transfers [post]
+func (controller *Controller) TransferHandler(c *gin.Context) {
+	var request models.TransferRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Logger.WithError(err).Warn("bad JSON body")
+		utils.HandleError(c, utils.ErrInvalidRequest)
+		return
+	}
+
+	transfer, err := controller.transfer(c, request)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewTransferResponse(transfer))
+}
+
+// transfer validates and executes a transfer, setting the consistency
+// token header on success.
+func (controller *Controller) transfer(c *gin.Context, request models.TransferRequest) (*models.Transfer, error) {
+	if err := service.ValidateTransferRequest(request); err != nil {
+		utils.Logger.WithError(err).Warn("invalid transfer request")
+		return nil, err
+	}
+
+	transfer, err := service.TransferService(controller.DB, controller.Cache, request, controller.MaxRateAge)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service TransferService failed")
+		return nil, err
+	}
+
+	controller.setConsistencyToken(c)
+	return transfer, nil
+}
+
+// GetTransferHandler godoc
+// @Summary      Get a transfer
+// @Description  Return a transfer with the rate applied to it, if any.
+// @Tags         transfer
+// @Produce      json
+// @Param        TRANSFER_ID  path      string  true  "Transfer UUID"
+// @Success      200          {object}  models.TransferResponse
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Router       /v1/transfers/{TRANSFER_ID} [get]
+func (controller *Controller) GetTransferHandler(c *gin.Context) {
+	transferID := c.Param("TRANSFER_ID")
+	if err := ValidateUUID(transferID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	transfer, err := service.GetTransferService(controller.DB, transferID)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service GetTransferService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewTransferResponse(transfer))
+}
+
+// NewTransferResponse converts a transfer to its API representation.
+func NewTransferResponse(transfer *models.Transfer) models.TransferResponse {
+	response := models.TransferResponse{
+		Id:             transfer.Id,
+		FromWalletId:   transfer.FromWalletId,
+		ToWalletId:     transfer.ToWalletId,
+		SourceAmount:   transfer.SourceAmount,
+		SourceCurrency: transfer.SourceCurrency,
+		TargetAmount:   transfer.TargetAmount,
+		TargetCurrency: transfer.TargetCurrency,
+		RateId:         transfer.RateId,
+		CreatedAt:      transfer.CreatedTime,
+	}
+	if transfer.RateUnits != nil && transfer.RatePrecision != nil {
+		response.Rate = currency.FormatRate(*transfer.RateUnits, *transfer.RatePrecision)
+	}
+	if transfer.RoundingMode != nil {
+		response.RoundingMode = *transfer.RoundingMode
+	}
+	return response
+}
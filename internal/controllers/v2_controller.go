@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/pkg/currency"
+	"JavaCode/pkg/money"
+	"JavaCode/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetBalanceV2Handler godoc
+// @Summary  Get Balance
+// @Description  Return balance by UUID as a decimal amount with currency, optionally as of a past time
+// @Tags     wallet-v2
+// @Param    WALLET_UUID path string true "UUID wallet"
+// @Param    at query string false "RFC3339 timestamp to compute the balance at from the ledger"
+// @Param    consistency query string false "Set to \"strong\" to read from the primary"
+// @Param    X-Consistency-Token header string false "Token returned by a previous operation"
+// @Param    Cache-Control header string false "Set to \"no-cache\" to bypass the balance cache"
+// @Success  200 {object} models.BalanceResponseV2
+// @Failure  400 {object} utils.ErrorResponse
+// @Failure  404 {object} utils.ErrorResponse
+// @Failure  422 {object} utils.ErrorResponse "History unavailable before the wallet's opening balance"
+// @Router   /v2/wallets/{WALLET_UUID} [get]
+func (controller *Controller) GetBalanceV2Handler(c *gin.Context) {
+	wallet, err := controller.lookupWallet(c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	response, err := NewBalanceResponseV2(wallet)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateWalletV2Handler godoc
+// @Summary      Create a wallet
+// @Description  Create an empty wallet in the given currency. The currency cannot be changed later.
+// @Tags         wallet-v2
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateWalletRequest  true  "Wallet parameters"
+// @Success      201      {object}  models.BalanceResponseV2
+// @Failure      400      {object}  utils.ErrorResponse         "Invalid request / unsupported currency"
+// @Failure      500      {object}  utils.ErrorResponse         "Internal server error"
+// @Router       /v2/wallets [post]
+func (controller *Controller) CreateWalletV2Handler(c *gin.Context) {
+	wallet, err := controller.createWallet(c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	response, err := NewBalanceResponseV2(wallet)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// WalletOperationV2Handler godoc
+// @Summary      Perform a wallet operation
+// @Description  Deposit funds to, or withdraw funds from, a wallet. The amount is a decimal string with currency, e.g. "12.34 EUR".
+// @Tags         wallet-v2
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.WalletOperationRequestV2  true  "Operation parameters"
+// @Success      200      {object}  map[string]string                "Operation successful"
+// @Header       200      {string}  X-Consistency-Token              "Read-your-writes token (only with replicas)"
+// @Failure      400      {object}  utils.ErrorResponse              "Invalid request / invalid amount / excess precision / unsupported currency"
+// @Failure      404      {object}  utils.ErrorResponse              "Wallet not found"
+// @Failure      409      {object}  utils.ErrorResponse              "Wallet frozen or closed / duplicate reference"
+// @Failure      422      {object}  utils.ErrorResponse              "Currency does not match the wallet / amount overflow"
+// @Failure      500      {object}  utils.ErrorResponse              "Internal server error"
+// @Router       /v2/wallet [post]
+func (controller *Controller) WalletOperationV2Handler(c *gin.Context) {
+	var request models.WalletOperationRequestV2
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Logger.WithError(err).Warn("bad JSON body")
+		utils.HandleError(c, utils.ErrInvalidRequest)
+		return
+	}
+
+	amount, err := ParsePositiveAmount(request.Amount)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("invalid amount")
+		utils.HandleError(c, err)
+		return
+	}
+
+	err = controller.applyOperation(c, models.WalletOperationRequest{
+		WalletID:         request.WalletID,
+		OperationType:    request.OperationType,
+		Amount:           amount.Units,
+		Currency:         amount.Currency,
+		OperationDetails: request.OperationDetails,
+	})
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Operation successful"})
+}
+
+// TransferV2Handler godoc
+// @Summary      Transfer between wallets
+// @Description  Debit one wallet and credit another, converting at the current rate if the currencies differ. Amounts are decimal strings with currency.
+// @Tags         transfer-v2
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.TransferRequestV2  true  "Transfer parameters"
+// @Success      201      {object}  models.TransferResponseV2
+// @Header       201      {string}  X-Consistency-Token       "Read-your-writes token (only with replicas)"
+// @Failure      400      {object}  utils.ErrorResponse       "Invalid request / invalid amount / excess precision / unsupported currency"
+// @Failure      404      {object}  utils.ErrorResponse       "Wallet not found"
+// @Failure      422      {object}  utils.ErrorResponse       "Currency mismatch / no rate / stale rate / amount overflow"
+// @Failure      500      {object}  utils.ErrorResponse       "Internal server error"
+// @Router       /v2/transfers [post]
+func (controller *Controller) TransferV2Handler(c *gin.Context) {
+	var request models.TransferRequestV2
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Logger.WithError(err).Warn("bad JSON body")
+		utils.HandleError(c, utils.ErrInvalidRequest)
+		return
+	}
+
+	amount, err := ParsePositiveAmount(request.Amount)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("invalid amount")
+		utils.HandleError(c, err)
+		return
+	}
+
+	transfer, err := controller.transfer(c, models.TransferRequest{
+		FromWalletID: request.FromWalletID,
+		ToWalletID:   request.ToWalletID,
+		Amount:       amount.Units,
+		Currency:     amount.Currency,
+	})
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewTransferResponseV2(transfer))
+}
+
+// NewBalanceResponseV2 converts a wallet to its v2 API representation.
+//
+// It returns utils.ErrAmountOverflow if the balance does not fit in 64 bits.
+func NewBalanceResponseV2(wallet *models.Wallet) (models.BalanceResponseV2, error) {
+	balance, err := money.FromUnsigned(wallet.Balance, wallet.Currency)
+	if err != nil {
+		return models.BalanceResponseV2{}, utils.ErrAmountOverflow
+	}
+	return models.BalanceResponseV2{
+		Uuid:    wallet.Id,
+		Balance: balance.String(),
+		Status:  wallet.Status,
+		Owner:   wallet.Owner,
+		Name:    wallet.Name,
+		Labels:  wallet.Labels,
+	}, nil
+}
+
+// NewTransferResponseV2 converts a transfer to its v2 API representation.
+func NewTransferResponseV2(transfer *models.Transfer) models.TransferResponseV2 {
+	response := models.TransferResponseV2{
+		Id:           transfer.Id,
+		FromWalletId: transfer.FromWalletId,
+		ToWalletId:   transfer.ToWalletId,
+		SourceAmount: money.New(transfer.SourceAmount, transfer.SourceCurrency).String(),
+		TargetAmount: money.New(transfer.TargetAmount, transfer.TargetCurrency).String(),
+		RateId:       transfer.RateId,
+		CreatedAt:    transfer.CreatedTime,
+	}
+	if transfer.RateUnits != nil && transfer.RatePrecision != nil {
+		response.Rate = currency.FormatRate(*transfer.RateUnits, *transfer.RatePrecision)
+	}
+	if transfer.RoundingMode != nil {
+		response.RoundingMode = *transfer.RoundingMode
+	}
+	return response
+}
+
+// ParsePositiveAmount parses a v2 amount such as "12.34 EUR".
+//
+// Returns:
+//   - the amount if it is well-formed and greater than zero;
+//   - utils.ErrInvalidAmount if it is malformed, zero or negative;
+//   - utils.ErrAmountPrecision if it has more decimal places than the currency allows;
+//   - utils.ErrAmountOverflow if it does not fit in 64 bits;
+//   - utils.ErrUnsupportedCurrency if the currency is not supported.
+func ParsePositiveAmount(text string) (money.Money, error) {
+	amount, err := money.Parse(text)
+	switch {
+	case errors.Is(err, money.ErrPrecision):
+		return money.Money{}, utils.ErrAmountPrecision
+	case errors.Is(err, money.ErrOverflow):
+		return money.Money{}, utils.ErrAmountOverflow
+	case errors.Is(err, money.ErrCurrency):
+		return money.Money{}, utils.ErrUnsupportedCurrency
+	case err != nil:
+		return money.Money{}, utils.ErrInvalidAmount
+	}
+	if !amount.IsPositive() {
+		return money.Money{}, utils.ErrInvalidAmount
+	}
+	return amount, nil
+}
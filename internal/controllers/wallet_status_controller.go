@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"JavaCode/internal/cache"
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/utils"
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// FreezeWalletHandler godoc
+// @Summary      Freeze a wallet
+// @Description  Freeze an active wallet. Withdrawals and outgoing transfers are refused; with blockDeposits, deposits and incoming transfers too.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        WALLET_UUID  path      string                      true  "UUID wallet"
+// @Param        request      body      models.FreezeWalletRequest  true  "Reason and deposit blocking"
+// @Success      200          {object}  models.BalanceResponse
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Failure      409          {object}  utils.ErrorResponse  "Wallet is not active"
+// @Router       /v1/admin/wallets/{WALLET_UUID}/freeze [post]
+func (controller *Controller) FreezeWalletHandler(c *gin.Context) {
+	walletUUID := c.Param("WALLET_UUID")
+	if err := ValidateUUID(walletUUID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	var request models.FreezeWalletRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Logger.WithError(err).Warn("bad JSON body")
+		utils.HandleError(c, utils.ErrInvalidRequest)
+		return
+	}
+
+	wallet, err := service.FreezeWalletService(controller.DB, controller.Cache, walletUUID, request.Reason, request.BlockDeposits)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service FreezeWalletService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewBalanceResponse(wallet))
+}
+
+// UnfreezeWalletHandler godoc
+// @Summary      Unfreeze a wallet
+// @Description  Make a frozen wallet active again.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        WALLET_UUID  path      string                      true  "UUID wallet"
+// @Param        request      body      models.WalletStatusRequest  true  "Reason"
+// @Success      200          {object}  models.BalanceResponse
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Failure      409          {object}  utils.ErrorResponse  "Wallet is not frozen"
+// @Router       /v1/admin/wallets/{WALLET_UUID}/unfreeze [post]
+func (controller *Controller) UnfreezeWalletHandler(c *gin.Context) {
+	controller.changeWalletStatus(c, "UnfreezeWalletService", service.UnfreezeWalletService)
+}
+
+// CloseWalletHandler godoc
+// @Summary      Close a wallet
+// @Description  Close an active wallet with a zero balance. A closed wallet refuses every operation and cannot be reopened.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        WALLET_UUID  path      string                      true  "UUID wallet"
+// @Param        request      body      models.WalletStatusRequest  true  "Reason"
+// @Success      200          {object}  models.BalanceResponse
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Failure      409          {object}  utils.ErrorResponse  "Wallet is not active or its balance is not zero"
+// @Router       /v1/admin/wallets/{WALLET_UUID}/close [post]
+func (controller *Controller) CloseWalletHandler(c *gin.Context) {
+	controller.changeWalletStatus(c, "CloseWalletService", service.CloseWalletService)
+}
+
+// ListWalletStatusChangesHandler godoc
+// @Summary      List status changes of a wallet
+// @Description  Return the wallet's status history with the recorded reasons, oldest first.
+// @Tags         admin
+// @Produce      json
+// @Param        WALLET_UUID  path      string  true  "UUID wallet"
+// @Success      200          {array}   models.WalletStatusChange
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Router       /v1/admin/wallets/{WALLET_UUID}/status-changes [get]
+func (controller *Controller) ListWalletStatusChangesHandler(c *gin.Context) {
+	walletUUID := c.Param("WALLET_UUID")
+	if err := ValidateUUID(walletUUID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	changes, err := service.ListWalletStatusChangesService(controller.DB, walletUUID)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service ListWalletStatusChangesService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// changeWalletStatus applies a status transition that takes only a reason.
+func (controller *Controller) changeWalletStatus(c *gin.Context, name string,
+	transition func(db *sql.DB, balances *cache.Balances, walletUUID, reason string) (*models.Wallet, error)) {
+	walletUUID := c.Param("WALLET_UUID")
+	if err := ValidateUUID(walletUUID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	var request models.WalletStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Logger.WithError(err).Warn("bad JSON body")
+		utils.HandleError(c, utils.ErrInvalidRequest)
+		return
+	}
+
+	wallet, err := transition(controller.DB, controller.Cache, walletUUID, request.Reason)
+	if err != nil {
+		utils.Logger.WithError(err).Warnf("service %s failed", name)
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewBalanceResponse(wallet))
+}
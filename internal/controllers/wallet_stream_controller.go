@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/internal/stream"
+	"JavaCode/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LastEventIdHeader is sent by reconnecting EventSource clients.
+	LastEventIdHeader = "Last-Event-ID"
+
+	// defaultStreamHeartbeat is used when the controller has no StreamHeartbeat.
+	defaultStreamHeartbeat = 15 * time.Second
+)
+
+// StreamWalletHandler godoc
+// @Summary      Stream balance updates
+// @Description  Push the wallet's events (wallet.balance_changed, wallet.status_changed) as they commit, as server-sent events with the event id and type, or as models.WalletStreamMessage JSON messages when the request upgrades to a WebSocket. A stream without a cursor starts with a "balance" event carrying the current balance; a reconnecting client resumes after the Last-Event-ID header or the lastEventId query parameter. Idle streams get a keep-alive every heartbeat.
+// @Tags         wallet
+// @Produce      text/event-stream
+// @Param        WALLET_UUID   path      string  true   "UUID wallet"
+// @Param        Last-Event-ID header    string  false  "Id of the last event received"
+// @Param        lastEventId   query     int     false  "Id of the last event received, for WebSocket clients"
+// @Success      200           {object}  models.BalanceChangedEvent
+// @Failure      400           {object}  utils.ErrorResponse  "Invalid uuid or event id"
+// @Failure      404           {object}  utils.ErrorResponse  "Wallet not found"
+// @Failure      500           {object}  utils.ErrorResponse  "Internal server error"
+// @Router       /v1/wallets/{WALLET_UUID}/stream [get]
+func (controller *Controller) StreamWalletHandler(c *gin.Context) {
+	walletUUID := c.Param("WALLET_UUID")
+	if err := ValidateUUID(walletUUID); err != nil {
+		utils.Logger.WithError(err).Warn("Invalid uuid")
+		utils.HandleError(c, err)
+		return
+	}
+
+	lastEventID, resume, err := ParseLastEventId(c)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("invalid last event id")
+		utils.HandleError(c, err)
+		return
+	}
+
+	wallet, latestID, err := service.OpenWalletStreamService(controller.DB, walletUUID)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service OpenWalletStreamService failed")
+		utils.HandleError(c, err)
+		return
+	}
+	// Without a cursor the client gets the current balance and the events after it.
+	var initial *models.Wallet
+	if !resume {
+		initial, lastEventID = wallet, latestID
+	}
+
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		controller.streamWebSocket(c, initial, lastEventID)
+		return
+	}
+	controller.streamEvents(c, initial, lastEventID)
+}
+
+// streamEvents serves the stream as server-sent events.
+func (controller *Controller) streamEvents(c *gin.Context, initial *models.Wallet, lastEventID int64) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	if initial != nil {
+		data, _ := json.Marshal(NewBalanceResponse(initial))
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", models.StreamBalance, data)
+	}
+	w.Flush()
+
+	err := controller.followWallet(c.Request.Context(), c.Param("WALLET_UUID"), lastEventID,
+		func(events []models.OutboxEvent) error {
+			if len(events) == 0 {
+				io.WriteString(w, ": ping\n\n")
+			}
+			for _, event := range events {
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, event.Payload)
+			}
+			w.Flush()
+			return nil
+		})
+	if err != nil {
+		utils.Logger.WithError(err).Warn("wallet event stream ended")
+	}
+}
+
+// streamWebSocket serves the stream as WebSocket text messages. Messages
+// from the client are ignored; the stream ends when it disconnects.
+func (controller *Controller) streamWebSocket(c *gin.Context, initial *models.Wallet, lastEventID int64) {
+	walletUUID := c.Param("WALLET_UUID")
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+		go func() {
+			_, _ = io.Copy(io.Discard, ws)
+			cancel()
+		}()
+
+		if initial != nil {
+			data, _ := json.Marshal(NewBalanceResponse(initial))
+			if err := websocket.JSON.Send(ws, models.WalletStreamMessage{Type: models.StreamBalance, Data: data}); err != nil {
+				return
+			}
+		}
+
+		err := controller.followWallet(ctx, walletUUID, lastEventID, func(events []models.OutboxEvent) error {
+			if len(events) == 0 {
+				return websocket.JSON.Send(ws, models.WalletStreamMessage{Type: models.StreamPing})
+			}
+			for _, event := range events {
+				message := models.WalletStreamMessage{Id: event.Id, Type: event.Type, Data: event.Payload}
+				if err := websocket.JSON.Send(ws, message); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil && ctx.Err() == nil {
+			utils.Logger.WithError(err).Warn("wallet event stream ended")
+		}
+	}}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// followWallet runs service.FollowWalletEventsService with the controller's
+// notifier and heartbeat, polling every second when no notifier is set.
+func (controller *Controller) followWallet(ctx context.Context, walletUUID string, lastEventID int64,
+	send func([]models.OutboxEvent) error) error {
+	var notifier stream.Notifier = stream.Poller{Interval: time.Second}
+	if controller.Events != nil {
+		notifier = controller.Events
+	}
+	heartbeat := defaultStreamHeartbeat
+	if controller.StreamHeartbeat > 0 {
+		heartbeat = controller.StreamHeartbeat
+	}
+	return service.FollowWalletEventsService(ctx, controller.DB, notifier, walletUUID, lastEventID, heartbeat, send)
+}
+
+// ParseLastEventId reads the stream cursor from the Last-Event-ID header or
+// the lastEventId query parameter, reporting whether one was given.
+func ParseLastEventId(c *gin.Context) (int64, bool, error) {
+	raw := c.GetHeader(LastEventIdHeader)
+	if raw == "" {
+		raw = c.Query("lastEventId")
+	}
+	if raw == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, utils.ErrInvalidRequest
+	}
+	return id, true, nil
+}
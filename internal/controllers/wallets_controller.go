@@ -3,39 +3,371 @@ package controllers
 import (
 	"JavaCode/internal/models"
 	"JavaCode/internal/service"
+	"JavaCode/pkg/currency"
 	"JavaCode/utils"
+	"database/sql"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const (
+	// ConsistencyHeader carries the read-your-writes token returned by
+	// WalletOperationHandler and accepted by GetBalanceHandler.
+	ConsistencyHeader = "X-Consistency-Token"
+
+	// ConsistencyStrong forces balance reads to be served by the primary.
+	ConsistencyStrong = "strong"
+
+	// CacheStatusHeader reports whether a balance was served from the cache.
+	CacheStatusHeader = "X-Cache"
+)
+
+// lsnPattern matches the textual form of a PostgreSQL WAL position.
+var lsnPattern = regexp.MustCompile(`^[0-9A-Fa-f]{1,8}/[0-9A-Fa-f]{1,8}$`)
+
 // GetBalanceHandler godoc
 // @Summary  Get Balance
-// @Description  Return balance by UUID
+// @Description  Return balance by UUID, optionally as of a past time
 // @Tags     wallet
 // @Param    WALLET_UUID path string true "UUID wallet"
+// @Param    at query string false "RFC3339 timestamp to compute the balance at from the ledger"
+// @Param    consistency query string false "Set to \"strong\" to read from the primary"
+// @Param    X-Consistency-Token header string false "Token returned by a previous operation"
+// @Param    Cache-Control header string false "Set to \"no-cache\" to bypass the balance cache"
 // @Success  200 {object} models.BalanceResponse
+// @Header   200 {string} X-Cache "HIT or MISS (only with the balance cache enabled)"
 // @Failure  400 {object} utils.ErrorResponse
 // @Failure  404 {object} utils.ErrorResponse
-// @Router   /wallets/{WALLET_UUID} [get]
+// @Failure  422 {object} utils.ErrorResponse "History unavailable before the wallet's opening balance"
+// @Router   /v1/wallets/{WALLET_UUID} [get]
 func (controller *Controller) GetBalanceHandler(c *gin.Context) {
+	wallet, err := controller.lookupWallet(c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewBalanceResponse(wallet))
+}
+
+// lookupWallet reads the wallet named by the WALLET_UUID path parameter,
+// honouring the request's consistency parameters and cache directives.
+func (controller *Controller) lookupWallet(c *gin.Context) (*models.Wallet, error) {
 	walletUUID := c.Param("WALLET_UUID")
 
 	if _, err := uuid.Parse(walletUUID); err != nil {
 		utils.Logger.WithError(err).Warn("Invalid uuid")
+		return nil, utils.ErrInvalidRequest
+	}
+
+	consistency := c.Query("consistency")
+	token := c.GetHeader(ConsistencyHeader)
+	if err := ValidateConsistency(consistency, token); err != nil {
+		utils.Logger.WithError(err).Warn("invalid consistency parameters")
+		return nil, err
+	}
+
+	var at time.Time
+	if raw := c.Query("at"); raw != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, raw); err != nil {
+			utils.Logger.WithError(err).Warn("invalid at parameter")
+			return nil, utils.ErrInvalidRequest
+		}
+		at = at.UTC()
+	}
+
+	var (
+		wallet *models.Wallet
+		err    error
+	)
+	// Strong reads and reads carrying a token may need writes made by other
+	// instances, which the local cache cannot know about. Historical
+	// balances are not cached.
+	if controller.Cache != nil && at.IsZero() && consistency == "" && token == "" && !IsCacheBypassed(c) {
+		var hit bool
+		wallet, hit, err = service.GetWalletsCachedService(controller.DB, controller.Cache, walletUUID)
+		if hit {
+			c.Header(CacheStatusHeader, "HIT")
+		} else {
+			c.Header(CacheStatusHeader, "MISS")
+		}
+	} else {
+		wallet, err = controller.readWallet(walletUUID, at, consistency, token)
+	}
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service GetWalletService failed")
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// CreateWalletHandler godoc
+// @Summary      Create a wallet
+// @Description  Create an empty wallet in the given currency. The currency cannot be changed later.
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateWalletRequest  true  "Wallet parameters"
+// @Success      201      {object}  models.BalanceResponse
+// @Failure      400      {object}  utils.ErrorResponse         "Invalid request / unsupported currency"
+// @Failure      500      {object}  utils.ErrorResponse         "Internal server error"
+// @Router       /v1/wallets [post]
+func (controller *Controller) CreateWalletHandler(c *gin.Context) {
+	wallet, err := controller.createWallet(c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewBalanceResponse(wallet))
+}
+
+// createWallet creates a wallet from a models.CreateWalletRequest body.
+func (controller *Controller) createWallet(c *gin.Context) (*models.Wallet, error) {
+	var request models.CreateWalletRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Logger.WithError(err).Warn("bad JSON body")
+		return nil, utils.ErrInvalidRequest
+	}
+
+	if err := service.ValidateCurrency(request.Currency); err != nil {
+		utils.Logger.WithError(err).Warn("invalid currency")
+		return nil, err
+	}
+
+	wallet, err := service.CreateWalletService(controller.DB, request)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service CreateWalletService failed")
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// UpdateWalletHandler godoc
+// @Summary      Update wallet metadata
+// @Description  Change the owner, name or labels of a wallet. Omitted fields are kept, an empty owner or name clears it, and a null label value removes the label.
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        WALLET_UUID  path      string                      true  "UUID wallet"
+// @Param        request      body      models.UpdateWalletRequest  true  "Metadata changes"
+// @Success      200          {object}  models.BalanceResponse
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Router       /v1/wallets/{WALLET_UUID} [patch]
+func (controller *Controller) UpdateWalletHandler(c *gin.Context) {
+	walletUUID := c.Param("WALLET_UUID")
+	if err := ValidateUUID(walletUUID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	var request models.UpdateWalletRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Logger.WithError(err).Warn("bad JSON body")
 		utils.HandleError(c, utils.ErrInvalidRequest)
 		return
 	}
 
-	wallet, err := service.GetWalletsService(controller.DB, walletUUID)
+	wallet, err := service.UpdateWalletService(controller.DB, controller.Cache, walletUUID, request)
 	if err != nil {
-		utils.Logger.WithError(err).Warn("service GetWalletService failed")
+		utils.Logger.WithError(err).Warn("service UpdateWalletService failed")
 		utils.HandleError(c, err)
 		return
 	}
 
-	result := models.BalanceResponse{Uuid: wallet.Id, Balance: wallet.Balance}
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, NewBalanceResponse(wallet))
+}
+
+// NewBalanceResponse converts a wallet to its API representation.
+func NewBalanceResponse(wallet *models.Wallet) models.BalanceResponse {
+	exponent, _ := currency.Exponent(wallet.Currency)
+	return models.BalanceResponse{
+		Uuid:            wallet.Id,
+		Balance:         wallet.Balance,
+		Currency:        wallet.Currency,
+		Exponent:        exponent,
+		Status:          wallet.Status,
+		DepositsBlocked: wallet.DepositsBlocked,
+		Owner:           wallet.Owner,
+		Name:            wallet.Name,
+		Labels:          wallet.Labels,
+		CreatedAt:       wallet.CreatedTime,
+	}
+}
+
+// ListWalletsHandler godoc
+// @Summary      List wallets
+// @Description  Search wallets by owner, status, currency, balance and creation time. Results are paginated with an opaque cursor.
+// @Tags         wallet
+// @Produce      json
+// @Param        owner        query     string  false  "Owner reference"
+// @Param        name         query     string  false  "Part of the name, ignoring case"
+// @Param        label        query     []string  false  "Label as key:value; repeat to require several" collectionFormat(multi)
+// @Param        status       query     string  false  "ACTIVE, FROZEN or CLOSED"
+// @Param        currency     query     string  false  "ISO 4217 code"
+// @Param        minBalance   query     int     false  "Minimum balance in minor units (inclusive)"
+// @Param        maxBalance   query     int     false  "Maximum balance in minor units (inclusive)"
+// @Param        createdFrom  query     string  false  "RFC3339 creation time lower bound (inclusive)"
+// @Param        createdTo    query     string  false  "RFC3339 creation time upper bound (exclusive)"
+// @Param        sort         query     string  false  "createdAt, -createdAt, balance or -balance (default createdAt)"
+// @Param        limit        query     int     false  "Page size (default 50, max 500)"
+// @Param        cursor       query     string  false  "nextCursor of the previous page"
+// @Success      200          {object}  models.WalletListResponse
+// @Failure      400          {object}  utils.ErrorResponse
+// @Router       /v1/wallets [get]
+func (controller *Controller) ListWalletsHandler(c *gin.Context) {
+	filter, err := ParseWalletFilter(c)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("invalid wallet filter")
+		utils.HandleError(c, err)
+		return
+	}
+
+	readDB := service.SelectReadDBService(controller.DB, controller.replica(), "")
+	page, err := service.ListWalletsService(readDB, filter, c.Query("cursor"))
+	if err != nil && readDB != controller.DB && errors.Is(err, utils.ErrDatabase) {
+		utils.Logger.WithError(err).Warn("replica read failed, retrying on primary")
+		page, err = service.ListWalletsService(controller.DB, filter, c.Query("cursor"))
+	}
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service ListWalletsService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	response := models.WalletListResponse{
+		Wallets:    make([]models.BalanceResponse, 0, len(page.Wallets)),
+		NextCursor: page.NextCursor,
+	}
+	for i := range page.Wallets {
+		response.Wallets = append(response.Wallets, NewBalanceResponse(&page.Wallets[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ParseWalletFilter reads the wallet listing query parameters.
+//
+// Returns:
+//   - the filter, without a cursor;
+//   - utils.ErrInvalidRequest if a parameter is malformed;
+//   - utils.ErrUnsupportedCurrency if the currency is not supported.
+func ParseWalletFilter(c *gin.Context) (models.WalletFilter, error) {
+	filter := models.WalletFilter{
+		Owner:    c.Query("owner"),
+		Name:     c.Query("name"),
+		Status:   strings.ToUpper(c.Query("status")),
+		Currency: currency.Normalize(c.Query("currency")),
+	}
+
+	for _, label := range c.QueryArray("label") {
+		key, value, ok := strings.Cut(label, ":")
+		if !ok || key == "" {
+			return filter, utils.ErrInvalidRequest
+		}
+		if filter.Labels == nil {
+			filter.Labels = map[string]string{}
+		}
+		filter.Labels[key] = value
+	}
+
+	switch filter.Status {
+	case "", models.WalletActive, models.WalletFrozen, models.WalletClosed:
+	default:
+		return filter, utils.ErrInvalidRequest
+	}
+	if filter.Currency != "" {
+		if err := service.ValidateCurrency(filter.Currency); err != nil {
+			return filter, err
+		}
+	}
+
+	var err error
+	if filter.MinBalance, err = parseBalanceQuery(c, "minBalance"); err != nil {
+		return filter, err
+	}
+	if filter.MaxBalance, err = parseBalanceQuery(c, "maxBalance"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedFrom, err = parseTimeQuery(c, "createdFrom"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseTimeQuery(c, "createdTo"); err != nil {
+		return filter, err
+	}
+
+	sort := c.Query("sort")
+	filter.Desc = strings.HasPrefix(sort, "-")
+	filter.Sort = strings.TrimPrefix(sort, "-")
+	switch filter.Sort {
+	case "", models.WalletSortCreated, models.WalletSortBalance:
+	default:
+		return filter, utils.ErrInvalidRequest
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		if filter.Limit, err = strconv.Atoi(raw); err != nil || filter.Limit <= 0 {
+			return filter, utils.ErrInvalidRequest
+		}
+	}
+	return filter, nil
+}
+
+func parseBalanceQuery(c *gin.Context, name string) (*uint64, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseUint(raw, 10, 63)
+	if err != nil {
+		return nil, utils.ErrInvalidRequest
+	}
+	return &value, nil
+}
+
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, utils.ErrInvalidRequest
+	}
+	value = value.UTC()
+	return &value, nil
+}
+
+// readWallet reads a wallet bypassing the cache, routing the read to a
+// replica unless strong consistency is requested or the replica lags.
+// A non-zero at reads the balance as of that time from the ledger.
+func (controller *Controller) readWallet(walletUUID string, at time.Time, consistency, token string) (*models.Wallet, error) {
+	read := func(db *sql.DB) (*models.Wallet, error) {
+		if at.IsZero() {
+			return service.GetWalletsService(db, walletUUID)
+		}
+		return service.GetBalanceAtService(db, walletUUID, at)
+	}
+
+	readDB := controller.DB
+	if consistency != ConsistencyStrong {
+		readDB = service.SelectReadDBService(controller.DB, controller.replica(), token)
+	}
+
+	wallet, err := read(readDB)
+	if err != nil && readDB != controller.DB && errors.Is(err, utils.ErrDatabase) {
+		utils.Logger.WithError(err).Warn("replica read failed, retrying on primary")
+		wallet, err = read(controller.DB)
+	}
+	return wallet, err
 }
 
 // WalletOperationHandler godoc
@@ -46,10 +378,13 @@ func (controller *Controller) GetBalanceHandler(c *gin.Context) {
 // @Produce      json
 // @Param        request  body      models.WalletOperationRequest  true  "Operation parameters"
 // @Success      200      {object}  map[string]string              "Operation successful"
-// @Failure      400      {object}  utils.ErrorResponse            "Invalid request / negative amount"
+// @Header       200      {string}  X-Consistency-Token            "Read-your-writes token (only with replicas)"
+// @Failure      400      {object}  utils.ErrorResponse            "Invalid request / negative amount / unsupported currency"
 // @Failure      404      {object}  utils.ErrorResponse            "Wallet not found"
+// @Failure      409      {object}  utils.ErrorResponse            "Wallet frozen or closed / duplicate reference"
+// @Failure      422      {object}  utils.ErrorResponse            "Currency does not match the wallet / amount overflow"
 // @Failure      500      {object}  utils.ErrorResponse            "Internal server error"
-// @Router       /wallet [post]
+// @Router       /v1/wallet [post]
 func (controller *Controller) WalletOperationHandler(c *gin.Context) {
 	var request models.WalletOperationRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -58,32 +393,44 @@ func (controller *Controller) WalletOperationHandler(c *gin.Context) {
 		return
 	}
 
-	if request.Amount <= 0 {
-		utils.Logger.Warn("amount must be greater than zero")
-		utils.HandleError(c, utils.ErrNegativeBalance)
-		return
-	}
-
-	if err := ValidateUUID(request.WalletID); err != nil {
-		utils.Logger.WithError(err).Warn("invalid UUID")
+	if err := controller.applyOperation(c, request); err != nil {
 		utils.HandleError(c, err)
 		return
 	}
 
-	if err := ValidateOperationType(request.OperationType); err != nil {
-		utils.Logger.WithError(err).Warn("operation failed")
-		utils.HandleError(c, err)
-		return
+	c.JSON(http.StatusOK, gin.H{"message": "Operation successful"})
+}
+
+// applyOperation validates and applies a wallet operation, setting the
+// consistency token header on success.
+func (controller *Controller) applyOperation(c *gin.Context, request models.WalletOperationRequest) error {
+	if err := service.ValidateOperationRequest(request); err != nil {
+		utils.Logger.WithError(err).Warn("invalid operation request")
+		return err
 	}
 
-	err := service.HandleOperationService(controller.DB, request.WalletID, request.OperationType, request.Amount)
+	err := service.HandleOperationService(controller.DB, controller.Cache, request)
 	if err != nil {
 		utils.Logger.WithError(err).Warn("service Handle Operation failed")
-		utils.HandleError(c, err)
-		return
+		return err
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Operation successful"})
+	controller.setConsistencyToken(c)
+	return nil
+}
+
+// setConsistencyToken returns the primary's WAL position in ConsistencyHeader
+// after a write, so that clients can read their writes from replicas.
+func (controller *Controller) setConsistencyToken(c *gin.Context) {
+	if len(controller.Replicas) == 0 {
+		return
+	}
+	token, err := service.ConsistencyTokenService(controller.DB)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("failed to read consistency token")
+		return
+	}
+	c.Header(ConsistencyHeader, token)
 }
 
 // ValidateUUID checks if the given string is a valid UUID format.
@@ -113,3 +460,33 @@ func ValidateOperationType(operationType string) error {
 	}
 	return nil
 }
+
+// ValidateConsistency checks the read consistency parameters of a balance request.
+//
+// Allowed consistency values are "" and ConsistencyStrong; the token, if set,
+// must be a WAL position as returned in ConsistencyHeader.
+//
+// Returns:
+//   - nil if the parameters are valid;
+//   - utils.ErrInvalidRequest otherwise.
+func ValidateConsistency(consistency, token string) error {
+	if consistency != "" && consistency != ConsistencyStrong {
+		return utils.ErrInvalidRequest
+	}
+	if token != "" && !lsnPattern.MatchString(token) {
+		return utils.ErrInvalidRequest
+	}
+	return nil
+}
+
+// IsCacheBypassed reports whether the request asks to skip the balance cache
+// via a "Cache-Control: no-cache" or "no-store" header.
+func IsCacheBypassed(c *gin.Context) bool {
+	for _, directive := range strings.Split(c.GetHeader("Cache-Control"), ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "no-cache", "no-store":
+			return true
+		}
+	}
+	return false
+}
@@ -1,13 +1,18 @@
 package controllers_test
 
 import (
+	"JavaCode/internal/cache"
 	"JavaCode/internal/controllers"
+	"JavaCode/internal/models"
+	"JavaCode/utils"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -19,14 +24,38 @@ func expectSuccessfulTx(mock sqlmock.Sqlmock, uuid string, delta int) {
 	mock.ExpectBegin()
 	mock.ExpectQuery("SELECT id, balance.* FOR UPDATE").
 		WithArgs(uuid).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "created_at", "updated_at"}).
-			AddRow(uuid, 1000, time.Now(), time.Now()))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(uuid, 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
 	mock.ExpectExec("UPDATE wallets SET balance = balance.*").
 		WithArgs(delta, uuid).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectLedgerPosting(mock, 1)
+	expectOutboxEvents(mock, 1)
 	mock.ExpectCommit()
 }
 
+// expectLedgerPosting expects a ledger transaction that looks up
+// systemAccounts system accounts and inserts its entries.
+func expectLedgerPosting(mock sqlmock.Sqlmock, systemAccounts int) {
+	for i := 0; i < systemAccounts; i++ {
+		mock.ExpectQuery("SELECT id FROM ledger_accounts").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("00000000-0000-0000-0000-000000000001"))
+	}
+	mock.ExpectQuery("INSERT INTO ledger_transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectExec("INSERT INTO ledger_entries").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+}
+
+// expectOutboxEvents expects n outbox events, each queued for webhooks.
+func expectOutboxEvents(mock sqlmock.Sqlmock, n int) {
+	for i := 0; i < n; i++ {
+		mock.ExpectQuery("INSERT INTO outbox_events").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(i+1, time.Now()))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+}
+
 func TestValidateUUID(t *testing.T) {
 	t.Run("Test 1: Test valid UUIDs", func(t *testing.T) {
 		tests := []struct {
@@ -157,8 +186,8 @@ func TestController_GetBalanceHandler(t *testing.T) {
 				name:     "Ok",
 				input:    "a1c122d7-fbc1-4ebb-bdd5-4ddb793c92bf",
 				wantCode: http.StatusOK,
-				mockRows: sqlmock.NewRows([]string{"id", "balance", "created_at", "updated_at"}).
-					AddRow("a1c122d7-fbc1-4ebb-bdd5-4ddb793c92bf", 1000, time.Now(), time.Now()),
+				mockRows: sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+					AddRow("a1c122d7-fbc1-4ebb-bdd5-4ddb793c92bf", 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()),
 				expectQuery: true,
 			},
 		}
@@ -169,7 +198,7 @@ func TestController_GetBalanceHandler(t *testing.T) {
 				defer db.Close()
 
 				if tt.expectQuery {
-					q := "SELECT id, balance, created_at, updated_at FROM wallets WHERE id = \\$1"
+					q := "SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1"
 					qExp := mock.ExpectQuery(q).WithArgs(tt.input)
 
 					if tt.mockErr != nil {
@@ -222,17 +251,17 @@ func TestController_WalletOperationHandler(t *testing.T) {
 			},
 			{
 				name:     "Negative Amount",
-				input:    `{"walletId": "f4c863ec-0300-495d-852d-c115e197390b", "operationType": "DEPOSIT", "amount": -1000}`,
+				input:    `{"walletId": "f4c863ec-0300-495d-852d-c115e197390b", "operationType": "DEPOSIT", "amount": -1000, "currency": "RUB"}`,
 				wantCode: http.StatusBadRequest,
 			},
 			{
 				name:     "Missing operationType",
-				input:    `{"walletId": "f4c863ec-0300-495d-852d-c115e197390b", "amount": 1000}`,
+				input:    `{"walletId": "f4c863ec-0300-495d-852d-c115e197390b", "amount": 1000, "currency": "RUB"}`,
 				wantCode: http.StatusBadRequest,
 			},
 			{
 				name:     "Valid Body",
-				input:    `{"walletId": "f4c863ec-0300-495d-852d-c115e197390b", "operationType": "DEPOSIT", "amount": 1000}`,
+				input:    `{"walletId": "f4c863ec-0300-495d-852d-c115e197390b", "operationType": "DEPOSIT", "amount": 1000, "currency": "RUB"}`,
 				wantCode: http.StatusOK,
 			},
 		}
@@ -269,17 +298,17 @@ func TestController_WalletOperationHandler(t *testing.T) {
 		}{
 			{
 				name:     "Invalid UUID",
-				input:    `{"walletId": "f4c8-030-495d-852d-c115e197390b", "operationType": "DEPOSIT", "amount": 1000}`,
+				input:    `{"walletId": "f4c8-030-495d-852d-c115e197390b", "operationType": "DEPOSIT", "amount": 1000, "currency": "RUB"}`,
 				wantCode: http.StatusBadRequest,
 			},
 			{
 				name:     "Null UUID",
-				input:    `{"walletId": "", "operationType": "DEPOSIT", "amount": -1000}`,
+				input:    `{"walletId": "", "operationType": "DEPOSIT", "amount": -1000, "currency": "RUB"}`,
 				wantCode: http.StatusBadRequest,
 			},
 			{
 				name:     "Valid UUID",
-				input:    `{"walletId": "f4c863ec-0300-495d-852d-c115e197390b", "operationType": "DEPOSIT", "amount": 1000}`,
+				input:    `{"walletId": "f4c863ec-0300-495d-852d-c115e197390b", "operationType": "DEPOSIT", "amount": 1000, "currency": "RUB"}`,
 				wantCode: http.StatusOK,
 			},
 		}
@@ -335,7 +364,8 @@ func TestController_WalletOperationHandler(t *testing.T) {
 				bodyStr := fmt.Sprintf(`{
 				"walletId": "f4c863ec-0300-495d-852d-c115e197390b",
 				"operationType": "%s",
-				"amount": %d
+				"amount": %d,
+				"currency": "RUB"
 			}`, tt.operation, tt.amount)
 
 				req, _ := http.NewRequest(http.MethodPost, "/api/v1/wallet", strings.NewReader(bodyStr))
@@ -350,3 +380,1159 @@ func TestController_WalletOperationHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestValidateConsistency(t *testing.T) {
+	tests := []struct {
+		name        string
+		consistency string
+		token       string
+		wantErr     bool
+	}{
+		{"Defaults", "", "", false},
+		{"Strong", "strong", "", false},
+		{"Token", "", "0/16B3748", false},
+		{"Unknown consistency", "eventual", "", true},
+		{"Malformed token", "", "16B3748", true},
+		{"Injected token", "", "0/1; DROP TABLE wallets", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := controllers.ValidateConsistency(tt.consistency, tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestController_GetBalanceHandler_Replicas(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletID = "a1c122d7-fbc1-4ebb-bdd5-4ddb793c92bf"
+	const query = "SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1"
+
+	walletRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(walletID, 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now())
+	}
+
+	newContext := func(target string, token string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		if token != "" {
+			req.Header.Set(controllers.ConsistencyHeader, token)
+		}
+		c.Request = req
+		return c, w
+	}
+
+	t.Run("Test 1: Read from replica", func(t *testing.T) {
+		primary, primaryMock, _ := sqlmock.New()
+		replica, replicaMock, _ := sqlmock.New()
+		defer primary.Close()
+		defer replica.Close()
+
+		replicaMock.ExpectQuery(query).WithArgs(walletID).WillReturnRows(walletRows())
+
+		c, w := newContext("/api/v1/wallets/"+walletID, "")
+		ctrl := controllers.Controller{DB: primary, Replicas: []*sql.DB{replica}}
+		ctrl.GetBalanceHandler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, replicaMock.ExpectationsWereMet())
+		assert.NoError(t, primaryMock.ExpectationsWereMet())
+	})
+
+	t.Run("Test 2: Strong consistency reads from primary", func(t *testing.T) {
+		primary, primaryMock, _ := sqlmock.New()
+		replica, replicaMock, _ := sqlmock.New()
+		defer primary.Close()
+		defer replica.Close()
+
+		primaryMock.ExpectQuery(query).WithArgs(walletID).WillReturnRows(walletRows())
+
+		c, w := newContext("/api/v1/wallets/"+walletID+"?consistency=strong", "")
+		ctrl := controllers.Controller{DB: primary, Replicas: []*sql.DB{replica}}
+		ctrl.GetBalanceHandler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, primaryMock.ExpectationsWereMet())
+		assert.NoError(t, replicaMock.ExpectationsWereMet())
+	})
+
+	t.Run("Test 3: Lagging replica falls back to primary", func(t *testing.T) {
+		primary, primaryMock, _ := sqlmock.New()
+		replica, replicaMock, _ := sqlmock.New()
+		defer primary.Close()
+		defer replica.Close()
+
+		replicaMock.ExpectQuery("SELECT COALESCE\\(pg_last_wal_replay_lsn\\(\\).*").
+			WithArgs("0/16B3748").
+			WillReturnRows(sqlmock.NewRows([]string{"caught_up"}).AddRow(false))
+		primaryMock.ExpectQuery(query).WithArgs(walletID).WillReturnRows(walletRows())
+
+		c, w := newContext("/api/v1/wallets/"+walletID, "0/16B3748")
+		ctrl := controllers.Controller{DB: primary, Replicas: []*sql.DB{replica}}
+		ctrl.GetBalanceHandler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, primaryMock.ExpectationsWereMet())
+		assert.NoError(t, replicaMock.ExpectationsWereMet())
+	})
+
+	t.Run("Test 4: Invalid consistency", func(t *testing.T) {
+		primary, _, _ := sqlmock.New()
+		defer primary.Close()
+
+		c, w := newContext("/api/v1/wallets/"+walletID+"?consistency=weak", "")
+		ctrl := controllers.Controller{DB: primary}
+		ctrl.GetBalanceHandler(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestController_WalletOperationHandler_ConsistencyToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	primary, mock, _ := sqlmock.New()
+	replica, _, _ := sqlmock.New()
+	defer primary.Close()
+	defer replica.Close()
+
+	expectSuccessfulTx(mock, "f4c863ec-0300-495d-852d-c115e197390b", 1000)
+	mock.ExpectQuery("SELECT pg_current_wal_lsn\\(\\)::text").
+		WillReturnRows(sqlmock.NewRows([]string{"lsn"}).AddRow("0/16B3748"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body := `{"walletId": "f4c863ec-0300-495d-852d-c115e197390b", "operationType": "DEPOSIT", "amount": 1000, "currency": "RUB"}`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/wallet", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	c.Request = req
+
+	ctrl := controllers.Controller{DB: primary, Replicas: []*sql.DB{replica}}
+	ctrl.WalletOperationHandler(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0/16B3748", w.Header().Get(controllers.ConsistencyHeader))
+}
+
+func TestController_GetBalanceHandler_Cache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletID = "a1c122d7-fbc1-4ebb-bdd5-4ddb793c92bf"
+	const query = "SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1"
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	ctrl := controllers.Controller{DB: db, Cache: cache.NewBalances(cache.NewLRU(10, time.Minute))}
+
+	get := func(cacheControl string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/wallets/"+walletID, nil)
+		if cacheControl != "" {
+			req.Header.Set("Cache-Control", cacheControl)
+		}
+		c.Request = req
+		ctrl.GetBalanceHandler(c)
+		return w
+	}
+
+	expectRead := func(balance int) {
+		mock.ExpectQuery(query).WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, balance, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+	}
+
+	expectRead(1000)
+	w := get("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "MISS", w.Header().Get(controllers.CacheStatusHeader))
+
+	w = get("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "HIT", w.Header().Get(controllers.CacheStatusHeader))
+	assert.Contains(t, w.Body.String(), `"balance":1000`)
+
+	expectRead(2000)
+	w = get("no-cache")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(controllers.CacheStatusHeader))
+	assert.Contains(t, w.Body.String(), `"balance":2000`)
+
+	// A committed operation must be visible to the next read.
+	expectSuccessfulTx(mock, walletID, 500)
+	wc := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(wc)
+	body := `{"walletId": "` + walletID + `", "operationType": "DEPOSIT", "amount": 500, "currency": "RUB"}`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/wallet", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	c.Request = req
+	ctrl.WalletOperationHandler(c)
+	assert.Equal(t, http.StatusOK, wc.Code)
+
+	expectRead(1500)
+	w = get("")
+	assert.Equal(t, "MISS", w.Header().Get(controllers.CacheStatusHeader))
+	assert.Contains(t, w.Body.String(), `"balance":1500`)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestController_ReadinessHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		version  int64
+		wantCode int
+	}{
+		{"Schema up to date", 20250417135508, http.StatusOK},
+		{"Schema outdated", 20250417120934, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			defer db.Close()
+
+			mock.ExpectPing()
+			mock.ExpectQuery("SELECT COALESCE\\(MAX\\(version_id\\), 0\\)").
+				WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(tt.version))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/readyz", nil)
+
+			ctrl := controllers.Controller{DB: db, SchemaVersion: 20250417135508}
+			ctrl.ReadinessHandler(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestController_CreateWalletHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		input    string
+		wantCode int
+	}{
+		{"Invalid JSON Body", "{invalid-json", http.StatusBadRequest},
+		{"Missing currency", `{}`, http.StatusBadRequest},
+		{"Unsupported currency", `{"currency": "ABC"}`, http.StatusBadRequest},
+		{"Valid", `{"currency": "EUR"}`, http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			if tt.wantCode == http.StatusCreated {
+				mock.ExpectQuery("INSERT INTO wallets").
+					WithArgs(sqlmock.AnyArg(), "EUR", "", "", []byte("{}")).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/wallets", strings.NewReader(tt.input))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			ctrl := controllers.Controller{DB: db}
+			ctrl.CreateWalletHandler(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusCreated {
+				assert.Contains(t, w.Body.String(), `"currency":"EUR"`)
+				assert.Contains(t, w.Body.String(), `"exponent":2`)
+			}
+		})
+	}
+}
+
+func TestController_WalletOperationHandler_Currency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	tests := []struct {
+		name     string
+		currency string
+		wantCode int
+	}{
+		{"Missing currency", "", http.StatusBadRequest},
+		{"Unsupported currency", "ABC", http.StatusBadRequest},
+		{"Currency mismatch", "USD", http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			if tt.wantCode == http.StatusUnprocessableEntity {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id, balance.* FOR UPDATE").
+					WithArgs(walletID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+						AddRow(walletID, 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+				mock.ExpectRollback()
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			body := fmt.Sprintf(`{"walletId": "%s", "operationType": "DEPOSIT", "amount": 100, "currency": "%s"}`, walletID, tt.currency)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/wallet", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			ctrl := controllers.Controller{DB: db}
+			ctrl.WalletOperationHandler(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestController_TransferHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		fromID = "f4c863ec-0300-495d-852d-c115e197390b"
+		toID   = "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"
+	)
+
+	tests := []struct {
+		name     string
+		input    string
+		wantCode int
+	}{
+		{"Invalid JSON Body", "{invalid-json", http.StatusBadRequest},
+		{"Zero amount", fmt.Sprintf(`{"fromWalletId": "%s", "toWalletId": "%s", "amount": 0, "currency": "RUB"}`, fromID, toID), http.StatusBadRequest},
+		{"Invalid UUID", fmt.Sprintf(`{"fromWalletId": "%s", "toWalletId": "bad", "amount": 100, "currency": "RUB"}`, fromID), http.StatusBadRequest},
+		{"Same wallet", fmt.Sprintf(`{"fromWalletId": "%s", "toWalletId": "%s", "amount": 100, "currency": "RUB"}`, fromID, fromID), http.StatusBadRequest},
+		{"Valid", fmt.Sprintf(`{"fromWalletId": "%s", "toWalletId": "%s", "amount": 100, "currency": "RUB"}`, fromID, toID), http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			if tt.wantCode == http.StatusCreated {
+				mock.ExpectBegin()
+				for _, id := range []string{toID, fromID} {
+					mock.ExpectQuery("SELECT id, balance.* FOR UPDATE").
+						WithArgs(id).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+							AddRow(id, 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+				}
+				mock.ExpectExec("UPDATE wallets SET balance").WithArgs(-100, fromID).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE wallets SET balance").WithArgs(100, toID).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO transfers").
+					WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+				expectLedgerPosting(mock, 0)
+				expectOutboxEvents(mock, 2)
+				mock.ExpectCommit()
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/transfers", strings.NewReader(tt.input))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			ctrl := controllers.Controller{DB: db}
+			ctrl.TransferHandler(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusCreated {
+				assert.Contains(t, w.Body.String(), `"targetAmount":100`)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			}
+		})
+	}
+}
+
+func TestController_GetTransferHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const transferID = "5b0f7b0e-8a53-4b39-a0c4-2f7d4c5e2c11"
+
+	t.Run("Not found", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM transfers WHERE id = \\$1").
+			WithArgs(transferID).
+			WillReturnError(sql.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "TRANSFER_ID", Value: transferID}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/transfers/"+transferID, nil)
+
+		ctrl := controllers.Controller{DB: db}
+		ctrl.GetTransferHandler(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Converted transfer", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM transfers WHERE id = \\$1").
+			WithArgs(transferID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "from_wallet_id", "to_wallet_id", "source_amount",
+				"source_currency", "target_amount", "target_currency", "rate_id", "rate_units", "rate_precision",
+				"rounding_mode", "created_at"}).
+				AddRow(transferID, "f4c863ec-0300-495d-852d-c115e197390b", "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c",
+					1000, "EUR", 1083, "USD", 7, 10834, 4, "HALF_EVEN", time.Now()))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "TRANSFER_ID", Value: transferID}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/transfers/"+transferID, nil)
+
+		ctrl := controllers.Controller{DB: db}
+		ctrl.GetTransferHandler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"rate":"1.0834"`)
+		assert.Contains(t, w.Body.String(), `"roundingMode":"HALF_EVEN"`)
+	})
+}
+
+func TestParsePositiveAmount(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr error
+	}{
+		{"12.34 EUR", 1234, nil},
+		{"0 EUR", 0, utils.ErrInvalidAmount},
+		{"-1 EUR", 0, utils.ErrInvalidAmount},
+		{"12,34 EUR", 0, utils.ErrInvalidAmount},
+		{"1234", 0, utils.ErrInvalidAmount},
+		{"12.345 EUR", 0, utils.ErrAmountPrecision},
+		{"92233720368547758.08 EUR", 0, utils.ErrAmountOverflow},
+		{"1 ABC", 0, utils.ErrUnsupportedCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			amount, err := controllers.ParsePositiveAmount(tt.input)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, amount.Units)
+		})
+	}
+}
+
+func TestController_WalletOperationV2Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	tests := []struct {
+		name      string
+		amount    string
+		wantCode  int
+		wantError string
+	}{
+		{"Excess precision", "10.001 RUB", http.StatusBadRequest, "excess_precision"},
+		{"Overflow", "92233720368547758.08 RUB", http.StatusUnprocessableEntity, "amount_overflow"},
+		{"Missing currency", "10.00", http.StatusBadRequest, "invalid_amount"},
+		{"Valid", "10.50 RUB", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			if tt.wantCode == http.StatusOK {
+				expectSuccessfulTx(mock, walletID, 1050)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			body := fmt.Sprintf(`{"walletId": "%s", "operationType": "DEPOSIT", "amount": "%s"}`, walletID, tt.amount)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v2/wallet", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			ctrl := controllers.Controller{DB: db}
+			ctrl.WalletOperationV2Handler(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantError != "" {
+				assert.Contains(t, w.Body.String(), `"error":"`+tt.wantError+`"`)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestController_GetBalanceV2Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1").
+		WithArgs(walletID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(walletID, 2005, "EUR", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/v2/wallets/"+walletID, nil)
+
+	ctrl := controllers.Controller{DB: db}
+	ctrl.GetBalanceV2Handler(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"balance":"20.05 EUR"`)
+}
+
+func TestController_VerifyWalletBalanceHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	tests := []struct {
+		name           string
+		ledgerBalance  int64
+		wantConsistent string
+	}{
+		{"Consistent", 1000, `"consistent":true`},
+		{"Mismatch", 900, `"consistent":false`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1").
+				WithArgs(walletID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+					AddRow(walletID, 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+			mock.ExpectQuery("SELECT COALESCE\\(SUM.*FROM ledger_entries WHERE account_id = \\$1").
+				WithArgs(walletID).
+				WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(tt.ledgerBalance))
+			mock.ExpectRollback()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/admin/wallets/"+walletID+"/verify", nil)
+
+			ctrl := controllers.Controller{DB: db}
+			ctrl.VerifyWalletBalanceHandler(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantConsistent)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestController_ListLedgerEntriesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/admin/wallets/"+walletID+"/entries?limit=abc", nil)
+
+		ctrl := controllers.Controller{}
+		ctrl.ListLedgerEntriesHandler(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Entries", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+		mock.ExpectQuery("SELECT .* FROM ledger_entries e JOIN ledger_transactions t").
+			WithArgs(walletID, "order-1001", 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "transaction_id", "type", "description", "reference", "metadata",
+				"account_id", "direction", "amount", "currency", "created_at"}).
+				AddRow(2, "5b0f7b0e-8a53-4b39-a0c4-2f7d4c5e2c11", "DEPOSIT", "Order #1001", "order-1001", `{"invoice": "INV-7"}`,
+					walletID, "CREDIT", 1000, "RUB", time.Now()))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/admin/wallets/"+walletID+"/entries?limit=10&reference=order-1001", nil)
+
+		ctrl := controllers.Controller{DB: db}
+		ctrl.ListLedgerEntriesHandler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"direction":"CREDIT"`)
+		assert.Contains(t, w.Body.String(), `"transactionType":"DEPOSIT"`)
+		assert.Contains(t, w.Body.String(), `"reference":"order-1001"`)
+		assert.Contains(t, w.Body.String(), `"metadata":{"invoice":"INV-7"}`)
+	})
+}
+
+func TestController_GetBalanceHandler_At(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	t.Run("Invalid timestamp", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/wallets/"+walletID+"?at=yesterday", nil)
+
+		ctrl := controllers.Controller{}
+		ctrl.GetBalanceHandler(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Historical balance bypasses the cache", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, 5000, "RUB", "ACTIVE", false, "", "", "{}", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Now()))
+		mock.ExpectQuery("SELECT t.type, e.created_at FROM ledger_entries e").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"type", "created_at"}))
+		mock.ExpectQuery("WITH s AS").
+			WithArgs(walletID, time.Date(2025, 3, 31, 21, 0, 0, 0, time.UTC)).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(1200))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/wallets/"+walletID+"?at=2025-04-01T00:00:00%2B03:00", nil)
+
+		ctrl := controllers.Controller{DB: db, Cache: cache.NewBalances(cache.NewLRU(10, time.Minute))}
+		ctrl.GetBalanceHandler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"balance":1200`)
+		assert.Empty(t, w.Header().Get(controllers.CacheStatusHeader))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestController_WalletStatusHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	expectLockedWallet := func(mock sqlmock.Sqlmock, balance int, status string) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.* FOR UPDATE").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, balance, "RUB", status, false, "", "", "{}", time.Now(), time.Now()))
+	}
+
+	newContext := func(path, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+		c.Request, _ = http.NewRequest(http.MethodPost, "/api/v1/admin/wallets/"+walletID+path, strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c, w
+	}
+
+	t.Run("Freeze", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectLockedWallet(mock, 1000, "ACTIVE")
+		mock.ExpectExec("UPDATE wallets SET status").
+			WithArgs("FROZEN", true, walletID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO wallet_status_changes").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectOutboxEvents(mock, 1)
+		mock.ExpectCommit()
+
+		c, w := newContext("/freeze", `{"reason": "AML check", "blockDeposits": true}`)
+		ctrl := controllers.Controller{DB: db}
+		ctrl.FreezeWalletHandler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"FROZEN"`)
+		assert.Contains(t, w.Body.String(), `"depositsBlocked":true`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Close a wallet with funds", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectLockedWallet(mock, 1000, "ACTIVE")
+		mock.ExpectRollback()
+
+		c, w := newContext("/close", `{"reason": "customer request"}`)
+		ctrl := controllers.Controller{DB: db}
+		ctrl.CloseWalletHandler(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "wallet_not_empty")
+	})
+
+	t.Run("Unfreeze without a reason", func(t *testing.T) {
+		c, w := newContext("/unfreeze", `{}`)
+		ctrl := controllers.Controller{}
+		ctrl.UnfreezeWalletHandler(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Operation on a closed wallet", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectLockedWallet(mock, 0, "CLOSED")
+		mock.ExpectRollback()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		body := fmt.Sprintf(`{"walletId": "%s", "operationType": "DEPOSIT", "amount": 100, "currency": "RUB"}`, walletID)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/api/v1/wallet", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		ctrl := controllers.Controller{DB: db}
+		ctrl.WalletOperationHandler(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "wallet_closed")
+	})
+}
+
+func TestController_ListWalletsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+	}{
+		{"Unknown status", "status=OPEN", http.StatusBadRequest},
+		{"Unsupported currency", "currency=ABC", http.StatusBadRequest},
+		{"Negative balance", "minBalance=-1", http.StatusBadRequest},
+		{"Invalid time", "createdFrom=2025-01-01", http.StatusBadRequest},
+		{"Unknown sort", "sort=owner", http.StatusBadRequest},
+		{"Invalid limit", "limit=0", http.StatusBadRequest},
+		{"Label without a value", "label=tier", http.StatusBadRequest},
+		{"Valid", "status=frozen&currency=rub&maxBalance=1000&sort=-createdAt&limit=10", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			if tt.wantCode == http.StatusOK {
+				mock.ExpectQuery("FROM wallets WHERE status = \\$1 AND currency = \\$2 AND balance <= \\$3 "+
+					"ORDER BY created_at DESC, id DESC LIMIT \\$4").
+					WithArgs("FROZEN", "RUB", uint64(1000), 11).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+						AddRow("f4c863ec-0300-495d-852d-c115e197390b", 500, "RUB", "FROZEN", false, "customer-1", "", "{}", time.Now(), time.Now()))
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/wallets?"+tt.query, nil)
+
+			ctrl := controllers.Controller{DB: db}
+			ctrl.ListWalletsHandler(c)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"owner":"customer-1"`)
+				assert.NotContains(t, w.Body.String(), "nextCursor")
+				assert.NoError(t, mock.ExpectationsWereMet())
+			}
+		})
+	}
+}
+
+func TestController_UpdateWalletHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	t.Run("Invalid JSON body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/api/v1/wallets/"+walletID, strings.NewReader(`{"labels": []}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		ctrl := controllers.Controller{}
+		ctrl.UpdateWalletHandler(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Labels are returned and the cache invalidated", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.* FOR UPDATE").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+		mock.ExpectQuery("UPDATE wallets SET owner").
+			WithArgs("customer-1", "Savings", []byte(`{"tier":"gold"}`), walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+		mock.ExpectCommit()
+
+		balances := cache.NewBalances(cache.NewLRU(10, time.Minute))
+		balances.Fill(models.Wallet{Id: walletID, Balance: 1000, Currency: "RUB"}, balances.Generation(walletID))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+		body := `{"owner": "customer-1", "name": "Savings", "labels": {"tier": "gold"}}`
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/api/v1/wallets/"+walletID, strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		ctrl := controllers.Controller{DB: db, Cache: balances}
+		ctrl.UpdateWalletHandler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"Savings"`)
+		assert.Contains(t, w.Body.String(), `"labels":{"tier":"gold"}`)
+		_, cached := balances.Get(walletID)
+		assert.False(t, cached)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestController_WebhookHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const webhookID = "9e4c2f1a-6b7d-4c8e-a0f3-2d5b8c1e7f90"
+
+	newContext := func(method, path, body string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = params
+		c.Request, _ = http.NewRequest(method, "/api/v1/webhooks"+path, strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c, w
+	}
+	webhookRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "client_id", "url", "secret", "events", "wallet_id",
+			"low_balance_threshold", "active", "created_at"}).
+			AddRow(webhookID, "billing", "https://billing.example.com/hooks", "whsec_x", "{deposit,freeze}", "",
+				nil, true, time.Now())
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("INSERT INTO webhook_subscriptions").
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+
+		c, w := newContext(http.MethodPost, "", `{"clientId": "billing", "url": "https://billing.example.com/hooks", "events": ["deposit", "freeze"]}`, nil)
+		ctrl := controllers.Controller{DB: db}
+		ctrl.CreateWebhookHandler(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"secret":"whsec_`)
+		assert.Contains(t, w.Body.String(), `"events":["deposit","freeze"]`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Create with unknown event", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		c, w := newContext(http.MethodPost, "", `{"clientId": "billing", "url": "https://billing.example.com/hooks", "events": ["refund"]}`, nil)
+		ctrl := controllers.Controller{DB: db}
+		ctrl.CreateWebhookHandler(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Get hides the secret", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM webhook_subscriptions WHERE id = \\$1").
+			WithArgs(webhookID).
+			WillReturnRows(webhookRows())
+
+		c, w := newContext(http.MethodGet, "/"+webhookID, "", gin.Params{{Key: "WEBHOOK_ID", Value: webhookID}})
+		ctrl := controllers.Controller{DB: db}
+		ctrl.GetWebhookHandler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "whsec_")
+	})
+
+	t.Run("Delivery log", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM webhook_subscriptions WHERE id = \\$1").
+			WithArgs(webhookID).
+			WillReturnRows(webhookRows())
+		mock.ExpectQuery("SELECT .* FROM webhook_deliveries d").
+			WithArgs(webhookID, "DEAD", 100).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "event_type", "payload",
+				"status", "attempts", "last_status_code", "last_error", "next_attempt_at", "created_at", "delivered_at"}).
+				AddRow(7, webhookID, "0b8f2a4c-4d1e-4b7a-9d3c-6f5e2a1b0c9d", "deposit", []byte(`{"balance":1500}`),
+					"DEAD", 10, 503, "endpoint responded with status 503", time.Now(), time.Now(), nil))
+
+		c, w := newContext(http.MethodGet, "/"+webhookID+"/deliveries?status=dead", "", gin.Params{{Key: "WEBHOOK_ID", Value: webhookID}})
+		ctrl := controllers.Controller{DB: db}
+		ctrl.ListWebhookDeliveriesHandler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"DEAD"`)
+		assert.Contains(t, w.Body.String(), `"lastStatusCode":503`)
+		assert.Contains(t, w.Body.String(), `"payload":{"balance":1500}`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Retry a delivery that is not dead", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM webhook_subscriptions WHERE id = \\$1").
+			WithArgs(webhookID).
+			WillReturnRows(webhookRows())
+		mock.ExpectExec("UPDATE webhook_deliveries d SET status = 'PENDING'").
+			WithArgs(int64(7), webhookID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		c, w := newContext(http.MethodPost, "/"+webhookID+"/deliveries/7/retry", "",
+			gin.Params{{Key: "WEBHOOK_ID", Value: webhookID}, {Key: "DELIVERY_ID", Value: "7"}})
+		ctrl := controllers.Controller{DB: db}
+		ctrl.RetryWebhookDeliveryHandler(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "delivery_not_found")
+	})
+
+	t.Run("Delete unknown webhook", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("WITH deactivated AS").
+			WithArgs(webhookID).
+			WillReturnError(sql.ErrNoRows)
+
+		c, w := newContext(http.MethodDelete, "/"+webhookID, "", gin.Params{{Key: "WEBHOOK_ID", Value: webhookID}})
+		ctrl := controllers.Controller{DB: db}
+		ctrl.DeleteWebhookHandler(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestController_ScheduledOperationHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const scheduleID = "7d1e5c2a-3b4f-4a6d-9e8c-1f2a3b4c5d6e"
+
+	newContext := func(method, path, body string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = params
+		c.Request, _ = http.NewRequest(method, "/api/v1/scheduled-operations"+path, strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c, w
+	}
+	scheduleRows := func(status string) *sqlmock.Rows {
+		start := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+		return sqlmock.NewRows([]string{"id", "wallet_id", "operation_type", "amount", "currency", "description",
+			"metadata", "schedule", "start_at", "end_at", "max_retries", "status", "next_run_at", "attempts",
+			"next_attempt_at", "created_at", "updated_at"}).
+			AddRow(scheduleID, "f4c863ec-0300-495d-852d-c115e197390b", "WITHDRAW", 1000, "RUB", "", []byte("{}"),
+				"monthly", start, nil, 3, status, nil, 0, nil, start, start)
+	}
+
+	t.Run("Create with invalid schedule", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		c, w := newContext(http.MethodPost, "", `{"walletId": "f4c863ec-0300-495d-852d-c115e197390b", "operationType": "WITHDRAW", "amount": 1000, "currency": "RUB", "schedule": "0 25 * * *"}`, nil)
+		ctrl := controllers.Controller{DB: db}
+		ctrl.CreateScheduledOperationHandler(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Cancel a completed operation", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectExec("UPDATE scheduled_operations SET status = 'CANCELLED'").
+			WithArgs(scheduleID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT .* FROM scheduled_operations WHERE id = \\$1").
+			WithArgs(scheduleID).
+			WillReturnRows(scheduleRows("COMPLETED"))
+
+		c, w := newContext(http.MethodDelete, "/"+scheduleID, "", gin.Params{{Key: "SCHEDULE_ID", Value: scheduleID}})
+		ctrl := controllers.Controller{DB: db}
+		ctrl.CancelScheduledOperationHandler(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "schedule_not_active")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Runs", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM scheduled_operations WHERE id = \\$1").
+			WithArgs(scheduleID).
+			WillReturnRows(scheduleRows("ACTIVE"))
+		mock.ExpectQuery("SELECT .* FROM scheduled_operation_runs").
+			WithArgs(scheduleID, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "schedule_id", "run_at", "attempt", "status", "reference",
+				"error", "executed_at"}).
+				AddRow(42, scheduleID, time.Now(), 2, "FAILED", "schedule:"+scheduleID+":1751360400", "negative_amount", time.Now()))
+
+		c, w := newContext(http.MethodGet, "/"+scheduleID+"/runs?limit=5", "", gin.Params{{Key: "SCHEDULE_ID", Value: scheduleID}})
+		ctrl := controllers.Controller{DB: db}
+		ctrl.ListScheduledRunsHandler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"FAILED"`)
+		assert.Contains(t, w.Body.String(), `"error":"negative_amount"`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestController_StreamWalletHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+	walletRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(walletID, 1500, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now())
+	}
+	eventRows := func(id int64) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "event_id", "type", "wallet_id", "payload", "created_at", "attempts"}).
+			AddRow(id, "0b8f2a4c-4d1e-4b7a-9d3c-6f5e2a1b0c9d", models.EventBalanceChanged, walletID,
+				[]byte(`{"balance":2000}`), time.Now(), 0)
+	}
+	expectOpen := func(mock sqlmock.Sqlmock, lastID int64) {
+		mock.ExpectQuery("SELECT COALESCE\\(MAX\\(id\\), 0\\) FROM outbox_events").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(lastID))
+		mock.ExpectQuery("SELECT (.+) FROM wallets WHERE id = \\$1").WillReturnRows(walletRows())
+	}
+
+	t.Run("Test 1: Server-sent events start from the current balance", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectOpen(mock, 6)
+		mock.ExpectQuery("SELECT (.+) FROM outbox_events WHERE wallet_id = \\$1 AND id > \\$2").
+			WithArgs(walletID, int64(6), 100).
+			WillReturnRows(eventRows(7))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+		c.Request, _ = http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/wallets/"+walletID+"/stream", nil)
+
+		ctrl := controllers.Controller{DB: db, StreamHeartbeat: time.Hour}
+		ctrl.StreamWalletHandler(c)
+
+		body := w.Body.String()
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Contains(t, body, "event: balance\ndata: {\"uuid\":\""+walletID+"\",\"balance\":1500")
+		assert.Contains(t, body, "id: 7\nevent: wallet.balance_changed\ndata: {\"balance\":2000}\n\n")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Test 2: Invalid Last-Event-ID", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "WALLET_UUID", Value: walletID}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/api/v1/wallets/"+walletID+"/stream", nil)
+		c.Request.Header.Set(controllers.LastEventIdHeader, "abc")
+
+		ctrl := controllers.Controller{DB: db}
+		ctrl.StreamWalletHandler(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Test 3: WebSocket resumes after lastEventId", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectOpen(mock, 9)
+		mock.ExpectQuery("SELECT (.+) FROM outbox_events WHERE wallet_id = \\$1 AND id > \\$2").
+			WithArgs(walletID, int64(6), 100).
+			WillReturnRows(eventRows(7))
+
+		ctrl := controllers.Controller{DB: db, StreamHeartbeat: time.Hour}
+		router := gin.New()
+		router.GET("/api/v1/wallets/:WALLET_UUID/stream", ctrl.StreamWalletHandler)
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/wallets/" + walletID + "/stream?lastEventId=6"
+		ws, err := websocket.Dial(url, "", server.URL)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		defer ws.Close()
+
+		var message models.WalletStreamMessage
+		if err := websocket.JSON.Receive(ws, &message); err != nil {
+			t.Fatalf("receive: %v", err)
+		}
+		assert.Equal(t, int64(7), message.Id)
+		assert.Equal(t, models.EventBalanceChanged, message.Type)
+		assert.JSONEq(t, `{"balance":2000}`, string(message.Data))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// CreateWebhookHandler godoc
+// @Summary      Register a webhook endpoint
+// @Description  Register an endpoint that receives signed JSON POSTs for the selected events: deposit, withdrawal, low_balance (with a threshold) and freeze. The signing secret is only returned here.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateWebhookRequest  true  "Endpoint parameters"
+// @Success      201      {object}  models.CreateWebhookResponse
+// @Failure      400      {object}  utils.ErrorResponse  "Invalid request"
+// @Failure      404      {object}  utils.ErrorResponse  "Wallet not found"
+// @Failure      500      {object}  utils.ErrorResponse  "Internal server error"
+// @Router       /v1/webhooks [post]
+func (controller *Controller) CreateWebhookHandler(c *gin.Context) {
+	var request models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Logger.WithError(err).Warn("bad JSON body")
+		utils.HandleError(c, utils.ErrInvalidRequest)
+		return
+	}
+
+	subscription, err := service.CreateWebhookService(controller.DB, request)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service CreateWebhookService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.CreateWebhookResponse{
+		WebhookResponse: NewWebhookResponse(subscription),
+		Secret:          subscription.Secret,
+	})
+}
+
+// ListWebhooksHandler godoc
+// @Summary      List webhook endpoints
+// @Description  Return the registered webhook endpoints, oldest first, without their secrets.
+// @Tags         webhooks
+// @Produce      json
+// @Param        clientId  query     string  false  "Only the endpoints of this client"
+// @Success      200       {array}   models.WebhookResponse
+// @Failure      500       {object}  utils.ErrorResponse  "Internal server error"
+// @Router       /v1/webhooks [get]
+func (controller *Controller) ListWebhooksHandler(c *gin.Context) {
+	subscriptions, err := service.ListWebhooksService(controller.DB, c.Query("clientId"))
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service ListWebhooksService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	response := make([]models.WebhookResponse, 0, len(subscriptions))
+	for i := range subscriptions {
+		response = append(response, NewWebhookResponse(&subscriptions[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetWebhookHandler godoc
+// @Summary      Get a webhook endpoint
+// @Tags         webhooks
+// @Produce      json
+// @Param        WEBHOOK_ID  path      string  true  "Webhook UUID"
+// @Success      200         {object}  models.WebhookResponse
+// @Failure      400         {object}  utils.ErrorResponse
+// @Failure      404         {object}  utils.ErrorResponse
+// @Router       /v1/webhooks/{WEBHOOK_ID} [get]
+func (controller *Controller) GetWebhookHandler(c *gin.Context) {
+	webhookID := c.Param("WEBHOOK_ID")
+	if err := ValidateUUID(webhookID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	subscription, err := service.GetWebhookService(controller.DB, webhookID)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service GetWebhookService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewWebhookResponse(subscription))
+}
+
+// DeleteWebhookHandler godoc
+// @Summary      Deactivate a webhook endpoint
+// @Description  Stop deliveries to the endpoint. Pending deliveries are dead-lettered; the delivery log is kept.
+// @Tags         webhooks
+// @Param        WEBHOOK_ID  path  string  true  "Webhook UUID"
+// @Success      204
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /v1/webhooks/{WEBHOOK_ID} [delete]
+func (controller *Controller) DeleteWebhookHandler(c *gin.Context) {
+	webhookID := c.Param("WEBHOOK_ID")
+	if err := ValidateUUID(webhookID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	if err := service.DeleteWebhookService(controller.DB, webhookID); err != nil {
+		utils.Logger.WithError(err).Warn("service DeleteWebhookService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveriesHandler godoc
+// @Summary      List webhook deliveries
+// @Description  Return the delivery log of an endpoint, newest first, with the outcome of the last attempt.
+// @Tags         webhooks
+// @Produce      json
+// @Param        WEBHOOK_ID  path      string  true   "Webhook UUID"
+// @Param        status      query     string  false  "PENDING, DELIVERED or DEAD"
+// @Param        limit       query     int     false  "Maximum number of deliveries (default 100, max 1000)"
+// @Success      200         {array}   models.WebhookDeliveryResponse
+// @Failure      400         {object}  utils.ErrorResponse
+// @Failure      404         {object}  utils.ErrorResponse
+// @Router       /v1/webhooks/{WEBHOOK_ID}/deliveries [get]
+func (controller *Controller) ListWebhookDeliveriesHandler(c *gin.Context) {
+	webhookID := c.Param("WEBHOOK_ID")
+	if err := ValidateUUID(webhookID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 {
+			utils.Logger.Warnf("invalid limit: %q", raw)
+			utils.HandleError(c, utils.ErrInvalidRequest)
+			return
+		}
+	}
+
+	deliveries, err := service.ListWebhookDeliveriesService(controller.DB, webhookID, c.Query("status"), limit)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service ListWebhookDeliveriesService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	response := make([]models.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		entry := models.WebhookDeliveryResponse{
+			Id:             delivery.Id,
+			EventId:        delivery.EventId,
+			EventType:      delivery.EventType,
+			Payload:        delivery.Payload,
+			Status:         delivery.Status,
+			Attempts:       delivery.Attempts,
+			LastStatusCode: delivery.LastStatusCode,
+			LastError:      delivery.LastError,
+			CreatedAt:      delivery.CreatedTime,
+			DeliveredAt:    delivery.DeliveredTime,
+		}
+		if delivery.Status == models.DeliveryPending {
+			entry.NextAttemptAt = &delivery.NextAttemptAt
+		}
+		response = append(response, entry)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RetryWebhookDeliveryHandler godoc
+// @Summary      Retry a dead-lettered delivery
+// @Description  Requeue a delivery that ran out of attempts, with a fresh set of attempts.
+// @Tags         webhooks
+// @Param        WEBHOOK_ID   path  string  true  "Webhook UUID"
+// @Param        DELIVERY_ID  path  int     true  "Delivery id"
+// @Success      202
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse  "Webhook not found / no such dead delivery"
+// @Router       /v1/webhooks/{WEBHOOK_ID}/deliveries/{DELIVERY_ID}/retry [post]
+func (controller *Controller) RetryWebhookDeliveryHandler(c *gin.Context) {
+	webhookID := c.Param("WEBHOOK_ID")
+	if err := ValidateUUID(webhookID); err != nil {
+		utils.Logger.WithError(err).Warn("invalid UUID")
+		utils.HandleError(c, err)
+		return
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("DELIVERY_ID"), 10, 64)
+	if err != nil || deliveryID <= 0 {
+		utils.Logger.Warnf("invalid delivery id: %q", c.Param("DELIVERY_ID"))
+		utils.HandleError(c, utils.ErrInvalidRequest)
+		return
+	}
+
+	if err := service.RetryWebhookDeliveryService(controller.DB, webhookID, deliveryID); err != nil {
+		utils.Logger.WithError(err).Warn("service RetryWebhookDeliveryService failed")
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// NewWebhookResponse converts a webhook endpoint to its API representation, without the secret.
+func NewWebhookResponse(subscription *models.WebhookSubscription) models.WebhookResponse {
+	return models.WebhookResponse{
+		Id:                  subscription.Id,
+		ClientId:            subscription.ClientId,
+		URL:                 subscription.URL,
+		Events:              subscription.Events,
+		WalletId:            subscription.WalletId,
+		LowBalanceThreshold: subscription.LowBalanceThreshold,
+		Active:              subscription.Active,
+		CreatedAt:           subscription.CreatedTime,
+	}
+}
@@ -0,0 +1,8 @@
+// Package grpcserver serves the wallet API over gRPC.
+//
+// Its handlers call the same service layer as the REST controllers, so both
+// APIs validate requests and fail in the same way. Service errors are mapped
+// to gRPC status codes, with the REST error code as the reason of a
+// google.rpc.ErrorInfo detail. The standard health and reflection services
+// are registered alongside walletpb.WalletService.
+package grpcserver
@@ -0,0 +1,77 @@
+package grpcserver
+
+import (
+	"JavaCode/utils"
+	"context"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the ErrorInfo domain of the errors returned by the server.
+const ErrorDomain = "wallet"
+
+// errorCodes maps service errors to gRPC status codes, in the order
+// utils.NewErrorResponse checks them.
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{utils.ErrInvalidRequest, codes.InvalidArgument},
+	{utils.ErrInvalidAmount, codes.InvalidArgument},
+	{utils.ErrAmountPrecision, codes.InvalidArgument},
+	{utils.ErrAmountOverflow, codes.OutOfRange},
+	{utils.ErrNegativeBalance, codes.InvalidArgument},
+	{utils.ErrWalletNotFound, codes.NotFound},
+	{utils.ErrWalletExists, codes.AlreadyExists},
+	{utils.ErrWalletFrozen, codes.FailedPrecondition},
+	{utils.ErrWalletClosed, codes.FailedPrecondition},
+	{utils.ErrWalletNotEmpty, codes.FailedPrecondition},
+	{utils.ErrInvalidStatus, codes.FailedPrecondition},
+	{utils.ErrDatabase, codes.Internal},
+	{utils.ErrHistoryUnavailable, codes.FailedPrecondition},
+	{utils.ErrUnsupportedCurrency, codes.InvalidArgument},
+	{utils.ErrCurrencyMismatch, codes.FailedPrecondition},
+	{utils.ErrRateNotFound, codes.FailedPrecondition},
+	{utils.ErrStaleRate, codes.FailedPrecondition},
+	{utils.ErrTransferNotFound, codes.NotFound},
+	{utils.ErrDuplicateReference, codes.AlreadyExists},
+	{utils.ErrWebhookNotFound, codes.NotFound},
+	{utils.ErrDeliveryNotFound, codes.NotFound},
+	{utils.ErrScheduleNotFound, codes.NotFound},
+	{utils.ErrScheduleNotActive, codes.FailedPrecondition},
+	{utils.ErrNotReady, codes.Unavailable},
+}
+
+// Code returns the gRPC status code of a service error; unknown errors are Internal.
+func Code(err error) codes.Code {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+	for _, mapping := range errorCodes {
+		if errors.Is(err, mapping.err) {
+			return mapping.code
+		}
+	}
+	return codes.Internal
+}
+
+// Error converts a service error to a gRPC status error carrying the REST
+// error code and message, so that clients of both APIs see the same errors.
+func Error(err error) error {
+	code := Code(err)
+	if code == codes.Canceled || code == codes.DeadlineExceeded {
+		return status.Error(code, err.Error())
+	}
+
+	response := utils.NewErrorResponse(err)
+	st := status.New(code, response.Message)
+	if detailed, derr := st.WithDetails(&errdetails.ErrorInfo{Reason: response.Error, Domain: ErrorDomain}); derr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,193 @@
+package grpcserver_test
+
+import (
+	"JavaCode/internal/grpcserver"
+	"JavaCode/pkg/walletpb"
+	"JavaCode/utils"
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+// dial serves wallets on an in-memory listener and returns a connected client.
+func dial(t *testing.T, wallets *grpcserver.WalletServer) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server, _ := grpcserver.New(wallets)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func walletRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+		AddRow(walletID, 1500, "RUB", "ACTIVE", false, "customer-1842", "", "{}", time.Now(), time.Now())
+}
+
+// reason returns the ErrorInfo reason of a status error.
+func reason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{utils.ErrInvalidRequest, codes.InvalidArgument},
+		{fmt.Errorf("%w: timeout", utils.ErrDatabase), codes.Internal},
+		{utils.ErrWalletNotFound, codes.NotFound},
+		{utils.ErrWalletFrozen, codes.FailedPrecondition},
+		{utils.ErrDuplicateReference, codes.AlreadyExists},
+		{utils.ErrAmountOverflow, codes.OutOfRange},
+		{fmt.Errorf("%w: database unreachable", utils.ErrNotReady), codes.Unavailable},
+		{context.Canceled, codes.Canceled},
+		{io.ErrUnexpectedEOF, codes.Internal},
+	}
+	for _, tt := range tests {
+		if got := grpcserver.Code(tt.err); got != tt.want {
+			t.Errorf("Code(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWalletServer(t *testing.T) {
+	t.Run("Test 1: GetBalance", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM wallets WHERE id = \\$1").WithArgs(walletID).WillReturnRows(walletRows())
+
+		client := walletpb.NewWalletServiceClient(dial(t, &grpcserver.WalletServer{DB: db}))
+		wallet, err := client.GetBalance(context.Background(), &walletpb.GetBalanceRequest{WalletId: walletID})
+
+		if err != nil {
+			t.Fatalf("GetBalance: %v", err)
+		}
+		if wallet.Balance != 1500 || wallet.Currency != "RUB" || wallet.Exponent != 2 || wallet.Owner != "customer-1842" {
+			t.Errorf("unexpected wallet %v", wallet)
+		}
+	})
+
+	t.Run("Test 2: Errors carry the status code and REST error code", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM wallets WHERE id = \\$1").WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		client := walletpb.NewWalletServiceClient(dial(t, &grpcserver.WalletServer{DB: db}))
+
+		_, err := client.GetBalance(context.Background(), &walletpb.GetBalanceRequest{WalletId: "not-a-uuid"})
+		if status.Code(err) != codes.InvalidArgument || reason(err) != "invalid_request" {
+			t.Errorf("invalid id: got %v (%s)", err, reason(err))
+		}
+
+		_, err = client.GetBalance(context.Background(), &walletpb.GetBalanceRequest{WalletId: walletID})
+		if status.Code(err) != codes.NotFound || reason(err) != "wallet_not_found" {
+			t.Errorf("missing wallet: got %v (%s)", err, reason(err))
+		}
+	})
+
+	t.Run("Test 3: ApplyOperation validates like the REST API", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		client := walletpb.NewWalletServiceClient(dial(t, &grpcserver.WalletServer{DB: db}))
+		tests := []struct {
+			name    string
+			request *walletpb.ApplyOperationRequest
+			reason  string
+		}{
+			{"Zero amount", &walletpb.ApplyOperationRequest{WalletId: walletID,
+				OperationType: walletpb.OperationType_OPERATION_TYPE_DEPOSIT, Currency: "RUB"}, "negative_amount"},
+			{"No operation type", &walletpb.ApplyOperationRequest{WalletId: walletID, Amount: 100, Currency: "RUB"}, "invalid_request"},
+			{"Unsupported currency", &walletpb.ApplyOperationRequest{WalletId: walletID,
+				OperationType: walletpb.OperationType_OPERATION_TYPE_WITHDRAW, Amount: 100, Currency: "XYZ"}, "unsupported_currency"},
+		}
+		for _, tt := range tests {
+			_, err := client.ApplyOperation(context.Background(), tt.request)
+			if status.Code(err) != codes.InvalidArgument || reason(err) != tt.reason {
+				t.Errorf("%s: got %v (%s), want %s", tt.name, err, reason(err), tt.reason)
+			}
+		}
+	})
+
+	t.Run("Test 4: StreamHistory sends the entries newest first", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM wallets WHERE id = \\$1").WithArgs(walletID).WillReturnRows(walletRows())
+		mock.ExpectQuery("SELECT .* FROM ledger_entries e JOIN ledger_transactions t .* AND e.id < \\$3").
+			WithArgs(walletID, "", int64(9223372036854775807), 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "transaction_id", "type", "description", "reference", "metadata",
+				"account_id", "direction", "amount", "currency", "created_at"}).
+				AddRow(9, "5b0f7b0e-8a53-4b39-a0c4-2f7d4c5e2c11", "WITHDRAW", "", "", `{}`, walletID, "DEBIT", 500, "RUB", time.Now()).
+				AddRow(4, "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c", "DEPOSIT", "Order #1001", "order-1001", `{"invoice": "INV-7"}`,
+					walletID, "CREDIT", 2000, "RUB", time.Now()))
+
+		client := walletpb.NewWalletServiceClient(dial(t, &grpcserver.WalletServer{DB: db}))
+		stream, err := client.StreamHistory(context.Background(), &walletpb.StreamHistoryRequest{WalletId: walletID, Limit: 2})
+		if err != nil {
+			t.Fatalf("StreamHistory: %v", err)
+		}
+
+		var ids []int64
+		for {
+			entry, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Recv: %v", err)
+			}
+			ids = append(ids, entry.Id)
+			if entry.Id == 4 && entry.Metadata["invoice"] != "INV-7" {
+				t.Errorf("metadata not sent: %v", entry.Metadata)
+			}
+		}
+		if len(ids) != 2 || ids[0] != 9 || ids[1] != 4 {
+			t.Errorf("got entries %v, want [9 4]", ids)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 5: Health", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		response, err := healthpb.NewHealthClient(dial(t, &grpcserver.WalletServer{DB: db})).
+			Check(context.Background(), &healthpb.HealthCheckRequest{})
+		if err != nil || response.Status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("health: got %v, %v", response, err)
+		}
+	})
+}
@@ -0,0 +1,226 @@
+package grpcserver
+
+import (
+	"JavaCode/internal/cache"
+	"JavaCode/internal/models"
+	"JavaCode/internal/service"
+	"JavaCode/pkg/currency"
+	"JavaCode/pkg/walletpb"
+	"JavaCode/utils"
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// operationTypes maps the protobuf operation types to the service ones.
+var operationTypes = map[walletpb.OperationType]string{
+	walletpb.OperationType_OPERATION_TYPE_DEPOSIT:  service.DEPOSIT,
+	walletpb.OperationType_OPERATION_TYPE_WITHDRAW: service.WITHDRAW,
+}
+
+// WalletServer implements walletpb.WalletServiceServer on top of the service layer.
+type WalletServer struct {
+	walletpb.UnimplementedWalletServiceServer
+
+	DB *sql.DB
+
+	// Cache is the optional balance read cache shared with the REST API; nil disables it.
+	Cache *cache.Balances
+
+	// MaxRateAge is the REST API's limit on the age of rates without validTo.
+	MaxRateAge time.Duration
+}
+
+// New returns a gRPC server serving wallets, the health service and
+// reflection, with request logging. The health server reports SERVING until
+// its status is changed.
+func New(wallets *WalletServer) (*grpc.Server, *health.Server) {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(logUnary),
+		grpc.ChainStreamInterceptor(logStream),
+	)
+	walletpb.RegisterWalletServiceServer(server, wallets)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	return server, healthServer
+}
+
+// GetBalance implements walletpb.WalletServiceServer.
+func (s *WalletServer) GetBalance(_ context.Context, request *walletpb.GetBalanceRequest) (*walletpb.Wallet, error) {
+	if _, err := uuid.Parse(request.GetWalletId()); err != nil {
+		return nil, Error(utils.ErrInvalidRequest)
+	}
+
+	wallet, _, err := service.GetWalletsCachedService(s.DB, s.Cache, request.GetWalletId())
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service GetWalletsCachedService failed")
+		return nil, Error(err)
+	}
+	return NewWallet(wallet), nil
+}
+
+// ApplyOperation implements walletpb.WalletServiceServer.
+func (s *WalletServer) ApplyOperation(_ context.Context, request *walletpb.ApplyOperationRequest) (*walletpb.ApplyOperationResponse, error) {
+	operation := models.WalletOperationRequest{
+		WalletID:      request.GetWalletId(),
+		OperationType: operationTypes[request.GetOperationType()],
+		Amount:        request.GetAmount(),
+		Currency:      request.GetCurrency(),
+		OperationDetails: models.OperationDetails{
+			Description: request.GetDescription(),
+			Reference:   request.GetReference(),
+			Metadata:    request.GetMetadata(),
+		},
+	}
+	if err := service.ValidateOperationRequest(operation); err != nil {
+		utils.Logger.WithError(err).Warn("invalid operation request")
+		return nil, Error(err)
+	}
+
+	if err := service.HandleOperationService(s.DB, s.Cache, operation); err != nil {
+		utils.Logger.WithError(err).Warn("service Handle Operation failed")
+		return nil, Error(err)
+	}
+
+	wallet, err := service.GetWalletsService(s.DB, operation.WalletID)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service GetWalletsService failed")
+		return nil, Error(err)
+	}
+	return &walletpb.ApplyOperationResponse{Wallet: NewWallet(wallet)}, nil
+}
+
+// Transfer implements walletpb.WalletServiceServer.
+func (s *WalletServer) Transfer(_ context.Context, request *walletpb.TransferRequest) (*walletpb.TransferResponse, error) {
+	transferRequest := models.TransferRequest{
+		FromWalletID: request.GetFromWalletId(),
+		ToWalletID:   request.GetToWalletId(),
+		Amount:       request.GetAmount(),
+		Currency:     request.GetCurrency(),
+	}
+	if err := service.ValidateTransferRequest(transferRequest); err != nil {
+		utils.Logger.WithError(err).Warn("invalid transfer request")
+		return nil, Error(err)
+	}
+
+	transfer, err := service.TransferService(s.DB, s.Cache, transferRequest, s.MaxRateAge)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("service TransferService failed")
+		return nil, Error(err)
+	}
+	return NewTransfer(transfer), nil
+}
+
+// StreamHistory implements walletpb.WalletServiceServer.
+func (s *WalletServer) StreamHistory(request *walletpb.StreamHistoryRequest, stream walletpb.WalletService_StreamHistoryServer) error {
+	if _, err := uuid.Parse(request.GetWalletId()); err != nil {
+		return Error(utils.ErrInvalidRequest)
+	}
+
+	err := service.StreamLedgerEntriesService(stream.Context(), s.DB, request.GetWalletId(), request.GetReference(),
+		int(request.GetLimit()), func(entries []models.LedgerEntry) error {
+			for i := range entries {
+				if err := stream.Send(NewLedgerEntry(&entries[i])); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		utils.Logger.WithError(err).Warn("service StreamLedgerEntriesService failed")
+		return Error(err)
+	}
+	return nil
+}
+
+// NewWallet converts a wallet to its protobuf representation.
+func NewWallet(wallet *models.Wallet) *walletpb.Wallet {
+	exponent, _ := currency.Exponent(wallet.Currency)
+	return &walletpb.Wallet{
+		Id:              wallet.Id,
+		Balance:         wallet.Balance,
+		Currency:        wallet.Currency,
+		Exponent:        int32(exponent),
+		Status:          wallet.Status,
+		DepositsBlocked: wallet.DepositsBlocked,
+		Owner:           wallet.Owner,
+		Name:            wallet.Name,
+		Labels:          wallet.Labels,
+		CreatedAt:       timestamppb.New(wallet.CreatedTime),
+	}
+}
+
+// NewTransfer converts a transfer to its protobuf representation.
+func NewTransfer(transfer *models.Transfer) *walletpb.TransferResponse {
+	response := &walletpb.TransferResponse{
+		Id:             transfer.Id,
+		FromWalletId:   transfer.FromWalletId,
+		ToWalletId:     transfer.ToWalletId,
+		SourceAmount:   transfer.SourceAmount,
+		SourceCurrency: transfer.SourceCurrency,
+		TargetAmount:   transfer.TargetAmount,
+		TargetCurrency: transfer.TargetCurrency,
+		RateId:         transfer.RateId,
+		CreatedAt:      timestamppb.New(transfer.CreatedTime),
+	}
+	if transfer.RateUnits != nil && transfer.RatePrecision != nil {
+		response.Rate = currency.FormatRate(*transfer.RateUnits, *transfer.RatePrecision)
+	}
+	if transfer.RoundingMode != nil {
+		response.RoundingMode = *transfer.RoundingMode
+	}
+	return response
+}
+
+// NewLedgerEntry converts a ledger entry to its protobuf representation.
+func NewLedgerEntry(entry *models.LedgerEntry) *walletpb.LedgerEntry {
+	return &walletpb.LedgerEntry{
+		Id:              entry.Id,
+		TransactionId:   entry.TransactionId,
+		TransactionType: entry.TransactionType,
+		Direction:       entry.Direction,
+		Amount:          entry.Amount,
+		Currency:        entry.Currency,
+		Description:     entry.Details.Description,
+		Reference:       entry.Details.Reference,
+		Metadata:        entry.Details.Metadata,
+		CreatedAt:       timestamppb.New(entry.CreatedTime),
+	}
+}
+
+// logUnary logs every unary call with its status code and latency.
+func logUnary(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	response, err := handler(ctx, request)
+	logCall(info.FullMethod, err, start)
+	return response, err
+}
+
+// logStream logs every streaming call with its status code and latency.
+func logStream(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(server, stream)
+	logCall(info.FullMethod, err, start)
+	return err
+}
+
+func logCall(method string, err error, start time.Time) {
+	utils.Logger.WithFields(logrus.Fields{
+		"method":  method,
+		"code":    status.Code(err).String(),
+		"latency": time.Since(start),
+	}).Info("grpc call finished")
+}
@@ -0,0 +1,92 @@
+package loadtest
+
+import (
+	"JavaCode/pkg/walletclient"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// maxInconsistent caps Consistency.Inconsistent.
+const maxInconsistent = 100
+
+// Consistency is the outcome of the check that the load neither lost nor
+// created money.
+type Consistency struct {
+	// Expected is the total balance before the load plus the deposits and
+	// minus the withdrawals that were applied. Transfers do not change it.
+	Expected int64 `json:"expected"`
+	// Actual is the total balance of the wallets after the load.
+	Actual     int64 `json:"actual"`
+	Difference int64 `json:"difference"`
+	// Resolved counts the operations whose outcome was unknown, e.g. after
+	// a timeout, and that were settled by sending them again with the same
+	// reference: the server either applies them or rejects the duplicate.
+	Resolved int `json:"resolved"`
+	// Unresolved counts the operations still of unknown outcome; with any,
+	// Expected is not exact and the check fails.
+	Unresolved int `json:"unresolved"`
+	// Inconsistent lists wallets whose balance does not match their ledger.
+	Inconsistent []walletclient.BalanceVerification `json:"inconsistent,omitempty"`
+	OK           bool                               `json:"ok"`
+}
+
+// Check settles the operations of unknown outcome, then compares the
+// total balance of the wallets with the money the load moved and verifies
+// each wallet's balance against its ledger.
+//
+// It returns an error if the balances cannot be read.
+func (r *Runner) Check(ctx context.Context, api API) (*Consistency, error) {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+
+	result := &Consistency{}
+	for _, operation := range pending {
+		_, err := api.ApplyOperation(ctx, operation)
+		switch {
+		case err == nil, errors.Is(err, walletclient.ErrDuplicateReference):
+			r.applied += signedAmount(operation)
+			result.Resolved++
+		case isRejection(err):
+			result.Resolved++
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		default:
+			result.Unresolved++
+		}
+	}
+	result.Expected = r.baseline + r.applied
+
+	balances := make([]uint64, len(r.cfg.Wallets))
+	verifications := make([]*walletclient.BalanceVerification, len(r.cfg.Wallets))
+	err := forEach(ctx, len(r.cfg.Wallets), r.cfg.Concurrency, func(ctx context.Context, i int) error {
+		wallet, err := api.GetBalance(ctx, r.cfg.Wallets[i], walletclient.ReadStrong())
+		if err != nil {
+			return fmt.Errorf("wallet %s: %w", r.cfg.Wallets[i], err)
+		}
+		balances[i] = wallet.Balance
+		if verifications[i], err = api.VerifyWalletBalance(ctx, r.cfg.Wallets[i]); err != nil {
+			return fmt.Errorf("wallet %s: %w", r.cfg.Wallets[i], err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, balance := range balances {
+		result.Actual += int64(balance)
+		if !verifications[i].Consistent && len(result.Inconsistent) < maxInconsistent {
+			result.Inconsistent = append(result.Inconsistent, *verifications[i])
+		}
+	}
+	result.Difference = result.Actual - result.Expected
+	result.OK = result.Difference == 0 && result.Unresolved == 0 && len(result.Inconsistent) == 0
+
+	r.mu.Lock()
+	r.report.Consistency = result
+	r.mu.Unlock()
+	return result, nil
+}
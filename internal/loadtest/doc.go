@@ -0,0 +1,14 @@
+// Package loadtest generates load on the wallet API and checks afterwards
+// that no money was lost or created.
+//
+// A scenario picks each request: balance reads, deposits, withdrawals or
+// transfers on a set of fixture wallets. Load is either closed loop, a
+// fixed number of workers sending requests back to back, or open loop, a
+// constant rate of requests regardless of how fast the server answers. In
+// the open loop latency is measured from when a request was due rather
+// than when it was sent, so a server falling behind shows in the
+// percentiles instead of lowering the rate.
+//
+// Latencies are recorded in histograms with three significant digits,
+// which can be written in the HdrHistogram percentile format.
+package loadtest
@@ -0,0 +1,61 @@
+package loadtest
+
+import (
+	"JavaCode/pkg/walletclient"
+	"context"
+	"fmt"
+)
+
+// Fixtures describes the wallets created for a load test.
+type Fixtures struct {
+	Count    int
+	Currency string
+	// Balance is deposited to each wallet, in minor units.
+	Balance int64
+	// RunID labels the wallets, so that those of a run can be found and
+	// told apart from real ones.
+	RunID string
+	// Concurrency is the number of wallets created at a time.
+	Concurrency int
+}
+
+// FixtureOwner is the owner of the wallets created for load tests.
+const FixtureOwner = "loadtest"
+
+// CreateWallets creates and funds the fixture wallets and returns their ids.
+func CreateWallets(ctx context.Context, api API, f Fixtures) ([]string, error) {
+	ids := make([]string, f.Count)
+	err := forEach(ctx, f.Count, max(f.Concurrency, 1), func(ctx context.Context, i int) error {
+		wallet, err := api.CreateWallet(ctx, walletclient.CreateWalletRequest{
+			Currency: f.Currency,
+			Owner:    FixtureOwner,
+			Name:     fmt.Sprintf("Load test %s #%d", f.RunID, i+1),
+			Labels:   map[string]string{"loadtest": f.RunID},
+		})
+		if err != nil {
+			return fmt.Errorf("create wallet: %w", err)
+		}
+		ids[i] = wallet.Uuid
+
+		if f.Balance > 0 {
+			_, err = api.ApplyOperation(ctx, walletclient.WalletOperationRequest{
+				WalletID:      wallet.Uuid,
+				OperationType: walletclient.Deposit,
+				Amount:        f.Balance,
+				Currency:      f.Currency,
+				OperationDetails: walletclient.OperationDetails{
+					Description: "Load test funding",
+					Reference:   fmt.Sprintf("loadtest-%s-funding", f.RunID),
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("fund wallet %s: %w", wallet.Uuid, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
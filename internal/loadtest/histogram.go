@@ -0,0 +1,208 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Histogram buckets: values below subBuckets microseconds are exact, larger
+// ones keep three significant digits, as an HdrHistogram does.
+const (
+	subBuckets     = 2048
+	halfSubBuckets = subBuckets / 2
+	subBucketBits  = 11
+)
+
+// Histogram records latencies in microseconds with a bounded relative
+// error of 0.1%, in constant memory per order of magnitude.
+type Histogram struct {
+	counts []int64
+	total  int64
+	sum    float64
+	sumSq  float64
+	min    int64
+	max    int64
+}
+
+// NewHistogram returns an empty histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{min: math.MaxInt64}
+}
+
+// bucketIndex returns the bucket of a value.
+func bucketIndex(v int64) int {
+	if v < subBuckets {
+		return int(v)
+	}
+	shift := bits.Len64(uint64(v)) - subBucketBits
+	return shift*halfSubBuckets + int(v>>shift)
+}
+
+// bucketHighest returns the highest value of a bucket.
+func bucketHighest(i int) int64 {
+	if i < subBuckets {
+		return int64(i)
+	}
+	shift := (i - halfSubBuckets) / halfSubBuckets
+	sub := int64(i - shift*halfSubBuckets)
+	return (sub+1)<<shift - 1
+}
+
+// Record adds a latency.
+func (h *Histogram) Record(d time.Duration) {
+	v := max(d.Microseconds(), 0)
+	i := bucketIndex(v)
+	if i >= len(h.counts) {
+		h.counts = append(h.counts, make([]int64, i+1-len(h.counts))...)
+	}
+	h.counts[i]++
+	h.total++
+	h.sum += float64(v)
+	h.sumSq += float64(v) * float64(v)
+	h.min = min(h.min, v)
+	h.max = max(h.max, v)
+}
+
+// Merge adds the latencies recorded by other.
+func (h *Histogram) Merge(other *Histogram) {
+	if len(other.counts) > len(h.counts) {
+		h.counts = append(h.counts, make([]int64, len(other.counts)-len(h.counts))...)
+	}
+	for i, n := range other.counts {
+		h.counts[i] += n
+	}
+	h.total += other.total
+	h.sum += other.sum
+	h.sumSq += other.sumSq
+	h.min = min(h.min, other.min)
+	h.max = max(h.max, other.max)
+}
+
+// Count returns the number of recorded latencies.
+func (h *Histogram) Count() int64 {
+	return h.total
+}
+
+// Min returns the lowest recorded latency.
+func (h *Histogram) Min() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.min) * time.Microsecond
+}
+
+// Max returns the highest recorded latency.
+func (h *Histogram) Max() time.Duration {
+	return time.Duration(h.max) * time.Microsecond
+}
+
+// Mean returns the mean latency.
+func (h *Histogram) Mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.sum/float64(h.total)) * time.Microsecond
+}
+
+// StdDev returns the standard deviation of the latencies.
+func (h *Histogram) StdDev() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	mean := h.sum / float64(h.total)
+	variance := max(h.sumSq/float64(h.total)-mean*mean, 0)
+	return time.Duration(math.Sqrt(variance)) * time.Microsecond
+}
+
+// Percentile returns the latency below which p percent of the latencies fall.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	return time.Duration(h.percentile(p)) * time.Microsecond
+}
+
+func (h *Histogram) percentile(p float64) int64 {
+	if h.total == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(min(max(p, 0), 100) / 100 * float64(h.total)))
+	rank = max(rank, 1)
+	var seen int64
+	for i, n := range h.counts {
+		seen += n
+		if seen >= rank {
+			return min(bucketHighest(i), h.max)
+		}
+	}
+	return h.max
+}
+
+// Percentiles are the latency percentiles of a report.
+type Percentiles struct {
+	Count int64         `json:"count"`
+	Min   time.Duration `json:"min"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+	P999  time.Duration `json:"p999"`
+	Max   time.Duration `json:"max"`
+}
+
+// Summary returns the usual percentiles.
+func (h *Histogram) Summary() Percentiles {
+	return Percentiles{
+		Count: h.total,
+		Min:   h.Min(),
+		Mean:  h.Mean(),
+		P50:   h.Percentile(50),
+		P90:   h.Percentile(90),
+		P99:   h.Percentile(99),
+		P999:  h.Percentile(99.9),
+		Max:   h.Max(),
+	}
+}
+
+// MarshalJSON encodes the histogram as its percentiles.
+func (h *Histogram) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.Summary())
+}
+
+// WritePercentiles writes the percentile distribution in the text format of
+// HdrHistogram (.hgrm), with values in milliseconds, which its plotter reads.
+func (h *Histogram) WritePercentiles(w io.Writer) error {
+	const ticksPerHalfDistance = 5
+	ms := func(us int64) float64 { return float64(us) / 1000 }
+
+	if _, err := fmt.Fprintf(w, "%12s %14s %10s %14s\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)"); err != nil {
+		return err
+	}
+	if h.total > 0 {
+		for p := 0.0; p < 100; {
+			value := h.percentile(p)
+			var below int64
+			for _, n := range h.counts[:bucketIndex(value)+1] {
+				below += n
+			}
+			if below >= h.total {
+				break
+			}
+			fmt.Fprintf(w, "%12.3f %2.12f %10d %14.2f\n", ms(value), p/100, below, 1/(1-p/100))
+
+			halfDistance := math.Pow(2, math.Floor(math.Log2(100/(100-p)))+1)
+			p += 100 / (ticksPerHalfDistance * halfDistance)
+		}
+		fmt.Fprintf(w, "%12.3f %2.12f %10d\n", ms(h.max), 1.0, h.total)
+	}
+
+	mean, stddev := 0.0, 0.0
+	if h.total > 0 {
+		mean, stddev = ms(h.Mean().Microseconds()), ms(h.StdDev().Microseconds())
+	}
+	fmt.Fprintf(w, "#[Mean    = %12.3f, StdDeviation   = %12.3f]\n", mean, stddev)
+	fmt.Fprintf(w, "#[Max     = %12.3f, Total count    = %12d]\n", ms(h.max), h.total)
+	_, err := fmt.Fprintf(w, "#[Buckets = %12d, SubBuckets     = %12d]\n", len(h.counts), subBuckets)
+	return err
+}
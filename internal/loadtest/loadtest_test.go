@@ -0,0 +1,256 @@
+package loadtest
+
+import (
+	"JavaCode/pkg/walletclient"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAPI keeps wallets in memory. Every failEvery-th operation is applied
+// but reported as a timeout, as when a response is lost.
+type fakeAPI struct {
+	mu         sync.Mutex
+	balances   map[string]uint64
+	references map[string]bool
+	created    int
+	calls      int
+	failEvery  int
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{balances: make(map[string]uint64), references: make(map[string]bool)}
+}
+
+func (f *fakeAPI) CreateWallet(_ context.Context, req walletclient.CreateWalletRequest) (*walletclient.BalanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created++
+	id := fmt.Sprintf("00000000-0000-0000-0000-%012d", f.created)
+	f.balances[id] = 0
+	return &walletclient.BalanceResponse{Uuid: id, Currency: req.Currency}, nil
+}
+
+func (f *fakeAPI) GetBalance(_ context.Context, id string, _ ...walletclient.ReadOption) (*walletclient.BalanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	balance, ok := f.balances[id]
+	if !ok {
+		return nil, walletclient.ErrWalletNotFound
+	}
+	return &walletclient.BalanceResponse{Uuid: id, Balance: balance, Currency: "RUB"}, nil
+}
+
+func (f *fakeAPI) ApplyOperation(_ context.Context, req walletclient.WalletOperationRequest) (*walletclient.OperationResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := req.WalletID + "/" + req.Reference
+	if f.references[key] {
+		return nil, walletclient.ErrDuplicateReference
+	}
+	balance := f.balances[req.WalletID]
+	if req.OperationType == walletclient.Withdraw {
+		if uint64(req.Amount) > balance {
+			return nil, &walletclient.Error{StatusCode: 400, Code: "negative_amount"}
+		}
+		f.balances[req.WalletID] = balance - uint64(req.Amount)
+	} else {
+		f.balances[req.WalletID] = balance + uint64(req.Amount)
+	}
+	f.references[key] = true
+
+	f.calls++
+	if f.failEvery > 0 && f.calls%f.failEvery == 0 {
+		return nil, context.DeadlineExceeded
+	}
+	return &walletclient.OperationResult{Reference: req.Reference}, nil
+}
+
+func (f *fakeAPI) Transfer(_ context.Context, req walletclient.TransferRequest) (*walletclient.TransferResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if uint64(req.Amount) > f.balances[req.FromWalletID] {
+		return nil, &walletclient.Error{StatusCode: 400, Code: "negative_amount"}
+	}
+	f.balances[req.FromWalletID] -= uint64(req.Amount)
+	f.balances[req.ToWalletID] += uint64(req.Amount)
+	return &walletclient.TransferResponse{FromWalletId: req.FromWalletID, ToWalletId: req.ToWalletID}, nil
+}
+
+func (f *fakeAPI) VerifyWalletBalance(_ context.Context, id string) (*walletclient.BalanceVerification, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &walletclient.BalanceVerification{WalletId: id, Balance: f.balances[id], LedgerBalance: int64(f.balances[id]), Consistent: true}, nil
+}
+
+func TestRun_ClosedLoopConsistent(t *testing.T) {
+	for _, name := range ScenarioNames() {
+		t.Run(name, func(t *testing.T) {
+			api := newFakeAPI()
+			scenario, err := LookupScenario(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wallets, err := CreateWallets(context.Background(), api,
+				Fixtures{Count: min(scenario.Wallets, 20), Currency: "RUB", Balance: 500, RunID: "test", Concurrency: 4})
+			if err != nil {
+				t.Fatalf("CreateWallets: %v", err)
+			}
+			api.failEvery = 7
+
+			runner, err := NewRunner(api, Config{Scenario: scenario, Wallets: wallets, MaxAmount: 100, Duration: 50 * time.Millisecond, Concurrency: 4})
+			if err != nil {
+				t.Fatalf("NewRunner: %v", err)
+			}
+			report, err := runner.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if report.Requests == 0 || report.Mode != ClosedLoop {
+				t.Fatalf("report = %+v, want closed-loop requests", report)
+			}
+
+			api.failEvery = 0
+			consistency, err := runner.Check(context.Background(), api)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if !consistency.OK {
+				t.Errorf("consistency = %+v, want OK", consistency)
+			}
+			if name != "transfers" && name != "read-heavy" && consistency.Resolved == 0 {
+				t.Errorf("consistency = %+v, want lost responses resolved", consistency)
+			}
+		})
+	}
+}
+
+func TestRun_OpenLoop(t *testing.T) {
+	api := newFakeAPI()
+	wallets, err := CreateWallets(context.Background(), api, Fixtures{Count: 3, Currency: "RUB", Balance: 1000, RunID: "test"})
+	if err != nil {
+		t.Fatalf("CreateWallets: %v", err)
+	}
+	runner, err := NewRunner(api, Config{Scenario: Scenarios["write-heavy"], Wallets: wallets, MaxAmount: 10,
+		Duration: 200 * time.Millisecond, Rate: 100, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Mode != OpenLoop || report.Requests < 10 || report.Requests > 21 {
+		t.Errorf("report = %+v, want about 20 requests at 100/s for 200ms", report)
+	}
+}
+
+func TestCheck_DetectsLostMoney(t *testing.T) {
+	api := newFakeAPI()
+	wallets, _ := CreateWallets(context.Background(), api, Fixtures{Count: 2, Currency: "RUB", Balance: 1000, RunID: "test"})
+	runner, _ := NewRunner(api, Config{Scenario: Scenarios["transfers"], Wallets: wallets, MaxAmount: 10, Duration: 20 * time.Millisecond})
+	if _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	api.balances[wallets[0]] -= 7
+	consistency, err := runner.Check(context.Background(), api)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if consistency.OK || consistency.Difference != -7 {
+		t.Errorf("consistency = %+v, want a difference of -7", consistency)
+	}
+}
+
+func TestNewRunner_Invalid(t *testing.T) {
+	if _, err := NewRunner(newFakeAPI(), Config{Scenario: Scenarios["hot-wallet"], MaxAmount: 1}); err == nil {
+		t.Error("NewRunner without wallets: want an error")
+	}
+	if _, err := LookupScenario("spiky"); err == nil || !strings.Contains(err.Error(), "read-heavy") {
+		t.Errorf("LookupScenario error = %v, want the known scenarios", err)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram()
+	for i := 1; i <= 10000; i++ {
+		h.Record(time.Duration(i) * time.Microsecond)
+	}
+	for _, tc := range []struct {
+		p    float64
+		want time.Duration
+	}{
+		{50, 5000 * time.Microsecond},
+		{99, 9900 * time.Microsecond},
+		{100, 10000 * time.Microsecond},
+	} {
+		got := h.Percentile(tc.p)
+		if diff := got - tc.want; diff < 0 || diff > tc.want/500 {
+			t.Errorf("Percentile(%v) = %v, want %v within 0.2%%", tc.p, got, tc.want)
+		}
+	}
+	if h.Min() != time.Microsecond || h.Count() != 10000 {
+		t.Errorf("min = %v, count = %d", h.Min(), h.Count())
+	}
+
+	other := NewHistogram()
+	other.Record(time.Second)
+	h.Merge(other)
+	if h.Max() != time.Second || h.Count() != 10001 {
+		t.Errorf("after merge max = %v, count = %d", h.Max(), h.Count())
+	}
+
+	var buf bytes.Buffer
+	if err := h.WritePercentiles(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Total count    =        10001") || strings.Count(buf.String(), "\n") < 50 {
+		t.Errorf("percentiles:\n%s", buf.String())
+	}
+}
+
+func TestIsAmbiguous(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{context.DeadlineExceeded, true},
+		{&walletclient.Error{StatusCode: 503}, true},
+		{&walletclient.Error{StatusCode: 409, Code: "wallet_frozen"}, false},
+		{errors.New("connection reset"), true},
+	} {
+		if got := isAmbiguous(tc.err); got != tc.want {
+			t.Errorf("isAmbiguous(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestRun_SameSeedNewReferences(t *testing.T) {
+	api := newFakeAPI()
+	wallets, err := CreateWallets(context.Background(), api, Fixtures{Count: 2, Currency: "RUB", Balance: 1000, RunID: "test"})
+	if err != nil {
+		t.Fatalf("CreateWallets: %v", err)
+	}
+	for run := 1; run <= 2; run++ {
+		runner, err := NewRunner(api, Config{Scenario: Scenarios["write-heavy"], Wallets: wallets, MaxAmount: 10,
+			Duration: 20 * time.Millisecond, Seed: 42})
+		if err != nil {
+			t.Fatalf("NewRunner: %v", err)
+		}
+		report, err := runner.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		for _, sample := range report.Errors {
+			if strings.Contains(sample, "reference") {
+				t.Errorf("run %d: got error %q, want references unique to the run", run, sample)
+			}
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package loadtest
+
+import (
+	"JavaCode/pkg/walletclient"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// API is the part of the wallet API the load test uses; *walletclient.Client implements it.
+type API interface {
+	CreateWallet(ctx context.Context, req walletclient.CreateWalletRequest) (*walletclient.BalanceResponse, error)
+	GetBalance(ctx context.Context, walletID string, opts ...walletclient.ReadOption) (*walletclient.BalanceResponse, error)
+	ApplyOperation(ctx context.Context, req walletclient.WalletOperationRequest) (*walletclient.OperationResult, error)
+	Transfer(ctx context.Context, req walletclient.TransferRequest) (*walletclient.TransferResponse, error)
+	VerifyWalletBalance(ctx context.Context, walletID string) (*walletclient.BalanceVerification, error)
+}
+
+// Load modes.
+const (
+	ClosedLoop = "closed-loop"
+	OpenLoop   = "open-loop"
+)
+
+// Config describes a load test run.
+type Config struct {
+	Scenario Scenario
+	// Wallets are the ids of the fixture wallets, all in the same currency.
+	Wallets []string
+	// MaxAmount is the largest amount of an operation, in minor units.
+	MaxAmount int64
+	// Duration is how long load is generated.
+	Duration time.Duration
+	// Rate is the number of requests started per second, whether or not
+	// earlier ones completed (open loop). 0 runs Concurrency workers that
+	// each send a request as soon as their previous one completed (closed loop).
+	Rate float64
+	// Concurrency is the number of workers, or the limit of requests in
+	// flight in the open loop.
+	Concurrency int
+	// Timeout limits each request.
+	Timeout time.Duration
+	// Seed makes the sequence of operations repeatable; 0 picks a random one.
+	Seed uint64
+}
+
+// OpStats are the results of the requests of one operation kind.
+type OpStats struct {
+	// OK counts the requests that succeeded.
+	OK int64 `json:"ok"`
+	// Rejected counts the requests refused as expected under load, e.g.
+	// withdrawals exceeding the balance.
+	Rejected int64 `json:"rejected"`
+	// Errors counts the other failed requests.
+	Errors int64 `json:"errors"`
+	// Latency is measured from when the request was due to be sent, so that
+	// in the open loop time spent waiting for a free slot is included.
+	Latency *Histogram `json:"latency"`
+}
+
+// Report is the outcome of a load test.
+type Report struct {
+	Scenario string        `json:"scenario"`
+	Mode     string        `json:"mode"`
+	Wallets  int           `json:"wallets"`
+	Duration time.Duration `json:"duration"`
+	// TargetRate is the configured rate of the open loop.
+	TargetRate float64 `json:"targetRate,omitempty"`
+	Requests   int64   `json:"requests"`
+	// Throughput is the number of completed requests per second.
+	Throughput float64 `json:"throughput"`
+	// Saturated counts the open-loop requests that had to wait for a
+	// request in flight to complete: the target did not keep up with the rate.
+	Saturated  int64               `json:"saturated,omitempty"`
+	Latency    *Histogram          `json:"latency"`
+	Operations map[string]*OpStats `json:"operations"`
+	// Errors samples the distinct request errors.
+	Errors      []string     `json:"errors,omitempty"`
+	Consistency *Consistency `json:"consistency,omitempty"`
+}
+
+// maxErrorSamples caps Report.Errors.
+const maxErrorSamples = 10
+
+// Runner generates load on a set of wallets and keeps account of the money
+// moved, for the consistency check afterwards.
+type Runner struct {
+	api API
+	cfg Config
+
+	currency string
+	baseline int64
+
+	mu       sync.Mutex
+	report   *Report
+	errors   map[string]bool
+	applied  int64
+	pending  []walletclient.WalletOperationRequest
+	sequence atomic.Int64
+	runID    string
+}
+
+// NewRunner returns a runner of cfg against api.
+func NewRunner(api API, cfg Config) (*Runner, error) {
+	if len(cfg.Wallets) == 0 {
+		return nil, errors.New("no wallets")
+	}
+	if cfg.Scenario.Next == nil {
+		return nil, errors.New("no scenario")
+	}
+	if cfg.MaxAmount <= 0 {
+		return nil, fmt.Errorf("max amount must be positive, got %d", cfg.MaxAmount)
+	}
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = rand.Uint64()
+	}
+
+	report := &Report{
+		Scenario:   cfg.Scenario.Name,
+		Mode:       ClosedLoop,
+		Wallets:    len(cfg.Wallets),
+		Latency:    NewHistogram(),
+		Operations: make(map[string]*OpStats),
+	}
+	if cfg.Rate > 0 {
+		report.Mode, report.TargetRate = OpenLoop, cfg.Rate
+	}
+	for _, kind := range []string{OpRead, OpDeposit, OpWithdraw, OpTransfer} {
+		report.Operations[kind] = &OpStats{Latency: NewHistogram()}
+	}
+	// References are unique to the run even when the seed repeats the
+	// operations of an earlier one, so that the API does not take them
+	// for retries of that run.
+	return &Runner{api: api, cfg: cfg, report: report, errors: make(map[string]bool), runID: uuid.NewString()}, nil
+}
+
+// Run reads the starting balances, then generates load for the configured
+// duration or until ctx is done.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	if err := r.readBaseline(ctx); err != nil {
+		return nil, err
+	}
+
+	loadCtx, cancel := context.WithTimeout(ctx, r.cfg.Duration)
+	defer cancel()
+
+	started := time.Now()
+	if r.cfg.Rate > 0 {
+		r.openLoop(ctx, loadCtx.Done())
+	} else {
+		r.closedLoop(ctx, loadCtx.Done())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Duration = time.Since(started)
+	r.report.Requests = r.report.Latency.Count()
+	if seconds := r.report.Duration.Seconds(); seconds > 0 {
+		r.report.Throughput = float64(r.report.Requests) / seconds
+	}
+	return r.report, ctx.Err()
+}
+
+// readBaseline reads the balances of the wallets before the load.
+func (r *Runner) readBaseline(ctx context.Context) error {
+	balances := make([]*walletclient.BalanceResponse, len(r.cfg.Wallets))
+	err := forEach(ctx, len(r.cfg.Wallets), r.cfg.Concurrency, func(ctx context.Context, i int) error {
+		wallet, err := r.api.GetBalance(ctx, r.cfg.Wallets[i], walletclient.ReadStrong())
+		if err != nil {
+			return fmt.Errorf("wallet %s: %w", r.cfg.Wallets[i], err)
+		}
+		balances[i] = wallet
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.currency = balances[0].Currency
+	for _, wallet := range balances {
+		if wallet.Currency != r.currency {
+			return fmt.Errorf("wallet %s is in %s, not %s: the wallets must share a currency", wallet.Uuid, wallet.Currency, r.currency)
+		}
+		r.baseline += int64(wallet.Balance)
+	}
+	return nil
+}
+
+// closedLoop runs workers that send requests back to back until stop is
+// closed. Requests in flight then complete.
+func (r *Runner) closedLoop(ctx context.Context, stop <-chan struct{}) {
+	var wg sync.WaitGroup
+	for worker := 0; worker < r.cfg.Concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(r.cfg.Seed, uint64(worker)))
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				r.execute(ctx, r.next(rng), time.Now())
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// openLoop starts requests at the configured rate, whether or not earlier
+// requests completed, up to Concurrency in flight, until stop is closed.
+func (r *Runner) openLoop(ctx context.Context, stop <-chan struct{}) {
+	interval := time.Duration(float64(time.Second) / r.cfg.Rate)
+	rng := rand.New(rand.NewPCG(r.cfg.Seed, 0))
+	slots := make(chan struct{}, r.cfg.Concurrency)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	started := time.Now()
+	for i := 0; ; i++ {
+		due := started.Add(time.Duration(i) * interval)
+		if wait := time.Until(due); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-stop:
+				timer.Stop()
+				return
+			}
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			r.mu.Lock()
+			r.report.Saturated++
+			r.mu.Unlock()
+			select {
+			case slots <- struct{}{}:
+			case <-stop:
+				return
+			}
+		}
+
+		op := r.next(rng)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			r.execute(ctx, op, due)
+		}()
+	}
+}
+
+func (r *Runner) next(rng *rand.Rand) Op {
+	return r.cfg.Scenario.Next(rng, len(r.cfg.Wallets), r.cfg.MaxAmount)
+}
+
+// execute sends an operation and records its outcome, with the latency
+// measured from due.
+func (r *Runner) execute(ctx context.Context, op Op, due time.Time) {
+	reqCtx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	defer cancel()
+
+	wallet := r.cfg.Wallets[op.Wallet]
+	var (
+		err       error
+		operation walletclient.WalletOperationRequest
+	)
+	switch op.Kind {
+	case OpRead:
+		_, err = r.api.GetBalance(reqCtx, wallet)
+	case OpDeposit, OpWithdraw:
+		operation = walletclient.WalletOperationRequest{
+			WalletID:      wallet,
+			OperationType: walletclient.Deposit,
+			Amount:        op.Amount,
+			Currency:      r.currency,
+			OperationDetails: walletclient.OperationDetails{
+				Reference: fmt.Sprintf("loadtest-%s-%d", r.runID, r.sequence.Add(1)),
+			},
+		}
+		if op.Kind == OpWithdraw {
+			operation.OperationType = walletclient.Withdraw
+		}
+		_, err = r.api.ApplyOperation(reqCtx, operation)
+	case OpTransfer:
+		_, err = r.api.Transfer(reqCtx, walletclient.TransferRequest{
+			FromWalletID: wallet, ToWalletID: r.cfg.Wallets[op.To], Amount: op.Amount, Currency: r.currency,
+		})
+	}
+	latency := time.Since(due)
+
+	// A request cut off by an interrupted run is neither counted nor
+	// measured, but the operation may still have been applied.
+	if ctx.Err() != nil && err != nil && !isRejection(err) {
+		if op.Kind == OpDeposit || op.Kind == OpWithdraw {
+			r.mu.Lock()
+			r.pending = append(r.pending, operation)
+			r.mu.Unlock()
+		}
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.report.Operations[op.Kind]
+	stats.Latency.Record(latency)
+	r.report.Latency.Record(latency)
+	switch {
+	case err == nil:
+		stats.OK++
+		r.applied += signedAmount(operation)
+	case isRejection(err):
+		stats.Rejected++
+	default:
+		stats.Errors++
+		if !r.errors[err.Error()] && len(r.errors) < maxErrorSamples {
+			r.errors[err.Error()] = true
+			r.report.Errors = append(r.report.Errors, err.Error())
+		}
+		if (op.Kind == OpDeposit || op.Kind == OpWithdraw) && isAmbiguous(err) {
+			r.pending = append(r.pending, operation)
+		}
+	}
+}
+
+// isRejection reports whether err is a refusal expected under load, which
+// leaves the balances unchanged.
+func isRejection(err error) bool {
+	return errors.Is(err, walletclient.ErrNegativeBalance)
+}
+
+// isAmbiguous reports whether a failed request may have been applied: no
+// response was received, or the server failed.
+func isAmbiguous(err error) bool {
+	var apiErr *walletclient.Error
+	return !errors.As(err, &apiErr) || apiErr.StatusCode >= 500
+}
+
+// signedAmount is the change of the total balance made by an operation.
+func signedAmount(operation walletclient.WalletOperationRequest) int64 {
+	switch operation.OperationType {
+	case walletclient.Deposit:
+		return operation.Amount
+	case walletclient.Withdraw:
+		return -operation.Amount
+	}
+	return 0
+}
+
+// forEach calls fn for 0..n-1 on up to concurrency goroutines and returns
+// the first error.
+func forEach(ctx context.Context, n, concurrency int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		next     atomic.Int64
+		once     sync.Once
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for worker := 0; worker < min(concurrency, n); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= n || ctx.Err() != nil {
+					return
+				}
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() { firstErr = err; cancel() })
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
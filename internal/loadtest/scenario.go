@@ -0,0 +1,108 @@
+package loadtest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+)
+
+// Operation kinds.
+const (
+	OpRead     = "read"
+	OpDeposit  = "deposit"
+	OpWithdraw = "withdraw"
+	OpTransfer = "transfer"
+)
+
+// Op is a request of a scenario on the fixture wallets, given by index.
+type Op struct {
+	Kind   string
+	Wallet int
+	// To is the credited wallet of a transfer.
+	To     int
+	Amount int64
+}
+
+// Scenario describes a load profile.
+type Scenario struct {
+	Name        string
+	Description string
+	// Wallets is the default number of fixture wallets.
+	Wallets int
+	// Next picks the next operation on wallets fixture wallets with amounts
+	// from 1 to maxAmount.
+	Next func(rng *rand.Rand, wallets int, maxAmount int64) Op
+}
+
+// Scenarios are the available load profiles by name.
+var Scenarios = map[string]Scenario{
+	"read-heavy": {
+		Name:        "read-heavy",
+		Description: "90% balance reads, 10% deposits and withdrawals on 100 wallets",
+		Wallets:     100,
+		Next:        mix(90, 0),
+	},
+	"write-heavy": {
+		Name:        "write-heavy",
+		Description: "10% balance reads, 90% deposits and withdrawals on 100 wallets",
+		Wallets:     100,
+		Next:        mix(10, 0),
+	},
+	"hot-wallet": {
+		Name:        "hot-wallet",
+		Description: "deposits, withdrawals and reads contending on a single wallet",
+		Wallets:     1,
+		Next:        mix(20, 0),
+	},
+	"many-wallets": {
+		Name:        "many-wallets",
+		Description: "deposits and withdrawals spread over 10000 wallets",
+		Wallets:     10000,
+		Next:        mix(0, 0),
+	},
+	"transfers": {
+		Name:        "transfers",
+		Description: "transfers between random pairs of 100 wallets",
+		Wallets:     100,
+		Next:        mix(0, 100),
+	},
+}
+
+// ScenarioNames returns the scenario names in order.
+func ScenarioNames() []string {
+	names := make([]string, 0, len(Scenarios))
+	for name := range Scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupScenario returns the scenario with the given name.
+func LookupScenario(name string) (Scenario, error) {
+	scenario, ok := Scenarios[name]
+	if !ok {
+		return Scenario{}, fmt.Errorf("unknown scenario %q, want one of %v", name, ScenarioNames())
+	}
+	return scenario, nil
+}
+
+// mix returns a generator of reads, transfers and, for the rest, equal
+// numbers of deposits and withdrawals, in percent, on uniformly chosen wallets.
+func mix(readPercent, transferPercent int) func(*rand.Rand, int, int64) Op {
+	return func(rng *rand.Rand, wallets int, maxAmount int64) Op {
+		op := Op{Wallet: rng.IntN(wallets), Amount: 1 + rng.Int64N(maxAmount)}
+		switch n := rng.IntN(100); {
+		case n < readPercent:
+			op.Kind = OpRead
+		case n < readPercent+transferPercent && wallets > 1:
+			op.Kind = OpTransfer
+			op.To = (op.Wallet + 1 + rng.IntN(wallets-1)) % wallets
+		case n%2 == 0:
+			op.Kind = OpDeposit
+		default:
+			op.Kind = OpWithdraw
+		}
+		return op
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// WalletImport is a wallet to create with an opening balance: one line or
+// row of an import file.
+type WalletImport struct {
+	// Id keeps the wallet's id, e.g. from an export; generated if empty.
+	Id       string `json:"id,omitempty"`
+	Currency string `json:"currency"`
+	// Balance is the opening balance in minor units of Currency.
+	Balance uint64 `json:"balance"`
+	// Status is ACTIVE (the default), FROZEN or CLOSED.
+	Status          string            `json:"status,omitempty"`
+	DepositsBlocked bool              `json:"depositsBlocked,omitempty"`
+	Owner           string            `json:"owner,omitempty"`
+	Name            string            `json:"name,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+
+	// OperationDetails are recorded with the opening-balance transaction,
+	// e.g. the account number in the legacy system as the reference.
+	OperationDetails
+}
+
+// WalletExport is a wallet in an export file. Export files can be imported
+// again; the timestamps are not restored.
+type WalletExport struct {
+	Id              string            `json:"id"`
+	Currency        string            `json:"currency"`
+	Balance         uint64            `json:"balance"`
+	Status          string            `json:"status"`
+	DepositsBlocked bool              `json:"depositsBlocked,omitempty"`
+	Owner           string            `json:"owner,omitempty"`
+	Name            string            `json:"name,omitempty"`
+	Labels          map[string]string `json:"labels"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
+}
+
+// Outcomes of an imported row.
+const (
+	ImportCreated = "created"
+	// ImportValid is the outcome of a row that a dry run would have created.
+	ImportValid  = "valid"
+	ImportFailed = "failed"
+)
+
+// WalletImportResult is the outcome of one row of an import file.
+type WalletImportResult struct {
+	// Line is the line of the row in the file, starting at 1.
+	Line int `json:"line"`
+	// Id is the wallet's id, if the row was valid.
+	Id     string `json:"id,omitempty"`
+	Status string `json:"status"`
+	// Error and Message are the error code and message of a failed row, as
+	// returned by the API.
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message,omitempty"`
+}
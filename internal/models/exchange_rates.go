@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// ExchangeRate is a conversion rate between two currencies valid for a period.
+//
+// One unit of BaseCurrency is worth RateUnits / 10^Precision units of QuoteCurrency.
+type ExchangeRate struct {
+	Id            int64
+	BaseCurrency  string
+	QuoteCurrency string
+	RateUnits     int64
+	Precision     int
+	RoundingMode  string
+	ValidFrom     time.Time
+	ValidTo       *time.Time
+	CreatedTime   time.Time
+}
+
+// CreateExchangeRateRequest represents the request body for publishing a rate.
+type CreateExchangeRateRequest struct {
+	// BaseCurrency is the ISO 4217 code converted from.
+	// required: true
+	BaseCurrency string `json:"baseCurrency" example:"EUR"`
+
+	// QuoteCurrency is the ISO 4217 code converted to.
+	// required: true
+	QuoteCurrency string `json:"quoteCurrency" example:"USD"`
+
+	// Rate is the decimal number of QuoteCurrency units per BaseCurrency unit.
+	// required: true
+	Rate string `json:"rate" example:"1.0834"`
+
+	// Precision is the number of decimal places the rate is stored with (0-12).
+	// required: true
+	Precision int `json:"precision" example:"4"`
+
+	// RoundingMode applies to converted amounts: HALF_UP, HALF_EVEN, DOWN or UP.
+	// required: true
+	RoundingMode string `json:"roundingMode" example:"HALF_EVEN"`
+
+	// ValidFrom is when the rate starts to apply; defaults to now.
+	ValidFrom *time.Time `json:"validFrom,omitempty" example:"2025-05-10T00:00:00Z"`
+
+	// ValidTo is when the rate stops applying; transfers after it are rejected
+	// as stale until a newer rate is published. If empty, the rate expires the
+	// server's maximum rate age after ValidFrom.
+	ValidTo *time.Time `json:"validTo,omitempty" example:"2025-05-11T00:00:00Z"`
+}
+
+// ExchangeRateResponse represents an exchange rate returned by the API.
+type ExchangeRateResponse struct {
+	Id            int64      `json:"id" example:"1"`
+	BaseCurrency  string     `json:"baseCurrency" example:"EUR"`
+	QuoteCurrency string     `json:"quoteCurrency" example:"USD"`
+	Rate          string     `json:"rate" example:"1.0834"`
+	Precision     int        `json:"precision" example:"4"`
+	RoundingMode  string     `json:"roundingMode" example:"HALF_EVEN"`
+	ValidFrom     time.Time  `json:"validFrom" example:"2025-05-10T00:00:00Z"`
+	ValidTo       *time.Time `json:"validTo,omitempty" example:"2025-05-11T00:00:00Z"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Ledger account types. Wallet accounts belong to customers; the others are
+// system accounts, one per currency, that money enters or leaves through.
+const (
+	AccountWallet  = "WALLET"
+	AccountCashIn  = "CASH_IN"
+	AccountCashOut = "CASH_OUT"
+	AccountFees    = "FEES"
+	AccountFX      = "FX"
+	AccountOpening = "OPENING"
+)
+
+// Ledger entry directions. An account's balance is its credits minus its debits.
+const (
+	Debit  = "DEBIT"
+	Credit = "CREDIT"
+)
+
+// Ledger transaction types.
+const (
+	LedgerDeposit  = "DEPOSIT"
+	LedgerWithdraw = "WITHDRAW"
+	LedgerTransfer = "TRANSFER"
+	LedgerOpening  = "OPENING"
+)
+
+// LedgerTransaction is a set of entries that debit and credit accounts
+// by the same total in each currency.
+type LedgerTransaction struct {
+	Id   string
+	Type string
+	// WalletId is the wallet of a deposit or withdrawal; empty otherwise.
+	WalletId string
+	OperationDetails
+	Entries     []LedgerEntry
+	CreatedTime time.Time
+}
+
+// LedgerEntry is a single debit or credit of an account.
+type LedgerEntry struct {
+	Id            int64
+	TransactionId string
+	// TransactionType and the transaction's details are only filled in when
+	// entries are read back.
+	TransactionType string
+	Details         OperationDetails
+	AccountId       string
+	Direction       string
+	Amount          int64
+	Currency        string
+	CreatedTime     time.Time
+}
+
+// LedgerEntryResponse represents a ledger entry returned by the API.
+type LedgerEntryResponse struct {
+	Id              int64     `json:"id" example:"42"`
+	TransactionId   string    `json:"transactionId" example:"5b0f7b0e-8a53-4b39-a0c4-2f7d4c5e2c11"`
+	TransactionType string    `json:"transactionType" example:"DEPOSIT"`
+	AccountId       string    `json:"accountId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+	Direction       string    `json:"direction" example:"CREDIT"`
+	Amount          int64     `json:"amount" example:"1000"`
+	Currency        string    `json:"currency" example:"RUB"`
+	CreatedAt       time.Time `json:"createdAt" example:"2025-05-20T12:00:00Z"`
+	OperationDetails
+}
+
+// BalanceVerification compares a wallet's balance with the sum of its ledger entries.
+type BalanceVerification struct {
+	WalletId string `json:"walletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+	Currency string `json:"currency" example:"RUB"`
+	// Balance is the stored balance projection.
+	Balance uint64 `json:"balance" example:"1000"`
+	// LedgerBalance is the wallet account's credits minus debits.
+	LedgerBalance int64 `json:"ledgerBalance" example:"1000"`
+	Consistent    bool  `json:"consistent" example:"true"`
+}
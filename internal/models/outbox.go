@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Outbox event types.
+const (
+	EventBalanceChanged = "wallet.balance_changed"
+	EventStatusChanged  = "wallet.status_changed"
+)
+
+// OutboxEvent is an event recorded in the same transaction as the change it
+// describes, waiting to be delivered by the dispatcher.
+type OutboxEvent struct {
+	// Id orders the events; events of a wallet are delivered in Id order.
+	Id       int64
+	EventId  string
+	Type     string
+	WalletId string
+	// Payload is the JSON body delivered to the sinks.
+	Payload     json.RawMessage
+	CreatedTime time.Time
+	// Attempts counts the failed deliveries so far.
+	Attempts int
+}
+
+// BalanceChangedEvent is the payload of a wallet.balance_changed event.
+type BalanceChangedEvent struct {
+	EventId  string `json:"eventId" example:"0b8f2a4c-4d1e-4b7a-9d3c-6f5e2a1b0c9d"`
+	Type     string `json:"type" example:"wallet.balance_changed"`
+	WalletId string `json:"walletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+	Currency string `json:"currency" example:"RUB"`
+	// Balance is the wallet balance after the change.
+	Balance uint64 `json:"balance" example:"1500"`
+	// Delta is the signed change of the balance.
+	Delta int64 `json:"delta" example:"500"`
+	// Operation is the ledger transaction type: DEPOSIT, WITHDRAW or TRANSFER.
+	Operation     string    `json:"operation" example:"DEPOSIT"`
+	TransactionId string    `json:"transactionId" example:"5b0f7b0e-8a53-4b39-a0c4-2f7d4c5e2c11"`
+	Reference     string    `json:"reference,omitempty" example:"order-1042"`
+	OccurredAt    time.Time `json:"occurredAt"`
+}
+
+// WalletStatusChangedEvent is the payload of a wallet.status_changed event.
+type WalletStatusChangedEvent struct {
+	EventId    string `json:"eventId" example:"0b8f2a4c-4d1e-4b7a-9d3c-6f5e2a1b0c9d"`
+	Type       string `json:"type" example:"wallet.status_changed"`
+	WalletId   string `json:"walletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+	FromStatus string `json:"fromStatus" example:"ACTIVE"`
+	ToStatus   string `json:"toStatus" example:"FROZEN"`
+	// DepositsBlocked is set when a frozen wallet refuses deposits as well.
+	DepositsBlocked bool      `json:"depositsBlocked,omitempty" example:"false"`
+	Reason          string    `json:"reason" example:"AML investigation #4411"`
+	OccurredAt      time.Time `json:"occurredAt"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Discrepancy is a wallet whose stored balance differs from its ledger.
+type Discrepancy struct {
+	WalletId string `json:"walletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+	Currency string `json:"currency" example:"RUB"`
+	// Balance is the stored balance projection.
+	Balance uint64 `json:"balance" example:"1500"`
+	// LedgerBalance is the wallet account's credits minus debits.
+	LedgerBalance int64 `json:"ledgerBalance" example:"1000"`
+	// Difference is Balance minus LedgerBalance.
+	Difference int64 `json:"difference" example:"500"`
+	// Frozen is set when the wallet was frozen by this run.
+	Frozen bool `json:"frozen" example:"false"`
+}
+
+// ReconcileReport is the outcome of a reconciliation run.
+type ReconcileReport struct {
+	StartedAt      time.Time     `json:"startedAt"`
+	FinishedAt     time.Time     `json:"finishedAt"`
+	WalletsChecked int64         `json:"walletsChecked"`
+	Discrepancies  []Discrepancy `json:"discrepancies"`
+}
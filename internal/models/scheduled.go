@@ -0,0 +1,131 @@
+package models
+
+import "time"
+
+// Scheduled operation statuses.
+const (
+	ScheduleActive = "ACTIVE"
+	// ScheduleCompleted marks an operation with no occurrences left.
+	ScheduleCompleted = "COMPLETED"
+	ScheduleCancelled = "CANCELLED"
+)
+
+// Scheduled operation run statuses.
+const (
+	RunSucceeded = "SUCCEEDED"
+	RunFailed    = "FAILED"
+)
+
+// ScheduledOperation is a deposit or withdrawal executed by the scheduler,
+// once or on a recurrence.
+type ScheduledOperation struct {
+	Id            string
+	WalletId      string
+	OperationType string
+	Amount        int64
+	Currency      string
+	Description   string
+	Metadata      map[string]string
+	// Schedule is empty for a one-off operation; otherwise daily, weekly,
+	// monthly or a cron expression.
+	Schedule   string
+	StartTime  time.Time
+	EndTime    *time.Time
+	MaxRetries int
+	Status     string
+	// NextRunTime is the occurrence to execute next; nil unless active.
+	NextRunTime *time.Time
+	// Attempts counts the failed attempts at the next occurrence.
+	Attempts int
+	// NextAttemptTime is when the next occurrence is attempted, later than
+	// NextRunTime while failed attempts are retried; nil unless active.
+	NextAttemptTime *time.Time
+	CreatedTime     time.Time
+	UpdatedTime     time.Time
+}
+
+// CreateScheduledOperationRequest represents the request body for scheduling a deposit or withdrawal.
+type CreateScheduledOperationRequest struct {
+	// WalletID is the unique identifier of the wallet.
+	// required: true
+	WalletID string `json:"walletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+
+	// OperationType is "DEPOSIT" or "WITHDRAW".
+	// required: true
+	OperationType string `json:"operationType" example:"WITHDRAW"`
+
+	// Amount is the amount in minor units of Currency.
+	// required: true
+	Amount int64 `json:"amount" example:"1000"`
+
+	// Currency must match the wallet currency.
+	// required: true
+	Currency string `json:"currency" example:"RUB"`
+
+	// Description is recorded on every executed operation.
+	Description string `json:"description,omitempty" example:"Monthly subscription"`
+
+	// Metadata is recorded on every executed operation.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// StartAt is the first occurrence; now if not given.
+	StartAt time.Time `json:"startAt" example:"2025-07-15T09:00:00Z"`
+
+	// Schedule repeats the operation: daily, weekly or monthly at the time
+	// of StartAt, or a cron expression in UTC. Empty runs it once.
+	Schedule string `json:"schedule,omitempty" example:"monthly"`
+
+	// EndAt is the last time an occurrence may fall on.
+	EndAt *time.Time `json:"endAt,omitempty" example:"2026-07-15T09:00:00Z"`
+
+	// MaxRetries is the number of times a failed occurrence is retried,
+	// e.g. after insufficient funds, before it is skipped.
+	MaxRetries int `json:"maxRetries,omitempty" example:"3"`
+}
+
+// ScheduledOperationResponse represents a scheduled operation returned by the API.
+type ScheduledOperationResponse struct {
+	Id            string            `json:"id" example:"7d1e5c2a-3b4f-4a6d-9e8c-1f2a3b4c5d6e"`
+	WalletId      string            `json:"walletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+	OperationType string            `json:"operationType" example:"WITHDRAW"`
+	Amount        int64             `json:"amount" example:"1000"`
+	Currency      string            `json:"currency" example:"RUB"`
+	Description   string            `json:"description,omitempty" example:"Monthly subscription"`
+	Metadata      map[string]string `json:"metadata"`
+	Schedule      string            `json:"schedule,omitempty" example:"monthly"`
+	StartAt       time.Time         `json:"startAt" example:"2025-07-15T09:00:00Z"`
+	EndAt         *time.Time        `json:"endAt,omitempty" example:"2026-07-15T09:00:00Z"`
+	MaxRetries    int               `json:"maxRetries" example:"3"`
+	// Status is ACTIVE, COMPLETED or CANCELLED.
+	Status        string     `json:"status" example:"ACTIVE"`
+	NextRunAt     *time.Time `json:"nextRunAt,omitempty" example:"2025-08-15T09:00:00Z"`
+	Attempts      int        `json:"attempts" example:"0"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty" example:"2025-08-15T09:00:00Z"`
+	CreatedAt     time.Time  `json:"createdAt" example:"2025-07-10T09:00:00Z"`
+}
+
+// ScheduledRun is an attempt to execute an occurrence of a scheduled operation.
+type ScheduledRun struct {
+	Id         int64
+	ScheduleId string
+	// RunTime is the occurrence attempted.
+	RunTime   time.Time
+	Attempt   int
+	Status    string
+	Reference string
+	// Error is the error code of a failed attempt, e.g. negative_amount.
+	Error        string
+	ExecutedTime time.Time
+}
+
+// ScheduledRunResponse represents a run returned by the API.
+type ScheduledRunResponse struct {
+	Id      int64     `json:"id" example:"42"`
+	RunAt   time.Time `json:"runAt" example:"2025-08-15T09:00:00Z"`
+	Attempt int       `json:"attempt" example:"1"`
+	// Status is SUCCEEDED or FAILED.
+	Status     string    `json:"status" example:"FAILED"`
+	Reference  string    `json:"reference" example:"schedule:7d1e5c2a-3b4f-4a6d-9e8c-1f2a3b4c5d6e:1755248400"`
+	Error      string    `json:"error,omitempty" example:"negative_amount"`
+	ExecutedAt time.Time `json:"executedAt" example:"2025-08-15T09:00:01Z"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "encoding/json"
+
+// Wallet stream message types besides the outbox event types.
+const (
+	// StreamBalance carries the current balance when a stream starts without a cursor.
+	StreamBalance = "balance"
+	// StreamPing is the keep-alive of an idle WebSocket stream.
+	StreamPing = "ping"
+)
+
+// WalletStreamMessage is a message of the WebSocket wallet stream.
+type WalletStreamMessage struct {
+	// Id is the event id to resume after; it is omitted for balance and ping messages.
+	Id   int64  `json:"id,omitempty" example:"42"`
+	Type string `json:"type" example:"wallet.balance_changed"`
+	// Data is the event payload, or a BalanceResponse for balance messages.
+	Data json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Transfer records a movement of funds between two wallets.
+//
+// For cross-currency transfers the applied rate is copied onto the record,
+// so that the conversion can be reproduced later.
+type Transfer struct {
+	Id             string
+	FromWalletId   string
+	ToWalletId     string
+	SourceAmount   int64
+	SourceCurrency string
+	TargetAmount   int64
+	TargetCurrency string
+	RateId         *int64
+	RateUnits      *int64
+	RatePrecision  *int
+	RoundingMode   *string
+	CreatedTime    time.Time
+}
+
+// TransferRequest represents the request body for a transfer between wallets.
+type TransferRequest struct {
+	// FromWalletID is the wallet debited.
+	// required: true
+	FromWalletID string `json:"fromWalletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+
+	// ToWalletID is the wallet credited.
+	// required: true
+	ToWalletID string `json:"toWalletId" example:"1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"`
+
+	// Amount is debited from the source wallet, in minor units of Currency.
+	// required: true
+	Amount int64 `json:"amount" example:"1000"`
+
+	// Currency must match the source wallet currency. If the destination
+	// wallet uses another currency the amount is converted at the current rate.
+	// required: true
+	Currency string `json:"currency" example:"RUB"`
+}
+
+// TransferResponse represents a transfer returned by the API.
+type TransferResponse struct {
+	Id             string    `json:"id" example:"5b0f7b0e-8a53-4b39-a0c4-2f7d4c5e2c11"`
+	FromWalletId   string    `json:"fromWalletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+	ToWalletId     string    `json:"toWalletId" example:"1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"`
+	SourceAmount   int64     `json:"sourceAmount" example:"1000"`
+	SourceCurrency string    `json:"sourceCurrency" example:"EUR"`
+	TargetAmount   int64     `json:"targetAmount" example:"1083"`
+	TargetCurrency string    `json:"targetCurrency" example:"USD"`
+	RateId         *int64    `json:"rateId,omitempty" example:"1"`
+	Rate           string    `json:"rate,omitempty" example:"1.0834"`
+	RoundingMode   string    `json:"roundingMode,omitempty" example:"HALF_EVEN"`
+	CreatedAt      time.Time `json:"createdAt" example:"2025-05-10T12:00:00Z"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// The v2 API exchanges amounts as decimal strings with a currency code,
+// e.g. "12.34 EUR", instead of integer minor units.
+
+// WalletOperationRequestV2 represents the v2 request body for a deposit or withdrawal.
+type WalletOperationRequestV2 struct {
+	// WalletID is the unique identifier of the wallet.
+	// required: true
+	WalletID string `json:"walletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+
+	// OperationType is "DEPOSIT" or "WITHDRAW".
+	// required: true
+	OperationType string `json:"operationType" example:"DEPOSIT"`
+
+	// Amount is a positive decimal amount and the wallet's currency.
+	// It may not have more decimal places than the currency allows.
+	// required: true
+	Amount string `json:"amount" example:"12.34 EUR"`
+
+	OperationDetails
+}
+
+// BalanceResponseV2 represents the v2 response containing the wallet balance.
+type BalanceResponseV2 struct {
+	Uuid    string `json:"uuid" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+	Balance string `json:"balance" example:"12.34 EUR"`
+	// Status is ACTIVE, FROZEN or CLOSED.
+	Status string            `json:"status" example:"ACTIVE"`
+	Owner  string            `json:"owner,omitempty" example:"customer-1842"`
+	Name   string            `json:"name,omitempty" example:"Savings"`
+	Labels map[string]string `json:"labels"`
+}
+
+// TransferRequestV2 represents the v2 request body for a transfer between wallets.
+type TransferRequestV2 struct {
+	// FromWalletID is the wallet debited.
+	// required: true
+	FromWalletID string `json:"fromWalletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+
+	// ToWalletID is the wallet credited.
+	// required: true
+	ToWalletID string `json:"toWalletId" example:"1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"`
+
+	// Amount is debited from the source wallet and must be in its currency.
+	// required: true
+	Amount string `json:"amount" example:"10.00 EUR"`
+}
+
+// TransferResponseV2 represents a transfer returned by the v2 API.
+type TransferResponseV2 struct {
+	Id           string    `json:"id" example:"5b0f7b0e-8a53-4b39-a0c4-2f7d4c5e2c11"`
+	FromWalletId string    `json:"fromWalletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+	ToWalletId   string    `json:"toWalletId" example:"1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"`
+	SourceAmount string    `json:"sourceAmount" example:"10.00 EUR"`
+	TargetAmount string    `json:"targetAmount" example:"10.83 USD"`
+	RateId       *int64    `json:"rateId,omitempty" example:"1"`
+	Rate         string    `json:"rate,omitempty" example:"1.0834"`
+	RoundingMode string    `json:"roundingMode,omitempty" example:"HALF_EVEN"`
+	CreatedAt    time.Time `json:"createdAt" example:"2025-05-10T12:00:00Z"`
+}
@@ -3,10 +3,33 @@ package models
 
 import "time"
 
+// Wallet statuses.
+//
+// An active wallet can be frozen and unfrozen again, or closed once its
+// balance is zero. Closed is final.
+const (
+	WalletActive = "ACTIVE"
+	WalletFrozen = "FROZEN"
+	WalletClosed = "CLOSED"
+)
+
 // Wallet represents a user's wallet with balance and timestamps.
+//
+// Balance is expressed in minor units of Currency (an ISO 4217 code),
+// which is set at creation and never changes.
 type Wallet struct {
-	Id          string
-	Balance     uint64
+	Id       string
+	Balance  uint64
+	Currency string
+	Status   string
+	// DepositsBlocked is set on frozen wallets that refuse deposits as well.
+	DepositsBlocked bool
+	// Owner optionally identifies the wallet's owner in the client's system.
+	Owner string
+	// Name is an optional display name.
+	Name string
+	// Labels are free-form key/value pairs set by the client; never nil.
+	Labels      map[string]string
 	CreatedTime time.Time
 	UpdatedTime time.Time
 }
@@ -28,11 +51,159 @@ type WalletOperationRequest struct {
 	// Must be a positive integer.
 	// required: true
 	// example: 500
-	Amount int `json:"amount" example:"1000"`
+	Amount int64 `json:"amount" example:"1000"`
+
+	// Currency is the ISO 4217 code of Amount and must match the wallet's currency.
+	// Amount is expressed in minor units of this currency (e.g. cents).
+	// required: true
+	// example: EUR
+	Currency string `json:"currency" example:"EUR"`
+
+	OperationDetails
+}
+
+// OperationDetails are optional client-supplied details of a deposit or
+// withdrawal, stored with its ledger transaction.
+type OperationDetails struct {
+	// Description is free text, e.g. shown in statements.
+	Description string `json:"description,omitempty" example:"Order #1001"`
+
+	// Reference is the client's id for the operation, e.g. an order or
+	// invoice number. It must be unique per wallet.
+	Reference string `json:"reference,omitempty" example:"order-1001"`
+
+	// Metadata holds free-form key/value pairs.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// CreateWalletRequest represents the request body for creating a wallet.
+type CreateWalletRequest struct {
+	// Currency is the ISO 4217 code of the wallet; it cannot be changed later.
+	// required: true
+	// example: EUR
+	Currency string `json:"currency" example:"EUR"`
+
+	// Owner optionally identifies the wallet's owner, e.g. a customer id.
+	// example: customer-1842
+	Owner string `json:"owner,omitempty" example:"customer-1842"`
+
+	// Name is an optional display name.
+	// example: Savings
+	Name string `json:"name,omitempty" example:"Savings"`
+
+	// Labels are free-form key/value pairs, e.g. {"tier": "gold"}.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// UpdateWalletRequest represents the request body for updating wallet metadata.
+//
+// Omitted fields are left unchanged and an empty name or owner clears it.
+// Labels are merged into the existing ones; a null value removes the label.
+type UpdateWalletRequest struct {
+	Name   *string            `json:"name" example:"Savings"`
+	Owner  *string            `json:"owner" example:"customer-1842"`
+	Labels map[string]*string `json:"labels"`
 }
 
 // BalanceResponse represents the response containing the wallet balance.
 type BalanceResponse struct {
 	Uuid    string `json:"uuid" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
 	Balance uint64 `json:"balance" example:"1000"`
+	// Currency is the ISO 4217 code of the wallet.
+	Currency string `json:"currency" example:"EUR"`
+	// Exponent is the number of decimal places in Balance (2 means Balance is in cents).
+	Exponent int `json:"exponent" example:"2"`
+	// Status is ACTIVE, FROZEN or CLOSED.
+	Status string `json:"status" example:"ACTIVE"`
+	// DepositsBlocked is set on frozen wallets that refuse deposits as well.
+	DepositsBlocked bool              `json:"depositsBlocked,omitempty" example:"false"`
+	Owner           string            `json:"owner,omitempty" example:"customer-1842"`
+	Name            string            `json:"name,omitempty" example:"Savings"`
+	Labels          map[string]string `json:"labels"`
+	CreatedAt       time.Time         `json:"createdAt" example:"2025-06-10T12:00:00Z"`
+}
+
+// Wallet listing sort columns.
+const (
+	WalletSortCreated = "createdAt"
+	WalletSortBalance = "balance"
+)
+
+// WalletFilter selects a page of wallets. Zero fields do not filter.
+type WalletFilter struct {
+	Owner string
+	// Name matches wallets whose name contains it, ignoring case.
+	Name string
+	// Labels matches wallets that have all of these labels.
+	Labels     map[string]string
+	Status     string
+	Currency   string
+	MinBalance *uint64
+	MaxBalance *uint64
+	// CreatedFrom is inclusive, CreatedTo exclusive.
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+
+	// Sort is WalletSortCreated or WalletSortBalance; Desc reverses the order.
+	Sort string
+	Desc bool
+	// After continues the listing after this wallet.
+	After *WalletCursor
+	Limit int
+}
+
+// WalletCursor is the position of a wallet in a listing.
+type WalletCursor struct {
+	Sort        string    `json:"s"`
+	Desc        bool      `json:"d,omitempty"`
+	Balance     uint64    `json:"b,omitempty"`
+	CreatedTime time.Time `json:"c"`
+	Id          string    `json:"i"`
+}
+
+// WalletPage is one page of a wallet listing.
+type WalletPage struct {
+	Wallets []Wallet
+	// NextCursor is empty on the last page.
+	NextCursor string
+}
+
+// WalletListResponse represents one page of a wallet listing.
+type WalletListResponse struct {
+	Wallets []BalanceResponse `json:"wallets"`
+	// NextCursor is passed as the cursor parameter to fetch the next page; empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty" example:"eyJzIjoiY3JlYXRlZEF0In0"`
+}
+
+// FreezeWalletRequest represents the request body for freezing a wallet.
+type FreezeWalletRequest struct {
+	// Reason is recorded in the wallet's status history.
+	// required: true
+	Reason string `json:"reason" example:"AML investigation #4411"`
+
+	// BlockDeposits makes the wallet refuse deposits as well as withdrawals.
+	BlockDeposits bool `json:"blockDeposits" example:"false"`
+}
+
+// WalletStatusRequest represents the request body for unfreezing or closing a wallet.
+type WalletStatusRequest struct {
+	// Reason is recorded in the wallet's status history.
+	// required: true
+	Reason string `json:"reason" example:"Investigation closed"`
+}
+
+// HealthResponse represents the response of the health probes.
+type HealthResponse struct {
+	Status        string `json:"status" example:"ok"`
+	SchemaVersion int64  `json:"schemaVersion,omitempty" example:"20250417135508"`
+}
+
+// WalletStatusChange is an entry of a wallet's status history.
+type WalletStatusChange struct {
+	Id          int64     `json:"id" example:"7"`
+	WalletId    string    `json:"walletId" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+	FromStatus  string    `json:"fromStatus" example:"ACTIVE"`
+	ToStatus    string    `json:"toStatus" example:"FROZEN"`
+	Reason      string    `json:"reason" example:"AML investigation #4411"`
+	ChangedTime time.Time `json:"changedAt" example:"2025-06-05T12:00:00Z"`
 }
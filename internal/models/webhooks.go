@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types a subscription can filter on.
+const (
+	// WebhookDeposit is sent when funds enter a wallet: a deposit or an incoming transfer.
+	WebhookDeposit = "deposit"
+	// WebhookWithdrawal is sent when funds leave a wallet: a withdrawal or an outgoing transfer.
+	WebhookWithdrawal = "withdrawal"
+	// WebhookLowBalance is sent when a balance drops below the subscription's threshold.
+	WebhookLowBalance = "low_balance"
+	// WebhookFreeze is sent when a wallet is frozen.
+	WebhookFreeze = "freeze"
+)
+
+// WebhookEvents lists the webhook event types.
+var WebhookEvents = []string{WebhookDeposit, WebhookWithdrawal, WebhookLowBalance, WebhookFreeze}
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	// DeliveryDead marks a delivery that ran out of attempts.
+	DeliveryDead = "DEAD"
+)
+
+// WebhookSubscription is an endpoint registered by a client to receive events.
+type WebhookSubscription struct {
+	Id       string
+	ClientId string
+	URL      string
+	// Secret signs the deliveries.
+	Secret string
+	Events []string
+	// WalletId limits the subscription to one wallet; empty for all wallets.
+	WalletId string
+	// LowBalanceThreshold is required with the low_balance event.
+	LowBalanceThreshold *int64
+	Active              bool
+	CreatedTime         time.Time
+}
+
+// CreateWebhookRequest represents the request body for registering a webhook endpoint.
+type CreateWebhookRequest struct {
+	// ClientId identifies the client owning the endpoint.
+	// required: true
+	ClientId string `json:"clientId" example:"billing-service"`
+
+	// URL receives the deliveries as POST requests.
+	// required: true
+	URL string `json:"url" example:"https://billing.example.com/hooks/wallet"`
+
+	// Events filters the delivered events: deposit, withdrawal, low_balance, freeze.
+	// required: true
+	Events []string `json:"events" example:"deposit,low_balance"`
+
+	// WalletId limits the endpoint to one wallet.
+	WalletId string `json:"walletId,omitempty" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+
+	// LowBalanceThreshold, in minor units, is required with the low_balance event.
+	LowBalanceThreshold *int64 `json:"lowBalanceThreshold,omitempty" example:"1000"`
+}
+
+// WebhookResponse represents a webhook endpoint returned by the API.
+type WebhookResponse struct {
+	Id                  string    `json:"id" example:"9e4c2f1a-6b7d-4c8e-a0f3-2d5b8c1e7f90"`
+	ClientId            string    `json:"clientId" example:"billing-service"`
+	URL                 string    `json:"url" example:"https://billing.example.com/hooks/wallet"`
+	Events              []string  `json:"events" example:"deposit,low_balance"`
+	WalletId            string    `json:"walletId,omitempty" example:"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"`
+	LowBalanceThreshold *int64    `json:"lowBalanceThreshold,omitempty" example:"1000"`
+	Active              bool      `json:"active" example:"true"`
+	CreatedAt           time.Time `json:"createdAt" example:"2025-06-30T09:00:00Z"`
+}
+
+// CreateWebhookResponse is returned once, when an endpoint is registered:
+// it is the only response that includes the signing secret.
+type CreateWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret" example:"whsec_3f9a1c..."`
+}
+
+// WebhookDelivery is an event queued for, or sent to, a webhook endpoint.
+type WebhookDelivery struct {
+	Id             int64
+	SubscriptionId string
+	EventId        string
+	EventType      string
+	// Payload is the data of the event.
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int
+	LastStatusCode *int
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedTime    time.Time
+	DeliveredTime  *time.Time
+}
+
+// PendingWebhookDelivery is a delivery claimed by the worker together with
+// the endpoint it goes to.
+type PendingWebhookDelivery struct {
+	WebhookDelivery
+	URL    string
+	Secret string
+}
+
+// WebhookDeliveryResponse represents a delivery log entry returned by the API.
+type WebhookDeliveryResponse struct {
+	Id             int64           `json:"id" example:"42"`
+	EventId        string          `json:"eventId" example:"0b8f2a4c-4d1e-4b7a-9d3c-6f5e2a1b0c9d"`
+	EventType      string          `json:"eventType" example:"deposit"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status" example:"DELIVERED"`
+	Attempts       int             `json:"attempts" example:"1"`
+	LastStatusCode *int            `json:"lastStatusCode,omitempty" example:"200"`
+	LastError      string          `json:"lastError,omitempty" example:""`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt" example:"2025-06-30T09:00:00Z"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// WebhookPayload is the signed JSON body POSTed to a webhook endpoint.
+type WebhookPayload struct {
+	// Id is the event id; a redelivery carries the same id.
+	Id        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
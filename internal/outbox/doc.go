@@ -0,0 +1,7 @@
+// Package outbox delivers events recorded in the transactional outbox.
+//
+// It defines a pluggable Sink interface with implementations that log
+// events, append them to a file, POST them to a webhook or hand them to a
+// message broker. Sinks must be safe to call again with an event they have
+// already accepted: delivery is at least once.
+package outbox
@@ -0,0 +1,99 @@
+package outbox_test
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/outbox"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var event = models.OutboxEvent{
+	Id:       1,
+	EventId:  "0b8f2a4c-4d1e-4b7a-9d3c-6f5e2a1b0c9d",
+	Type:     models.EventBalanceChanged,
+	WalletId: "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f",
+	Payload:  []byte(`{"balance":1500}`),
+}
+
+func TestWebhookSink(t *testing.T) {
+	t.Run("Test 1: Posts the payload", func(t *testing.T) {
+		var body []byte
+		var header http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			header = r.Header
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		sink := &outbox.WebhookSink{URL: server.URL}
+		if err := sink.Publish(context.Background(), event); err != nil {
+			t.Fatalf("Publish: got %v, want nil", err)
+		}
+		if string(body) != `{"balance":1500}` {
+			t.Errorf("body: got %s", body)
+		}
+		if header.Get("X-Event-Id") != event.EventId || header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected headers: %v", header)
+		}
+	})
+
+	t.Run("Test 2: Non-2xx response fails the delivery", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		sink := &outbox.WebhookSink{URL: server.URL}
+		if err := sink.Publish(context.Background(), event); err == nil {
+			t.Error("Publish: got nil, want an error")
+		}
+	})
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink, err := outbox.NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := sink.Publish(context.Background(), event); err != nil {
+			t.Fatalf("Publish: got %v, want nil", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"balance\":1500}\n{\"balance\":1500}\n"; string(data) != want {
+		t.Errorf("file: got %q, want %q", data, want)
+	}
+}
+
+type broker struct{ topic, key string }
+
+func (b *broker) Publish(_ context.Context, topic, key string, _ []byte) error {
+	b.topic, b.key = topic, key
+	return nil
+}
+
+func TestBrokerSink(t *testing.T) {
+	b := &broker{}
+	sink := &outbox.BrokerSink{Broker: b, Topic: "wallet-events"}
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish: got %v, want nil", err)
+	}
+	if b.topic != "wallet-events" || b.key != event.WalletId {
+		t.Errorf("published to %q with key %q, want wallet-events keyed by wallet", b.topic, b.key)
+	}
+}
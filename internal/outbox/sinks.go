@@ -0,0 +1,118 @@
+package outbox
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/utils"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Sink delivers outbox events.
+//
+// Publish returns nil only once the event has been accepted; on error the
+// event is retried later, and no later event of the same wallet is
+// published before it.
+type Sink interface {
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
+
+// LogSink writes events to the application log.
+type LogSink struct{}
+
+// Publish logs the event payload.
+func (LogSink) Publish(_ context.Context, event models.OutboxEvent) error {
+	utils.Logger.Infof("event %s %s for wallet %s: %s", event.Type, event.EventId, event.WalletId, event.Payload)
+	return nil
+}
+
+// FileSink appends events to a file, one JSON payload per line.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens path for appending, creating it if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Publish appends the event payload and syncs the file.
+func (s *FileSink) Publish(_ context.Context, event models.OutboxEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	line := append(append([]byte{}, event.Payload...), '\n')
+	if _, err := s.file.Write(line); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// WebhookSink POSTs events to an HTTP endpoint.
+//
+// Any 2xx response accepts the event. The event id is sent in the
+// X-Event-Id header so that the receiver can drop redeliveries.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// Publish POSTs the event payload to the webhook URL.
+func (s *WebhookSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(event.Payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Event-Id", event.EventId)
+	request.Header.Set("X-Event-Type", event.Type)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// Broker is a message broker client, such as a Kafka or NATS producer.
+//
+// Publish must return only once the broker has acknowledged the message.
+// Brokers that partition topics should route by key, which keeps the events
+// of a wallet in order.
+type Broker interface {
+	Publish(ctx context.Context, topic, key string, payload []byte) error
+}
+
+// BrokerSink publishes events to a topic of a message broker, keyed by wallet.
+type BrokerSink struct {
+	Broker Broker
+	Topic  string
+}
+
+// Publish hands the event payload to the broker.
+func (s *BrokerSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	return s.Broker.Publish(ctx, s.Topic, event.WalletId, event.Payload)
+}
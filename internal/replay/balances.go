@@ -0,0 +1,105 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ExpectedBalance is the final balance of a wallet after a replay, one line
+// of a balances file. A wallet export (walletctl export wallets) is one.
+type ExpectedBalance struct {
+	Id      string `json:"id"`
+	Balance uint64 `json:"balance"`
+}
+
+// BalanceMismatch is a wallet whose balance after the replay is not the expected one.
+type BalanceMismatch struct {
+	// Id is the wallet id after the replay; the recorded id if it differs.
+	Id       string `json:"id"`
+	Recorded string `json:"recorded,omitempty"`
+	Balance  uint64 `json:"balance"`
+	Expected uint64 `json:"expected"`
+	// Error is set if the balance could not be read.
+	Error string `json:"error,omitempty"`
+}
+
+// CheckBalances reads the expected balances from r and compares them with
+// the balances of the target, read with strong consistency. Recorded wallet
+// ids are replaced with the ids of the replay.
+//
+// It returns the wallets that do not match, and an error if r is not a
+// valid balances file or ctx is done.
+func (p *Replayer) CheckBalances(ctx context.Context, r io.Reader) ([]BalanceMismatch, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	var mismatches []BalanceMismatch
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var expected ExpectedBalance
+		if err := json.Unmarshal(data, &expected); err != nil {
+			return mismatches, fmt.Errorf("line %d: %v", line, err)
+		}
+		if expected.Id == "" {
+			return mismatches, fmt.Errorf("line %d: id is required", line)
+		}
+		if err := ctx.Err(); err != nil {
+			return mismatches, err
+		}
+
+		mismatch := BalanceMismatch{Id: p.ID(expected.Id), Expected: expected.Balance}
+		if mismatch.Id != expected.Id {
+			mismatch.Recorded = expected.Id
+		}
+		balance, err := p.balance(ctx, mismatch.Id)
+		if err != nil {
+			mismatch.Error = err.Error()
+			mismatches = append(mismatches, mismatch)
+			continue
+		}
+		if balance != expected.Balance {
+			mismatch.Balance = balance
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+	return mismatches, scanner.Err()
+}
+
+// balance reads the balance of a wallet from the target.
+func (p *Replayer) balance(ctx context.Context, id string) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	defer cancel()
+
+	u := p.opts.BaseURL + "/api/v1/wallets/" + url.PathEscape(id) + "?consistency=strong"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := p.target.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var wallet struct {
+		Balance uint64 `json:"balance"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&wallet); err != nil {
+		return 0, err
+	}
+	return wallet.Balance, nil
+}
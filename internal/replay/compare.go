@@ -0,0 +1,120 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// uuidPattern matches the ids generated by the server.
+var uuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// isUUID reports whether s is a UUID.
+func isUUID(s string) bool {
+	return len(s) == 36 && uuidPattern.MatchString(s)
+}
+
+// rewrite replaces the recorded ids in s with the ids of this replay.
+func (p *Replayer) rewrite(s string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if len(p.ids) == 0 {
+		return s
+	}
+	return uuidPattern.ReplaceAllStringFunc(s, func(id string) string {
+		if mapped, ok := p.ids[id]; ok {
+			return mapped
+		}
+		return id
+	})
+}
+
+// matchID reports whether got is the replayed counterpart of the recorded
+// id want, remembering the pair the first time it is seen.
+func (p *Replayer) matchID(want, got string) bool {
+	if !isUUID(got) {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if mapped, ok := p.ids[want]; ok {
+		return mapped == got
+	}
+	p.ids[want] = got
+	return true
+}
+
+// ID returns the id a recorded id was replaced with, or the id itself.
+func (p *Replayer) ID(recorded string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if mapped, ok := p.ids[recorded]; ok {
+		return mapped
+	}
+	return recorded
+}
+
+// compare appends to diffs the differences between the expected value want
+// and the actual value got at path. Fields missing from want and ignored
+// fields are not compared.
+func (p *Replayer) compare(path string, want, got any, diffs []string) []string {
+	switch want := want.(type) {
+	case map[string]any:
+		got, ok := got.(map[string]any)
+		if !ok {
+			return append(diffs, fmt.Sprintf("%s: got %s, want an object", path, describe(got)))
+		}
+		keys := make([]string, 0, len(want))
+		for key := range want {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if p.ignore[key] {
+				continue
+			}
+			value, ok := got[key]
+			if !ok {
+				diffs = append(diffs, fmt.Sprintf("%s.%s: missing", path, key))
+				continue
+			}
+			diffs = p.compare(path+"."+key, want[key], value, diffs)
+		}
+		return diffs
+	case []any:
+		got, ok := got.([]any)
+		if !ok {
+			return append(diffs, fmt.Sprintf("%s: got %s, want an array", path, describe(got)))
+		}
+		if len(got) != len(want) {
+			return append(diffs, fmt.Sprintf("%s: got %d items, want %d", path, len(got), len(want)))
+		}
+		for i := range want {
+			diffs = p.compare(fmt.Sprintf("%s[%d]", path, i), want[i], got[i], diffs)
+		}
+		return diffs
+	case string:
+		if got, ok := got.(string); ok && (got == p.rewrite(want) || (isUUID(want) && p.matchID(want, got))) {
+			return diffs
+		}
+	default:
+		if got == want {
+			return diffs
+		}
+	}
+	return append(diffs, fmt.Sprintf("%s: got %s, want %s", path, describe(got), describe(want)))
+}
+
+// describe formats a JSON value for a difference.
+func describe(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(data) > 80 {
+		return string(data[:77]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,15 @@
+// Package replay sends recorded API requests to a wallet service again and
+// compares the responses with the recorded ones.
+//
+// A replay file is JSON Lines, one request per line:
+//
+//	{"time":"2025-06-10T12:00:00Z","method":"POST","path":"/api/v1/wallet",
+//	 "body":{"walletId":"…","operationType":"DEPOSIT","amount":100},
+//	 "expect":{"status":200}}
+//
+// The expected body is matched as a subset: fields missing from it are not
+// compared. Ids are generated by the server, so a wallet created by the
+// replay gets a different id than the recorded one; when an expected UUID
+// differs from the returned one, the recorded id is replaced by the new one
+// in the requests and expectations that follow.
+package replay
@@ -0,0 +1,373 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLineSize caps the length of a recorded request.
+const maxLineSize = 1 << 20
+
+// maxFailures caps the failed requests kept in a Summary.
+const maxFailures = 100
+
+// DefaultIgnore are the response fields that differ on every run.
+var DefaultIgnore = []string{"createdAt", "updatedAt", "changedAt", "occurredAt"}
+
+// Request is a recorded API request, one line of a replay file.
+type Request struct {
+	// Time is when the request was recorded; it paces the replay with Options.Speed.
+	Time   time.Time `json:"time,omitempty"`
+	Method string    `json:"method"`
+	// Path is the request path with the query, e.g. "/api/v1/wallets/…?consistency=strong".
+	Path   string            `json:"path"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   json.RawMessage   `json:"body,omitempty"`
+	// Expect is the recorded response; without it the response is not checked.
+	Expect *Response `json:"expect,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Target sends the replayed requests. *http.Client sends them to a running
+// instance; Handler serves them in process.
+type Target interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Handler is a Target serving requests with an http.Handler, without a network.
+type Handler struct {
+	http.Handler
+}
+
+// Do serves req and returns the recorded response.
+func (h Handler) Do(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+// Options control a replay.
+type Options struct {
+	// BaseURL is prepended to the recorded paths, e.g. "http://localhost:8080".
+	BaseURL string
+	// Rate caps the requests sent per second; 0 sends them as fast as possible.
+	Rate float64
+	// Speed keeps the recorded gaps between requests, shortened Speed times;
+	// 0 ignores the recorded times.
+	Speed float64
+	// Concurrency is the number of requests in flight, 1 if not set. With
+	// more than one, requests may complete out of the recorded order.
+	Concurrency int
+	// Timeout limits each request, 30 seconds if not set.
+	Timeout time.Duration
+	// Ignore lists the response fields that are not compared, at any depth.
+	Ignore []string
+	// OnResult, if set, is called with the result of every request.
+	OnResult func(Result)
+}
+
+// Result is the outcome of a replayed request.
+type Result struct {
+	Line     int           `json:"line"`
+	Method   string        `json:"method"`
+	Path     string        `json:"path"`
+	Status   int           `json:"status,omitempty"`
+	Duration time.Duration `json:"duration"`
+	// Diffs lists the differences from the recorded response.
+	Diffs []string `json:"diffs,omitempty"`
+	// Error is set if no response was received.
+	Error string `json:"error,omitempty"`
+}
+
+// Failed reports whether the request failed or its response did not match.
+func (r Result) Failed() bool {
+	return r.Error != "" || len(r.Diffs) > 0
+}
+
+// Summary is the outcome of a replay.
+type Summary struct {
+	Requests   int           `json:"requests"`
+	Mismatches int           `json:"mismatches"`
+	Errors     int           `json:"errors"`
+	Duration   time.Duration `json:"duration"`
+	// Rate is the achieved number of requests per second.
+	Rate float64       `json:"rate"`
+	P50  time.Duration `json:"p50"`
+	P95  time.Duration `json:"p95"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+	// Failures lists the first failed requests.
+	Failures []Result `json:"failures,omitempty"`
+	// Balances lists the wallets whose final balance does not match, see CheckBalances.
+	Balances []BalanceMismatch `json:"balances,omitempty"`
+}
+
+// Failed reports whether any request or final balance did not match.
+func (s *Summary) Failed() bool {
+	return s.Mismatches > 0 || s.Errors > 0 || len(s.Balances) > 0
+}
+
+// Replayer replays requests against a target, remembering the ids it has
+// remapped for the requests that follow.
+type Replayer struct {
+	target Target
+	opts   Options
+	ignore map[string]bool
+
+	mu  sync.RWMutex
+	ids map[string]string
+}
+
+// New returns a Replayer sending requests to target.
+func New(target Target, opts Options) *Replayer {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+
+	ignore := make(map[string]bool, len(opts.Ignore))
+	for _, field := range opts.Ignore {
+		ignore[field] = true
+	}
+	return &Replayer{target: target, opts: opts, ignore: ignore, ids: make(map[string]string)}
+}
+
+type job struct {
+	line int
+	req  Request
+}
+
+// Run replays the requests read from r and summarizes the results.
+//
+// It returns an error if r is not a valid replay file or ctx is done; a
+// request that fails or does not match is only reported in the summary.
+func (p *Replayer) Run(ctx context.Context, r io.Reader) (*Summary, error) {
+	var (
+		mu        sync.Mutex
+		summary   = &Summary{}
+		durations []time.Duration
+	)
+	record := func(result Result) {
+		mu.Lock()
+		summary.Requests++
+		durations = append(durations, result.Duration)
+		if result.Error != "" {
+			summary.Errors++
+		} else if len(result.Diffs) > 0 {
+			summary.Mismatches++
+		}
+		if result.Failed() && len(summary.Failures) < maxFailures {
+			summary.Failures = append(summary.Failures, result)
+		}
+		if p.opts.OnResult != nil {
+			p.opts.OnResult(result)
+		}
+		mu.Unlock()
+	}
+
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for i := 0; i < p.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				record(p.replay(ctx, j.line, j.req))
+			}
+		}()
+	}
+
+	started := time.Now()
+	err := p.dispatch(ctx, r, jobs)
+	close(jobs)
+	wg.Wait()
+
+	summary.Duration = time.Since(started)
+	if seconds := summary.Duration.Seconds(); seconds > 0 {
+		summary.Rate = float64(summary.Requests) / seconds
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	summary.P50 = percentile(durations, 50)
+	summary.P95 = percentile(durations, 95)
+	summary.P99 = percentile(durations, 99)
+	summary.Max = percentile(durations, 100)
+	return summary, err
+}
+
+// dispatch reads the requests and hands them to the workers at the
+// configured pace.
+func (p *Replayer) dispatch(ctx context.Context, r io.Reader, jobs chan<- job) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	var (
+		interval  time.Duration
+		next      time.Time
+		started   time.Time
+		firstTime time.Time
+	)
+	if p.opts.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / p.opts.Rate)
+	}
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var req Request
+		if err := json.Unmarshal(data, &req); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if req.Method == "" || !strings.HasPrefix(req.Path, "/") {
+			return fmt.Errorf("line %d: method and an absolute path are required", line)
+		}
+
+		now := time.Now()
+		if started.IsZero() {
+			started, firstTime, next = now, req.Time, now
+		}
+		due := next
+		if p.opts.Speed > 0 && !req.Time.IsZero() && !firstTime.IsZero() {
+			recorded := started.Add(time.Duration(float64(req.Time.Sub(firstTime)) / p.opts.Speed))
+			if recorded.After(due) {
+				due = recorded
+			}
+		}
+		if err := sleepUntil(ctx, due); err != nil {
+			return err
+		}
+		next = due.Add(interval)
+
+		select {
+		case jobs <- job{line: line, req: req}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return scanner.Err()
+}
+
+// replay sends a request and compares the response with the recorded one.
+func (p *Replayer) replay(ctx context.Context, line int, rec Request) Result {
+	path := p.rewrite(rec.Path)
+	result := Result{Line: line, Method: rec.Method, Path: path}
+
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	defer cancel()
+
+	var body io.Reader
+	if len(rec.Body) > 0 {
+		body = strings.NewReader(p.rewrite(string(rec.Body)))
+	}
+	req, err := http.NewRequestWithContext(ctx, rec.Method, p.opts.BaseURL+path, body)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	for name, value := range rec.Header {
+		req.Header.Set(name, p.rewrite(value))
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	started := time.Now()
+	resp, err := p.target.Do(req)
+	if err == nil {
+		var data []byte
+		data, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err == nil {
+			result.Status = resp.StatusCode
+			if rec.Expect != nil {
+				result.Diffs = p.compareResponse(rec.Expect, resp.StatusCode, data)
+			}
+		}
+	}
+	result.Duration = time.Since(started)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// compareResponse lists the differences between a response and the recorded one.
+func (p *Replayer) compareResponse(expect *Response, status int, body []byte) []string {
+	var diffs []string
+	if expect.Status != 0 && expect.Status != status {
+		diffs = append(diffs, fmt.Sprintf("status: got %d, want %d", status, expect.Status))
+	}
+	if len(expect.Body) == 0 {
+		return diffs
+	}
+
+	want, err := decode(expect.Body)
+	if err != nil {
+		return append(diffs, fmt.Sprintf("expected body: %v", err))
+	}
+	got, err := decode(body)
+	if err != nil {
+		return append(diffs, fmt.Sprintf("body: %v", err))
+	}
+	return p.compare("$", want, got, diffs)
+}
+
+// decode parses JSON keeping numbers exact.
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// sleepUntil waits until t or until ctx is done.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// percentile returns the p-th percentile of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (len(sorted)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
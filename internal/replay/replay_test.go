@@ -0,0 +1,169 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeWallets is a minimal wallet API: wallets get new ids on every run.
+type fakeWallets struct {
+	mu       sync.Mutex
+	ids      []string
+	balances map[string]uint64
+}
+
+func (f *fakeWallets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/wallets":
+		id := f.ids[0]
+		f.ids = f.ids[1:]
+		f.balances[id] = 0
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"uuid": id, "balance": 0, "createdAt": time.Now()})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/wallet":
+		var op struct {
+			WalletID      string `json:"walletId"`
+			OperationType string `json:"operationType"`
+			Amount        uint64 `json:"amount"`
+		}
+		json.NewDecoder(r.Body).Decode(&op)
+		balance, ok := f.balances[op.WalletID]
+		switch {
+		case !ok:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "wallet_not_found"})
+		case op.OperationType == "WITHDRAW" && op.Amount > balance:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "negative_amount"})
+		case op.OperationType == "WITHDRAW":
+			f.balances[op.WalletID] = balance - op.Amount
+			json.NewEncoder(w).Encode(map[string]string{"message": "Operation successful"})
+		default:
+			f.balances[op.WalletID] = balance + op.Amount
+			json.NewEncoder(w).Encode(map[string]string{"message": "Operation successful"})
+		}
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/v1/wallets/"):
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/wallets/")
+		balance, ok := f.balances[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "wallet_not_found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"uuid": id, "balance": balance, "createdAt": time.Now()})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+const (
+	recordedID = "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+	replayedID = "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"
+)
+
+const recording = `{"method":"POST","path":"/api/v1/wallets","body":{"currency":"RUB"},"expect":{"status":201,"body":{"uuid":"` + recordedID + `","balance":0,"createdAt":"2025-06-10T12:00:00Z"}}}
+{"method":"POST","path":"/api/v1/wallet","body":{"walletId":"` + recordedID + `","operationType":"DEPOSIT","amount":500},"expect":{"status":200}}
+
+{"method":"POST","path":"/api/v1/wallet","body":{"walletId":"` + recordedID + `","operationType":"WITHDRAW","amount":1000},"expect":{"status":400,"body":{"error":"negative_amount"}}}
+{"method":"GET","path":"/api/v1/wallets/` + recordedID + `","expect":{"status":200,"body":{"uuid":"` + recordedID + `","balance":500}}}
+`
+
+func TestRun_RemapsIDs(t *testing.T) {
+	api := &fakeWallets{ids: []string{replayedID}, balances: map[string]uint64{}}
+	var results []Result
+	p := New(Handler{api}, Options{Ignore: DefaultIgnore, OnResult: func(r Result) { results = append(results, r) }})
+
+	summary, err := p.Run(context.Background(), strings.NewReader(recording))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if summary.Requests != 4 || summary.Failed() {
+		t.Errorf("summary = %+v, want 4 matching requests", summary)
+	}
+	if got := p.ID(recordedID); got != replayedID {
+		t.Errorf("ID(%s) = %s, want %s", recordedID, got, replayedID)
+	}
+	if len(results) != 4 || results[3].Path != "/api/v1/wallets/"+replayedID || results[1].Line != 2 || results[2].Line != 4 {
+		t.Errorf("results = %+v", results)
+	}
+
+	mismatches, err := p.CheckBalances(context.Background(), strings.NewReader(`{"id":"`+recordedID+`","balance":700}`))
+	if err != nil {
+		t.Fatalf("CheckBalances: %v", err)
+	}
+	want := BalanceMismatch{Id: replayedID, Recorded: recordedID, Balance: 500, Expected: 700}
+	if len(mismatches) != 1 || mismatches[0] != want {
+		t.Errorf("mismatches = %+v, want %+v", mismatches, want)
+	}
+}
+
+func TestRun_ReportsMismatches(t *testing.T) {
+	api := &fakeWallets{ids: []string{replayedID}, balances: map[string]uint64{replayedID: 0}}
+	p := New(Handler{api}, Options{})
+
+	recording := `{"method":"POST","path":"/api/v1/wallet","body":{"walletId":"` + replayedID + `","operationType":"DEPOSIT","amount":100},"expect":{"status":200}}
+{"method":"GET","path":"/api/v1/wallets/` + replayedID + `","expect":{"status":200,"body":{"balance":50,"labels":{}}}}
+{"method":"POST","path":"/api/v1/wallet","body":{"walletId":"` + replayedID + `","operationType":"WITHDRAW","amount":500},"expect":{"status":200}}
+`
+	summary, err := p.Run(context.Background(), strings.NewReader(recording))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if summary.Requests != 3 || summary.Mismatches != 2 || len(summary.Failures) != 2 {
+		t.Fatalf("summary = %+v, want 2 of 3 mismatching", summary)
+	}
+	diffs := strings.Join(summary.Failures[0].Diffs, "; ")
+	if diffs != "$.balance: got 100, want 50; $.labels: missing" {
+		t.Errorf("diffs = %q", diffs)
+	}
+	if diffs := summary.Failures[1].Diffs; len(diffs) != 1 || diffs[0] != "status: got 400, want 200" {
+		t.Errorf("diffs = %q", diffs)
+	}
+}
+
+func TestRun_InvalidLine(t *testing.T) {
+	p := New(Handler{&fakeWallets{}}, Options{})
+	_, err := p.Run(context.Background(), strings.NewReader("{\"method\":\"GET\"}\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Run error = %v, want a line 1 error", err)
+	}
+}
+
+func TestRun_Rate(t *testing.T) {
+	api := &fakeWallets{balances: map[string]uint64{replayedID: 0}}
+	p := New(Handler{api}, Options{Rate: 50})
+
+	recording := strings.Repeat(`{"method":"GET","path":"/api/v1/wallets/`+replayedID+`"}`+"\n", 5)
+	summary, err := p.Run(context.Background(), strings.NewReader(recording))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if summary.Requests != 5 || summary.Duration < 80*time.Millisecond {
+		t.Errorf("summary = %+v, want 5 requests over at least 80ms", summary)
+	}
+}
+
+func TestRun_Speed(t *testing.T) {
+	api := &fakeWallets{balances: map[string]uint64{replayedID: 0}}
+	p := New(Handler{api}, Options{Speed: 10})
+
+	recording := `{"time":"2025-06-10T12:00:00Z","method":"GET","path":"/api/v1/wallets/` + replayedID + `"}
+{"time":"2025-06-10T12:00:01Z","method":"GET","path":"/api/v1/wallets/` + replayedID + `"}
+`
+	summary, err := p.Run(context.Background(), strings.NewReader(recording))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if summary.Duration < 100*time.Millisecond {
+		t.Errorf("duration = %v, want the recorded second shortened to 100ms", summary.Duration)
+	}
+}
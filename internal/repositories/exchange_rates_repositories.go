@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/utils"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const exchangeRateColumns = "id, base_currency, quote_currency, rate_units, precision, rounding_mode, valid_from, valid_to, created_at"
+
+// CreateExchangeRate inserts a new exchange rate.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - rate: rate to insert; Id and CreatedTime are filled in
+//
+// Returns:
+//   - nil if successful
+//   - any error on failure
+func CreateExchangeRate(db Querier, rate *models.ExchangeRate) error {
+	const query = `INSERT INTO exchange_rates
+		(base_currency, quote_currency, rate_units, precision, rounding_mode, valid_from, valid_to)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
+	return db.QueryRow(query, rate.BaseCurrency, rate.QuoteCurrency, rate.RateUnits, rate.Precision,
+		rate.RoundingMode, rate.ValidFrom, rate.ValidTo).Scan(&rate.Id, &rate.CreatedTime)
+}
+
+// ListExchangeRates returns the rates for a currency pair, newest first.
+// Empty currencies match any currency.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - base, quote: optional currency filters
+//
+// Returns:
+//   - the matching rates
+//   - any error on failure
+func ListExchangeRates(db Querier, base, quote string) ([]models.ExchangeRate, error) {
+	query := "SELECT " + exchangeRateColumns + ` FROM exchange_rates
+		WHERE ($1 = '' OR base_currency = $1) AND ($2 = '' OR quote_currency = $2)
+		ORDER BY base_currency, quote_currency, valid_from DESC`
+	rows, err := db.Query(query, base, quote)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []models.ExchangeRate
+	for rows.Next() {
+		rate, err := scanExchangeRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, *rate)
+	}
+	return rates, rows.Err()
+}
+
+// GetLatestExchangeRate returns the most recent rate for a pair that took effect at or before at.
+//
+// The rate may have expired (ValidTo before at); the caller decides how to treat stale rates.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - base, quote: currency pair
+//   - at: point in time
+//
+// Returns:
+//   - the rate if found
+//   - utils.ErrRateNotFound if the pair has no rate in effect yet
+//   - any other error on failure
+func GetLatestExchangeRate(db Querier, base, quote string, at time.Time) (*models.ExchangeRate, error) {
+	query := "SELECT " + exchangeRateColumns + ` FROM exchange_rates
+		WHERE base_currency = $1 AND quote_currency = $2 AND valid_from <= $3
+		ORDER BY valid_from DESC, id DESC LIMIT 1`
+	rate, err := scanExchangeRate(db.QueryRow(query, base, quote, at))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.ErrRateNotFound
+		}
+		return nil, err
+	}
+	return rate, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanExchangeRate(row rowScanner) (*models.ExchangeRate, error) {
+	var (
+		rate    models.ExchangeRate
+		validTo sql.NullTime
+	)
+	err := row.Scan(&rate.Id, &rate.BaseCurrency, &rate.QuoteCurrency, &rate.RateUnits, &rate.Precision,
+		&rate.RoundingMode, &rate.ValidFrom, &validTo, &rate.CreatedTime)
+	if err != nil {
+		return nil, err
+	}
+	if validTo.Valid {
+		rate.ValidTo = &validTo.Time
+	}
+	return &rate, nil
+}
@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/utils"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"strings"
+)
+
+// GetSystemAccount returns the id of the system ledger account of the given
+// type and currency, creating the account on first use.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - accountType: one of the non-wallet models.Account* types
+//   - currency: ISO 4217 code
+//
+// Returns:
+//   - the account id
+//   - any error on failure
+func GetSystemAccount(db Querier, accountType, currency string) (string, error) {
+	// A plain read takes no row lock, so operations posting to the same
+	// system account do not wait on each other.
+	const query = "SELECT id FROM ledger_accounts WHERE type = $1 AND currency = $2 AND wallet_id IS NULL"
+	var id string
+	err := db.QueryRow(query, accountType, currency).Scan(&id)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return id, err
+	}
+
+	// On first use, a concurrent transaction may create the account first;
+	// the insert then waits for it and the account is read back either way.
+	_, err = db.Exec(`INSERT INTO ledger_accounts (id, type, currency) VALUES ($1, $2, $3)
+		ON CONFLICT (type, currency) WHERE wallet_id IS NULL DO NOTHING`, uuid.NewString(), accountType, currency)
+	if err != nil {
+		return "", err
+	}
+	err = db.QueryRow(query, accountType, currency).Scan(&id)
+	return id, err
+}
+
+// LedgerReferenceExists reports whether a wallet already has a ledger
+// transaction with the given reference.
+//
+// Parameters:
+//   - db: transaction holding the wallet lock, so that no transaction with
+//     the reference can commit concurrently
+//   - walletID: wallet identifier
+//   - reference: client reference of the operation
+//
+// Returns:
+//   - whether the reference is taken
+//   - any error on failure
+func LedgerReferenceExists(db Querier, walletID, reference string) (bool, error) {
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM ledger_transactions WHERE wallet_id = $1 AND reference = $2)",
+		walletID, reference).Scan(&exists)
+	return exists, err
+}
+
+// PostLedgerTransaction inserts a ledger transaction and all of its entries.
+//
+// The database rejects the commit if the entries do not balance.
+//
+// Parameters:
+//   - db: transactional context (e.g., *sql.Tx) that also updates the balance projections
+//   - txn: transaction to insert; CreatedTime is filled in
+//
+// Returns:
+//   - nil if successful
+//   - utils.ErrDuplicateReference if the wallet already has a transaction with the same reference
+//   - any other error on failure
+func PostLedgerTransaction(db Querier, txn *models.LedgerTransaction) error {
+	metadata := txn.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO ledger_transactions (id, type, wallet_id, description, reference, metadata)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, NULLIF($4, ''), NULLIF($5, ''), $6) RETURNING created_at`
+	err = db.QueryRow(query, txn.Id, txn.Type, txn.WalletId, txn.Description, txn.Reference, metadataJSON).
+		Scan(&txn.CreatedTime)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "ledger_transactions_wallet_reference_idx" {
+			return utils.ErrDuplicateReference
+		}
+		return err
+	}
+
+	values := make([]string, 0, len(txn.Entries))
+	args := make([]any, 0, len(txn.Entries)*5)
+	for i, entry := range txn.Entries {
+		n := i * 5
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, txn.Id, entry.AccountId, entry.Direction, entry.Amount, entry.Currency)
+	}
+	_, err = db.Exec("INSERT INTO ledger_entries (transaction_id, account_id, direction, amount, currency) VALUES "+
+		strings.Join(values, ", "), args...)
+	return err
+}
+
+// GetLedgerBalance returns an account's credits minus its debits.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - accountID: ledger account identifier (the wallet id for wallet accounts)
+//
+// Returns:
+//   - the balance, 0 for an account without entries
+//   - any error on failure
+func GetLedgerBalance(db Querier, accountID string) (int64, error) {
+	const query = `SELECT COALESCE(SUM(CASE direction WHEN 'CREDIT' THEN amount ELSE -amount END), 0)
+		FROM ledger_entries WHERE account_id = $1`
+	var balance int64
+	err := db.QueryRow(query, accountID).Scan(&balance)
+	return balance, err
+}
+
+// ListLedgerEntries returns the most recent entries of an account, newest first.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - accountID: ledger account identifier
+//   - reference: if not empty, only entries of the transaction with this reference
+//   - limit: maximum number of entries
+//
+// Returns:
+//   - the entries with their transaction type and details
+//   - any error on failure
+func ListLedgerEntries(db Querier, accountID, reference string, limit int) ([]models.LedgerEntry, error) {
+	const query = `SELECT e.id, e.transaction_id, t.type, COALESCE(t.description, ''), COALESCE(t.reference, ''),
+			t.metadata, e.account_id, e.direction, e.amount, e.currency, e.created_at
+		FROM ledger_entries e JOIN ledger_transactions t ON t.id = e.transaction_id
+		WHERE e.account_id = $1 AND ($2 = '' OR t.reference = $2) ORDER BY e.id DESC LIMIT $3`
+	rows, err := db.Query(query, accountID, reference, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanLedgerEntries(rows)
+}
+
+// ListLedgerEntriesBefore returns the entries of an account older than the
+// given entry, newest first, to page through its history.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - accountID: ledger account identifier
+//   - reference: if not empty, only entries of the transaction with this reference
+//   - beforeID: id of the oldest entry already seen
+//   - limit: maximum number of entries
+//
+// Returns:
+//   - the entries with their transaction type and details
+//   - any error on failure
+func ListLedgerEntriesBefore(db Querier, accountID, reference string, beforeID int64, limit int) ([]models.LedgerEntry, error) {
+	const query = `SELECT e.id, e.transaction_id, t.type, COALESCE(t.description, ''), COALESCE(t.reference, ''),
+			t.metadata, e.account_id, e.direction, e.amount, e.currency, e.created_at
+		FROM ledger_entries e JOIN ledger_transactions t ON t.id = e.transaction_id
+		WHERE e.account_id = $1 AND ($2 = '' OR t.reference = $2) AND e.id < $3 ORDER BY e.id DESC LIMIT $4`
+	rows, err := db.Query(query, accountID, reference, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanLedgerEntries(rows)
+}
+
+// ListWalletLedgerEntries returns the entries of all wallet accounts newer
+// than the given entry, oldest first, to page through the history of every
+// wallet.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - afterID: id of the newest entry already seen, 0 to start
+//   - limit: maximum number of entries
+//
+// Returns:
+//   - the entries with their transaction type and details
+//   - any error on failure
+func ListWalletLedgerEntries(db Querier, afterID int64, limit int) ([]models.LedgerEntry, error) {
+	const query = `SELECT e.id, e.transaction_id, t.type, COALESCE(t.description, ''), COALESCE(t.reference, ''),
+			t.metadata, e.account_id, e.direction, e.amount, e.currency, e.created_at
+		FROM ledger_entries e JOIN ledger_transactions t ON t.id = e.transaction_id
+		JOIN ledger_accounts a ON a.id = e.account_id
+		WHERE a.type = 'WALLET' AND e.id > $1 ORDER BY e.id LIMIT $2`
+	rows, err := db.Query(query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanLedgerEntries(rows)
+}
+
+// scanLedgerEntries reads the entries selected by a ledger entry query.
+func scanLedgerEntries(rows *sql.Rows) ([]models.LedgerEntry, error) {
+	defer rows.Close()
+
+	var entries []models.LedgerEntry
+	for rows.Next() {
+		var (
+			entry    models.LedgerEntry
+			metadata []byte
+		)
+		if err := rows.Scan(&entry.Id, &entry.TransactionId, &entry.TransactionType, &entry.Details.Description,
+			&entry.Details.Reference, &metadata, &entry.AccountId, &entry.Direction, &entry.Amount, &entry.Currency,
+			&entry.CreatedTime); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(metadata, &entry.Details.Metadata); err != nil {
+			return nil, fmt.Errorf("ledger transaction %s metadata: %w", entry.TransactionId, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
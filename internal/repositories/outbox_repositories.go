@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"JavaCode/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// outboxLockID elects the instance that dispatches the events.
+const outboxLockID = 7246_1703
+
+// InsertOutboxEvent records an event to be delivered by the dispatcher.
+//
+// Parameters:
+//   - db: transaction that makes the change the event describes
+//   - event: event to insert; Id and CreatedTime are filled in
+//
+// Returns:
+//   - nil if successful
+//   - any error on failure
+func InsertOutboxEvent(db Querier, event *models.OutboxEvent) error {
+	const query = `INSERT INTO outbox_events (event_id, type, wallet_id, payload)
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	return db.QueryRow(query, event.EventId, event.Type, event.WalletId, []byte(event.Payload)).
+		Scan(&event.Id, &event.CreatedTime)
+}
+
+// TryLockOutbox takes the session-scoped lock that elects the instance
+// dispatching the events. The lock is held until the connection is closed.
+//
+// Parameters:
+//   - ctx: context of the query
+//   - conn: dedicated connection that keeps the lock
+//
+// Returns:
+//   - whether the lock was acquired
+//   - any error on failure
+func TryLockOutbox(ctx context.Context, conn *sql.Conn) (bool, error) {
+	var locked bool
+	err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", outboxLockID).Scan(&locked)
+	return locked, err
+}
+
+// ListPendingOutboxEvents returns the oldest undelivered events, in id order.
+//
+// Events of a wallet whose oldest undelivered event is waiting for a retry
+// are left out, so that no event overtakes an earlier one of the same wallet.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - limit: maximum number of events
+//
+// Returns:
+//   - the events
+//   - any error on failure
+func ListPendingOutboxEvents(db Querier, limit int) ([]models.OutboxEvent, error) {
+	const query = `SELECT e.id, e.event_id, e.type, e.wallet_id, e.payload, e.created_at, e.attempts
+		FROM outbox_events e
+		WHERE e.published_at IS NULL AND NOT EXISTS (
+			SELECT 1 FROM outbox_events r
+			WHERE r.wallet_id = e.wallet_id AND r.published_at IS NULL AND r.next_attempt_at > NOW()
+		)
+		ORDER BY e.id LIMIT $1`
+	rows, err := db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanOutboxEvents(rows)
+}
+
+// ListWalletEvents returns the events of a wallet recorded after the given
+// one, in id order, whether delivered or not.
+//
+// Changes to a wallet lock its row before they record an event, so a
+// wallet's events commit in id order and none can appear below afterID later.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - walletUUID: wallet identifier
+//   - afterID: id of the last event already seen, 0 for all
+//   - limit: maximum number of events
+//
+// Returns:
+//   - the events
+//   - any error on failure
+func ListWalletEvents(db Querier, walletUUID string, afterID int64, limit int) ([]models.OutboxEvent, error) {
+	const query = `SELECT id, event_id, type, wallet_id, payload, created_at, attempts
+		FROM outbox_events
+		WHERE wallet_id = $1 AND id > $2
+		ORDER BY id LIMIT $3`
+	rows, err := db.Query(query, walletUUID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanOutboxEvents(rows)
+}
+
+// GetLastWalletEventId returns the id of a wallet's latest event.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - walletUUID: wallet identifier
+//
+// Returns:
+//   - the id, or 0 if the wallet has no events
+//   - any error on failure
+func GetLastWalletEventId(db Querier, walletUUID string) (int64, error) {
+	var id int64
+	err := db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM outbox_events WHERE wallet_id = $1", walletUUID).Scan(&id)
+	return id, err
+}
+
+// scanOutboxEvents reads the events selected by an outbox query.
+func scanOutboxEvents(rows *sql.Rows) ([]models.OutboxEvent, error) {
+	defer rows.Close()
+
+	var events []models.OutboxEvent
+	for rows.Next() {
+		var (
+			event   models.OutboxEvent
+			payload []byte
+		)
+		if err := rows.Scan(&event.Id, &event.EventId, &event.Type, &event.WalletId, &payload,
+			&event.CreatedTime, &event.Attempts); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// MarkOutboxEventPublished records the delivery of an event.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - id: event id
+//
+// Returns:
+//   - any error on failure
+func MarkOutboxEventPublished(db Querier, id int64) error {
+	_, err := db.Exec("UPDATE outbox_events SET published_at = NOW() WHERE id = $1", id)
+	return err
+}
+
+// MarkOutboxEventFailed records a failed delivery and when to try again.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - id: event id
+//   - reason: delivery error
+//   - nextAttempt: earliest time of the next delivery
+//
+// Returns:
+//   - any error on failure
+func MarkOutboxEventFailed(db Querier, id int64, reason string, nextAttempt time.Time) error {
+	const query = `UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $1`
+	_, err := db.Exec(query, id, reason, nextAttempt)
+	return err
+}
+
+// DeletePublishedOutboxEvents removes events delivered before the given time.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - before: delivery time cutoff
+//
+// Returns:
+//   - the number of events removed
+//   - any error on failure
+func DeletePublishedOutboxEvents(db Querier, before time.Time) (int64, error) {
+	result, err := db.Exec("DELETE FROM outbox_events WHERE published_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteOutboxEvents removes events recorded before the given time,
+// whether delivered or not.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - before: recording time cutoff
+//
+// Returns:
+//   - the number of events removed
+//   - any error on failure
+func DeleteOutboxEvents(db Querier, before time.Time) (int64, error) {
+	result, err := db.Exec("DELETE FROM outbox_events WHERE created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
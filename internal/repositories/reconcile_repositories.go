@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"JavaCode/internal/models"
+	"github.com/lib/pq"
+)
+
+// reconcileLockID keeps concurrent instances from reconciling at the same time.
+const reconcileLockID = 7246_1705
+
+// TryLockReconcile takes the transaction-scoped lock that serializes
+// reconciliation runs across instances.
+//
+// Parameters:
+//   - db: transaction that will reconcile the balances
+//
+// Returns:
+//   - whether the lock was acquired
+//   - any error on failure
+func TryLockReconcile(db Querier) (bool, error) {
+	var locked bool
+	err := db.QueryRow("SELECT pg_try_advisory_xact_lock($1)", reconcileLockID).Scan(&locked)
+	return locked, err
+}
+
+// CountWallets returns the number of wallets.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//
+// Returns:
+//   - the count
+//   - any error on failure
+func CountWallets(db Querier) (int64, error) {
+	var count int64
+	err := db.QueryRow("SELECT COUNT(*) FROM wallets").Scan(&count)
+	return count, err
+}
+
+// FindBalanceDiscrepancies recomputes every wallet's balance from its ledger
+// entries and returns the wallets whose stored balance differs.
+//
+// Parameters:
+//   - db: DB connection or transaction; use a single snapshot for consistent results
+//
+// Returns:
+//   - the discrepancies ordered by wallet id
+//   - any error on failure
+func FindBalanceDiscrepancies(db Querier) ([]models.Discrepancy, error) {
+	const query = `SELECT w.id, w.currency, w.balance, COALESCE(l.balance, 0)
+		FROM wallets w
+		LEFT JOIN (
+			SELECT account_id, SUM(CASE direction WHEN 'CREDIT' THEN amount ELSE -amount END) AS balance
+			FROM ledger_entries GROUP BY account_id
+		) l ON l.account_id = w.id
+		WHERE w.balance <> COALESCE(l.balance, 0)
+		ORDER BY w.id`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var discrepancies []models.Discrepancy
+	for rows.Next() {
+		var d models.Discrepancy
+		if err := rows.Scan(&d.WalletId, &d.Currency, &d.Balance, &d.LedgerBalance); err != nil {
+			return nil, err
+		}
+		d.Difference = int64(d.Balance) - d.LedgerBalance
+		discrepancies = append(discrepancies, d)
+	}
+	return discrepancies, rows.Err()
+}
+
+// FreezeWallets freezes the given active wallets and records the change in
+// their status history.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - walletUUIDs: wallets to freeze
+//   - reason: reason recorded in the status history
+//
+// Returns:
+//   - the ids of the wallets that were active and are now frozen
+//   - any error on failure
+func FreezeWallets(db Querier, walletUUIDs []string, reason string) ([]string, error) {
+	const query = `WITH frozen AS (
+			UPDATE wallets SET status = 'FROZEN', updated_at = NOW()
+			WHERE id = ANY($1) AND status = 'ACTIVE' RETURNING id
+		)
+		INSERT INTO wallet_status_changes (wallet_id, from_status, to_status, reason)
+		SELECT id, 'ACTIVE', 'FROZEN', $2 FROM frozen
+		RETURNING wallet_id`
+	rows, err := db.Query(query, pq.Array(walletUUIDs), reason)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var frozen []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		frozen = append(frozen, id)
+	}
+	return frozen, rows.Err()
+}
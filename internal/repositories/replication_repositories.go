@@ -0,0 +1,38 @@
+package repositories
+
+// GetCurrentWalLSN returns the current write-ahead log position of the primary.
+//
+// Parameters:
+//   - db: connection to the primary
+//
+// Returns:
+//   - the LSN in its textual form (e.g. "0/16B3748")
+//   - any error on failure
+func GetCurrentWalLSN(db Querier) (string, error) {
+	var lsn string
+	const query = "SELECT pg_current_wal_lsn()::text"
+	if err := db.QueryRow(query).Scan(&lsn); err != nil {
+		return "", err
+	}
+	return lsn, nil
+}
+
+// IsReplayedUpTo reports whether the server has replayed WAL up to the given LSN.
+//
+// A server that is not in recovery (the primary) always reports true.
+//
+// Parameters:
+//   - db: connection to a replica
+//   - lsn: WAL position returned by GetCurrentWalLSN
+//
+// Returns:
+//   - true if the replica has caught up with lsn
+//   - any error on failure
+func IsReplayedUpTo(db Querier, lsn string) (bool, error) {
+	var caughtUp bool
+	const query = "SELECT COALESCE(pg_last_wal_replay_lsn() >= $1::pg_lsn, TRUE)"
+	if err := db.QueryRow(query, lsn).Scan(&caughtUp); err != nil {
+		return false, err
+	}
+	return caughtUp, nil
+}
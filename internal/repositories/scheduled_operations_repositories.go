@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/utils"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// schedulerLockID elects the instance that runs scheduled operations.
+const schedulerLockID = 7246_1704
+
+const scheduleColumns = `id, wallet_id, operation_type, amount, currency, COALESCE(description, ''), metadata,
+	schedule, start_at, end_at, max_retries, status, next_run_at, attempts, next_attempt_at, created_at, updated_at`
+
+const scheduledRunColumns = `id, schedule_id, run_at, attempt, status, reference, COALESCE(error, ''), executed_at`
+
+// scanScheduledOperation reads a row selected with scheduleColumns.
+func scanScheduledOperation(row rowScanner) (*models.ScheduledOperation, error) {
+	var (
+		operation   models.ScheduledOperation
+		metadata    []byte
+		end         sql.NullTime
+		nextRun     sql.NullTime
+		nextAttempt sql.NullTime
+	)
+	err := row.Scan(&operation.Id, &operation.WalletId, &operation.OperationType, &operation.Amount,
+		&operation.Currency, &operation.Description, &metadata, &operation.Schedule, &operation.StartTime, &end,
+		&operation.MaxRetries, &operation.Status, &nextRun, &operation.Attempts, &nextAttempt,
+		&operation.CreatedTime, &operation.UpdatedTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(metadata, &operation.Metadata); err != nil {
+		return nil, err
+	}
+	if end.Valid {
+		operation.EndTime = &end.Time
+	}
+	if nextRun.Valid {
+		operation.NextRunTime = &nextRun.Time
+	}
+	if nextAttempt.Valid {
+		operation.NextAttemptTime = &nextAttempt.Time
+	}
+	return &operation, nil
+}
+
+// scanScheduledOperations reads all rows selected with scheduleColumns and closes them.
+func scanScheduledOperations(rows *sql.Rows) ([]models.ScheduledOperation, error) {
+	defer rows.Close()
+
+	var operations []models.ScheduledOperation
+	for rows.Next() {
+		operation, err := scanScheduledOperation(rows)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, *operation)
+	}
+	return operations, rows.Err()
+}
+
+// CreateScheduledOperation inserts a scheduled operation.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - operation: operation to insert; CreatedTime and UpdatedTime are filled in
+//
+// Returns:
+//   - nil if successful
+//   - any error on failure
+func CreateScheduledOperation(db Querier, operation *models.ScheduledOperation) error {
+	metadata, err := json.Marshal(operation.Metadata)
+	if err != nil {
+		return err
+	}
+	if operation.Metadata == nil {
+		metadata = []byte("{}")
+	}
+	const query = `INSERT INTO scheduled_operations (id, wallet_id, operation_type, amount, currency, description,
+			metadata, schedule, start_at, end_at, max_retries, status, next_run_at, attempts, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING created_at, updated_at`
+	return db.QueryRow(query, operation.Id, operation.WalletId, operation.OperationType, operation.Amount,
+		operation.Currency, operation.Description, metadata, operation.Schedule, operation.StartTime,
+		operation.EndTime, operation.MaxRetries, operation.Status, operation.NextRunTime, operation.Attempts,
+		operation.NextAttemptTime).
+		Scan(&operation.CreatedTime, &operation.UpdatedTime)
+}
+
+// GetScheduledOperation returns a scheduled operation by id.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - id: scheduled operation identifier
+//
+// Returns:
+//   - the operation
+//   - utils.ErrScheduleNotFound if it does not exist
+//   - any other error on failure
+func GetScheduledOperation(db Querier, id string) (*models.ScheduledOperation, error) {
+	operation, err := scanScheduledOperation(db.QueryRow("SELECT "+scheduleColumns+" FROM scheduled_operations WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, utils.ErrScheduleNotFound
+	}
+	return operation, err
+}
+
+// ListScheduledOperations returns scheduled operations, oldest first.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - walletID: if not empty, only the operations of this wallet
+//   - status: if not empty, only operations with this status
+//
+// Returns:
+//   - the operations
+//   - any error on failure
+func ListScheduledOperations(db Querier, walletID, status string) ([]models.ScheduledOperation, error) {
+	rows, err := db.Query("SELECT "+scheduleColumns+` FROM scheduled_operations
+		WHERE ($1 = '' OR wallet_id::text = $1) AND ($2 = '' OR status = $2) ORDER BY created_at, id`, walletID, status)
+	if err != nil {
+		return nil, err
+	}
+	return scanScheduledOperations(rows)
+}
+
+// CancelScheduledOperation cancels an active scheduled operation. Its runs are kept.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - id: scheduled operation identifier
+//
+// Returns:
+//   - whether an active operation was cancelled
+//   - any error on failure
+func CancelScheduledOperation(db Querier, id string) (bool, error) {
+	const query = `UPDATE scheduled_operations SET status = 'CANCELLED', next_run_at = NULL, next_attempt_at = NULL,
+		updated_at = NOW() WHERE id = $1 AND status = 'ACTIVE'`
+	result, err := db.Exec(query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// TryLockScheduler takes the session-scoped lock that elects the instance
+// running scheduled operations. The lock is held until the connection is closed.
+//
+// Parameters:
+//   - ctx: context of the query
+//   - conn: dedicated connection that keeps the lock
+//
+// Returns:
+//   - whether the lock was acquired
+//   - any error on failure
+func TryLockScheduler(ctx context.Context, conn *sql.Conn) (bool, error) {
+	var locked bool
+	err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", schedulerLockID).Scan(&locked)
+	return locked, err
+}
+
+// LeaseDueScheduledOperations claims the active operations whose next
+// attempt is due, earliest first, by moving their next attempt to leaseUntil.
+// Operations locked by other workers are skipped, and the claimed ones are
+// not due again until the lease expires.
+//
+// Parameters:
+//   - db: DB connection; the claim commits on its own
+//   - limit: maximum number of operations
+//   - leaseUntil: time the claimed operations become due again if their outcome is not recorded
+//
+// Returns:
+//   - the operations, with NextAttemptTime set to leaseUntil
+//   - any error on failure
+func LeaseDueScheduledOperations(db Querier, limit int, leaseUntil time.Time) ([]models.ScheduledOperation, error) {
+	rows, err := db.Query(`WITH due AS (
+			SELECT id AS due_id FROM scheduled_operations
+			WHERE status = 'ACTIVE' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, id LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE scheduled_operations SET next_attempt_at = $2, updated_at = NOW()
+		FROM due WHERE id = due_id
+		RETURNING `+scheduleColumns, limit, leaseUntil)
+	if err != nil {
+		return nil, err
+	}
+	return scanScheduledOperations(rows)
+}
+
+// UpdateScheduledOperationProgress records the status, next occurrence and
+// attempts of a scheduled operation after an attempt at the occurrence at
+// runAt. Nothing is updated if the operation has been cancelled or moved
+// past that occurrence in the meantime.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - operation: operation with the new Status, NextRunTime, Attempts and NextAttemptTime
+//   - runAt: occurrence that was attempted
+//
+// Returns:
+//   - whether the operation was updated
+//   - any error on failure
+func UpdateScheduledOperationProgress(db Querier, operation *models.ScheduledOperation, runAt time.Time) (bool, error) {
+	const query = `UPDATE scheduled_operations SET status = $2, next_run_at = $3, attempts = $4,
+		next_attempt_at = $5, updated_at = NOW() WHERE id = $1 AND status = 'ACTIVE' AND next_run_at = $6`
+	result, err := db.Exec(query, operation.Id, operation.Status, operation.NextRunTime, operation.Attempts,
+		operation.NextAttemptTime, runAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// InsertScheduledRun records an attempt to execute a scheduled operation.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - run: run to insert; Id and ExecutedTime are filled in
+//
+// Returns:
+//   - nil if successful
+//   - any error on failure
+func InsertScheduledRun(db Querier, run *models.ScheduledRun) error {
+	const query = `INSERT INTO scheduled_operation_runs (schedule_id, run_at, attempt, status, reference, error)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')) RETURNING id, executed_at`
+	return db.QueryRow(query, run.ScheduleId, run.RunTime, run.Attempt, run.Status, run.Reference, run.Error).
+		Scan(&run.Id, &run.ExecutedTime)
+}
+
+// ListScheduledRuns returns the most recent runs of a scheduled operation, newest first.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - scheduleID: scheduled operation identifier
+//   - limit: maximum number of runs
+//
+// Returns:
+//   - the runs
+//   - any error on failure
+func ListScheduledRuns(db Querier, scheduleID string, limit int) ([]models.ScheduledRun, error) {
+	rows, err := db.Query("SELECT "+scheduledRunColumns+` FROM scheduled_operation_runs
+		WHERE schedule_id = $1 ORDER BY id DESC LIMIT $2`, scheduleID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []models.ScheduledRun
+	for rows.Next() {
+		var run models.ScheduledRun
+		err := rows.Scan(&run.Id, &run.ScheduleId, &run.RunTime, &run.Attempt, &run.Status, &run.Reference,
+			&run.Error, &run.ExecutedTime)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// snapshotLockID keeps concurrent instances from taking snapshots at the same time.
+const snapshotLockID = 7246_1702
+
+// GetBalanceAt returns a wallet account's balance as of the given time: the
+// latest snapshot taken at or before it plus the entries created since.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - walletUUID: wallet identifier
+//   - at: point in time
+//
+// Returns:
+//   - the balance
+//   - any error on failure
+func GetBalanceAt(db Querier, walletUUID string, at time.Time) (int64, error) {
+	const query = `WITH s AS (
+			SELECT balance, taken_at FROM balance_snapshots
+			WHERE wallet_id = $1 AND taken_at <= $2
+			ORDER BY taken_at DESC LIMIT 1
+		)
+		SELECT COALESCE((SELECT balance FROM s), 0)
+			+ COALESCE(SUM(CASE direction WHEN 'CREDIT' THEN amount ELSE -amount END), 0)
+		FROM ledger_entries
+		WHERE account_id = $1 AND created_at <= $2
+			AND created_at > COALESCE((SELECT taken_at FROM s), '-infinity')`
+	var balance int64
+	err := db.QueryRow(query, walletUUID, at).Scan(&balance)
+	return balance, err
+}
+
+// GetLedgerOpening returns when a wallet's ledger history starts, if it
+// starts with an opening balance: wallets that existed before the ledger,
+// and imported wallets, are credited their balance by an OPENING transaction
+// and have no entries for earlier times.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - walletUUID: wallet identifier
+//
+// Returns:
+//   - the time of the opening entry, or the zero time if the wallet's first
+//     entry is not an opening one or it has no entries
+//   - any error on failure
+func GetLedgerOpening(db Querier, walletUUID string) (time.Time, error) {
+	const query = `SELECT t.type, e.created_at FROM ledger_entries e
+		JOIN ledger_transactions t ON t.id = e.transaction_id
+		WHERE e.account_id = $1 ORDER BY e.id LIMIT 1`
+	var (
+		transactionType string
+		createdAt       time.Time
+	)
+	err := db.QueryRow(query, walletUUID).Scan(&transactionType, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && transactionType != "OPENING") {
+		return time.Time{}, nil
+	}
+	return createdAt, err
+}
+
+// GetSnapshotCutoff returns the time a snapshot can be taken at: lag before
+// the database's current time, but just before the oldest transaction open
+// in the database, other than the caller's, if that started earlier. Both
+// are read from the database clock, which also stamps the ledger entries.
+//
+// Transactions of other roles are only seen with the pg_read_all_stats
+// role; see CanSeeAllSessions.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - lag: how far behind the current time the snapshot is taken
+//
+// Returns:
+//   - the cutoff
+//   - any error on failure
+func GetSnapshotCutoff(db Querier, lag time.Duration) (time.Time, error) {
+	const query = `SELECT LEAST(NOW() - $1 * INTERVAL '1 microsecond', MIN(xact_start) - INTERVAL '1 microsecond')
+		FROM pg_stat_activity
+		WHERE datname = current_database() AND backend_type = 'client backend' AND pid <> pg_backend_pid()`
+	var cutoff time.Time
+	err := db.QueryRow(query, lag.Microseconds()).Scan(&cutoff)
+	return cutoff, err
+}
+
+// CanSeeAllSessions reports whether the current role sees the transactions
+// of all roles in pg_stat_activity, as superusers and members of
+// pg_read_all_stats do.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//
+// Returns:
+//   - whether all sessions are visible
+//   - any error on failure
+func CanSeeAllSessions(db Querier) (bool, error) {
+	var visible bool
+	err := db.QueryRow("SELECT pg_has_role(current_user, 'pg_read_all_stats', 'USAGE')").Scan(&visible)
+	return visible, err
+}
+
+// TryLockSnapshots takes the transaction-scoped lock that serializes
+// snapshot runs across instances.
+//
+// Parameters:
+//   - db: transaction that will take the snapshots
+//
+// Returns:
+//   - whether the lock was acquired
+//   - any error on failure
+func TryLockSnapshots(db Querier) (bool, error) {
+	var locked bool
+	err := db.QueryRow("SELECT pg_try_advisory_xact_lock($1)", snapshotLockID).Scan(&locked)
+	return locked, err
+}
+
+// GetLastSnapshotTime returns the time of the latest snapshot run.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//
+// Returns:
+//   - the time, or the zero time if no snapshot was taken yet
+//   - any error on failure
+func GetLastSnapshotTime(db Querier) (time.Time, error) {
+	var last sql.NullTime
+	if err := db.QueryRow("SELECT MAX(taken_at) FROM balance_snapshots").Scan(&last); err != nil {
+		return time.Time{}, err
+	}
+	return last.Time, nil
+}
+
+// InsertBalanceSnapshots snapshots, as of cutoff, every wallet with ledger
+// entries created after since.
+//
+// Every wallet with entries up to since must already have a snapshot at or
+// before since, so only the entries after it need to be added.
+//
+// Parameters:
+//   - db: transaction holding the snapshot lock
+//   - since: time of the previous snapshot run
+//   - cutoff: snapshot time; entries created at or before it must all be committed
+//
+// Returns:
+//   - the number of snapshots taken
+//   - any error on failure
+func InsertBalanceSnapshots(db Querier, since, cutoff time.Time) (int64, error) {
+	const query = `INSERT INTO balance_snapshots (wallet_id, taken_at, balance)
+		SELECT e.account_id, $2,
+			COALESCE(s.balance, 0) + SUM(CASE e.direction WHEN 'CREDIT' THEN e.amount ELSE -e.amount END)
+		FROM ledger_entries e
+		JOIN wallets w ON w.id = e.account_id
+		LEFT JOIN LATERAL (
+			SELECT balance FROM balance_snapshots
+			WHERE wallet_id = e.account_id AND taken_at <= $1
+			ORDER BY taken_at DESC LIMIT 1
+		) s ON TRUE
+		WHERE e.created_at > $1 AND e.created_at <= $2
+		GROUP BY e.account_id, s.balance`
+	result, err := db.Exec(query, since, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
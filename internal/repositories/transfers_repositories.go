@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/utils"
+	"database/sql"
+	"errors"
+)
+
+// CreateTransfer records a transfer.
+//
+// Parameters:
+//   - db: transactional context (e.g., *sql.Tx) that also applied the balance changes
+//   - transfer: transfer to insert; CreatedTime is filled in
+//
+// Returns:
+//   - nil if successful
+//   - any error on failure
+func CreateTransfer(db Querier, transfer *models.Transfer) error {
+	const query = `INSERT INTO transfers
+		(id, from_wallet_id, to_wallet_id, source_amount, source_currency, target_amount, target_currency,
+		 rate_id, rate_units, rate_precision, rounding_mode)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING created_at`
+	return db.QueryRow(query, transfer.Id, transfer.FromWalletId, transfer.ToWalletId,
+		transfer.SourceAmount, transfer.SourceCurrency, transfer.TargetAmount, transfer.TargetCurrency,
+		transfer.RateId, transfer.RateUnits, transfer.RatePrecision, transfer.RoundingMode).
+		Scan(&transfer.CreatedTime)
+}
+
+// GetTransferByUUID retrieves a transfer by UUID.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - transferUUID: transfer identifier
+//
+// Returns:
+//   - the transfer if found
+//   - utils.ErrTransferNotFound if not found
+//   - any other error on failure
+func GetTransferByUUID(db Querier, transferUUID string) (*models.Transfer, error) {
+	const query = `SELECT id, from_wallet_id, to_wallet_id, source_amount, source_currency,
+		target_amount, target_currency, rate_id, rate_units, rate_precision, rounding_mode, created_at
+		FROM transfers WHERE id = $1`
+
+	var (
+		transfer      models.Transfer
+		rateId        sql.NullInt64
+		rateUnits     sql.NullInt64
+		ratePrecision sql.NullInt32
+		roundingMode  sql.NullString
+	)
+	err := db.QueryRow(query, transferUUID).Scan(&transfer.Id, &transfer.FromWalletId, &transfer.ToWalletId,
+		&transfer.SourceAmount, &transfer.SourceCurrency, &transfer.TargetAmount, &transfer.TargetCurrency,
+		&rateId, &rateUnits, &ratePrecision, &roundingMode, &transfer.CreatedTime)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.ErrTransferNotFound
+		}
+		return nil, err
+	}
+
+	if rateId.Valid {
+		transfer.RateId = &rateId.Int64
+	}
+	if rateUnits.Valid {
+		transfer.RateUnits = &rateUnits.Int64
+	}
+	if ratePrecision.Valid {
+		precision := int(ratePrecision.Int32)
+		transfer.RatePrecision = &precision
+	}
+	if roundingMode.Valid {
+		transfer.RoundingMode = &roundingMode.String
+	}
+	return &transfer, nil
+}
@@ -4,10 +4,75 @@ import (
 	"JavaCode/internal/models"
 	"JavaCode/utils"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/lib/pq"
+	"strings"
 )
 
+// walletColumns are the columns scanned by scanWallet.
+const walletColumns = "id, balance, currency, status, deposits_blocked, COALESCE(owner, ''), COALESCE(name, ''), labels, " +
+	"created_at, updated_at"
+
+// CreateWallet inserts a new wallet with a zero balance.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - wallet: the wallet's id, currency (ISO 4217) and optional owner, name and labels
+//
+// Returns:
+//   - the created wallet
+//   - utils.ErrWalletExists if a wallet with the same id exists
+//   - any other error on failure
+func CreateWallet(db Querier, wallet models.Wallet) (*models.Wallet, error) {
+	wallet.Balance, wallet.Status = 0, models.WalletActive
+	if wallet.Labels == nil {
+		wallet.Labels = map[string]string{}
+	}
+	labels, err := json.Marshal(wallet.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `INSERT INTO wallets (id, balance, currency, owner, name, labels)
+		VALUES ($1, 0, $2, NULLIF($3, ''), NULLIF($4, ''), $5) RETURNING created_at, updated_at`
+	if err := db.QueryRow(query, wallet.Id, wallet.Currency, wallet.Owner, wallet.Name, labels).
+		Scan(&wallet.CreatedTime, &wallet.UpdatedTime); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "wallets_pkey" {
+			return nil, utils.ErrWalletExists
+		}
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// SetWalletMetadata stores a wallet's owner, name and labels.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - wallet: the wallet with its new metadata
+//
+// Returns:
+//   - nil if successful
+//   - utils.ErrWalletNotFound if the wallet doesn't exist
+//   - any other error on failure
+func SetWalletMetadata(db Querier, wallet *models.Wallet) error {
+	labels, err := json.Marshal(wallet.Labels)
+	if err != nil {
+		return err
+	}
+
+	const query = `UPDATE wallets SET owner = NULLIF($1, ''), name = NULLIF($2, ''), labels = $3, updated_at = NOW()
+		WHERE id = $4 RETURNING updated_at`
+	err = db.QueryRow(query, wallet.Owner, wallet.Name, labels, wallet.Id).Scan(&wallet.UpdatedTime)
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.ErrWalletNotFound
+	}
+	return err
+}
+
 // GetWalletByUUID retrieves a wallet by UUID.
 //
 // Parameters:
@@ -19,17 +84,14 @@ import (
 //   - utils.ErrWalletNotFound if not found
 //   - any other error on failure
 func GetWalletByUUID(db Querier, walletUUID string) (*models.Wallet, error) {
-	var wallet models.Wallet
-	const query = "SELECT id, balance, created_at, updated_at FROM wallets WHERE id = $1"
-	err := db.QueryRow(query, walletUUID).Scan(&wallet.Id, &wallet.Balance, &wallet.CreatedTime, &wallet.UpdatedTime)
-
+	wallet, err := scanWallet(db.QueryRow("SELECT "+walletColumns+" FROM wallets WHERE id = $1", walletUUID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, utils.ErrWalletNotFound
 		}
 		return nil, err
 	}
-	return &wallet, nil
+	return wallet, nil
 }
 
 // GetWalletForUpdate retrieves and locks a wallet by UUID.
@@ -43,17 +105,129 @@ func GetWalletByUUID(db Querier, walletUUID string) (*models.Wallet, error) {
 //   - utils.ErrWalletNotFound if not found
 //   - any other error on failure
 func GetWalletForUpdate(db Querier, walletUUID string) (*models.Wallet, error) {
-	var wallet models.Wallet
-	query := "SELECT id, balance, created_at, updated_at FROM wallets WHERE id = $1 FOR UPDATE"
-	err := db.QueryRow(query, walletUUID).
-		Scan(&wallet.Id, &wallet.Balance, &wallet.CreatedTime, &wallet.UpdatedTime)
-
+	wallet, err := scanWallet(db.QueryRow("SELECT "+walletColumns+" FROM wallets WHERE id = $1 FOR UPDATE", walletUUID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, utils.ErrWalletNotFound
 		}
 		return nil, err
 	}
+	return wallet, nil
+}
+
+// ListWallets returns one page of wallets matching filter, ordered by the
+// filter's sort column and then by id.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - filter: conditions, order, the position to continue after and the page size
+//
+// Returns:
+//   - the wallets
+//   - any error on failure
+func ListWallets(db Querier, filter models.WalletFilter) ([]models.Wallet, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	where := func(condition string, values ...any) {
+		placeholders := make([]any, len(values))
+		for i, value := range values {
+			args = append(args, value)
+			placeholders[i] = len(args)
+		}
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
+	}
+
+	if filter.Owner != "" {
+		where("owner = $%d", filter.Owner)
+	}
+	if filter.Name != "" {
+		where(`name ILIKE $%d ESCAPE '\'`, "%"+likeEscaper.Replace(filter.Name)+"%")
+	}
+	if len(filter.Labels) > 0 {
+		labels, err := json.Marshal(filter.Labels)
+		if err != nil {
+			return nil, err
+		}
+		where("labels @> $%d", labels)
+	}
+	if filter.Status != "" {
+		where("status = $%d", filter.Status)
+	}
+	if filter.Currency != "" {
+		where("currency = $%d", filter.Currency)
+	}
+	if filter.MinBalance != nil {
+		where("balance >= $%d", *filter.MinBalance)
+	}
+	if filter.MaxBalance != nil {
+		where("balance <= $%d", *filter.MaxBalance)
+	}
+	if filter.CreatedFrom != nil {
+		where("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		where("created_at < $%d", *filter.CreatedTo)
+	}
+
+	column, direction, comparison := "created_at", "ASC", ">"
+	if filter.Sort == models.WalletSortBalance {
+		column = "balance"
+	}
+	if filter.Desc {
+		direction, comparison = "DESC", "<"
+	}
+	if after := filter.After; after != nil {
+		var value any = after.CreatedTime
+		if filter.Sort == models.WalletSortBalance {
+			value = after.Balance
+		}
+		where("("+column+", id) "+comparison+" ($%d, $%d)", value, after.Id)
+	}
+
+	query := "SELECT " + walletColumns + " FROM wallets"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d", column, direction, direction, len(args))
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []models.Wallet
+	for rows.Next() {
+		wallet, err := scanWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, *wallet)
+	}
+	return wallets, rows.Err()
+}
+
+// likeEscaper escapes the LIKE wildcards in a search term.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func scanWallet(row rowScanner) (*models.Wallet, error) {
+	var (
+		wallet models.Wallet
+		labels []byte
+	)
+	if err := row.Scan(&wallet.Id, &wallet.Balance, &wallet.Currency, &wallet.Status, &wallet.DepositsBlocked,
+		&wallet.Owner, &wallet.Name, &labels, &wallet.CreatedTime, &wallet.UpdatedTime); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(labels, &wallet.Labels); err != nil {
+		return nil, fmt.Errorf("wallet %s labels: %w", wallet.Id, err)
+	}
+	if wallet.Labels == nil {
+		wallet.Labels = map[string]string{}
+	}
 	return &wallet, nil
 }
 
@@ -67,9 +241,12 @@ func GetWalletForUpdate(db Querier, walletUUID string) (*models.Wallet, error) {
 // Returns:
 //   - nil if successful
 //   - utils.ErrNegativeBalance if balance goes below zero
+//   - utils.ErrAmountOverflow if the balance would exceed the column range
+//   - utils.ErrWalletFrozen if funds are taken out of a frozen wallet
+//   - utils.ErrWalletClosed if the wallet is closed
 //   - utils.ErrWalletNotFound if wallet doesn't exist
 //   - any other error on failure
-func ChainBalance(db Querier, walletUUID string, delta int) error {
+func ChainBalance(db Querier, walletUUID string, delta int64) error {
 	const query = "UPDATE wallets SET balance = balance + $1, updated_at = NOW() WHERE id = $2"
 	result, err := db.Exec(query, delta, walletUUID)
 	if err != nil {
@@ -77,6 +254,15 @@ func ChainBalance(db Querier, walletUUID string, delta int) error {
 		if errors.As(err, &pqErr) && pqErr.Constraint == "wallets_balance_check" {
 			return utils.ErrNegativeBalance
 		}
+		if errors.As(err, &pqErr) && pqErr.Constraint == "wallets_frozen" {
+			return utils.ErrWalletFrozen
+		}
+		if errors.As(err, &pqErr) && pqErr.Constraint == "wallets_closed" {
+			return utils.ErrWalletClosed
+		}
+		if errors.As(err, &pqErr) && pqErr.Code == "22003" { // numeric_value_out_of_range
+			return utils.ErrAmountOverflow
+		}
 		return err
 	}
 
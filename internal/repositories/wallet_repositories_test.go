@@ -1,6 +1,7 @@
 package repositories_test
 
 import (
+	"JavaCode/internal/models"
 	"JavaCode/internal/repositories"
 	"JavaCode/utils"
 	"database/sql"
@@ -19,11 +20,11 @@ func TestGetWalletByUUID(t *testing.T) {
 		walletID := "abc-123"
 		now := time.Now()
 
-		mock.ExpectQuery("SELECT id, balance, created_at, updated_at FROM wallets WHERE id = \\$1").
+		mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1").
 			WithArgs(walletID).
 			WillReturnRows(
-				sqlmock.NewRows([]string{"id", "balance", "created_at", "updated_at"}).
-					AddRow(walletID, 1000, now, now),
+				sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+					AddRow(walletID, 1000, "RUB", "ACTIVE", false, "", "", "{}", now, now),
 			)
 
 		result, err := repositories.GetWalletByUUID(db, walletID)
@@ -42,7 +43,7 @@ func TestGetWalletByUUID(t *testing.T) {
 
 		walletID := "not-found"
 
-		mock.ExpectQuery("SELECT id, balance, created_at, updated_at FROM wallets WHERE id = \\$1").
+		mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1").
 			WithArgs(walletID).
 			WillReturnError(sql.ErrNoRows)
 
@@ -58,7 +59,7 @@ func TestGetWalletByUUID(t *testing.T) {
 
 		walletID := "abc-123"
 
-		mock.ExpectQuery("SELECT id, balance, created_at, updated_at FROM wallets WHERE id = \\$1").
+		mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1").
 			WithArgs(walletID).
 			WillReturnError(sql.ErrConnDone)
 
@@ -77,10 +78,10 @@ func TestGetWalletForUpdate(t *testing.T) {
 		walletID := "abc-123"
 		now := time.Now()
 
-		mock.ExpectQuery("SELECT id, balance, created_at, updated_at FROM wallets WHERE id = \\$1 FOR UPDATE").
+		mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1 FOR UPDATE").
 			WithArgs(walletID).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "created_at", "updated_at"}).
-				AddRow(walletID, 1500, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, 1500, "RUB", "ACTIVE", false, "", "", "{}", now, now))
 
 		result, err := repositories.GetWalletForUpdate(db, walletID)
 		if err != nil {
@@ -97,7 +98,7 @@ func TestGetWalletForUpdate(t *testing.T) {
 
 		walletID := "not-found"
 
-		mock.ExpectQuery("SELECT id, balance, created_at, updated_at FROM wallets WHERE id = \\$1 FOR UPDATE").
+		mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1 FOR UPDATE").
 			WithArgs(walletID).
 			WillReturnError(sql.ErrNoRows)
 
@@ -114,7 +115,7 @@ func TestChainBalance(t *testing.T) {
 		defer db.Close()
 
 		walletID := "abc-123"
-		delta := 500
+		delta := int64(500)
 
 		mock.ExpectExec("UPDATE wallets SET balance = balance \\+ \\$1, updated_at = NOW\\(\\) WHERE id = \\$2").
 			WithArgs(delta, walletID).
@@ -131,7 +132,7 @@ func TestChainBalance(t *testing.T) {
 		defer db.Close()
 
 		walletID := "not-found"
-		delta := 100
+		delta := int64(100)
 
 		mock.ExpectExec("UPDATE wallets SET balance = balance \\+ \\$1, updated_at = NOW\\(\\) WHERE id = \\$2").
 			WithArgs(delta, walletID).
@@ -148,7 +149,7 @@ func TestChainBalance(t *testing.T) {
 		defer db.Close()
 
 		walletID := "abc-123"
-		delta := -99999
+		delta := int64(-99999)
 
 		pqErr := &pq.Error{Constraint: "wallets_balance_check"}
 
@@ -167,7 +168,7 @@ func TestChainBalance(t *testing.T) {
 		defer db.Close()
 
 		walletID := "abc-123"
-		delta := 100
+		delta := int64(100)
 
 		mock.ExpectExec("UPDATE wallets SET balance = balance \\+ \\$1, updated_at = NOW\\(\\) WHERE id = \\$2").
 			WithArgs(delta, walletID).
@@ -184,7 +185,7 @@ func TestChainBalance(t *testing.T) {
 		defer db.Close()
 
 		walletID := "abc-123"
-		delta := 100
+		delta := int64(100)
 
 		mock.ExpectExec("UPDATE wallets SET balance = balance \\+ \\$1, updated_at = NOW\\(\\) WHERE id = \\$2").
 			WithArgs(delta, walletID).
@@ -196,3 +197,323 @@ func TestChainBalance(t *testing.T) {
 		}
 	})
 }
+
+func TestGetCurrentWalLSN(t *testing.T) {
+	t.Run("Test 1: LSN returned", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT pg_current_wal_lsn\\(\\)::text").
+			WillReturnRows(sqlmock.NewRows([]string{"lsn"}).AddRow("0/16B3748"))
+
+		lsn, err := repositories.GetCurrentWalLSN(db)
+		if err != nil {
+			t.Errorf("expected nil, got error: %v", err)
+		}
+		if lsn != "0/16B3748" {
+			t.Errorf("unexpected lsn: %s", lsn)
+		}
+	})
+
+	t.Run("Test 2: DB error", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT pg_current_wal_lsn\\(\\)::text").
+			WillReturnError(sql.ErrConnDone)
+
+		_, err := repositories.GetCurrentWalLSN(db)
+		if !errors.Is(err, sql.ErrConnDone) {
+			t.Errorf("expected sql.ErrConnDone, got: %v", err)
+		}
+	})
+}
+
+func TestIsReplayedUpTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		caughtUp bool
+	}{
+		{"Replica caught up", true},
+		{"Replica lagging", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			mock.ExpectQuery("SELECT COALESCE\\(pg_last_wal_replay_lsn\\(\\) >= \\$1::pg_lsn, TRUE\\)").
+				WithArgs("0/16B3748").
+				WillReturnRows(sqlmock.NewRows([]string{"caught_up"}).AddRow(tt.caughtUp))
+
+			caughtUp, err := repositories.IsReplayedUpTo(db, "0/16B3748")
+			if err != nil {
+				t.Errorf("expected nil, got error: %v", err)
+			}
+			if caughtUp != tt.caughtUp {
+				t.Errorf("got %v, want %v", caughtUp, tt.caughtUp)
+			}
+		})
+	}
+}
+
+func TestCreateWallet(t *testing.T) {
+	t.Run("Test 1: Wallet created", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		now := time.Now()
+		mock.ExpectQuery("INSERT INTO wallets \\(id, balance, currency, owner, name, labels\\)\\s+"+
+			"VALUES \\(\\$1, 0, \\$2, NULLIF\\(\\$3, ''\\), NULLIF\\(\\$4, ''\\), \\$5\\) RETURNING created_at, updated_at").
+			WithArgs("abc-123", "EUR", "customer-1", "Savings", []byte(`{"tier":"gold"}`)).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+
+		wallet, err := repositories.CreateWallet(db, models.Wallet{
+			Id: "abc-123", Currency: "EUR", Owner: "customer-1", Name: "Savings", Labels: map[string]string{"tier": "gold"},
+		})
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if wallet.Id != "abc-123" || wallet.Currency != "EUR" || wallet.Balance != 0 || wallet.Status != models.WalletActive {
+			t.Errorf("unexpected wallet: %+v", wallet)
+		}
+	})
+
+	t.Run("Test 2: DB error", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("INSERT INTO wallets").WillReturnError(sql.ErrConnDone)
+
+		if _, err := repositories.CreateWallet(db, models.Wallet{Id: "abc-123", Currency: "EUR"}); !errors.Is(err, sql.ErrConnDone) {
+			t.Errorf("expected sql.ErrConnDone, got: %v", err)
+		}
+	})
+}
+
+func TestPostLedgerTransaction(t *testing.T) {
+	t.Run("Test 1: Transaction and entries inserted", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("INSERT INTO ledger_transactions \\(id, type, wallet_id, description, reference, metadata\\)\\s+"+
+			"VALUES \\(\\$1, \\$2, NULLIF\\(\\$3, ''\\)::uuid, NULLIF\\(\\$4, ''\\), NULLIF\\(\\$5, ''\\), \\$6\\) RETURNING created_at").
+			WithArgs("txn-1", "DEPOSIT", "wallet", "Order #1001", "order-1001", []byte(`{"invoice":"INV-7"}`)).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+		mock.ExpectExec("INSERT INTO ledger_entries \\(transaction_id, account_id, direction, amount, currency\\) "+
+			"VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5\\), \\(\\$6, \\$7, \\$8, \\$9, \\$10\\)").
+			WithArgs("txn-1", "cash-in", "DEBIT", int64(500), "RUB", "txn-1", "wallet", "CREDIT", int64(500), "RUB").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		err := repositories.PostLedgerTransaction(db, &models.LedgerTransaction{
+			Id:       "txn-1",
+			Type:     "DEPOSIT",
+			WalletId: "wallet",
+			OperationDetails: models.OperationDetails{
+				Description: "Order #1001",
+				Reference:   "order-1001",
+				Metadata:    map[string]string{"invoice": "INV-7"},
+			},
+			Entries: []models.LedgerEntry{
+				{AccountId: "cash-in", Direction: "DEBIT", Amount: 500, Currency: "RUB"},
+				{AccountId: "wallet", Direction: "CREDIT", Amount: 500, Currency: "RUB"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Duplicate reference", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("INSERT INTO ledger_transactions").
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "ledger_transactions_wallet_reference_idx"})
+
+		err := repositories.PostLedgerTransaction(db, &models.LedgerTransaction{
+			Id: "txn-2", Type: "DEPOSIT", WalletId: "wallet",
+			OperationDetails: models.OperationDetails{Reference: "order-1001"},
+		})
+		if !errors.Is(err, utils.ErrDuplicateReference) {
+			t.Errorf("expected ErrDuplicateReference, got: %v", err)
+		}
+	})
+
+	t.Run("Test 3: Entries insert fails", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("INSERT INTO ledger_transactions").
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+		mock.ExpectExec("INSERT INTO ledger_entries").
+			WillReturnError(sql.ErrConnDone)
+
+		err := repositories.PostLedgerTransaction(db, &models.LedgerTransaction{
+			Id:      "txn-1",
+			Type:    "DEPOSIT",
+			Entries: []models.LedgerEntry{{AccountId: "wallet", Direction: "CREDIT", Amount: 500, Currency: "RUB"}},
+		})
+		if !errors.Is(err, sql.ErrConnDone) {
+			t.Errorf("expected sql.ErrConnDone, got: %v", err)
+		}
+	})
+}
+
+func TestGetSystemAccount(t *testing.T) {
+	t.Run("Existing account", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id FROM ledger_accounts WHERE type = \\$1 AND currency = \\$2 AND wallet_id IS NULL").
+			WithArgs("CASH_IN", "EUR").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("cash-in-eur"))
+
+		id, err := repositories.GetSystemAccount(db, "CASH_IN", "EUR")
+		if err != nil || id != "cash-in-eur" {
+			t.Errorf("GetSystemAccount = %q, %v", id, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("First use", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id FROM ledger_accounts").
+			WithArgs("CASH_IN", "EUR").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectExec("INSERT INTO ledger_accounts .* ON CONFLICT \\(type, currency\\) WHERE wallet_id IS NULL DO NOTHING").
+			WithArgs(sqlmock.AnyArg(), "CASH_IN", "EUR").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT id FROM ledger_accounts").
+			WithArgs("CASH_IN", "EUR").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("cash-in-eur"))
+
+		id, err := repositories.GetSystemAccount(db, "CASH_IN", "EUR")
+		if err != nil || id != "cash-in-eur" {
+			t.Errorf("GetSystemAccount = %q, %v", id, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestChainBalance_Frozen(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE wallets SET balance").
+		WithArgs(int64(-100), "abc-123").
+		WillReturnError(&pq.Error{Code: "23514", Constraint: "wallets_frozen"})
+
+	err := repositories.ChainBalance(db, "abc-123", -100)
+	if !errors.Is(err, utils.ErrWalletFrozen) {
+		t.Errorf("expected ErrWalletFrozen, got: %v", err)
+	}
+}
+
+func TestChainBalance_Closed(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE wallets SET balance").
+		WithArgs(int64(100), "abc-123").
+		WillReturnError(&pq.Error{Code: "23514", Constraint: "wallets_closed"})
+
+	err := repositories.ChainBalance(db, "abc-123", 100)
+	if !errors.Is(err, utils.ErrWalletClosed) {
+		t.Errorf("expected ErrWalletClosed, got: %v", err)
+	}
+}
+
+func TestFindBalanceDiscrepancies(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT w.id, w.currency, w.balance, COALESCE\\(l.balance, 0\\).*WHERE w.balance <> COALESCE\\(l.balance, 0\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "currency", "balance", "ledger"}).
+			AddRow("abc-123", "RUB", 1500, 1000).
+			AddRow("def-456", "EUR", 0, 200))
+
+	discrepancies, err := repositories.FindBalanceDiscrepancies(db)
+	if err != nil {
+		t.Fatalf("expected nil, got error: %v", err)
+	}
+	if len(discrepancies) != 2 || discrepancies[0].Difference != 500 || discrepancies[1].Difference != -200 {
+		t.Errorf("unexpected discrepancies: %+v", discrepancies)
+	}
+}
+
+func TestListWallets(t *testing.T) {
+	columns := []string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}
+
+	t.Run("Test 1: Filters and cursor", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		minBalance := uint64(100)
+		from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		mock.ExpectQuery("SELECT id, balance, .* FROM wallets WHERE owner = \\$1 AND status = \\$2 AND balance >= \\$3 "+
+			"AND created_at >= \\$4 AND \\(balance, id\\) < \\(\\$5, \\$6\\) ORDER BY balance DESC, id DESC LIMIT \\$7").
+			WithArgs("customer-1", "ACTIVE", minBalance, from, uint64(5000), "abc-123", 11).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("abc-122", 4000, "RUB", "ACTIVE", false, "customer-1", "", `{"tier": "gold"}`, from, from))
+
+		wallets, err := repositories.ListWallets(db, models.WalletFilter{
+			Owner:       "customer-1",
+			Status:      "ACTIVE",
+			MinBalance:  &minBalance,
+			CreatedFrom: &from,
+			Sort:        models.WalletSortBalance,
+			Desc:        true,
+			After:       &models.WalletCursor{Balance: 5000, Id: "abc-123"},
+			Limit:       11,
+		})
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if len(wallets) != 1 || wallets[0].Owner != "customer-1" || wallets[0].Labels["tier"] != "gold" {
+			t.Errorf("unexpected wallets: %+v", wallets)
+		}
+	})
+
+	t.Run("Test 2: Name and labels", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("FROM wallets WHERE name ILIKE \\$1 ESCAPE '\\\\' AND labels @> \\$2 ORDER BY").
+			WithArgs("%50\\%\\_off%", []byte(`{"tier":"gold"}`), 10).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repositories.ListWallets(db, models.WalletFilter{
+			Name:   "50%_off",
+			Labels: map[string]string{"tier": "gold"},
+			Limit:  10,
+		})
+		if err != nil {
+			t.Errorf("expected nil, got error: %v", err)
+		}
+	})
+
+	t.Run("Test 3: No filters", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id, balance, .* FROM wallets ORDER BY created_at ASC, id ASC LIMIT \\$1").
+			WithArgs(50).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		wallets, err := repositories.ListWallets(db, models.WalletFilter{Limit: 50})
+		if err != nil || len(wallets) != 0 {
+			t.Errorf("ListWallets = %v, %v", wallets, err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import "JavaCode/internal/models"
+
+// SetWalletStatus changes a wallet's status and records the change in its
+// status history.
+//
+// Parameters:
+//   - db: transactional context holding the wallet's row lock
+//   - wallet: the wallet as locked, with its current status
+//   - status: the new status
+//   - depositsBlocked: whether the wallet refuses deposits (only for frozen wallets)
+//   - reason: reason recorded in the status history
+//
+// Returns:
+//   - nil if successful
+//   - any error on failure
+func SetWalletStatus(db Querier, wallet *models.Wallet, status string, depositsBlocked bool, reason string) error {
+	const update = `UPDATE wallets SET status = $1, deposits_blocked = $2, updated_at = NOW() WHERE id = $3`
+	if _, err := db.Exec(update, status, depositsBlocked, wallet.Id); err != nil {
+		return err
+	}
+
+	const insert = `INSERT INTO wallet_status_changes (wallet_id, from_status, to_status, reason)
+		VALUES ($1, $2, $3, $4)`
+	_, err := db.Exec(insert, wallet.Id, wallet.Status, status, reason)
+	return err
+}
+
+// ListWalletStatusChanges returns a wallet's status history, oldest first.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - walletUUID: wallet identifier
+//
+// Returns:
+//   - the status changes
+//   - any error on failure
+func ListWalletStatusChanges(db Querier, walletUUID string) ([]models.WalletStatusChange, error) {
+	const query = `SELECT id, wallet_id, from_status, to_status, reason, changed_at
+		FROM wallet_status_changes WHERE wallet_id = $1 ORDER BY id`
+	rows, err := db.Query(query, walletUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []models.WalletStatusChange
+	for rows.Next() {
+		var change models.WalletStatusChange
+		if err := rows.Scan(&change.Id, &change.WalletId, &change.FromStatus, &change.ToStatus,
+			&change.Reason, &change.ChangedTime); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
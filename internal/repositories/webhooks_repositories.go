@@ -0,0 +1,315 @@
+package repositories
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/utils"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"time"
+)
+
+const webhookColumns = `id, client_id, url, secret, events, COALESCE(wallet_id::text, ''), low_balance_threshold,
+	active, created_at`
+
+const deliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.last_status_code, COALESCE(d.last_error, ''), d.next_attempt_at, d.created_at, d.delivered_at`
+
+// scanWebhook reads a row selected with webhookColumns.
+func scanWebhook(row rowScanner) (*models.WebhookSubscription, error) {
+	var (
+		subscription models.WebhookSubscription
+		threshold    sql.NullInt64
+	)
+	err := row.Scan(&subscription.Id, &subscription.ClientId, &subscription.URL, &subscription.Secret,
+		pq.Array(&subscription.Events), &subscription.WalletId, &threshold, &subscription.Active,
+		&subscription.CreatedTime)
+	if err != nil {
+		return nil, err
+	}
+	if threshold.Valid {
+		subscription.LowBalanceThreshold = &threshold.Int64
+	}
+	return &subscription, nil
+}
+
+// scanDelivery reads a row selected with deliveryColumns, followed by dest.
+func scanDelivery(row rowScanner, dest ...any) (*models.WebhookDelivery, error) {
+	var (
+		delivery   models.WebhookDelivery
+		payload    []byte
+		statusCode sql.NullInt64
+		delivered  sql.NullTime
+	)
+	columns := append([]any{&delivery.Id, &delivery.SubscriptionId, &delivery.EventId, &delivery.EventType,
+		&payload, &delivery.Status, &delivery.Attempts, &statusCode, &delivery.LastError,
+		&delivery.NextAttemptAt, &delivery.CreatedTime, &delivered}, dest...)
+	if err := row.Scan(columns...); err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	if statusCode.Valid {
+		code := int(statusCode.Int64)
+		delivery.LastStatusCode = &code
+	}
+	if delivered.Valid {
+		delivery.DeliveredTime = &delivered.Time
+	}
+	return &delivery, nil
+}
+
+// CreateWebhookSubscription inserts a webhook endpoint.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - subscription: endpoint to insert; CreatedTime is filled in
+//
+// Returns:
+//   - nil if successful
+//   - any error on failure
+func CreateWebhookSubscription(db Querier, subscription *models.WebhookSubscription) error {
+	const query = `INSERT INTO webhook_subscriptions (id, client_id, url, secret, events, wallet_id, low_balance_threshold)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7) RETURNING created_at`
+	return db.QueryRow(query, subscription.Id, subscription.ClientId, subscription.URL, subscription.Secret,
+		pq.Array(subscription.Events), subscription.WalletId, subscription.LowBalanceThreshold).
+		Scan(&subscription.CreatedTime)
+}
+
+// GetWebhookSubscription returns a webhook endpoint by id.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - id: endpoint identifier
+//
+// Returns:
+//   - the endpoint
+//   - utils.ErrWebhookNotFound if it does not exist
+//   - any other error on failure
+func GetWebhookSubscription(db Querier, id string) (*models.WebhookSubscription, error) {
+	subscription, err := scanWebhook(db.QueryRow("SELECT "+webhookColumns+" FROM webhook_subscriptions WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, utils.ErrWebhookNotFound
+	}
+	return subscription, err
+}
+
+// ListWebhookSubscriptions returns the webhook endpoints, oldest first.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - clientID: if not empty, only the endpoints of this client
+//
+// Returns:
+//   - the endpoints
+//   - any error on failure
+func ListWebhookSubscriptions(db Querier, clientID string) ([]models.WebhookSubscription, error) {
+	rows, err := db.Query("SELECT "+webhookColumns+" FROM webhook_subscriptions "+
+		"WHERE $1 = '' OR client_id = $1 ORDER BY created_at, id", clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []models.WebhookSubscription
+	for rows.Next() {
+		subscription, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+// DeactivateWebhookSubscription stops deliveries to a webhook endpoint and
+// dead-letters its pending deliveries. The endpoint and its delivery log are kept.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - id: endpoint identifier
+//
+// Returns:
+//   - utils.ErrWebhookNotFound if the endpoint does not exist
+//   - any other error on failure
+func DeactivateWebhookSubscription(db Querier, id string) error {
+	const query = `WITH deactivated AS (
+			UPDATE webhook_subscriptions SET active = FALSE, updated_at = NOW() WHERE id = $1 RETURNING id
+		), dead AS (
+			UPDATE webhook_deliveries SET status = 'DEAD', last_error = 'webhook deactivated'
+			WHERE subscription_id IN (SELECT id FROM deactivated) AND status = 'PENDING'
+		)
+		SELECT id FROM deactivated`
+	var deactivated string
+	err := db.QueryRow(query, id).Scan(&deactivated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.ErrWebhookNotFound
+	}
+	return err
+}
+
+// InsertWebhookDeliveries queues an event for every active endpoint that
+// subscribes to one of its webhook event types.
+//
+// A low_balance delivery is only queued for endpoints whose threshold the
+// balance crossed, from at or above it to below it.
+//
+// Parameters:
+//   - db: transaction that makes the change the event describes
+//   - eventID: outbox event id, shared by the deliveries
+//   - eventTypes: webhook event types the event matches
+//   - walletID: wallet the event belongs to
+//   - payload: event data
+//   - balanceBefore, balanceAfter: wallet balance around the change
+//
+// Returns:
+//   - the number of deliveries queued
+//   - any error on failure
+func InsertWebhookDeliveries(db Querier, eventID string, eventTypes []string, walletID string, payload []byte,
+	balanceBefore, balanceAfter int64) (int64, error) {
+	const query = `INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT s.id, $1, t.type, $2
+		FROM webhook_subscriptions s JOIN unnest($3::text[]) AS t(type) ON t.type = ANY(s.events)
+		WHERE s.active AND (s.wallet_id IS NULL OR s.wallet_id = $4)
+			AND (t.type <> 'low_balance' OR ($5 >= s.low_balance_threshold AND $6 < s.low_balance_threshold))`
+	result, err := db.Exec(query, eventID, payload, pq.Array(eventTypes), walletID, balanceBefore, balanceAfter)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// LeaseWebhookDeliveries claims the pending deliveries that are due, oldest
+// first, by moving their next attempt to leaseUntil. Deliveries locked by
+// other workers are skipped, and the claimed ones are not due again until
+// the lease expires.
+//
+// Parameters:
+//   - db: DB connection; the claim commits on its own
+//   - limit: maximum number of deliveries
+//   - leaseUntil: time the claimed deliveries become due again if their outcome is not recorded
+//
+// Returns:
+//   - the deliveries with their endpoint
+//   - any error on failure
+func LeaseWebhookDeliveries(db Querier, limit int, leaseUntil time.Time) ([]models.PendingWebhookDelivery, error) {
+	query := `WITH due AS (
+			SELECT id AS due_id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, id LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d SET next_attempt_at = $2
+		FROM due, webhook_subscriptions s
+		WHERE d.id = due_id AND s.id = d.subscription_id
+		RETURNING ` + deliveryColumns + ", s.url, s.secret"
+	rows, err := db.Query(query, limit, leaseUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.PendingWebhookDelivery
+	for rows.Next() {
+		var pending models.PendingWebhookDelivery
+		delivery, err := scanDelivery(rows, &pending.URL, &pending.Secret)
+		if err != nil {
+			return nil, err
+		}
+		pending.WebhookDelivery = *delivery
+		deliveries = append(deliveries, pending)
+	}
+	return deliveries, rows.Err()
+}
+
+// MarkWebhookDelivered records a successful delivery.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - id: delivery id
+//   - statusCode: HTTP status of the response
+//
+// Returns:
+//   - any error on failure
+func MarkWebhookDelivered(db Querier, id int64, statusCode int) error {
+	const query = `UPDATE webhook_deliveries SET status = 'DELIVERED', attempts = attempts + 1,
+		last_status_code = $2, last_error = NULL, delivered_at = NOW() WHERE id = $1 AND status = 'PENDING'`
+	_, err := db.Exec(query, id, statusCode)
+	return err
+}
+
+// MarkWebhookDeliveryFailed records a failed delivery attempt.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - id: delivery id
+//   - statusCode: HTTP status of the response, 0 if there was none
+//   - reason: delivery error
+//   - nextAttempt: earliest time of the next attempt
+//   - dead: whether the delivery ran out of attempts
+//
+// Returns:
+//   - any error on failure
+func MarkWebhookDeliveryFailed(db Querier, id int64, statusCode int, reason string, nextAttempt time.Time, dead bool) error {
+	status := models.DeliveryPending
+	if dead {
+		status = models.DeliveryDead
+	}
+	const query = `UPDATE webhook_deliveries SET status = $2, attempts = attempts + 1,
+		last_status_code = NULLIF($3, 0), last_error = $4, next_attempt_at = $5 WHERE id = $1 AND status = 'PENDING'`
+	_, err := db.Exec(query, id, status, statusCode, reason, nextAttempt)
+	return err
+}
+
+// ListWebhookDeliveries returns the most recent deliveries of an endpoint, newest first.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - subscriptionID: endpoint identifier
+//   - status: if not empty, only deliveries with this status
+//   - limit: maximum number of deliveries
+//
+// Returns:
+//   - the deliveries
+//   - any error on failure
+func ListWebhookDeliveries(db Querier, subscriptionID, status string, limit int) ([]models.WebhookDelivery, error) {
+	query := "SELECT " + deliveryColumns + ` FROM webhook_deliveries d
+		WHERE d.subscription_id = $1 AND ($2 = '' OR d.status = $2) ORDER BY d.id DESC LIMIT $3`
+	rows, err := db.Query(query, subscriptionID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// RequeueWebhookDelivery makes a dead delivery of an active endpoint
+// pending again, with a fresh set of attempts.
+//
+// Parameters:
+//   - db: DB connection or transaction
+//   - subscriptionID: endpoint identifier
+//   - id: delivery id
+//
+// Returns:
+//   - whether a dead delivery was requeued
+//   - any error on failure
+func RequeueWebhookDelivery(db Querier, subscriptionID string, id int64) (bool, error) {
+	const query = `UPDATE webhook_deliveries d SET status = 'PENDING', attempts = 0, next_attempt_at = NOW()
+		FROM webhook_subscriptions s
+		WHERE d.id = $1 AND d.subscription_id = $2 AND d.status = 'DEAD' AND s.id = d.subscription_id AND s.active`
+	result, err := db.Exec(query, id, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -4,7 +4,6 @@ import (
 	_ "JavaCode/docs"
 	"JavaCode/internal/controllers"
 	"JavaCode/internal/middleware"
-	"database/sql"
 	"github.com/gin-gonic/gin"
 	"github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -14,17 +13,56 @@ import (
 //
 // It registers API version groups, binds handlers to endpoints,
 // and returns the fully configured *gin.Engine instance.
-func SetupRouter(db *sql.DB) *gin.Engine {
+func SetupRouter(controller *controllers.Controller) *gin.Engine {
 	router := gin.Default()
-	controller := controllers.Controller{DB: db}
 
 	apiV1Group := router.Group("/api/v1")
 	apiV1Group.Use(middleware.Logger())
 	{
+		apiV1Group.POST("wallets", controller.CreateWalletHandler)
+		apiV1Group.GET("wallets", controller.ListWalletsHandler)
 		apiV1Group.GET("wallets/:WALLET_UUID", controller.GetBalanceHandler)
+		apiV1Group.PATCH("wallets/:WALLET_UUID", controller.UpdateWalletHandler)
+		apiV1Group.GET("wallets/:WALLET_UUID/stream", controller.StreamWalletHandler)
 		apiV1Group.POST("wallet", controller.WalletOperationHandler)
+		apiV1Group.POST("transfers", controller.TransferHandler)
+		apiV1Group.GET("transfers/:TRANSFER_ID", controller.GetTransferHandler)
+
+		apiV1Group.POST("admin/rates", controller.CreateExchangeRateHandler)
+		apiV1Group.GET("admin/rates", controller.ListExchangeRatesHandler)
+		apiV1Group.GET("admin/wallets/:WALLET_UUID/entries", controller.ListLedgerEntriesHandler)
+		apiV1Group.GET("admin/wallets/:WALLET_UUID/verify", controller.VerifyWalletBalanceHandler)
+		apiV1Group.POST("admin/wallets/:WALLET_UUID/freeze", controller.FreezeWalletHandler)
+		apiV1Group.POST("admin/wallets/:WALLET_UUID/unfreeze", controller.UnfreezeWalletHandler)
+		apiV1Group.POST("admin/wallets/:WALLET_UUID/close", controller.CloseWalletHandler)
+		apiV1Group.GET("admin/wallets/:WALLET_UUID/status-changes", controller.ListWalletStatusChangesHandler)
+
+		apiV1Group.POST("webhooks", controller.CreateWebhookHandler)
+		apiV1Group.GET("webhooks", controller.ListWebhooksHandler)
+		apiV1Group.GET("webhooks/:WEBHOOK_ID", controller.GetWebhookHandler)
+		apiV1Group.DELETE("webhooks/:WEBHOOK_ID", controller.DeleteWebhookHandler)
+		apiV1Group.GET("webhooks/:WEBHOOK_ID/deliveries", controller.ListWebhookDeliveriesHandler)
+		apiV1Group.POST("webhooks/:WEBHOOK_ID/deliveries/:DELIVERY_ID/retry", controller.RetryWebhookDeliveryHandler)
+
+		apiV1Group.POST("scheduled-operations", controller.CreateScheduledOperationHandler)
+		apiV1Group.GET("scheduled-operations", controller.ListScheduledOperationsHandler)
+		apiV1Group.GET("scheduled-operations/:SCHEDULE_ID", controller.GetScheduledOperationHandler)
+		apiV1Group.DELETE("scheduled-operations/:SCHEDULE_ID", controller.CancelScheduledOperationHandler)
+		apiV1Group.GET("scheduled-operations/:SCHEDULE_ID/runs", controller.ListScheduledRunsHandler)
 	}
 
+	apiV2Group := router.Group("/api/v2")
+	apiV2Group.Use(middleware.Logger())
+	{
+		apiV2Group.POST("wallets", controller.CreateWalletV2Handler)
+		apiV2Group.GET("wallets/:WALLET_UUID", controller.GetBalanceV2Handler)
+		apiV2Group.POST("wallet", controller.WalletOperationV2Handler)
+		apiV2Group.POST("transfers", controller.TransferV2Handler)
+	}
+
+	router.GET("/healthz", controller.LivenessHandler)
+	router.GET("/readyz", controller.ReadinessHandler)
+
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	return router
 }
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/repositories"
+	"JavaCode/pkg/currency"
+	"JavaCode/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math"
+	"strings"
+)
+
+// exportBatchSize is the number of rows read at once by the exports.
+const exportBatchSize = 1000
+
+// OpeningBalanceDescription describes opening-balance transactions that
+// have no description of their own.
+const OpeningBalanceDescription = "Opening balance"
+
+// ImportStatusReason is recorded in the status history of wallets imported
+// as frozen or closed.
+const ImportStatusReason = "imported"
+
+// ImportWalletService creates a wallet with an opening balance.
+//
+// The balance is posted to the ledger as an opening-balance transaction
+// from the OPENING account of the currency, carrying the record's
+// description, reference and metadata. A frozen or closed wallet gets its
+// status afterwards, recorded in its status history. Everything happens in
+// one transaction, which a dry run rolls back, so that a dry run checks
+// the record against the database as well. No balance events are written:
+// the wallet did not exist before.
+//
+// It returns:
+//   - the created wallet;
+//   - utils.ErrInvalidRequest if the id, status, metadata or details are invalid;
+//   - utils.ErrUnsupportedCurrency if the currency is not supported;
+//   - utils.ErrAmountOverflow if the balance does not fit in 63 bits;
+//   - utils.ErrWalletNotEmpty if a closed wallet has a balance;
+//   - utils.ErrWalletExists if a wallet with the id exists;
+//   - utils.ErrDatabase on any other failure.
+func ImportWalletService(ctx context.Context, db *sql.DB, record models.WalletImport, dryRun bool) (*models.Wallet, error) {
+	wallet := models.Wallet{
+		Id:              strings.TrimSpace(record.Id),
+		Currency:        currency.Normalize(record.Currency),
+		Status:          strings.ToUpper(strings.TrimSpace(record.Status)),
+		DepositsBlocked: record.DepositsBlocked,
+		Owner:           strings.TrimSpace(record.Owner),
+		Name:            strings.TrimSpace(record.Name),
+		Labels:          record.Labels,
+	}
+	if wallet.Id == "" {
+		wallet.Id = uuid.NewString()
+	} else if id, err := uuid.Parse(wallet.Id); err != nil {
+		return nil, utils.ErrInvalidRequest
+	} else {
+		wallet.Id = id.String()
+	}
+	if wallet.Currency == "" {
+		return nil, utils.ErrInvalidRequest
+	}
+	if !currency.IsSupported(wallet.Currency) {
+		return nil, utils.ErrUnsupportedCurrency
+	}
+	if err := validateWalletMetadata(&wallet); err != nil {
+		return nil, err
+	}
+	if record.Balance > math.MaxInt64 {
+		return nil, utils.ErrAmountOverflow
+	}
+	switch wallet.Status {
+	case "", models.WalletActive:
+		wallet.Status = models.WalletActive
+		if wallet.DepositsBlocked {
+			return nil, utils.ErrInvalidRequest
+		}
+	case models.WalletFrozen:
+	case models.WalletClosed:
+		if record.Balance != 0 {
+			return nil, utils.ErrWalletNotEmpty
+		}
+	default:
+		return nil, utils.ErrInvalidRequest
+	}
+
+	details := record.OperationDetails
+	details.Description = strings.TrimSpace(details.Description)
+	details.Reference = strings.TrimSpace(details.Reference)
+	if details.Description == "" {
+		details.Description = OpeningBalanceDescription
+	}
+	if err := validateOperationDetails(details); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: begin tx: %v", utils.ErrDatabase, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	status, depositsBlocked := wallet.Status, wallet.DepositsBlocked
+	created, err := repositories.CreateWallet(tx, wallet)
+	if err != nil {
+		if errors.Is(err, utils.ErrWalletExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	if record.Balance > 0 {
+		opening, err := repositories.GetSystemAccount(tx, models.AccountOpening, created.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+		}
+		txn := &models.LedgerTransaction{
+			Id:               uuid.NewString(),
+			Type:             models.LedgerOpening,
+			WalletId:         created.Id,
+			OperationDetails: details,
+		}
+		ledgerMove(txn, opening, created.Id, int64(record.Balance), created.Currency)
+		if err := repositories.PostLedgerTransaction(tx, txn); err != nil {
+			return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+		}
+		if err := repositories.ChainBalance(tx, created.Id, int64(record.Balance)); err != nil {
+			return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+		}
+		created.Balance = record.Balance
+	}
+
+	if status != models.WalletActive {
+		if err := repositories.SetWalletStatus(tx, created, status, depositsBlocked, ImportStatusReason); err != nil {
+			return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+		}
+		created.Status, created.DepositsBlocked = status, depositsBlocked
+	}
+
+	if dryRun {
+		return created, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: commit: %v", utils.ErrDatabase, err)
+	}
+	return created, nil
+}
+
+// ExportWalletsService passes every wallet to send, oldest first.
+//
+// The wallets are read in batches from one snapshot of the database, so
+// the export is consistent however long it takes and whatever its size.
+//
+// It returns:
+//   - nil once every wallet was sent;
+//   - the error of send, which stops the export;
+//   - utils.ErrDatabase on failure.
+func ExportWalletsService(ctx context.Context, db *sql.DB, send func(*models.Wallet) error) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("%w: begin tx: %v", utils.ErrDatabase, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	filter := models.WalletFilter{Sort: models.WalletSortCreated, Limit: exportBatchSize}
+	for {
+		wallets, err := repositories.ListWallets(tx, filter)
+		if err != nil {
+			return fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+		}
+		for i := range wallets {
+			if err := send(&wallets[i]); err != nil {
+				return err
+			}
+		}
+		if len(wallets) < exportBatchSize {
+			return nil
+		}
+		last := wallets[len(wallets)-1]
+		filter.After = &models.WalletCursor{Sort: filter.Sort, CreatedTime: last.CreatedTime, Id: last.Id}
+	}
+}
+
+// ExportLedgerEntriesService passes the ledger entries of every wallet to
+// send, oldest first, read in batches from one snapshot of the database.
+//
+// It returns:
+//   - nil once every entry was sent;
+//   - the error of send, which stops the export;
+//   - utils.ErrDatabase on failure.
+func ExportLedgerEntriesService(ctx context.Context, db *sql.DB, send func(*models.LedgerEntry) error) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("%w: begin tx: %v", utils.ErrDatabase, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var afterID int64
+	for {
+		entries, err := repositories.ListWalletLedgerEntries(tx, afterID, exportBatchSize)
+		if err != nil {
+			return fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+		}
+		for i := range entries {
+			if err := send(&entries[i]); err != nil {
+				return err
+			}
+		}
+		if len(entries) < exportBatchSize {
+			return nil
+		}
+		afterID = entries[len(entries)-1].Id
+	}
+}
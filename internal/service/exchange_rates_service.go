@@ -0,0 +1,74 @@
+package service
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/repositories"
+	"JavaCode/pkg/currency"
+	"JavaCode/utils"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CreateExchangeRateService validates and stores a new exchange rate.
+//
+// A missing ValidFrom defaults to the current time.
+//
+// It returns:
+//   - the stored rate;
+//   - utils.ErrUnsupportedCurrency if either currency is not supported;
+//   - utils.ErrInvalidRequest if the rate, rounding mode or validity period is invalid;
+//   - utils.ErrDatabase if the insert fails.
+func CreateExchangeRateService(db *sql.DB, request models.CreateExchangeRateRequest) (*models.ExchangeRate, error) {
+	base := currency.Normalize(request.BaseCurrency)
+	quote := currency.Normalize(request.QuoteCurrency)
+	if !currency.IsSupported(base) || !currency.IsSupported(quote) {
+		return nil, utils.ErrUnsupportedCurrency
+	}
+	if base == quote {
+		return nil, fmt.Errorf("%w: base and quote currencies are the same", utils.ErrInvalidRequest)
+	}
+
+	units, err := currency.ParseRate(request.Rate, request.Precision)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidRequest, err)
+	}
+	if !currency.IsRoundingMode(currency.RoundingMode(request.RoundingMode)) {
+		return nil, fmt.Errorf("%w: unknown rounding mode %q", utils.ErrInvalidRequest, request.RoundingMode)
+	}
+
+	validFrom := time.Now()
+	if request.ValidFrom != nil {
+		validFrom = *request.ValidFrom
+	}
+	if request.ValidTo != nil && !request.ValidTo.After(validFrom) {
+		return nil, fmt.Errorf("%w: validTo must be after validFrom", utils.ErrInvalidRequest)
+	}
+
+	rate := &models.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		RateUnits:     units,
+		Precision:     request.Precision,
+		RoundingMode:  request.RoundingMode,
+		ValidFrom:     validFrom,
+		ValidTo:       request.ValidTo,
+	}
+	if err := repositories.CreateExchangeRate(db, rate); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return rate, nil
+}
+
+// ListExchangeRatesService returns the stored rates, optionally filtered by currency pair.
+//
+// It returns:
+//   - the rates, newest first within each pair;
+//   - utils.ErrDatabase if the query fails.
+func ListExchangeRatesService(db *sql.DB, base, quote string) ([]models.ExchangeRate, error) {
+	rates, err := repositories.ListExchangeRates(db, currency.Normalize(base), currency.Normalize(quote))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return rates, nil
+}
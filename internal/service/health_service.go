@@ -0,0 +1,29 @@
+package service
+
+import (
+	"JavaCode/pkg/migrate"
+	"JavaCode/utils"
+	"database/sql"
+	"fmt"
+)
+
+// ReadinessService checks that the database is reachable and its schema
+// is at least at expectedVersion (0 skips the schema check).
+//
+// It returns:
+//   - the applied schema version on success;
+//   - an error wrapping utils.ErrNotReady otherwise.
+func ReadinessService(db *sql.DB, expectedVersion int64) (int64, error) {
+	if err := db.Ping(); err != nil {
+		return 0, fmt.Errorf("%w: database unreachable", utils.ErrNotReady)
+	}
+
+	version, err := migrate.Version(db)
+	if err != nil {
+		return 0, fmt.Errorf("%w: schema version unavailable", utils.ErrNotReady)
+	}
+	if version < expectedVersion {
+		return version, fmt.Errorf("%w: schema version %d, want %d", utils.ErrNotReady, version, expectedVersion)
+	}
+	return version, nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/repositories"
+	"JavaCode/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// DefaultLedgerLimit is the number of entries returned when no limit is given.
+const DefaultLedgerLimit = 100
+
+// MaxLedgerLimit caps the number of entries returned at once.
+const MaxLedgerLimit = 1000
+
+// ledgerMove appends a balanced pair of entries to txn that moves amount
+// from one account to another.
+func ledgerMove(txn *models.LedgerTransaction, from, to string, amount int64, currencyCode string) {
+	txn.Entries = append(txn.Entries,
+		models.LedgerEntry{AccountId: from, Direction: models.Debit, Amount: amount, Currency: currencyCode},
+		models.LedgerEntry{AccountId: to, Direction: models.Credit, Amount: amount, Currency: currencyCode},
+	)
+}
+
+// postOperation records a deposit as a move from the cash-in account to the
+// wallet, and a withdrawal as a move from the wallet to the cash-out account,
+// together with the operation's details.
+func postOperation(tx *sql.Tx, txnID string, wallet *models.Wallet, operationType string, amount int64,
+	details models.OperationDetails) error {
+	txn := &models.LedgerTransaction{Id: txnID, WalletId: wallet.Id, OperationDetails: details}
+	switch operationType {
+	case DEPOSIT:
+		cashIn, err := repositories.GetSystemAccount(tx, models.AccountCashIn, wallet.Currency)
+		if err != nil {
+			return err
+		}
+		txn.Type = models.LedgerDeposit
+		ledgerMove(txn, cashIn, wallet.Id, amount, wallet.Currency)
+	case WITHDRAW:
+		cashOut, err := repositories.GetSystemAccount(tx, models.AccountCashOut, wallet.Currency)
+		if err != nil {
+			return err
+		}
+		txn.Type = models.LedgerWithdraw
+		ledgerMove(txn, wallet.Id, cashOut, amount, wallet.Currency)
+	default:
+		return fmt.Errorf("unknown operation type %q", operationType)
+	}
+	return repositories.PostLedgerTransaction(tx, txn)
+}
+
+// postTransfer records a transfer under the transfer's id. Cross-currency
+// transfers go through the FX account of each currency, so that every
+// currency balances on its own.
+func postTransfer(tx *sql.Tx, transfer *models.Transfer) error {
+	txn := &models.LedgerTransaction{Id: transfer.Id, Type: models.LedgerTransfer}
+	if transfer.SourceCurrency == transfer.TargetCurrency {
+		ledgerMove(txn, transfer.FromWalletId, transfer.ToWalletId, transfer.SourceAmount, transfer.SourceCurrency)
+		return repositories.PostLedgerTransaction(tx, txn)
+	}
+
+	fxSource, err := repositories.GetSystemAccount(tx, models.AccountFX, transfer.SourceCurrency)
+	if err != nil {
+		return err
+	}
+	fxTarget, err := repositories.GetSystemAccount(tx, models.AccountFX, transfer.TargetCurrency)
+	if err != nil {
+		return err
+	}
+	ledgerMove(txn, transfer.FromWalletId, fxSource, transfer.SourceAmount, transfer.SourceCurrency)
+	ledgerMove(txn, fxTarget, transfer.ToWalletId, transfer.TargetAmount, transfer.TargetCurrency)
+	return repositories.PostLedgerTransaction(tx, txn)
+}
+
+// VerifyWalletBalanceService compares a wallet's stored balance with the sum
+// of its ledger entries, reading both from the same snapshot.
+//
+// It returns:
+//   - the comparison;
+//   - utils.ErrWalletNotFound if the wallet does not exist;
+//   - utils.ErrDatabase on any other failure.
+func VerifyWalletBalanceService(db *sql.DB, walletUUID string) (*models.BalanceVerification, error) {
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	wallet, err := repositories.GetWalletByUUID(tx, walletUUID)
+	if err != nil {
+		if errors.Is(err, utils.ErrWalletNotFound) {
+			return nil, utils.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	ledgerBalance, err := repositories.GetLedgerBalance(tx, walletUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	return &models.BalanceVerification{
+		WalletId:      wallet.Id,
+		Currency:      wallet.Currency,
+		Balance:       wallet.Balance,
+		LedgerBalance: ledgerBalance,
+		Consistent:    ledgerBalance >= 0 && uint64(ledgerBalance) == wallet.Balance,
+	}, nil
+}
+
+// ListLedgerEntriesService returns the most recent ledger entries of a wallet,
+// only those of the operation with the given reference if it is not empty.
+//
+// A limit of 0 means DefaultLedgerLimit; larger limits are capped at MaxLedgerLimit.
+//
+// It returns:
+//   - the entries, newest first;
+//   - utils.ErrWalletNotFound if the wallet does not exist;
+//   - utils.ErrDatabase on any other failure.
+func ListLedgerEntriesService(db *sql.DB, walletUUID, reference string, limit int) ([]models.LedgerEntry, error) {
+	if _, err := GetWalletsService(db, walletUUID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultLedgerLimit
+	}
+	if limit > MaxLedgerLimit {
+		limit = MaxLedgerLimit
+	}
+
+	entries, err := repositories.ListLedgerEntries(db, walletUUID, reference, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return entries, nil
+}
+
+// StreamLedgerEntriesService sends the ledger entries of a wallet to send,
+// newest first, in pages of at most MaxLedgerLimit entries, only those of
+// the operation with the given reference if it is not empty.
+//
+// A limit of 0 sends the whole history. Paging stops early when ctx is done.
+//
+// It returns:
+//   - nil once the entries have been sent;
+//   - utils.ErrWalletNotFound if the wallet does not exist;
+//   - the error of send or ctx, which ends the stream;
+//   - utils.ErrDatabase on any other failure.
+func StreamLedgerEntriesService(ctx context.Context, db *sql.DB, walletUUID, reference string, limit int,
+	send func([]models.LedgerEntry) error) error {
+	if _, err := GetWalletsService(db, walletUUID); err != nil {
+		return err
+	}
+
+	var beforeID int64 = math.MaxInt64
+	for sent := 0; limit <= 0 || sent < limit; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page := MaxLedgerLimit
+		if limit > 0 {
+			page = min(page, limit-sent)
+		}
+		entries, err := repositories.ListLedgerEntriesBefore(db, walletUUID, reference, beforeID, page)
+		if err != nil {
+			return fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		if err := send(entries); err != nil {
+			return err
+		}
+		sent += len(entries)
+		beforeID = entries[len(entries)-1].Id
+		if len(entries) < page {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/outbox"
+	"JavaCode/internal/repositories"
+	"JavaCode/utils"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+// retryInitialBackoff is the wait before the first redelivery of an event
+// or webhook; it doubles with every failed attempt.
+const retryInitialBackoff = time.Second
+
+// recordBalanceChanged writes a wallet.balance_changed event to the outbox
+// in the transaction that changes the balance, and queues it for the
+// webhooks subscribed to it. wallet holds the balance before the change.
+func recordBalanceChanged(tx *sql.Tx, wallet *models.Wallet, balance uint64, delta int64,
+	operation, txnID, reference string) error {
+	payload := models.BalanceChangedEvent{
+		EventId:       uuid.NewString(),
+		Type:          models.EventBalanceChanged,
+		WalletId:      wallet.Id,
+		Currency:      wallet.Currency,
+		Balance:       balance,
+		Delta:         delta,
+		Operation:     operation,
+		TransactionId: txnID,
+		Reference:     reference,
+		OccurredAt:    time.Now().UTC(),
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	err = repositories.InsertOutboxEvent(tx, &models.OutboxEvent{
+		EventId:  payload.EventId,
+		Type:     payload.Type,
+		WalletId: payload.WalletId,
+		Payload:  data,
+	})
+	if err != nil {
+		return err
+	}
+
+	eventTypes := []string{models.WebhookDeposit}
+	if delta < 0 {
+		eventTypes = []string{models.WebhookWithdrawal, models.WebhookLowBalance}
+	}
+	_, err = repositories.InsertWebhookDeliveries(tx, payload.EventId, eventTypes, wallet.Id, data,
+		int64(wallet.Balance), int64(balance))
+	return err
+}
+
+// recordStatusChanged writes a wallet.status_changed event to the outbox in
+// the transaction that changes the status, and queues freezes for the
+// webhooks subscribed to them.
+func recordStatusChanged(tx *sql.Tx, walletID, fromStatus, toStatus string, depositsBlocked bool, reason string) error {
+	payload := models.WalletStatusChangedEvent{
+		EventId:         uuid.NewString(),
+		Type:            models.EventStatusChanged,
+		WalletId:        walletID,
+		FromStatus:      fromStatus,
+		ToStatus:        toStatus,
+		DepositsBlocked: depositsBlocked,
+		Reason:          reason,
+		OccurredAt:      time.Now().UTC(),
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	err = repositories.InsertOutboxEvent(tx, &models.OutboxEvent{
+		EventId:  payload.EventId,
+		Type:     payload.Type,
+		WalletId: payload.WalletId,
+		Payload:  data,
+	})
+	if err != nil || toStatus != models.WalletFrozen {
+		return err
+	}
+
+	_, err = repositories.InsertWebhookDeliveries(tx, payload.EventId, []string{models.WebhookFreeze}, walletID, data, 0, 0)
+	return err
+}
+
+// DispatchOutboxService delivers up to limit pending outbox events to sink.
+// Only the instance holding the outbox lock may call it, so that events are
+// published in order.
+//
+// Events are published in id order, outside any transaction, and each
+// outcome is recorded on its own. When an event fails, it is scheduled for
+// a retry with exponential backoff capped at maxBackoff, and the later
+// events of its wallet are held back until it is delivered. The later
+// events of a wallet are also held back when an outcome cannot be
+// recorded; that event is published again on the next run.
+//
+// It returns:
+//   - the number of events delivered;
+//   - the number of events that were pending, which equals limit if more may be waiting;
+//   - utils.ErrDatabase if the events could not be read or an outcome could
+//     not be recorded; the events of other wallets are still published.
+func DispatchOutboxService(ctx context.Context, db *sql.DB, sink outbox.Sink, limit int,
+	maxBackoff time.Duration) (int, int, error) {
+	events, err := repositories.ListPendingOutboxEvents(db, limit)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	delivered := 0
+	var failure error
+	blocked := map[string]bool{}
+	for _, event := range events {
+		if blocked[event.WalletId] {
+			continue
+		}
+		published, err := dispatchOutboxEvent(ctx, db, sink, event, maxBackoff)
+		if err != nil {
+			utils.Logger.WithError(err).Errorf("outbox event %d outcome not recorded", event.Id)
+			if failure == nil {
+				failure = fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+			}
+		}
+		if !published || err != nil {
+			blocked[event.WalletId] = true
+			continue
+		}
+		delivered++
+	}
+	return delivered, len(events), failure
+}
+
+// dispatchOutboxEvent publishes an event to sink and records the outcome.
+//
+// It returns:
+//   - whether the event was published;
+//   - the error of recording the outcome.
+func dispatchOutboxEvent(ctx context.Context, db *sql.DB, sink outbox.Sink, event models.OutboxEvent,
+	maxBackoff time.Duration) (bool, error) {
+	if err := sink.Publish(ctx, event); err != nil {
+		utils.Logger.WithError(err).Warnf("outbox event %d delivery failed (attempt %d)", event.Id, event.Attempts+1)
+		next := time.Now().Add(retryBackoff(event.Attempts, maxBackoff))
+		return false, repositories.MarkOutboxEventFailed(db, event.Id, err.Error(), next)
+	}
+	return true, repositories.MarkOutboxEventPublished(db, event.Id)
+}
+
+// PruneOutboxService removes events past their retention. With a
+// dispatcher, events are kept until retention after their delivery;
+// without one nothing delivers them, so they are removed retention after
+// they were recorded.
+//
+// It returns:
+//   - the number of events removed;
+//   - utils.ErrDatabase on failure.
+func PruneOutboxService(db *sql.DB, retention time.Duration, dispatched bool) (int64, error) {
+	prune := repositories.DeleteOutboxEvents
+	if dispatched {
+		prune = repositories.DeletePublishedOutboxEvents
+	}
+	removed, err := prune(db, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return removed, nil
+}
+
+// retryBackoff returns the wait before retrying a delivery that has failed
+// attempts times before, capped at maxBackoff.
+func retryBackoff(attempts int, maxBackoff time.Duration) time.Duration {
+	backoff := retryInitialBackoff
+	for i := 0; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
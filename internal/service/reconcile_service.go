@@ -0,0 +1,100 @@
+package service
+
+import (
+	"JavaCode/internal/cache"
+	"JavaCode/internal/models"
+	"JavaCode/internal/repositories"
+	"JavaCode/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ReconcileService recomputes every wallet's balance from the ledger and
+// reports the wallets whose stored balance differs.
+//
+// Balances and ledger entries are read from a single snapshot, so
+// concurrent operations never show up as discrepancies. If freeze is set,
+// mismatched wallets are frozen afterwards and invalidated in balances
+// (which may be nil). If another instance is reconciling the call does
+// nothing.
+//
+// It returns:
+//   - the report, or nil if another instance is reconciling;
+//   - utils.ErrDatabase if the check or the freeze fails.
+func ReconcileService(db *sql.DB, balances *cache.Balances, freeze bool) (*models.ReconcileReport, error) {
+	report := &models.ReconcileReport{StartedAt: time.Now(), Discrepancies: []models.Discrepancy{}}
+
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	// The snapshot, and with it the lock, is kept until the freeze is done.
+	defer func() { _ = tx.Rollback() }()
+
+	locked, err := repositories.TryLockReconcile(tx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	if report.WalletsChecked, err = repositories.CountWallets(tx); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	discrepancies, err := repositories.FindBalanceDiscrepancies(tx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if discrepancies != nil {
+		report.Discrepancies = discrepancies
+	}
+
+	if freeze && len(discrepancies) > 0 {
+		ids := make([]string, 0, len(discrepancies))
+		for _, d := range discrepancies {
+			ids = append(ids, d.WalletId)
+		}
+		frozen, err := freezeWallets(db, ids, "balance does not match the ledger")
+		for _, id := range ids {
+			balances.Invalidate(id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: freeze wallets: %v", utils.ErrDatabase, err)
+		}
+
+		frozenSet := make(map[string]bool, len(frozen))
+		for _, id := range frozen {
+			frozenSet[id] = true
+		}
+		for i := range report.Discrepancies {
+			report.Discrepancies[i].Frozen = frozenSet[report.Discrepancies[i].WalletId]
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// freezeWallets freezes the active wallets among ids, writing a
+// wallet.status_changed event for each in the same transaction.
+func freezeWallets(db *sql.DB, ids []string, reason string) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	frozen, err := repositories.FreezeWallets(tx, ids, reason)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range frozen {
+		if err := recordStatusChanged(tx, id, models.WalletActive, models.WalletFrozen, false, reason); err != nil {
+			return nil, err
+		}
+	}
+	return frozen, tx.Commit()
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"JavaCode/internal/repositories"
+	"JavaCode/utils"
+	"database/sql"
+)
+
+// ConsistencyTokenService returns a read-your-writes token for the primary.
+//
+// The token is the primary's current WAL position; any replica that has
+// replayed up to it is guaranteed to observe every previously committed write.
+//
+// It returns:
+//   - the token on success;
+//   - utils.ErrDatabase if the position cannot be read.
+func ConsistencyTokenService(db *sql.DB) (string, error) {
+	lsn, err := repositories.GetCurrentWalLSN(db)
+	if err != nil {
+		return "", utils.ErrDatabase
+	}
+	return lsn, nil
+}
+
+// SelectReadDBService chooses the connection a read should be served from.
+//
+// It returns the replica when no token is given or when the replica has
+// already replayed up to the token, and the primary otherwise.
+// A replica that cannot be queried is treated as lagging.
+func SelectReadDBService(primary, replica *sql.DB, token string) *sql.DB {
+	if replica == nil {
+		return primary
+	}
+	if token == "" {
+		return replica
+	}
+
+	caughtUp, err := repositories.IsReplayedUpTo(replica, token)
+	if err != nil {
+		utils.Logger.WithError(err).Warn("replica lag check failed, reading from primary")
+		return primary
+	}
+	if !caughtUp {
+		return primary
+	}
+	return replica
+}
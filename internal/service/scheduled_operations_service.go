@@ -0,0 +1,376 @@
+package service
+
+import (
+	"JavaCode/internal/cache"
+	"JavaCode/internal/models"
+	"JavaCode/internal/repositories"
+	"JavaCode/pkg/currency"
+	"JavaCode/pkg/recurrence"
+	"JavaCode/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MaxScheduledRetries caps the retries of a failed scheduled occurrence.
+const MaxScheduledRetries = 10
+
+// scheduleClockSkew is how far in the past a scheduled start may be, to
+// allow for clients whose clock is slightly ahead.
+const scheduleClockSkew = time.Minute
+
+// scheduledLease is how long a scheduled operation being run is kept from
+// other runs; if its outcome is not recorded by then, it is attempted again.
+const scheduledLease = 5 * time.Minute
+
+// DefaultRunLimit is the number of scheduled runs returned when no limit is given.
+const DefaultRunLimit = 100
+
+// MaxRunLimit caps the number of scheduled runs returned at once.
+const MaxRunLimit = 1000
+
+// CreateScheduledOperationService schedules a deposit or withdrawal.
+//
+// Without a schedule the operation runs once at StartAt (now if not given).
+// With one, it runs on every occurrence from StartAt up to EndAt, if given.
+// StartAt may not be in the past. A failed occurrence is retried up to
+// MaxRetries times with exponential backoff, then skipped.
+//
+// It returns:
+//   - the scheduled operation;
+//   - utils.ErrInvalidRequest if the request, its schedule or its time range is invalid;
+//   - utils.ErrNegativeBalance if the amount is not positive;
+//   - utils.ErrUnsupportedCurrency if the currency is not supported;
+//   - utils.ErrWalletNotFound if the wallet does not exist;
+//   - utils.ErrWalletClosed if the wallet is closed;
+//   - utils.ErrCurrencyMismatch if the currency differs from the wallet currency;
+//   - utils.ErrDatabase on any other failure.
+func CreateScheduledOperationService(db *sql.DB, request models.CreateScheduledOperationRequest) (*models.ScheduledOperation, error) {
+	err := ValidateOperationRequest(models.WalletOperationRequest{
+		WalletID:      request.WalletID,
+		OperationType: request.OperationType,
+		Amount:        request.Amount,
+		Currency:      request.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+	details := models.OperationDetails{
+		Description: strings.TrimSpace(request.Description),
+		Metadata:    request.Metadata,
+	}
+	if err := validateOperationDetails(details); err != nil {
+		return nil, err
+	}
+	if request.MaxRetries < 0 || request.MaxRetries > MaxScheduledRetries {
+		return nil, utils.ErrInvalidRequest
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	start := request.StartAt.UTC().Truncate(time.Second)
+	if request.StartAt.IsZero() {
+		start = now
+	}
+	if start.Before(now.Add(-scheduleClockSkew)) {
+		return nil, utils.ErrInvalidRequest
+	}
+	operation := &models.ScheduledOperation{
+		Id:            uuid.NewString(),
+		WalletId:      request.WalletID,
+		OperationType: request.OperationType,
+		Amount:        request.Amount,
+		Currency:      currency.Normalize(request.Currency),
+		Description:   details.Description,
+		Metadata:      details.Metadata,
+		Schedule:      strings.TrimSpace(request.Schedule),
+		StartTime:     start,
+		MaxRetries:    request.MaxRetries,
+		Status:        models.ScheduleActive,
+	}
+	if request.EndAt != nil {
+		if operation.Schedule == "" || request.EndAt.Before(start) {
+			return nil, utils.ErrInvalidRequest
+		}
+		end := request.EndAt.UTC()
+		operation.EndTime = &end
+	}
+
+	first := start
+	if operation.Schedule != "" {
+		schedule, err := recurrence.Parse(operation.Schedule, start)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", utils.ErrInvalidRequest, err)
+		}
+		first = schedule.Next(start.Add(-time.Second))
+		if first.IsZero() || (operation.EndTime != nil && first.After(*operation.EndTime)) {
+			return nil, utils.ErrInvalidRequest
+		}
+	}
+	operation.NextRunTime = &first
+	operation.NextAttemptTime = &first
+
+	wallet, err := GetWalletsService(db, operation.WalletId)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.Status == models.WalletClosed {
+		return nil, utils.ErrWalletClosed
+	}
+	if wallet.Currency != operation.Currency {
+		return nil, utils.ErrCurrencyMismatch
+	}
+
+	if err := repositories.CreateScheduledOperation(db, operation); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return operation, nil
+}
+
+// GetScheduledOperationService returns a scheduled operation by id.
+//
+// It returns:
+//   - the operation;
+//   - utils.ErrScheduleNotFound if it does not exist;
+//   - utils.ErrDatabase on any other failure.
+func GetScheduledOperationService(db *sql.DB, id string) (*models.ScheduledOperation, error) {
+	operation, err := repositories.GetScheduledOperation(db, id)
+	if err != nil {
+		if errors.Is(err, utils.ErrScheduleNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return operation, nil
+}
+
+// ListScheduledOperationsService returns the scheduled operations of a
+// wallet, or of all wallets if walletID is empty, oldest first.
+//
+// It returns:
+//   - the operations, empty if there are none;
+//   - utils.ErrInvalidRequest if walletID is not a UUID or status is not a schedule status;
+//   - utils.ErrDatabase on any other failure.
+func ListScheduledOperationsService(db *sql.DB, walletID, status string) ([]models.ScheduledOperation, error) {
+	if walletID != "" {
+		if err := uuid.Validate(walletID); err != nil {
+			return nil, utils.ErrInvalidRequest
+		}
+	}
+	status = strings.ToUpper(status)
+	if status != "" && status != models.ScheduleActive && status != models.ScheduleCompleted && status != models.ScheduleCancelled {
+		return nil, utils.ErrInvalidRequest
+	}
+
+	operations, err := repositories.ListScheduledOperations(db, walletID, status)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if operations == nil {
+		operations = []models.ScheduledOperation{}
+	}
+	return operations, nil
+}
+
+// CancelScheduledOperationService stops an active scheduled operation
+// before its next occurrence. Its runs are kept.
+//
+// It returns:
+//   - the cancelled operation;
+//   - utils.ErrScheduleNotFound if it does not exist;
+//   - utils.ErrScheduleNotActive if it is already completed or cancelled;
+//   - utils.ErrDatabase on any other failure.
+func CancelScheduledOperationService(db *sql.DB, id string) (*models.ScheduledOperation, error) {
+	cancelled, err := repositories.CancelScheduledOperation(db, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	operation, err := GetScheduledOperationService(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, utils.ErrScheduleNotActive
+	}
+	return operation, nil
+}
+
+// ListScheduledRunsService returns the most recent runs of a scheduled
+// operation, newest first. A limit of 0 means DefaultRunLimit; larger
+// limits are capped at MaxRunLimit.
+//
+// It returns:
+//   - the runs, empty if there are none;
+//   - utils.ErrScheduleNotFound if the operation does not exist;
+//   - utils.ErrDatabase on any other failure.
+func ListScheduledRunsService(db *sql.DB, id string, limit int) ([]models.ScheduledRun, error) {
+	if limit <= 0 {
+		limit = DefaultRunLimit
+	}
+	if limit > MaxRunLimit {
+		limit = MaxRunLimit
+	}
+
+	if _, err := GetScheduledOperationService(db, id); err != nil {
+		return nil, err
+	}
+	runs, err := repositories.ListScheduledRuns(db, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if runs == nil {
+		runs = []models.ScheduledRun{}
+	}
+	return runs, nil
+}
+
+// RunScheduledOperationsService executes up to limit scheduled operations
+// whose next attempt is due, and records every attempt as a run.
+//
+// The due operations are leased for scheduledLease in a statement of their
+// own, then executed one by one without holding any lock on them, and the
+// outcome of each is recorded in a short transaction. Each occurrence is
+// executed with a reference derived from the operation and the occurrence
+// time, so an occurrence that was executed but not recorded, e.g. because
+// the instance stopped, is not executed again when its lease expires: it
+// is reported as a duplicate, which counts as a success.
+//
+// A failed attempt, e.g. for insufficient funds, is retried with
+// exponential backoff capped at maxBackoff until the operation's retries
+// are used up; then the occurrence is skipped. Operations on a closed
+// wallet are cancelled. Occurrences missed while no scheduler was running
+// are executed late, one per call. An attempt that fails with a database
+// error is not recorded and is retried when its lease expires.
+//
+// It returns:
+//   - the number of occurrences executed successfully;
+//   - the number of operations attempted, which equals limit if more may be due;
+//   - utils.ErrDatabase if an operation could not be leased, executed or recorded.
+func RunScheduledOperationsService(ctx context.Context, db *sql.DB, balances *cache.Balances, limit int,
+	maxBackoff time.Duration) (int, int, error) {
+	operations, err := repositories.LeaseDueScheduledOperations(db, limit, time.Now().Add(scheduledLease))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	executed := 0
+	var failure error
+	for i := range operations {
+		operation := &operations[i]
+		succeeded, err := runScheduledOperation(ctx, db, balances, operation, maxBackoff)
+		if err != nil {
+			utils.Logger.WithError(err).Warnf("scheduled operation %s not recorded", operation.Id)
+			if failure == nil {
+				failure = fmt.Errorf("%w: scheduled operation %s: %v", utils.ErrDatabase, operation.Id, err)
+			}
+			continue
+		}
+		if succeeded {
+			executed++
+		}
+	}
+	return executed, len(operations), failure
+}
+
+// runScheduledOperation makes an attempt at the next occurrence of a leased
+// operation and records it. It returns whether the occurrence was executed,
+// or an error if the attempt failed with a database error or could not be
+// recorded.
+func runScheduledOperation(ctx context.Context, db *sql.DB, balances *cache.Balances,
+	operation *models.ScheduledOperation, maxBackoff time.Duration) (bool, error) {
+	runAt := *operation.NextRunTime
+	run := &models.ScheduledRun{
+		ScheduleId: operation.Id,
+		RunTime:    runAt,
+		Attempt:    operation.Attempts + 1,
+		Status:     models.RunSucceeded,
+		Reference:  scheduledReference(operation.Id, runAt),
+	}
+
+	err := HandleOperationService(db, balances, models.WalletOperationRequest{
+		WalletID:      operation.WalletId,
+		OperationType: operation.OperationType,
+		Amount:        operation.Amount,
+		Currency:      operation.Currency,
+		OperationDetails: models.OperationDetails{
+			Description: operation.Description,
+			Reference:   run.Reference,
+			Metadata:    operation.Metadata,
+		},
+	})
+	if errors.Is(err, utils.ErrDuplicateReference) {
+		err = nil
+	}
+
+	switch response := utils.NewErrorResponse(err); {
+	case err == nil:
+		advanceScheduledOperation(operation, runAt)
+	case response.Code >= http.StatusInternalServerError:
+		return false, err
+	case errors.Is(err, utils.ErrWalletClosed):
+		utils.Logger.WithError(err).Warnf("scheduled operation %s cancelled: wallet closed", operation.Id)
+		run.Status, run.Error = models.RunFailed, response.Error
+		operation.Status = models.ScheduleCancelled
+		operation.NextRunTime, operation.NextAttemptTime = nil, nil
+	case operation.Attempts < operation.MaxRetries:
+		utils.Logger.WithError(err).Warnf("scheduled operation %s failed (attempt %d)", operation.Id, run.Attempt)
+		run.Status, run.Error = models.RunFailed, response.Error
+		next := time.Now().Add(retryBackoff(operation.Attempts, maxBackoff))
+		operation.Attempts++
+		operation.NextAttemptTime = &next
+	default:
+		utils.Logger.WithError(err).Warnf("scheduled operation %s skipped %s after %d attempts",
+			operation.Id, runAt.Format(time.RFC3339), run.Attempt)
+		run.Status, run.Error = models.RunFailed, response.Error
+		advanceScheduledOperation(operation, runAt)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := repositories.InsertScheduledRun(tx, run); err != nil {
+		return false, err
+	}
+	updated, err := repositories.UpdateScheduledOperationProgress(tx, operation, runAt)
+	if err != nil {
+		return false, err
+	}
+	if !updated {
+		utils.Logger.Infof("scheduled operation %s changed during its run at %s", operation.Id, runAt.Format(time.RFC3339))
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return run.Status == models.RunSucceeded, nil
+}
+
+// scheduledReference returns the ledger reference of an occurrence, the
+// same for every attempt at it.
+func scheduledReference(id string, runAt time.Time) string {
+	return fmt.Sprintf("schedule:%s:%d", id, runAt.Unix())
+}
+
+// advanceScheduledOperation moves an operation past the occurrence at
+// runAt, completing it if there is no later occurrence before its end.
+func advanceScheduledOperation(operation *models.ScheduledOperation, runAt time.Time) {
+	var next time.Time
+	if operation.Schedule != "" {
+		if schedule, err := recurrence.Parse(operation.Schedule, operation.StartTime); err == nil {
+			next = schedule.Next(runAt)
+		}
+	}
+	if next.IsZero() || (operation.EndTime != nil && next.After(*operation.EndTime)) {
+		operation.Status = models.ScheduleCompleted
+		operation.NextRunTime, operation.NextAttemptTime = nil, nil
+		return
+	}
+	operation.Attempts = 0
+	operation.NextRunTime, operation.NextAttemptTime = &next, &next
+}
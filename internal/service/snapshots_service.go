@@ -0,0 +1,113 @@
+package service
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/repositories"
+	"JavaCode/utils"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetBalanceAtService returns a wallet as it was at the given time, with its
+// balance computed from the ledger.
+//
+// It returns:
+//   - the wallet with its historical balance;
+//   - utils.ErrInvalidRequest if at is in the future;
+//   - utils.ErrWalletNotFound if the wallet does not exist or was created after at;
+//   - utils.ErrHistoryUnavailable if at is before the wallet's opening balance
+//     was recorded in the ledger;
+//   - utils.ErrDatabase on any other failure.
+func GetBalanceAtService(db *sql.DB, walletUUID string, at time.Time) (*models.Wallet, error) {
+	if at.After(time.Now()) {
+		return nil, utils.ErrInvalidRequest
+	}
+
+	wallet, err := GetWalletsService(db, walletUUID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.CreatedTime.After(at) {
+		return nil, utils.ErrWalletNotFound
+	}
+
+	opening, err := repositories.GetLedgerOpening(db, walletUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if opening.After(at) {
+		return nil, utils.ErrHistoryUnavailable
+	}
+
+	balance, err := repositories.GetBalanceAt(db, walletUUID, at)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if balance < 0 {
+		return nil, fmt.Errorf("%w: negative ledger balance %d for wallet %s", utils.ErrDatabase, balance, walletUUID)
+	}
+
+	wallet.Balance = uint64(balance)
+	return wallet, nil
+}
+
+// TakeBalanceSnapshotsService snapshots the balance of every wallet that
+// changed since the previous run, as of lag ago.
+//
+// Entries are stamped with their transaction's start time, so an open
+// transaction may still commit entries older than the snapshot. The snapshot
+// is therefore never taken later than just before the oldest open
+// transaction started, and a long transaction holds snapshots back until it
+// ends. Both times are taken from the database clock. If another instance
+// is taking snapshots the call does nothing.
+//
+// It returns:
+//   - the number of snapshots taken;
+//   - utils.ErrDatabase on failure, or if the database role cannot see the
+//     transactions of other roles, which would let it snapshot past them.
+func TakeBalanceSnapshotsService(db *sql.DB, lag time.Duration) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	locked, err := repositories.TryLockSnapshots(tx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	visible, err := repositories.CanSeeAllSessions(tx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if !visible {
+		return 0, fmt.Errorf("%w: role cannot see the transactions of other roles, grant it pg_read_all_stats",
+			utils.ErrDatabase)
+	}
+
+	since, err := repositories.GetLastSnapshotTime(tx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	cutoff, err := repositories.GetSnapshotCutoff(tx, lag)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if !cutoff.After(since) {
+		return 0, nil
+	}
+
+	taken, err := repositories.InsertBalanceSnapshots(tx, since, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return taken, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/repositories"
+	"JavaCode/internal/stream"
+	"JavaCode/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// streamBatchSize is the maximum number of events read from the outbox at once.
+const streamBatchSize = 100
+
+// OpenWalletStreamService reads a wallet together with the id of its latest
+// event, for a stream that starts from the current balance.
+//
+// The id is read first, so the balance already includes every event up to
+// it and events after it are at worst sent although the balance includes them.
+//
+// It returns:
+//   - the wallet and the id of its latest event, 0 if it has none;
+//   - utils.ErrWalletNotFound if the wallet does not exist;
+//   - utils.ErrDatabase on failure.
+func OpenWalletStreamService(db *sql.DB, walletUUID string) (*models.Wallet, int64, error) {
+	lastID, err := repositories.GetLastWalletEventId(db, walletUUID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	wallet, err := GetWalletsService(db, walletUUID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return wallet, lastID, nil
+}
+
+// FollowWalletEventsService sends the events of a wallet recorded after
+// afterID to send, in id order, as they commit.
+//
+// The outbox is read again whenever notifier wakes the stream and every
+// heartbeat; a heartbeat with nothing new calls send with no events so the
+// caller can keep the connection alive. Events are read from the outbox
+// whether or not the dispatcher has delivered them.
+//
+// It returns:
+//   - nil once ctx is done;
+//   - the error of send, which ends the stream;
+//   - utils.ErrDatabase on failure.
+func FollowWalletEventsService(ctx context.Context, db *sql.DB, notifier stream.Notifier, walletUUID string,
+	afterID int64, heartbeat time.Duration, send func([]models.OutboxEvent) error) error {
+	wake, cancel := notifier.Subscribe(walletUUID)
+	defer cancel()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	beat := false
+	for {
+		events, err := repositories.ListWalletEvents(db, walletUUID, afterID, streamBatchSize)
+		if err != nil {
+			return fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+		}
+		if len(events) > 0 || beat {
+			if err := send(events); err != nil {
+				return err
+			}
+		}
+		beat = false
+		if len(events) > 0 {
+			afterID = events[len(events)-1].Id
+			ticker.Reset(heartbeat)
+			if len(events) == streamBatchSize {
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+		case <-ticker.C:
+			beat = true
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"JavaCode/internal/cache"
+	"JavaCode/internal/models"
+	"JavaCode/internal/repositories"
+	"JavaCode/pkg/currency"
+	"JavaCode/pkg/money"
+	"JavaCode/utils"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+// ValidateTransferRequest checks the fields of a transfer request before it
+// is passed to TransferService.
+//
+// It returns:
+//   - nil if the request is well-formed;
+//   - utils.ErrNegativeBalance if the amount is not positive;
+//   - utils.ErrInvalidRequest if a wallet id is invalid or the currency is missing;
+//   - utils.ErrUnsupportedCurrency if the currency is not supported.
+func ValidateTransferRequest(request models.TransferRequest) error {
+	if request.Amount <= 0 {
+		return utils.ErrNegativeBalance
+	}
+	for _, walletID := range []string{request.FromWalletID, request.ToWalletID} {
+		if _, err := uuid.Parse(walletID); err != nil {
+			return utils.ErrInvalidRequest
+		}
+	}
+	return ValidateCurrency(request.Currency)
+}
+
+// TransferService moves funds from one wallet to another.
+//
+// Both wallets are locked in a fixed order to avoid deadlocks with
+// concurrent transfers in the opposite direction. Neither wallet may be
+// closed, the source may not be frozen, and the destination may not be
+// frozen with deposits blocked. The request currency must
+// match the source wallet. If the destination wallet uses another currency,
+// the amount is converted with the latest rate in effect, which must not have
+// expired; a rate without an expiry expires maxRateAge after it took effect,
+// unless maxRateAge is 0. The rate is copied onto the transfer record. The transfer is
+// posted to the ledger under its own id, and a wallet.balance_changed event
+// is written to the outbox for each wallet. Once the transaction
+// is committed, both wallets are invalidated in balances (which may be nil).
+//
+// It returns:
+//   - the recorded transfer;
+//   - utils.ErrInvalidRequest if both wallets are the same;
+//   - utils.ErrWalletNotFound if either wallet does not exist;
+//   - utils.ErrWalletClosed or utils.ErrWalletFrozen if a wallet's status forbids the transfer;
+//   - utils.ErrCurrencyMismatch if the currency differs from the source wallet;
+//   - utils.ErrRateNotFound or utils.ErrStaleRate if no usable rate exists;
+//   - utils.ErrInvalidAmount if the converted amount rounds to zero or overflows;
+//   - utils.ErrNegativeBalance if the source wallet has insufficient funds;
+//   - utils.ErrAmountOverflow if the destination balance would not fit in 64 bits;
+//   - any other error on failure.
+func TransferService(db *sql.DB, balances *cache.Balances, request models.TransferRequest,
+	maxRateAge time.Duration) (*models.Transfer, error) {
+	fromID, toID := request.FromWalletID, request.ToWalletID
+	if fromID == toID {
+		return nil, utils.ErrInvalidRequest
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin tx error: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	wallets := map[string]*models.Wallet{}
+	first, second := fromID, toID
+	if second < first {
+		first, second = second, first
+	}
+	for _, id := range []string{first, second} {
+		wallet, err := repositories.GetWalletForUpdate(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		wallets[id] = wallet
+	}
+	from, to := wallets[fromID], wallets[toID]
+
+	if err := checkWalletStatus(from, false); err != nil {
+		return nil, err
+	}
+	if err := checkWalletStatus(to, true); err != nil {
+		return nil, err
+	}
+	if from.Currency != currency.Normalize(request.Currency) {
+		return nil, utils.ErrCurrencyMismatch
+	}
+
+	transfer := &models.Transfer{
+		Id:             uuid.NewString(),
+		FromWalletId:   fromID,
+		ToWalletId:     toID,
+		SourceAmount:   request.Amount,
+		SourceCurrency: from.Currency,
+		TargetAmount:   request.Amount,
+		TargetCurrency: to.Currency,
+	}
+	if from.Currency != to.Currency {
+		if err := convertTransfer(tx, transfer, time.Now(), maxRateAge); err != nil {
+			return nil, err
+		}
+	}
+
+	if from.Balance < uint64(transfer.SourceAmount) {
+		return nil, utils.ErrNegativeBalance
+	}
+	toBalance, err := money.FromUnsigned(to.Balance, to.Currency)
+	if err != nil {
+		return nil, utils.ErrAmountOverflow
+	}
+	newToBalance, err := money.Add(toBalance.Units, transfer.TargetAmount)
+	if err != nil {
+		return nil, utils.ErrAmountOverflow
+	}
+
+	if err := repositories.ChainBalance(tx, fromID, -transfer.SourceAmount); err != nil {
+		return nil, err
+	}
+	if err := repositories.ChainBalance(tx, toID, transfer.TargetAmount); err != nil {
+		return nil, err
+	}
+	if err := repositories.CreateTransfer(tx, transfer); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if err := postTransfer(tx, transfer); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	err = recordBalanceChanged(tx, from, from.Balance-uint64(transfer.SourceAmount), -transfer.SourceAmount,
+		models.LedgerTransfer, transfer.Id, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	err = recordBalanceChanged(tx, to, uint64(newToBalance), transfer.TargetAmount, models.LedgerTransfer, transfer.Id, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	err = tx.Commit()
+	// The outcome of a failed commit is unknown, so invalidate either way.
+	balances.Invalidate(fromID)
+	balances.Invalidate(toID)
+	if err != nil {
+		return nil, fmt.Errorf("commit error: %w", err)
+	}
+
+	return transfer, nil
+}
+
+// GetTransferService retrieves a transfer by UUID.
+//
+// It returns:
+//   - the transfer if found;
+//   - utils.ErrTransferNotFound if it does not exist;
+//   - utils.ErrDatabase on any other failure.
+func GetTransferService(db *sql.DB, transferUUID string) (*models.Transfer, error) {
+	transfer, err := repositories.GetTransferByUUID(db, transferUUID)
+	if err != nil {
+		if errors.Is(err, utils.ErrTransferNotFound) {
+			return nil, utils.ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return transfer, nil
+}
+
+// convertTransfer fills in the target amount and rate details of a
+// cross-currency transfer using the rate in effect at the given time.
+// A rate without ValidTo is stale once it is older than maxAge, if set.
+func convertTransfer(db repositories.Querier, transfer *models.Transfer, at time.Time, maxAge time.Duration) error {
+	rate, err := repositories.GetLatestExchangeRate(db, transfer.SourceCurrency, transfer.TargetCurrency, at)
+	if err != nil {
+		if errors.Is(err, utils.ErrRateNotFound) {
+			return utils.ErrRateNotFound
+		}
+		return fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if rate.ValidTo != nil && !rate.ValidTo.After(at) {
+		return utils.ErrStaleRate
+	}
+	if rate.ValidTo == nil && maxAge > 0 && at.Sub(rate.ValidFrom) > maxAge {
+		return utils.ErrStaleRate
+	}
+
+	fromExp, _ := currency.Exponent(transfer.SourceCurrency)
+	toExp, _ := currency.Exponent(transfer.TargetCurrency)
+	target, err := currency.Convert(transfer.SourceAmount, fromExp, toExp, rate.RateUnits, rate.Precision,
+		currency.RoundingMode(rate.RoundingMode))
+	if err != nil || target <= 0 {
+		return utils.ErrInvalidAmount
+	}
+
+	transfer.TargetAmount = target
+	transfer.RateId = &rate.Id
+	transfer.RateUnits = &rate.RateUnits
+	transfer.RatePrecision = &rate.Precision
+	transfer.RoundingMode = &rate.RoundingMode
+	return nil
+}
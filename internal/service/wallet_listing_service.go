@@ -0,0 +1,96 @@
+package service
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/repositories"
+	"JavaCode/utils"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// DefaultWalletLimit is the number of wallets returned when no limit is given.
+const DefaultWalletLimit = 50
+
+// MaxWalletLimit caps the number of wallets returned at once.
+const MaxWalletLimit = 500
+
+// ListWalletsService returns one page of the wallets matching filter.
+//
+// The listing is ordered by filter.Sort and then by id. A non-empty cursor,
+// as returned in the previous page's NextCursor, continues the listing; it
+// is only valid with the sort order it was issued for. A limit of 0 means
+// DefaultWalletLimit; larger limits are capped at MaxWalletLimit.
+//
+// It returns:
+//   - the page;
+//   - utils.ErrInvalidRequest if the sort order or the cursor is invalid;
+//   - utils.ErrDatabase on any other failure.
+func ListWalletsService(db *sql.DB, filter models.WalletFilter, cursor string) (*models.WalletPage, error) {
+	if filter.Sort == "" {
+		filter.Sort = models.WalletSortCreated
+	}
+	if filter.Sort != models.WalletSortCreated && filter.Sort != models.WalletSortBalance {
+		return nil, utils.ErrInvalidRequest
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultWalletLimit
+	}
+	if filter.Limit > MaxWalletLimit {
+		filter.Limit = MaxWalletLimit
+	}
+
+	if cursor != "" {
+		after, err := decodeWalletCursor(cursor)
+		if err != nil || after.Sort != filter.Sort || after.Desc != filter.Desc {
+			return nil, utils.ErrInvalidRequest
+		}
+		filter.After = after
+	}
+
+	// One extra row tells whether another page follows.
+	limit := filter.Limit
+	filter.Limit++
+	wallets, err := repositories.ListWallets(db, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	page := &models.WalletPage{Wallets: wallets}
+	if len(wallets) > limit {
+		page.Wallets = wallets[:limit]
+		last := page.Wallets[limit-1]
+		page.NextCursor = encodeWalletCursor(models.WalletCursor{
+			Sort:        filter.Sort,
+			Desc:        filter.Desc,
+			Balance:     last.Balance,
+			CreatedTime: last.CreatedTime,
+			Id:          last.Id,
+		})
+	}
+	if page.Wallets == nil {
+		page.Wallets = []models.Wallet{}
+	}
+	return page, nil
+}
+
+func encodeWalletCursor(cursor models.WalletCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeWalletCursor(s string) (*models.WalletCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var cursor models.WalletCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.Id == "" {
+		return nil, fmt.Errorf("cursor without a wallet id")
+	}
+	return &cursor, nil
+}
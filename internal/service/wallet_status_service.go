@@ -0,0 +1,155 @@
+package service
+
+import (
+	"JavaCode/internal/cache"
+	"JavaCode/internal/models"
+	"JavaCode/internal/repositories"
+	"JavaCode/utils"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FreezeWalletService freezes an active wallet. A frozen wallet refuses
+// withdrawals and outgoing transfers; with blockDeposits it refuses
+// deposits and incoming transfers as well.
+//
+// It returns:
+//   - the frozen wallet;
+//   - utils.ErrInvalidRequest if the reason is empty;
+//   - utils.ErrWalletNotFound if the wallet does not exist;
+//   - utils.ErrInvalidStatus if the wallet is not active;
+//   - utils.ErrDatabase on any other failure.
+func FreezeWalletService(db *sql.DB, balances *cache.Balances, walletUUID, reason string, blockDeposits bool) (*models.Wallet, error) {
+	return changeWalletStatus(db, balances, walletUUID, reason, func(wallet *models.Wallet) error {
+		if wallet.Status != models.WalletActive {
+			return utils.ErrInvalidStatus
+		}
+		wallet.Status, wallet.DepositsBlocked = models.WalletFrozen, blockDeposits
+		return nil
+	})
+}
+
+// UnfreezeWalletService makes a frozen wallet active again.
+//
+// It returns:
+//   - the active wallet;
+//   - utils.ErrInvalidRequest if the reason is empty;
+//   - utils.ErrWalletNotFound if the wallet does not exist;
+//   - utils.ErrInvalidStatus if the wallet is not frozen;
+//   - utils.ErrDatabase on any other failure.
+func UnfreezeWalletService(db *sql.DB, balances *cache.Balances, walletUUID, reason string) (*models.Wallet, error) {
+	return changeWalletStatus(db, balances, walletUUID, reason, func(wallet *models.Wallet) error {
+		if wallet.Status != models.WalletFrozen {
+			return utils.ErrInvalidStatus
+		}
+		wallet.Status, wallet.DepositsBlocked = models.WalletActive, false
+		return nil
+	})
+}
+
+// CloseWalletService closes an active wallet with a zero balance. A closed
+// wallet refuses every operation and cannot be reopened.
+//
+// It returns:
+//   - the closed wallet;
+//   - utils.ErrInvalidRequest if the reason is empty;
+//   - utils.ErrWalletNotFound if the wallet does not exist;
+//   - utils.ErrInvalidStatus if the wallet is not active;
+//   - utils.ErrWalletNotEmpty if the balance is not zero;
+//   - utils.ErrDatabase on any other failure.
+func CloseWalletService(db *sql.DB, balances *cache.Balances, walletUUID, reason string) (*models.Wallet, error) {
+	return changeWalletStatus(db, balances, walletUUID, reason, func(wallet *models.Wallet) error {
+		if wallet.Status != models.WalletActive {
+			return utils.ErrInvalidStatus
+		}
+		if wallet.Balance != 0 {
+			return utils.ErrWalletNotEmpty
+		}
+		wallet.Status = models.WalletClosed
+		return nil
+	})
+}
+
+// ListWalletStatusChangesService returns a wallet's status history, oldest first.
+//
+// It returns:
+//   - the status changes, empty if the status never changed;
+//   - utils.ErrWalletNotFound if the wallet does not exist;
+//   - utils.ErrDatabase on any other failure.
+func ListWalletStatusChangesService(db *sql.DB, walletUUID string) ([]models.WalletStatusChange, error) {
+	if _, err := GetWalletsService(db, walletUUID); err != nil {
+		return nil, err
+	}
+
+	changes, err := repositories.ListWalletStatusChanges(db, walletUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if changes == nil {
+		changes = []models.WalletStatusChange{}
+	}
+	return changes, nil
+}
+
+// changeWalletStatus locks a wallet, lets transition update its status, and
+// stores the change with its reason, writing a wallet.status_changed event
+// to the outbox. Once the transaction is committed, the wallet is
+// invalidated in balances (which may be nil).
+func changeWalletStatus(db *sql.DB, balances *cache.Balances, walletUUID, reason string,
+	transition func(wallet *models.Wallet) error) (*models.Wallet, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, utils.ErrInvalidRequest
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%w: begin tx: %v", utils.ErrDatabase, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	wallet, err := repositories.GetWalletForUpdate(tx, walletUUID)
+	if err != nil {
+		if errors.Is(err, utils.ErrWalletNotFound) {
+			return nil, utils.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	previous := *wallet
+	if err := transition(wallet); err != nil {
+		return nil, err
+	}
+	if err := repositories.SetWalletStatus(tx, &previous, wallet.Status, wallet.DepositsBlocked, reason); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	err = recordStatusChanged(tx, walletUUID, previous.Status, wallet.Status, wallet.DepositsBlocked, reason)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	err = tx.Commit()
+	balances.Invalidate(walletUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: commit: %v", utils.ErrDatabase, err)
+	}
+
+	utils.Logger.Infof("wallet %s status changed from %s to %s: %s", walletUUID, previous.Status, wallet.Status, reason)
+	return wallet, nil
+}
+
+// checkWalletStatus reports whether a wallet's status allows funds to be
+// taken out of it (credit false) or put into it (credit true).
+func checkWalletStatus(wallet *models.Wallet, credit bool) error {
+	switch wallet.Status {
+	case models.WalletClosed:
+		return utils.ErrWalletClosed
+	case models.WalletFrozen:
+		if !credit || wallet.DepositsBlocked {
+			return utils.ErrWalletFrozen
+		}
+	}
+	return nil
+}
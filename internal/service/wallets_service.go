@@ -1,12 +1,18 @@
 package service
 
 import (
+	"JavaCode/internal/cache"
 	"JavaCode/internal/models"
 	"JavaCode/internal/repositories"
+	"JavaCode/pkg/currency"
+	"JavaCode/pkg/money"
 	"JavaCode/utils"
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/google/uuid"
+	"regexp"
+	"strings"
 )
 
 const (
@@ -14,6 +20,134 @@ const (
 	WITHDRAW = "WITHDRAW"
 )
 
+// Wallet metadata limits.
+const (
+	MaxOwnerLength      = 255
+	MaxNameLength       = 255
+	MaxLabels           = 32
+	MaxLabelValueLength = 255
+)
+
+// Operation details limits.
+const (
+	MaxDescriptionLength   = 500
+	MaxReferenceLength     = 128
+	MaxMetadataKeys        = 32
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 500
+)
+
+// labelKeyPattern matches a valid label key.
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.\-/]{0,62}$`)
+
+// CreateWalletService creates an empty wallet in the requested currency,
+// optionally with an owner, a name and labels.
+//
+// It returns:
+//   - the created wallet;
+//   - utils.ErrUnsupportedCurrency if the currency is not supported;
+//   - utils.ErrInvalidRequest if the metadata exceeds its limits;
+//   - utils.ErrDatabase if the insert fails.
+func CreateWalletService(db *sql.DB, request models.CreateWalletRequest) (*models.Wallet, error) {
+	currencyCode := currency.Normalize(request.Currency)
+	if !currency.IsSupported(currencyCode) {
+		return nil, utils.ErrUnsupportedCurrency
+	}
+
+	wallet := models.Wallet{
+		Id:       uuid.NewString(),
+		Currency: currencyCode,
+		Owner:    strings.TrimSpace(request.Owner),
+		Name:     strings.TrimSpace(request.Name),
+		Labels:   request.Labels,
+	}
+	if err := validateWalletMetadata(&wallet); err != nil {
+		return nil, err
+	}
+
+	created, err := repositories.CreateWallet(db, wallet)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return created, nil
+}
+
+// UpdateWalletService changes a wallet's owner, name and labels.
+//
+// Fields missing from the request are kept and an empty owner or name
+// clears it. Labels are merged into the existing ones; a nil value removes
+// the label. Once the transaction is committed, the wallet is invalidated
+// in balances (which may be nil).
+//
+// It returns:
+//   - the updated wallet;
+//   - utils.ErrWalletNotFound if the wallet does not exist;
+//   - utils.ErrInvalidRequest if the metadata exceeds its limits;
+//   - utils.ErrDatabase on any other failure.
+func UpdateWalletService(db *sql.DB, balances *cache.Balances, walletUUID string, request models.UpdateWalletRequest) (*models.Wallet, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%w: begin tx: %v", utils.ErrDatabase, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	wallet, err := repositories.GetWalletForUpdate(tx, walletUUID)
+	if err != nil {
+		if errors.Is(err, utils.ErrWalletNotFound) {
+			return nil, utils.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	if request.Owner != nil {
+		wallet.Owner = strings.TrimSpace(*request.Owner)
+	}
+	if request.Name != nil {
+		wallet.Name = strings.TrimSpace(*request.Name)
+	}
+	for key, value := range request.Labels {
+		if value == nil {
+			delete(wallet.Labels, key)
+		} else {
+			wallet.Labels[key] = *value
+		}
+	}
+	if err := validateWalletMetadata(wallet); err != nil {
+		return nil, err
+	}
+
+	if err := repositories.SetWalletMetadata(tx, wallet); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	err = tx.Commit()
+	balances.Invalidate(walletUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: commit: %v", utils.ErrDatabase, err)
+	}
+	return wallet, nil
+}
+
+// validateWalletMetadata checks the owner, name and labels of a wallet
+// against their limits, replacing nil labels with an empty map.
+func validateWalletMetadata(wallet *models.Wallet) error {
+	if len(wallet.Owner) > MaxOwnerLength || len(wallet.Name) > MaxNameLength {
+		return utils.ErrInvalidRequest
+	}
+	if wallet.Labels == nil {
+		wallet.Labels = map[string]string{}
+	}
+	if len(wallet.Labels) > MaxLabels {
+		return utils.ErrInvalidRequest
+	}
+	for key, value := range wallet.Labels {
+		if !labelKeyPattern.MatchString(key) || len(value) > MaxLabelValueLength {
+			return utils.ErrInvalidRequest
+		}
+	}
+	return nil
+}
+
 // GetWalletsService retrieves a wallet by UUID.
 //
 // It returns:
@@ -31,15 +165,103 @@ func GetWalletsService(db *sql.DB, walletUUID string) (*models.Wallet, error) {
 	return wallet, nil
 }
 
+// GetWalletsCachedService retrieves a wallet by UUID through the balance cache.
+//
+// On a miss the wallet is read from db and cached, unless a write to the
+// same wallet was committed by this process while the read was in flight.
+// A nil balances disables caching.
+//
+// It returns:
+//   - the wallet and whether it was served from the cache;
+//   - the same errors as GetWalletsService.
+func GetWalletsCachedService(db *sql.DB, balances *cache.Balances, walletUUID string) (*models.Wallet, bool, error) {
+	if wallet, ok := balances.Get(walletUUID); ok {
+		return &wallet, true, nil
+	}
+
+	generation := balances.Generation(walletUUID)
+	wallet, err := GetWalletsService(db, walletUUID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	balances.Fill(*wallet, generation)
+	return wallet, false, nil
+}
+
+// ValidateOperationRequest checks the fields of a deposit or withdrawal
+// request before it is passed to HandleOperationService. Every API applies
+// the same checks in the same order.
+//
+// It returns:
+//   - nil if the request is well-formed;
+//   - utils.ErrNegativeBalance if the amount is not positive;
+//   - utils.ErrInvalidRequest if the wallet id or operation type is invalid,
+//     or the currency is missing;
+//   - utils.ErrUnsupportedCurrency if the currency is not supported.
+func ValidateOperationRequest(request models.WalletOperationRequest) error {
+	if request.Amount <= 0 {
+		return utils.ErrNegativeBalance
+	}
+	if _, err := uuid.Parse(request.WalletID); err != nil {
+		return utils.ErrInvalidRequest
+	}
+	if request.OperationType != DEPOSIT && request.OperationType != WITHDRAW {
+		return utils.ErrInvalidRequest
+	}
+	return ValidateCurrency(request.Currency)
+}
+
+// ValidateCurrency checks that a request currency is a supported ISO 4217 code.
+//
+// It returns:
+//   - nil if the currency is supported;
+//   - utils.ErrInvalidRequest if it is missing;
+//   - utils.ErrUnsupportedCurrency otherwise.
+func ValidateCurrency(code string) error {
+	if code == "" {
+		return utils.ErrInvalidRequest
+	}
+	if !currency.IsSupported(code) {
+		return utils.ErrUnsupportedCurrency
+	}
+	return nil
+}
+
 // HandleOperationService processes a deposit or withdrawal operation on a wallet.
 //
-// It calculates the delta (positive or negative) based on the operation type,
-// and applies the change via the repository layer.
+// An operation whose reference the wallet has already used is rejected as a
+// duplicate first, whatever the wallet's current status and balance.
+// It then checks that the wallet's status allows the operation and that the
+// request currency matches the wallet currency,
+// calculates the delta (positive or negative) based on the operation type,
+// checks the new balance for overflow, and applies the change via the repository layer.
+// The operation is posted to the ledger in the same transaction, with the
+// request's description, reference and metadata: a deposit
+// moves funds from the cash-in account, a withdrawal to the cash-out account. A
+// wallet.balance_changed event is written to the outbox in the same transaction.
+// Once the transaction is committed, the wallet is invalidated in balances (which may be nil).
 //
 // Returns:
 //   - nil on success;
+//   - utils.ErrInvalidRequest if the operation details exceed their limits;
+//   - utils.ErrWalletClosed if the wallet is closed;
+//   - utils.ErrWalletFrozen if the wallet is frozen and the operation is a
+//     withdrawal, or a deposit to a wallet that also blocks deposits;
+//   - utils.ErrCurrencyMismatch if the currencies differ;
+//   - utils.ErrAmountOverflow if the new balance would not fit in 64 bits;
+//   - utils.ErrDuplicateReference if the wallet already has an operation with the reference;
 //   - an error if the balance update fails.
-func HandleOperationService(db *sql.DB, walletID, operationType string, amount int) error {
+func HandleOperationService(db *sql.DB, balances *cache.Balances, request models.WalletOperationRequest) error {
+	walletID, amount := request.WalletID, request.Amount
+
+	details := request.OperationDetails
+	details.Description = strings.TrimSpace(details.Description)
+	details.Reference = strings.TrimSpace(details.Reference)
+	if err := validateOperationDetails(details); err != nil {
+		return err
+	}
+
 	tx, err := db.Begin()
 	if err != nil {
 		return fmt.Errorf("begin tx error: %w", err)
@@ -51,14 +273,41 @@ func HandleOperationService(db *sql.DB, walletID, operationType string, amount i
 		return err
 	}
 
-	var delta int
-	if operationType == DEPOSIT {
+	// A repeated operation is reported as a duplicate whatever the wallet
+	// looks like now, so that a retry of an applied operation is never
+	// mistaken for a failed one.
+	if details.Reference != "" {
+		exists, err := repositories.LedgerReferenceExists(tx, walletID, details.Reference)
+		if err != nil {
+			return fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+		}
+		if exists {
+			return utils.ErrDuplicateReference
+		}
+	}
+
+	if err := checkWalletStatus(wallet, request.OperationType == DEPOSIT); err != nil {
+		return err
+	}
+	if wallet.Currency != currency.Normalize(request.Currency) {
+		return utils.ErrCurrencyMismatch
+	}
+
+	var delta int64
+	if request.OperationType == DEPOSIT {
 		delta = amount
-	} else if operationType == WITHDRAW {
+	} else if request.OperationType == WITHDRAW {
 		delta = -amount
 	}
 
-	newBalance := int(wallet.Balance) + delta
+	balance, err := money.FromUnsigned(wallet.Balance, wallet.Currency)
+	if err != nil {
+		return utils.ErrAmountOverflow
+	}
+	newBalance, err := money.Add(balance.Units, delta)
+	if err != nil {
+		return utils.ErrAmountOverflow
+	}
 	if newBalance < 0 {
 		return utils.ErrNegativeBalance
 	}
@@ -66,10 +315,40 @@ func HandleOperationService(db *sql.DB, walletID, operationType string, amount i
 	if err := repositories.ChainBalance(tx, walletID, delta); err != nil {
 		return err
 	}
+	txnID := uuid.NewString()
+	if err := postOperation(tx, txnID, wallet, request.OperationType, amount, details); err != nil {
+		if errors.Is(err, utils.ErrDuplicateReference) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	err = recordBalanceChanged(tx, wallet, uint64(newBalance), delta, request.OperationType, txnID, details.Reference)
+	if err != nil {
+		return fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
 
-	if err := tx.Commit(); err != nil {
+	err = tx.Commit()
+	// The outcome of a failed commit is unknown, so invalidate either way.
+	balances.Invalidate(walletID)
+	if err != nil {
 		return fmt.Errorf("commit error: %w", err)
 	}
 
-	return err
+	return nil
+}
+
+// validateOperationDetails checks the details of an operation against their limits.
+func validateOperationDetails(details models.OperationDetails) error {
+	if len(details.Description) > MaxDescriptionLength || len(details.Reference) > MaxReferenceLength {
+		return utils.ErrInvalidRequest
+	}
+	if len(details.Metadata) > MaxMetadataKeys {
+		return utils.ErrInvalidRequest
+	}
+	for key, value := range details.Metadata {
+		if key == "" || len(key) > MaxMetadataKeyLength || len(value) > MaxMetadataValueLength {
+			return utils.ErrInvalidRequest
+		}
+	}
+	return nil
 }
@@ -1,22 +1,34 @@
 package service_test
 
 import (
+	"JavaCode/internal/cache"
+	"JavaCode/internal/models"
 	"JavaCode/internal/service"
+	"JavaCode/internal/webhooks"
 	"JavaCode/utils"
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
 
-func expectTxWithBalance(mock sqlmock.Sqlmock, walletID string, balance int, delta int, execErr error) {
+func expectTxWithBalance(mock sqlmock.Sqlmock, walletID string, balance int, delta int64, execErr error) {
 	mock.ExpectBegin()
 
 	mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
 		WithArgs(walletID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "created_at", "updated_at"}).
-			AddRow(walletID, balance, time.Now(), time.Now()))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(walletID, balance, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
 
 	if execErr != nil {
 		mock.ExpectExec("UPDATE wallets SET balance = balance \\+ \\$1, updated_at = NOW\\(\\) WHERE id = \\$2").
@@ -27,10 +39,64 @@ func expectTxWithBalance(mock sqlmock.Sqlmock, walletID string, balance int, del
 		mock.ExpectExec("UPDATE wallets SET balance = balance \\+ \\$1, updated_at = NOW\\(\\) WHERE id = \\$2").
 			WithArgs(delta, walletID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		if delta > 0 {
+			expectLedgerPosting(mock, "CASH_IN")
+		} else {
+			expectLedgerPosting(mock, "CASH_OUT")
+		}
+		expectOutboxEvent(mock, walletID)
 		mock.ExpectCommit()
 	}
 }
 
+// expectReferenceCheck expects the lookup of an operation reference of the wallet.
+func expectReferenceCheck(mock sqlmock.Sqlmock, walletID, reference string, exists bool) {
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM ledger_transactions").
+		WithArgs(walletID, reference).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(exists))
+}
+
+// expectLedgerPosting expects a ledger transaction that looks up the given
+// system account types and inserts its entries.
+func expectLedgerPosting(mock sqlmock.Sqlmock, systemAccounts ...string) {
+	for _, accountType := range systemAccounts {
+		mock.ExpectQuery("SELECT id FROM ledger_accounts").
+			WithArgs(accountType, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("00000000-0000-0000-0000-000000000001"))
+	}
+	mock.ExpectQuery("INSERT INTO ledger_transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectExec("INSERT INTO ledger_entries").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+}
+
+// expectOutboxEvent expects a wallet.balance_changed event of the wallet
+// and its webhook deliveries.
+func expectOutboxEvent(mock sqlmock.Sqlmock, walletID string) {
+	mock.ExpectQuery("INSERT INTO outbox_events").
+		WithArgs(sqlmock.AnyArg(), "wallet.balance_changed", walletID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	expectWebhookDeliveries(mock, 0)
+}
+
+// expectStatusEvent expects a wallet.status_changed event of the wallet,
+// queued for webhooks when the wallet is frozen.
+func expectStatusEvent(mock sqlmock.Sqlmock, walletID string, frozen bool) {
+	mock.ExpectQuery("INSERT INTO outbox_events").
+		WithArgs(sqlmock.AnyArg(), "wallet.status_changed", walletID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	if frozen {
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), walletID, int64(0), int64(0)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+}
+
+// expectWebhookDeliveries expects an event to be queued for n webhooks.
+func expectWebhookDeliveries(mock sqlmock.Sqlmock, n int64) *sqlmock.ExpectedExec {
+	return mock.ExpectExec("INSERT INTO webhook_deliveries").WillReturnResult(sqlmock.NewResult(0, n))
+}
+
 func TestGetWalletsService(t *testing.T) {
 	t.Run("Test 1: Not find wallet", func(t *testing.T) {
 		test := "f4c863ec-0300-495d-852d-c115e197390b"
@@ -38,7 +104,7 @@ func TestGetWalletsService(t *testing.T) {
 		db, mock, _ := sqlmock.New()
 		defer db.Close()
 
-		q := "SELECT id, balance, created_at, updated_at FROM wallets WHERE id = \\$1"
+		q := "SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1"
 		mock.ExpectQuery(q).WithArgs(test).WillReturnError(sql.ErrNoRows)
 
 		_, err := service.GetWalletsService(db, test)
@@ -54,7 +120,7 @@ func TestGetWalletsService(t *testing.T) {
 		db, mock, _ := sqlmock.New()
 		defer db.Close()
 
-		q := "SELECT id, balance, created_at, updated_at FROM wallets WHERE id = \\$1"
+		q := "SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1"
 		mock.ExpectQuery(q).WithArgs(test).WillReturnError(sql.ErrConnDone)
 
 		_, err := service.GetWalletsService(db, test)
@@ -68,13 +134,13 @@ func TestGetWalletsService(t *testing.T) {
 
 	t.Run("Test 3: Find wallet", func(t *testing.T) {
 		test := "f4c863ec-0300-495d-852d-c115e197390b"
-		mockRow := sqlmock.NewRows([]string{"id", "balance", "created_at", "updated_at"}).
-			AddRow("f4c863ec-0300-495d-852d-c115e197390b", 1000, time.Now(), time.Now())
+		mockRow := sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow("f4c863ec-0300-495d-852d-c115e197390b", 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now())
 
 		db, mock, _ := sqlmock.New()
 		defer db.Close()
 
-		q := "SELECT id, balance, created_at, updated_at FROM wallets WHERE id = \\$1"
+		q := "SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1"
 		qExp := mock.ExpectQuery(q).WithArgs(test)
 		qExp.WillReturnRows(mockRow)
 
@@ -90,14 +156,14 @@ func TestHandleOperationService(t *testing.T) {
 	t.Run("Test 1: Deposit success", func(t *testing.T) {
 		testWalletID := "f4c863ec-0300-495d-852d-c115e197390b"
 		startBalance := 1000
-		amount := 500
+		amount := int64(500)
 
 		db, mock, _ := sqlmock.New()
 		defer db.Close()
 
 		expectTxWithBalance(mock, testWalletID, startBalance, amount, nil)
 
-		err := service.HandleOperationService(db, testWalletID, "DEPOSIT", amount)
+		err := service.HandleOperationService(db, nil, models.WalletOperationRequest{WalletID: testWalletID, OperationType: "DEPOSIT", Amount: amount, Currency: "RUB"})
 		if err != nil {
 			t.Errorf("HandleOperationService (DEPOSIT): got %v, want nil", err)
 		}
@@ -106,14 +172,14 @@ func TestHandleOperationService(t *testing.T) {
 	t.Run("Test 2: Withdraw success", func(t *testing.T) {
 		testWalletID := "f4c863ec-0300-495d-852d-c115e197390b"
 		startBalance := 1500
-		amount := 300
+		amount := int64(300)
 
 		db, mock, _ := sqlmock.New()
 		defer db.Close()
 
 		expectTxWithBalance(mock, testWalletID, startBalance, -amount, nil)
 
-		err := service.HandleOperationService(db, testWalletID, "WITHDRAW", amount)
+		err := service.HandleOperationService(db, nil, models.WalletOperationRequest{WalletID: testWalletID, OperationType: "WITHDRAW", Amount: amount, Currency: "RUB"})
 		if err != nil {
 			t.Errorf("HandleOperationService (WITHDRAW): got %v, want nil", err)
 		}
@@ -122,7 +188,7 @@ func TestHandleOperationService(t *testing.T) {
 	t.Run("Test 3: Withdraw causes negative balance", func(t *testing.T) {
 		testWalletID := "f4c863ec-0300-495d-852d-c115e197390b"
 		startBalance := 1000
-		amount := 1500
+		amount := int64(1500)
 
 		db, mock, _ := sqlmock.New()
 		defer db.Close()
@@ -130,11 +196,11 @@ func TestHandleOperationService(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
 			WithArgs(testWalletID).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "created_at", "updated_at"}).
-				AddRow(testWalletID, startBalance, time.Now(), time.Now()))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(testWalletID, startBalance, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
 		mock.ExpectRollback()
 
-		err := service.HandleOperationService(db, testWalletID, "WITHDRAW", amount)
+		err := service.HandleOperationService(db, nil, models.WalletOperationRequest{WalletID: testWalletID, OperationType: "WITHDRAW", Amount: amount, Currency: "RUB"})
 		if !errors.Is(err, utils.ErrNegativeBalance) && !errors.Is(err, utils.ErrInvalidAmount) {
 			t.Errorf("HandleOperationService: got %v, want negative balance error", err)
 		}
@@ -143,16 +209,1928 @@ func TestHandleOperationService(t *testing.T) {
 	t.Run("Test 4: Repository exec error", func(t *testing.T) {
 		testWalletID := "f4c863ec-0300-495d-852d-c115e197390b"
 		startBalance := 1000
-		amount := 200
+		amount := int64(200)
 
 		db, mock, _ := sqlmock.New()
 		defer db.Close()
 
 		expectTxWithBalance(mock, testWalletID, startBalance, amount, sql.ErrConnDone)
 
-		err := service.HandleOperationService(db, testWalletID, "DEPOSIT", amount)
+		err := service.HandleOperationService(db, nil, models.WalletOperationRequest{WalletID: testWalletID, OperationType: "DEPOSIT", Amount: amount, Currency: "RUB"})
 		if err == nil {
 			t.Error("HandleOperationService: expected error, got nil")
 		}
 	})
 }
+
+func TestSelectReadDBService(t *testing.T) {
+	primary, _, _ := sqlmock.New()
+	defer primary.Close()
+
+	t.Run("Test 1: No replica", func(t *testing.T) {
+		if got := service.SelectReadDBService(primary, nil, "0/1"); got != primary {
+			t.Error("expected primary when no replica is configured")
+		}
+	})
+
+	t.Run("Test 2: No token", func(t *testing.T) {
+		replica, _, _ := sqlmock.New()
+		defer replica.Close()
+
+		if got := service.SelectReadDBService(primary, replica, ""); got != replica {
+			t.Error("expected replica when no token is given")
+		}
+	})
+
+	t.Run("Test 3: Token check", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			caughtUp    bool
+			err         error
+			wantReplica bool
+		}{
+			{"Replica caught up", true, nil, true},
+			{"Replica lagging", false, nil, false},
+			{"Replica unavailable", false, sql.ErrConnDone, false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				replica, mock, _ := sqlmock.New()
+				defer replica.Close()
+
+				q := mock.ExpectQuery("SELECT COALESCE\\(pg_last_wal_replay_lsn\\(\\).*").WithArgs("0/16B3748")
+				if tt.err != nil {
+					q.WillReturnError(tt.err)
+				} else {
+					q.WillReturnRows(sqlmock.NewRows([]string{"caught_up"}).AddRow(tt.caughtUp))
+				}
+
+				got := service.SelectReadDBService(primary, replica, "0/16B3748")
+				if (got == replica) != tt.wantReplica {
+					t.Errorf("replica selected = %v, want %v", got == replica, tt.wantReplica)
+				}
+			})
+		}
+	})
+}
+
+func TestConsistencyTokenService(t *testing.T) {
+	t.Run("Test 1: Token returned", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT pg_current_wal_lsn\\(\\)::text").
+			WillReturnRows(sqlmock.NewRows([]string{"lsn"}).AddRow("0/16B3748"))
+
+		token, err := service.ConsistencyTokenService(db)
+		if err != nil || token != "0/16B3748" {
+			t.Errorf("ConsistencyTokenService: got (%q, %v), want (\"0/16B3748\", nil)", token, err)
+		}
+	})
+
+	t.Run("Test 2: Error DataBase", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT pg_current_wal_lsn\\(\\)::text").WillReturnError(sql.ErrConnDone)
+
+		_, err := service.ConsistencyTokenService(db)
+		if !errors.Is(err, utils.ErrDatabase) {
+			t.Errorf("ConsistencyTokenService: got %v, want %v", err, utils.ErrDatabase)
+		}
+	})
+}
+
+func TestGetWalletsCachedService(t *testing.T) {
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+	const q = "SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1"
+
+	t.Run("Test 1: Miss then hit", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery(q).WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+
+		balances := cache.NewBalances(cache.NewLRU(10, time.Minute))
+
+		_, hit, err := service.GetWalletsCachedService(db, balances, walletID)
+		if err != nil || hit {
+			t.Errorf("first read: got (hit=%v, %v), want (hit=false, nil)", hit, err)
+		}
+
+		wallet, hit, err := service.GetWalletsCachedService(db, balances, walletID)
+		if err != nil || !hit || wallet.Balance != 1000 {
+			t.Errorf("second read: got (%+v, hit=%v, %v), want cached balance 1000", wallet, hit, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Not found is not cached", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery(q).WithArgs(walletID).WillReturnError(sql.ErrNoRows)
+
+		balances := cache.NewBalances(cache.NewLRU(10, time.Minute))
+
+		_, _, err := service.GetWalletsCachedService(db, balances, walletID)
+		if !errors.Is(err, utils.ErrWalletNotFound) {
+			t.Errorf("got %v, want %v", err, utils.ErrWalletNotFound)
+		}
+		if _, ok := balances.Get(walletID); ok {
+			t.Error("expected missing wallet not to be cached")
+		}
+	})
+}
+
+func TestHandleOperationService_InvalidatesCache(t *testing.T) {
+	testWalletID := "f4c863ec-0300-495d-852d-c115e197390b"
+
+	t.Run("Test 1: Committed operation", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectTxWithBalance(mock, testWalletID, 1000, 500, nil)
+
+		balances := cache.NewBalances(cache.NewLRU(10, time.Minute))
+		balances.Fill(models.Wallet{Id: testWalletID, Balance: 1000}, balances.Generation(testWalletID))
+
+		if err := service.HandleOperationService(db, balances, models.WalletOperationRequest{WalletID: testWalletID, OperationType: "DEPOSIT", Amount: 500, Currency: "RUB"}); err != nil {
+			t.Fatalf("HandleOperationService: got %v, want nil", err)
+		}
+		if _, ok := balances.Get(testWalletID); ok {
+			t.Error("expected wallet to be invalidated after commit")
+		}
+	})
+
+	t.Run("Test 2: Rejected operation keeps cache", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+			WithArgs(testWalletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(testWalletID, 100, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+		mock.ExpectRollback()
+
+		balances := cache.NewBalances(cache.NewLRU(10, time.Minute))
+		balances.Fill(models.Wallet{Id: testWalletID, Balance: 100}, balances.Generation(testWalletID))
+
+		err := service.HandleOperationService(db, balances, models.WalletOperationRequest{WalletID: testWalletID, OperationType: "WITHDRAW", Amount: 500, Currency: "RUB"})
+		if !errors.Is(err, utils.ErrNegativeBalance) {
+			t.Fatalf("HandleOperationService: got %v, want %v", err, utils.ErrNegativeBalance)
+		}
+		if _, ok := balances.Get(testWalletID); !ok {
+			t.Error("expected wallet to stay cached after a rejected operation")
+		}
+	})
+}
+
+func TestCreateWalletService(t *testing.T) {
+	t.Run("Test 1: Wallet created with normalized currency", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("INSERT INTO wallets").
+			WithArgs(sqlmock.AnyArg(), "EUR", "", "", []byte("{}")).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+
+		wallet, err := service.CreateWalletService(db, models.CreateWalletRequest{Currency: "eur"})
+		if err != nil {
+			t.Fatalf("CreateWalletService: got %v, want nil", err)
+		}
+		if wallet.Currency != "EUR" || wallet.Id == "" {
+			t.Errorf("unexpected wallet: %+v", wallet)
+		}
+	})
+
+	t.Run("Test 2: Unsupported currency", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		_, err := service.CreateWalletService(db, models.CreateWalletRequest{Currency: "XYZ"})
+		if !errors.Is(err, utils.ErrUnsupportedCurrency) {
+			t.Errorf("CreateWalletService: got %v, want %v", err, utils.ErrUnsupportedCurrency)
+		}
+	})
+}
+
+func TestHandleOperationService_CurrencyMismatch(t *testing.T) {
+	testWalletID := "f4c863ec-0300-495d-852d-c115e197390b"
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+		WithArgs(testWalletID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(testWalletID, 1000, "EUR", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+	mock.ExpectRollback()
+
+	err := service.HandleOperationService(db, nil, models.WalletOperationRequest{
+		WalletID: testWalletID, OperationType: "DEPOSIT", Amount: 100, Currency: "USD",
+	})
+	if !errors.Is(err, utils.ErrCurrencyMismatch) {
+		t.Errorf("HandleOperationService: got %v, want %v", err, utils.ErrCurrencyMismatch)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func expectTransferWallets(mock sqlmock.Sqlmock, fromID string, fromBalance int, fromCurrency, toID, toCurrency string) {
+	mock.ExpectBegin()
+	// Wallets are locked in id order: toID sorts before fromID in these tests.
+	mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+		WithArgs(toID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(toID, 0, toCurrency, "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+		WithArgs(fromID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(fromID, fromBalance, fromCurrency, "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+}
+
+func expectRate(mock sqlmock.Sqlmock, units int64, precision int, mode string, validTo any) {
+	mock.ExpectQuery("SELECT .* FROM exchange_rates").
+		WithArgs("EUR", "USD", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "base_currency", "quote_currency", "rate_units", "precision",
+			"rounding_mode", "valid_from", "valid_to", "created_at"}).
+			AddRow(7, "EUR", "USD", units, precision, mode, time.Now().Add(-time.Hour), validTo, time.Now()))
+}
+
+func TestTransferService(t *testing.T) {
+	const (
+		fromID = "f4c863ec-0300-495d-852d-c115e197390b"
+		toID   = "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"
+	)
+
+	t.Run("Test 1: Same currency", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectTransferWallets(mock, fromID, 1000, "RUB", toID, "RUB")
+		mock.ExpectExec("UPDATE wallets SET balance").WithArgs(-300, fromID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("UPDATE wallets SET balance").WithArgs(300, toID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("INSERT INTO transfers").
+			WithArgs(sqlmock.AnyArg(), fromID, toID, int64(300), "RUB", int64(300), "RUB", nil, nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+		expectLedgerPosting(mock)
+		expectOutboxEvent(mock, fromID)
+		expectOutboxEvent(mock, toID)
+		mock.ExpectCommit()
+
+		transfer, err := service.TransferService(db, nil, models.TransferRequest{
+			FromWalletID: fromID, ToWalletID: toID, Amount: 300, Currency: "RUB",
+		}, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("TransferService: got %v, want nil", err)
+		}
+		if transfer.TargetAmount != 300 || transfer.RateId != nil {
+			t.Errorf("unexpected transfer: %+v", transfer)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Converted with the recorded rate", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectTransferWallets(mock, fromID, 5000, "EUR", toID, "USD")
+		expectRate(mock, 10834, 4, "HALF_EVEN", nil)
+		mock.ExpectExec("UPDATE wallets SET balance").WithArgs(-1000, fromID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("UPDATE wallets SET balance").WithArgs(1083, toID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("INSERT INTO transfers").
+			WithArgs(sqlmock.AnyArg(), fromID, toID, int64(1000), "EUR", int64(1083), "USD",
+				int64(7), int64(10834), 4, "HALF_EVEN").
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+		// Each currency balances through its own FX account.
+		mock.ExpectQuery("SELECT id FROM ledger_accounts").
+			WithArgs("FX", "EUR").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("fx-eur"))
+		mock.ExpectQuery("SELECT id FROM ledger_accounts").
+			WithArgs("FX", "USD").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("fx-usd"))
+		mock.ExpectQuery("INSERT INTO ledger_transactions").
+			WithArgs(sqlmock.AnyArg(), "TRANSFER", "", "", "", []byte("{}")).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+		mock.ExpectExec("INSERT INTO ledger_entries").
+			WithArgs(
+				sqlmock.AnyArg(), fromID, "DEBIT", int64(1000), "EUR",
+				sqlmock.AnyArg(), "fx-eur", "CREDIT", int64(1000), "EUR",
+				sqlmock.AnyArg(), "fx-usd", "DEBIT", int64(1083), "USD",
+				sqlmock.AnyArg(), toID, "CREDIT", int64(1083), "USD",
+			).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+		expectOutboxEvent(mock, fromID)
+		expectOutboxEvent(mock, toID)
+		mock.ExpectCommit()
+
+		transfer, err := service.TransferService(db, nil, models.TransferRequest{
+			FromWalletID: fromID, ToWalletID: toID, Amount: 1000, Currency: "EUR",
+		}, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("TransferService: got %v, want nil", err)
+		}
+		if transfer.TargetAmount != 1083 || transfer.RateId == nil || *transfer.RateId != 7 {
+			t.Errorf("unexpected transfer: %+v", transfer)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 3: Stale rate", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectTransferWallets(mock, fromID, 5000, "EUR", toID, "USD")
+		expectRate(mock, 10834, 4, "HALF_EVEN", time.Now().Add(-time.Minute))
+		mock.ExpectRollback()
+
+		_, err := service.TransferService(db, nil, models.TransferRequest{
+			FromWalletID: fromID, ToWalletID: toID, Amount: 1000, Currency: "EUR",
+		}, 24*time.Hour)
+		if !errors.Is(err, utils.ErrStaleRate) {
+			t.Errorf("TransferService: got %v, want %v", err, utils.ErrStaleRate)
+		}
+	})
+
+	t.Run("Test 4: Rate without validTo past the maximum age", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		// The rate took effect an hour ago.
+		expectTransferWallets(mock, fromID, 5000, "EUR", toID, "USD")
+		expectRate(mock, 10834, 4, "HALF_EVEN", nil)
+		mock.ExpectRollback()
+
+		_, err := service.TransferService(db, nil, models.TransferRequest{
+			FromWalletID: fromID, ToWalletID: toID, Amount: 1000, Currency: "EUR",
+		}, 30*time.Minute)
+		if !errors.Is(err, utils.ErrStaleRate) {
+			t.Errorf("TransferService: got %v, want %v", err, utils.ErrStaleRate)
+		}
+	})
+
+	t.Run("Test 5: No rate", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectTransferWallets(mock, fromID, 5000, "EUR", toID, "USD")
+		mock.ExpectQuery("SELECT .* FROM exchange_rates").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := service.TransferService(db, nil, models.TransferRequest{
+			FromWalletID: fromID, ToWalletID: toID, Amount: 1000, Currency: "EUR",
+		}, 24*time.Hour)
+		if !errors.Is(err, utils.ErrRateNotFound) {
+			t.Errorf("TransferService: got %v, want %v", err, utils.ErrRateNotFound)
+		}
+	})
+
+	t.Run("Test 6: Insufficient funds", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectTransferWallets(mock, fromID, 100, "RUB", toID, "RUB")
+		mock.ExpectRollback()
+
+		_, err := service.TransferService(db, nil, models.TransferRequest{
+			FromWalletID: fromID, ToWalletID: toID, Amount: 300, Currency: "RUB",
+		}, 24*time.Hour)
+		if !errors.Is(err, utils.ErrNegativeBalance) {
+			t.Errorf("TransferService: got %v, want %v", err, utils.ErrNegativeBalance)
+		}
+	})
+
+	t.Run("Test 7: Same wallet", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		_, err := service.TransferService(db, nil, models.TransferRequest{
+			FromWalletID: fromID, ToWalletID: fromID, Amount: 300, Currency: "RUB",
+		}, 24*time.Hour)
+		if !errors.Is(err, utils.ErrInvalidRequest) {
+			t.Errorf("TransferService: got %v, want %v", err, utils.ErrInvalidRequest)
+		}
+	})
+}
+
+func TestCreateExchangeRateService(t *testing.T) {
+	tests := []struct {
+		name    string
+		request models.CreateExchangeRateRequest
+		wantErr error
+	}{
+		{"Unsupported currency", models.CreateExchangeRateRequest{BaseCurrency: "EUR", QuoteCurrency: "XYZ", Rate: "1.1", Precision: 4, RoundingMode: "HALF_UP"}, utils.ErrUnsupportedCurrency},
+		{"Same currency", models.CreateExchangeRateRequest{BaseCurrency: "EUR", QuoteCurrency: "EUR", Rate: "1", Precision: 4, RoundingMode: "HALF_UP"}, utils.ErrInvalidRequest},
+		{"Too precise", models.CreateExchangeRateRequest{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.08345", Precision: 4, RoundingMode: "HALF_UP"}, utils.ErrInvalidRequest},
+		{"Unknown rounding mode", models.CreateExchangeRateRequest{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.0834", Precision: 4, RoundingMode: "CEILING"}, utils.ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _, _ := sqlmock.New()
+			defer db.Close()
+
+			_, err := service.CreateExchangeRateService(db, tt.request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateExchangeRateService: got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("INSERT INTO exchange_rates").
+			WithArgs("EUR", "USD", int64(10834), 4, "HALF_EVEN", sqlmock.AnyArg(), nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+		rate, err := service.CreateExchangeRateService(db, models.CreateExchangeRateRequest{
+			BaseCurrency: "eur", QuoteCurrency: "usd", Rate: "1.0834", Precision: 4, RoundingMode: "HALF_EVEN",
+		})
+		if err != nil {
+			t.Fatalf("CreateExchangeRateService: got %v, want nil", err)
+		}
+		if rate.Id != 1 || rate.RateUnits != 10834 {
+			t.Errorf("unexpected rate: %+v", rate)
+		}
+	})
+}
+
+func TestHandleOperationService_Overflow(t *testing.T) {
+	testWalletID := "f4c863ec-0300-495d-852d-c115e197390b"
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+		WithArgs(testWalletID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(testWalletID, int64(math.MaxInt64-10), "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+	mock.ExpectRollback()
+
+	err := service.HandleOperationService(db, nil, models.WalletOperationRequest{
+		WalletID: testWalletID, OperationType: "DEPOSIT", Amount: 11, Currency: "RUB",
+	})
+	if !errors.Is(err, utils.ErrAmountOverflow) {
+		t.Errorf("HandleOperationService: got %v, want %v", err, utils.ErrAmountOverflow)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReconcileService(t *testing.T) {
+	discrepancyRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "currency", "balance", "ledger"}).
+			AddRow("f4c863ec-0300-495d-852d-c115e197390b", "RUB", 1500, 1000)
+	}
+
+	t.Run("Test 1: Report only", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM wallets").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery("SELECT w.id").WillReturnRows(discrepancyRows())
+		mock.ExpectRollback()
+
+		report, err := service.ReconcileService(db, nil, false)
+		if err != nil {
+			t.Fatalf("ReconcileService: got %v, want nil", err)
+		}
+		if report.WalletsChecked != 3 || len(report.Discrepancies) != 1 || report.Discrepancies[0].Frozen {
+			t.Errorf("unexpected report: %+v", report)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Mismatched wallets frozen", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM wallets").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery("SELECT w.id").WillReturnRows(discrepancyRows())
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE wallets SET status = 'FROZEN'").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("f4c863ec-0300-495d-852d-c115e197390b"))
+		expectStatusEvent(mock, "f4c863ec-0300-495d-852d-c115e197390b", true)
+		mock.ExpectCommit()
+		// The lock is held until the freeze is done.
+		mock.ExpectRollback()
+
+		report, err := service.ReconcileService(db, nil, true)
+		if err != nil {
+			t.Fatalf("ReconcileService: got %v, want nil", err)
+		}
+		if !report.Discrepancies[0].Frozen {
+			t.Errorf("expected wallet to be frozen: %+v", report.Discrepancies[0])
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 3: Nothing to freeze", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM wallets").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery("SELECT w.id").WillReturnRows(sqlmock.NewRows([]string{"id", "currency", "balance", "ledger"}))
+		mock.ExpectRollback()
+
+		report, err := service.ReconcileService(db, nil, true)
+		if err != nil {
+			t.Fatalf("ReconcileService: got %v, want nil", err)
+		}
+		if report.Discrepancies == nil || len(report.Discrepancies) != 0 {
+			t.Errorf("unexpected discrepancies: %+v", report.Discrepancies)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 4: Another instance is reconciling", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+		mock.ExpectRollback()
+
+		report, err := service.ReconcileService(db, nil, true)
+		if err != nil || report != nil {
+			t.Errorf("ReconcileService: got %+v, %v, want nil, nil", report, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestGetBalanceAtService(t *testing.T) {
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	walletRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(walletID, 5000, "RUB", "ACTIVE", false, "", "", "{}", created, time.Now())
+	}
+
+	t.Run("Test 1: Balance from snapshot and later entries", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		at := time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC)
+		mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1").
+			WithArgs(walletID).
+			WillReturnRows(walletRows())
+		mock.ExpectQuery("SELECT t.type, e.created_at FROM ledger_entries e .* ORDER BY e.id LIMIT 1").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"type", "created_at"}).AddRow("DEPOSIT", created))
+		mock.ExpectQuery("WITH s AS \\(.*FROM balance_snapshots.*FROM ledger_entries").
+			WithArgs(walletID, at).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(1200))
+
+		wallet, err := service.GetBalanceAtService(db, walletID, at)
+		if err != nil {
+			t.Fatalf("GetBalanceAtService: got %v, want nil", err)
+		}
+		if wallet.Balance != 1200 || wallet.Currency != "RUB" {
+			t.Errorf("unexpected wallet: %+v", wallet)
+		}
+	})
+
+	t.Run("Test 2: Before the wallet existed", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1").
+			WithArgs(walletID).
+			WillReturnRows(walletRows())
+
+		_, err := service.GetBalanceAtService(db, walletID, created.Add(-time.Hour))
+		if !errors.Is(err, utils.ErrWalletNotFound) {
+			t.Errorf("GetBalanceAtService: got %v, want %v", err, utils.ErrWalletNotFound)
+		}
+	})
+
+	t.Run("Test 3: Before the opening balance was recorded", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		// The wallet predates the ledger, which starts with its opening balance.
+		opened := created.AddDate(0, 2, 0)
+		mock.ExpectQuery("SELECT id, balance, currency, status, deposits_blocked, COALESCE\\(owner, ''\\), COALESCE\\(name, ''\\), labels, created_at, updated_at FROM wallets WHERE id = \\$1").
+			WithArgs(walletID).
+			WillReturnRows(walletRows())
+		mock.ExpectQuery("SELECT t.type, e.created_at FROM ledger_entries e").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"type", "created_at"}).AddRow("OPENING", opened))
+
+		_, err := service.GetBalanceAtService(db, walletID, opened.Add(-time.Hour))
+		if !errors.Is(err, utils.ErrHistoryUnavailable) {
+			t.Errorf("GetBalanceAtService: got %v, want %v", err, utils.ErrHistoryUnavailable)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 4: Future time", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		_, err := service.GetBalanceAtService(db, walletID, time.Now().Add(time.Hour))
+		if !errors.Is(err, utils.ErrInvalidRequest) {
+			t.Errorf("GetBalanceAtService: got %v, want %v", err, utils.ErrInvalidRequest)
+		}
+	})
+}
+
+func TestTakeBalanceSnapshotsService(t *testing.T) {
+	expectLockAndVisibility := func(mock sqlmock.Sqlmock, visible bool) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		mock.ExpectQuery("SELECT pg_has_role\\(current_user, 'pg_read_all_stats', 'USAGE'\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"pg_has_role"}).AddRow(visible))
+	}
+
+	t.Run("Test 1: Snapshots changed wallets since the last run", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		last := time.Now().Add(-2 * time.Hour)
+		cutoff := time.Now().Add(-time.Hour)
+		expectLockAndVisibility(mock, true)
+		mock.ExpectQuery("SELECT MAX\\(taken_at\\) FROM balance_snapshots").WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(last))
+		// The database picks the earlier of lag ago and the oldest open transaction.
+		mock.ExpectQuery("SELECT LEAST\\(NOW\\(\\) - \\$1 \\* INTERVAL '1 microsecond', MIN\\(xact_start\\)").
+			WithArgs(time.Minute.Microseconds()).
+			WillReturnRows(sqlmock.NewRows([]string{"least"}).AddRow(cutoff))
+		mock.ExpectExec("INSERT INTO balance_snapshots").
+			WithArgs(last, cutoff).
+			WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectCommit()
+
+		taken, err := service.TakeBalanceSnapshotsService(db, time.Minute)
+		if err != nil || taken != 4 {
+			t.Errorf("TakeBalanceSnapshotsService = %d, %v; want 4, nil", taken, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Role cannot see other sessions", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectLockAndVisibility(mock, false)
+		mock.ExpectRollback()
+
+		taken, err := service.TakeBalanceSnapshotsService(db, time.Minute)
+		if !errors.Is(err, utils.ErrDatabase) || taken != 0 {
+			t.Errorf("TakeBalanceSnapshotsService = %d, %v; want 0, %v", taken, err, utils.ErrDatabase)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 3: Open transaction older than the last run", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		last := time.Now().Add(-time.Hour)
+		expectLockAndVisibility(mock, true)
+		mock.ExpectQuery("SELECT MAX\\(taken_at\\) FROM balance_snapshots").WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(last))
+		mock.ExpectQuery("SELECT LEAST").
+			WillReturnRows(sqlmock.NewRows([]string{"least"}).AddRow(last.Add(-time.Minute)))
+		mock.ExpectRollback()
+
+		taken, err := service.TakeBalanceSnapshotsService(db, time.Minute)
+		if err != nil || taken != 0 {
+			t.Errorf("TakeBalanceSnapshotsService = %d, %v; want 0, nil", taken, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 4: Another instance holds the lock", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+		mock.ExpectRollback()
+
+		taken, err := service.TakeBalanceSnapshotsService(db, time.Minute)
+		if err != nil || taken != 0 {
+			t.Errorf("TakeBalanceSnapshotsService = %d, %v; want 0, nil", taken, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestHandleOperationService_WalletStatus(t *testing.T) {
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	tests := []struct {
+		name            string
+		status          string
+		depositsBlocked bool
+		operationType   string
+		wantErr         error
+	}{
+		{"Withdraw from frozen wallet", "FROZEN", false, service.WITHDRAW, utils.ErrWalletFrozen},
+		{"Deposit to frozen wallet blocking deposits", "FROZEN", true, service.DEPOSIT, utils.ErrWalletFrozen},
+		{"Deposit to closed wallet", "CLOSED", false, service.DEPOSIT, utils.ErrWalletClosed},
+		{"Withdraw from closed wallet", "CLOSED", false, service.WITHDRAW, utils.ErrWalletClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+				WithArgs(walletID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+					AddRow(walletID, 1000, "RUB", tt.status, tt.depositsBlocked, "", "", "{}", time.Now(), time.Now()))
+			mock.ExpectRollback()
+
+			err := service.HandleOperationService(db, nil, models.WalletOperationRequest{
+				WalletID: walletID, OperationType: tt.operationType, Amount: 100, Currency: "RUB",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("HandleOperationService: got %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("Deposit to frozen wallet", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, 1000, "RUB", "FROZEN", false, "", "", "{}", time.Now(), time.Now()))
+		mock.ExpectExec("UPDATE wallets SET balance").
+			WithArgs(int64(100), walletID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectLedgerPosting(mock, "CASH_IN")
+		expectOutboxEvent(mock, walletID)
+		mock.ExpectCommit()
+
+		err := service.HandleOperationService(db, nil, models.WalletOperationRequest{
+			WalletID: walletID, OperationType: service.DEPOSIT, Amount: 100, Currency: "RUB",
+		})
+		if err != nil {
+			t.Errorf("HandleOperationService: got %v, want nil", err)
+		}
+	})
+}
+
+func TestWalletStatusTransitions(t *testing.T) {
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	expectLockedWallet := func(mock sqlmock.Sqlmock, balance int, status string) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, balance, "RUB", status, status == "FROZEN", "", "", "{}", time.Now(), time.Now()))
+	}
+
+	t.Run("Test 1: Freeze an active wallet", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectLockedWallet(mock, 1000, "ACTIVE")
+		mock.ExpectExec("UPDATE wallets SET status = \\$1, deposits_blocked = \\$2").
+			WithArgs("FROZEN", true, walletID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO wallet_status_changes").
+			WithArgs(walletID, "ACTIVE", "FROZEN", "AML check").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectStatusEvent(mock, walletID, true)
+		mock.ExpectCommit()
+
+		wallet, err := service.FreezeWalletService(db, nil, walletID, " AML check ", true)
+		if err != nil {
+			t.Fatalf("FreezeWalletService: got %v, want nil", err)
+		}
+		if wallet.Status != models.WalletFrozen || !wallet.DepositsBlocked {
+			t.Errorf("unexpected wallet: %+v", wallet)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Unfreeze clears blocked deposits", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectLockedWallet(mock, 1000, "FROZEN")
+		mock.ExpectExec("UPDATE wallets SET status").
+			WithArgs("ACTIVE", false, walletID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO wallet_status_changes").
+			WithArgs(walletID, "FROZEN", "ACTIVE", "cleared").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectStatusEvent(mock, walletID, false)
+		mock.ExpectCommit()
+
+		wallet, err := service.UnfreezeWalletService(db, nil, walletID, "cleared")
+		if err != nil || wallet.Status != models.WalletActive || wallet.DepositsBlocked {
+			t.Errorf("UnfreezeWalletService = %+v, %v", wallet, err)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		balance    int
+		status     string
+		transition func(db *sql.DB) error
+		wantErr    error
+	}{
+		{"Test 3: Unfreeze an active wallet", 0, "ACTIVE", func(db *sql.DB) error {
+			_, err := service.UnfreezeWalletService(db, nil, walletID, "cleared")
+			return err
+		}, utils.ErrInvalidStatus},
+		{"Test 4: Freeze a closed wallet", 0, "CLOSED", func(db *sql.DB) error {
+			_, err := service.FreezeWalletService(db, nil, walletID, "AML check", false)
+			return err
+		}, utils.ErrInvalidStatus},
+		{"Test 5: Close a wallet with funds", 1000, "ACTIVE", func(db *sql.DB) error {
+			_, err := service.CloseWalletService(db, nil, walletID, "customer request")
+			return err
+		}, utils.ErrWalletNotEmpty},
+		{"Test 6: Close a frozen wallet", 0, "FROZEN", func(db *sql.DB) error {
+			_, err := service.CloseWalletService(db, nil, walletID, "customer request")
+			return err
+		}, utils.ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			expectLockedWallet(mock, tt.balance, tt.status)
+			mock.ExpectRollback()
+
+			if err := tt.transition(db); !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("Test 7: Reason is required", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		_, err := service.CloseWalletService(db, nil, walletID, "  ")
+		if !errors.Is(err, utils.ErrInvalidRequest) {
+			t.Errorf("CloseWalletService: got %v, want %v", err, utils.ErrInvalidRequest)
+		}
+	})
+}
+
+func TestListWalletsService(t *testing.T) {
+	columns := []string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}
+	created := time.Date(2025, 6, 1, 12, 0, 0, 123456000, time.UTC)
+
+	t.Run("Test 1: Next page continues after the last wallet", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("FROM wallets WHERE currency = \\$1 ORDER BY created_at ASC, id ASC LIMIT \\$2").
+			WithArgs("EUR", 3).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("w1", 100, "EUR", "ACTIVE", false, "", "", "{}", created, created).
+				AddRow("w2", 200, "EUR", "ACTIVE", false, "", "", "{}", created, created).
+				AddRow("w3", 300, "EUR", "ACTIVE", false, "", "", "{}", created, created))
+
+		page, err := service.ListWalletsService(db, models.WalletFilter{Currency: "EUR", Limit: 2}, "")
+		if err != nil {
+			t.Fatalf("ListWalletsService: got %v, want nil", err)
+		}
+		if len(page.Wallets) != 2 || page.NextCursor == "" {
+			t.Fatalf("unexpected page: %+v", page)
+		}
+
+		mock.ExpectQuery("FROM wallets WHERE currency = \\$1 AND \\(created_at, id\\) > \\(\\$2, \\$3\\)").
+			WithArgs("EUR", created, "w2", 3).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("w3", 300, "EUR", "ACTIVE", false, "", "", "{}", created, created))
+
+		page, err = service.ListWalletsService(db, models.WalletFilter{Currency: "EUR", Limit: 2}, page.NextCursor)
+		if err != nil {
+			t.Fatalf("ListWalletsService: got %v, want nil", err)
+		}
+		if len(page.Wallets) != 1 || page.NextCursor != "" {
+			t.Errorf("unexpected last page: %+v", page)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Invalid cursor", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		_, err := service.ListWalletsService(db, models.WalletFilter{}, "not-a-cursor")
+		if !errors.Is(err, utils.ErrInvalidRequest) {
+			t.Errorf("ListWalletsService: got %v, want %v", err, utils.ErrInvalidRequest)
+		}
+	})
+
+	t.Run("Test 3: Cursor issued for another sort order", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("FROM wallets ORDER BY balance ASC").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("w1", 100, "EUR", "ACTIVE", false, "", "", "{}", created, created).
+				AddRow("w2", 200, "EUR", "ACTIVE", false, "", "", "{}", created, created))
+
+		page, err := service.ListWalletsService(db, models.WalletFilter{Sort: models.WalletSortBalance, Limit: 1}, "")
+		if err != nil {
+			t.Fatalf("ListWalletsService: got %v, want nil", err)
+		}
+
+		_, err = service.ListWalletsService(db, models.WalletFilter{Limit: 1}, page.NextCursor)
+		if !errors.Is(err, utils.ErrInvalidRequest) {
+			t.Errorf("ListWalletsService: got %v, want %v", err, utils.ErrInvalidRequest)
+		}
+	})
+}
+
+func TestUpdateWalletService(t *testing.T) {
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	expectLockedWallet := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, 1000, "RUB", "ACTIVE", false, "customer-1", "Savings", `{"tier": "gold", "region": "eu"}`, time.Now(), time.Now()))
+	}
+	ptr := func(s string) *string { return &s }
+
+	t.Run("Test 1: Labels are merged and removed", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectLockedWallet(mock)
+		mock.ExpectQuery("UPDATE wallets SET owner = NULLIF\\(\\$1, ''\\), name = NULLIF\\(\\$2, ''\\), labels = \\$3").
+			WithArgs("customer-1", "", []byte(`{"region":"eu","tier":"platinum"}`), walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+		mock.ExpectCommit()
+
+		wallet, err := service.UpdateWalletService(db, nil, walletID, models.UpdateWalletRequest{
+			Name:   ptr(""),
+			Labels: map[string]*string{"tier": ptr("platinum"), "vip": nil},
+		})
+		if err != nil {
+			t.Fatalf("UpdateWalletService: got %v, want nil", err)
+		}
+		if wallet.Name != "" || wallet.Owner != "customer-1" || len(wallet.Labels) != 2 {
+			t.Errorf("unexpected wallet: %+v", wallet)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Invalid label key", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectLockedWallet(mock)
+		mock.ExpectRollback()
+
+		_, err := service.UpdateWalletService(db, nil, walletID, models.UpdateWalletRequest{
+			Labels: map[string]*string{"bad key": ptr("x")},
+		})
+		if !errors.Is(err, utils.ErrInvalidRequest) {
+			t.Errorf("UpdateWalletService: got %v, want %v", err, utils.ErrInvalidRequest)
+		}
+	})
+
+	t.Run("Test 3: Wallet not found", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").WithArgs(walletID).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := service.UpdateWalletService(db, nil, walletID, models.UpdateWalletRequest{Owner: ptr("customer-2")})
+		if !errors.Is(err, utils.ErrWalletNotFound) {
+			t.Errorf("UpdateWalletService: got %v, want %v", err, utils.ErrWalletNotFound)
+		}
+	})
+}
+
+func TestHandleOperationService_Details(t *testing.T) {
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	request := models.WalletOperationRequest{
+		WalletID: walletID, OperationType: service.DEPOSIT, Amount: 100, Currency: "RUB",
+		OperationDetails: models.OperationDetails{
+			Description: " Order #1001 ",
+			Reference:   "order-1001",
+			Metadata:    map[string]string{"invoice": "INV-7"},
+		},
+	}
+
+	expectPosting := func(mock sqlmock.Sqlmock) *sqlmock.ExpectedQuery {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+		expectReferenceCheck(mock, walletID, "order-1001", false)
+		mock.ExpectExec("UPDATE wallets SET balance").
+			WithArgs(int64(100), walletID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT id FROM ledger_accounts").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("00000000-0000-0000-0000-000000000001"))
+		return mock.ExpectQuery("INSERT INTO ledger_transactions").
+			WithArgs(sqlmock.AnyArg(), "DEPOSIT", walletID, "Order #1001", "order-1001", []byte(`{"invoice":"INV-7"}`))
+	}
+
+	t.Run("Test 1: Details stored with the ledger transaction", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectPosting(mock).WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+		mock.ExpectExec("INSERT INTO ledger_entries").WillReturnResult(sqlmock.NewResult(0, 2))
+		expectOutboxEvent(mock, walletID)
+		mock.ExpectCommit()
+
+		if err := service.HandleOperationService(db, nil, request); err != nil {
+			t.Fatalf("HandleOperationService: got %v, want nil", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Reference taken by a concurrent operation", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectPosting(mock).WillReturnError(&pq.Error{Code: "23505", Constraint: "ledger_transactions_wallet_reference_idx"})
+		mock.ExpectRollback()
+
+		err := service.HandleOperationService(db, nil, request)
+		if !errors.Is(err, utils.ErrDuplicateReference) {
+			t.Errorf("HandleOperationService: got %v, want %v", err, utils.ErrDuplicateReference)
+		}
+	})
+
+	// A retry of an applied withdrawal must be reported as a duplicate even
+	// when the wallet could no longer accept it.
+	for _, wallet := range []struct {
+		name    string
+		balance int
+		status  string
+	}{
+		{"balance gone", 0, "ACTIVE"},
+		{"wallet frozen", 1000, "FROZEN"},
+	} {
+		t.Run("Retry of an applied withdrawal, "+wallet.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+				WithArgs(walletID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+					AddRow(walletID, wallet.balance, "RUB", wallet.status, false, "", "", "{}", time.Now(), time.Now()))
+			expectReferenceCheck(mock, walletID, "order-1001", true)
+			mock.ExpectRollback()
+
+			withdrawal := request
+			withdrawal.OperationType = service.WITHDRAW
+			withdrawal.Amount = 500
+			err := service.HandleOperationService(db, nil, withdrawal)
+			if !errors.Is(err, utils.ErrDuplicateReference) {
+				t.Errorf("HandleOperationService: got %v, want %v", err, utils.ErrDuplicateReference)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("Test 3: Reference too long", func(t *testing.T) {
+		db, _, _ := sqlmock.New()
+		defer db.Close()
+
+		invalid := request
+		invalid.Reference = strings.Repeat("x", service.MaxReferenceLength+1)
+		err := service.HandleOperationService(db, nil, invalid)
+		if !errors.Is(err, utils.ErrInvalidRequest) {
+			t.Errorf("HandleOperationService: got %v, want %v", err, utils.ErrInvalidRequest)
+		}
+	})
+}
+
+// balanceChangedPayload matches a wallet.balance_changed payload by its balance, delta and operation.
+type balanceChangedPayload struct {
+	balance   uint64
+	delta     int64
+	operation string
+	reference string
+}
+
+func (p balanceChangedPayload) Match(v driver.Value) bool {
+	data, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	var event models.BalanceChangedEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return false
+	}
+	return event.Type == models.EventBalanceChanged && event.EventId != "" && event.TransactionId != "" &&
+		event.Balance == p.balance && event.Delta == p.delta && event.Operation == p.operation &&
+		event.Reference == p.reference
+}
+
+func TestHandleOperationService_OutboxEvent(t *testing.T) {
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+
+	t.Run("Test 1: Event written in the operation transaction", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+		expectReferenceCheck(mock, walletID, "payout-7", false)
+		mock.ExpectExec("UPDATE wallets SET balance").
+			WithArgs(int64(-400), walletID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectLedgerPosting(mock, "CASH_OUT")
+		mock.ExpectQuery("INSERT INTO outbox_events").
+			WithArgs(sqlmock.AnyArg(), "wallet.balance_changed", walletID,
+				balanceChangedPayload{balance: 600, delta: -400, operation: "WITHDRAW", reference: "payout-7"}).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+		// The balance before and after decide which low balance thresholds were crossed.
+		expectWebhookDeliveries(mock, 1).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), `{"withdrawal","low_balance"}`, walletID, int64(1000), int64(600))
+		mock.ExpectCommit()
+
+		err := service.HandleOperationService(db, nil, models.WalletOperationRequest{
+			WalletID: walletID, OperationType: "WITHDRAW", Amount: 400, Currency: "RUB",
+			OperationDetails: models.OperationDetails{Reference: "payout-7"},
+		})
+		if err != nil {
+			t.Fatalf("HandleOperationService: got %v, want nil", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Operation rolled back when the event cannot be written", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, 1000, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+		mock.ExpectExec("UPDATE wallets SET balance").
+			WithArgs(int64(100), walletID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectLedgerPosting(mock, "CASH_IN")
+		mock.ExpectQuery("INSERT INTO outbox_events").WillReturnError(errors.New("disk full"))
+		mock.ExpectRollback()
+
+		err := service.HandleOperationService(db, nil, models.WalletOperationRequest{
+			WalletID: walletID, OperationType: "DEPOSIT", Amount: 100, Currency: "RUB",
+		})
+		if !errors.Is(err, utils.ErrDatabase) {
+			t.Errorf("HandleOperationService: got %v, want %v", err, utils.ErrDatabase)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// recordingSink records the published events and fails those in fail.
+type recordingSink struct {
+	fail      map[int64]bool
+	published []int64
+}
+
+func (s *recordingSink) Publish(_ context.Context, event models.OutboxEvent) error {
+	if s.fail[event.Id] {
+		return errors.New("connection refused")
+	}
+	s.published = append(s.published, event.Id)
+	return nil
+}
+
+func TestDispatchOutboxService(t *testing.T) {
+	const (
+		walletA = "f4c863ec-0300-495d-852d-c115e197390b"
+		walletB = "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c"
+	)
+	pendingRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "event_id", "type", "wallet_id", "payload", "created_at", "attempts"}).
+			AddRow(1, "e1", "wallet.balance_changed", walletA, []byte(`{}`), time.Now(), 2).
+			AddRow(2, "e2", "wallet.balance_changed", walletB, []byte(`{}`), time.Now(), 0).
+			AddRow(3, "e3", "wallet.balance_changed", walletA, []byte(`{}`), time.Now(), 0)
+	}
+
+	t.Run("Test 1: Failed event holds back its wallet", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM outbox_events e WHERE e.published_at IS NULL AND NOT EXISTS").
+			WithArgs(10).
+			WillReturnRows(pendingRows())
+		mock.ExpectExec("UPDATE outbox_events SET attempts = attempts \\+ 1").
+			WithArgs(int64(1), "connection refused", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE outbox_events SET published_at = NOW\\(\\)").
+			WithArgs(int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		sink := &recordingSink{fail: map[int64]bool{1: true}}
+		delivered, pending, err := service.DispatchOutboxService(context.Background(), db, sink, 10, time.Minute)
+		if err != nil {
+			t.Fatalf("DispatchOutboxService: got %v, want nil", err)
+		}
+		if delivered != 1 || pending != 3 {
+			t.Errorf("DispatchOutboxService: got %d delivered of %d, want 1 of 3", delivered, pending)
+		}
+		if len(sink.published) != 1 || sink.published[0] != 2 {
+			t.Errorf("published %v, want [2]", sink.published)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Unrecorded delivery holds back its wallet", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM outbox_events e WHERE e.published_at IS NULL AND NOT EXISTS").
+			WithArgs(10).
+			WillReturnRows(pendingRows())
+		mock.ExpectExec("UPDATE outbox_events SET published_at = NOW\\(\\)").
+			WithArgs(int64(1)).
+			WillReturnError(errors.New("connection reset"))
+		mock.ExpectExec("UPDATE outbox_events SET published_at = NOW\\(\\)").
+			WithArgs(int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		sink := &recordingSink{}
+		delivered, pending, err := service.DispatchOutboxService(context.Background(), db, sink, 10, time.Minute)
+		if !errors.Is(err, utils.ErrDatabase) {
+			t.Errorf("DispatchOutboxService: got %v, want %v", err, utils.ErrDatabase)
+		}
+		if delivered != 1 || pending != 3 {
+			t.Errorf("DispatchOutboxService: got %d delivered of %d, want 1 of 3", delivered, pending)
+		}
+		if len(sink.published) != 2 || sink.published[0] != 1 || sink.published[1] != 2 {
+			t.Errorf("published %v, want [1 2]", sink.published)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestPruneOutboxService(t *testing.T) {
+	tests := []struct {
+		name       string
+		dispatched bool
+		query      string
+	}{
+		{"Test 1: Delivered events past retention", true, "DELETE FROM outbox_events WHERE published_at < \\$1"},
+		{"Test 2: Without a dispatcher, events past retention", false, "DELETE FROM outbox_events WHERE created_at < \\$1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			mock.ExpectExec(tt.query).WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 3))
+
+			removed, err := service.PruneOutboxService(db, time.Hour, tt.dispatched)
+			if err != nil || removed != 3 {
+				t.Errorf("PruneOutboxService: got %d, %v, want 3, nil", removed, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCreateWebhookService(t *testing.T) {
+	threshold := int64(1000)
+	valid := models.CreateWebhookRequest{
+		ClientId: "billing", URL: "https://billing.example.com/hooks",
+		Events: []string{"deposit", "low_balance", "deposit"}, LowBalanceThreshold: &threshold,
+	}
+
+	t.Run("Test 1: Endpoint registered with a secret", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("INSERT INTO webhook_subscriptions").
+			WithArgs(sqlmock.AnyArg(), "billing", "https://billing.example.com/hooks", sqlmock.AnyArg(),
+				`{"deposit","low_balance"}`, "", &threshold).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+
+		subscription, err := service.CreateWebhookService(db, valid)
+		if err != nil {
+			t.Fatalf("CreateWebhookService: got %v, want nil", err)
+		}
+		if !strings.HasPrefix(subscription.Secret, "whsec_") || len(subscription.Events) != 2 {
+			t.Errorf("unexpected endpoint: %+v", subscription)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		modify func(r *models.CreateWebhookRequest)
+	}{
+		{"Missing client", func(r *models.CreateWebhookRequest) { r.ClientId = " " }},
+		{"Relative URL", func(r *models.CreateWebhookRequest) { r.URL = "/hooks" }},
+		{"Unknown event", func(r *models.CreateWebhookRequest) { r.Events = []string{"refund"} }},
+		{"No events", func(r *models.CreateWebhookRequest) { r.Events = nil }},
+		{"Low balance without threshold", func(r *models.CreateWebhookRequest) { r.LowBalanceThreshold = nil }},
+		{"Threshold without low balance", func(r *models.CreateWebhookRequest) { r.Events = []string{"freeze"} }},
+		{"Invalid wallet", func(r *models.CreateWebhookRequest) { r.WalletId = "wallet-1" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _, _ := sqlmock.New()
+			defer db.Close()
+
+			request := valid
+			tt.modify(&request)
+			if _, err := service.CreateWebhookService(db, request); !errors.Is(err, utils.ErrInvalidRequest) {
+				t.Errorf("CreateWebhookService: got %v, want %v", err, utils.ErrInvalidRequest)
+			}
+		})
+	}
+}
+
+func TestDeliverWebhooksService(t *testing.T) {
+	const secret = "whsec_test"
+
+	var received []string
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhooks.Verify(secret, r.Header.Get(webhooks.SignatureHeader), body, time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var payload models.WebhookPayload
+		_ = json.Unmarshal(body, &payload)
+		received = append(received, payload.Type+":"+string(payload.Data))
+	}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	columns := []string{"id", "subscription_id", "event_id", "event_type", "payload", "status", "attempts",
+		"last_status_code", "last_error", "next_attempt_at", "created_at", "delivered_at", "url", "secret"}
+	row := func(rows *sqlmock.Rows, id int64, eventType string, attempts int, url, payload string) *sqlmock.Rows {
+		return rows.AddRow(id, "9e4c2f1a-6b7d-4c8e-a0f3-2d5b8c1e7f90", "0b8f2a4c-4d1e-4b7a-9d3c-6f5e2a1b0c9d",
+			eventType, []byte(payload), "PENDING", attempts, nil, "", time.Now(), time.Now(), nil, url, secret)
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	rows := sqlmock.NewRows(columns)
+	row(rows, 1, "withdrawal", 0, ok.URL, `{"balance":600}`)
+	row(rows, 2, "low_balance", 1, failing.URL, `{"balance":600}`)
+	row(rows, 3, "low_balance", 9, failing.URL, `{"balance":600}`)
+	row(rows, 4, "withdrawal", 0, ok.URL, `{"balance":`)
+	// The deliveries are leased in a statement of their own and sent
+	// outside any transaction.
+	mock.ExpectQuery("WITH due AS \\(.* FOR UPDATE SKIP LOCKED\\s*\\)\\s*UPDATE webhook_deliveries d SET next_attempt_at = \\$2").
+		WithArgs(10, sqlmock.AnyArg()).
+		WillReturnRows(rows)
+	mock.ExpectExec("UPDATE webhook_deliveries SET status = 'DELIVERED'").
+		WithArgs(int64(1), 200).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// An outcome that cannot be recorded does not stop the others.
+	mock.ExpectExec("UPDATE webhook_deliveries SET status = \\$2").
+		WithArgs(int64(2), "PENDING", 500, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnError(sql.ErrConnDone)
+	// The tenth failed attempt dead-letters the delivery.
+	mock.ExpectExec("UPDATE webhook_deliveries SET status = \\$2").
+		WithArgs(int64(3), "DEAD", 500, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// A payload that cannot be encoded is dead-lettered without being sent.
+	mock.ExpectExec("UPDATE webhook_deliveries SET status = \\$2").
+		WithArgs(int64(4), "DEAD", 0, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	delivered, attempted, err := service.DeliverWebhooksService(context.Background(), db, &webhooks.Sender{}, 10, 10,
+		time.Hour, time.Minute)
+	if !errors.Is(err, utils.ErrDatabase) {
+		t.Errorf("DeliverWebhooksService: got %v, want %v", err, utils.ErrDatabase)
+	}
+	if delivered != 1 || attempted != 4 {
+		t.Errorf("DeliverWebhooksService: got %d delivered of %d, want 1 of 4", delivered, attempted)
+	}
+	if len(received) != 1 || received[0] != `withdrawal:{"balance":600}` {
+		t.Errorf("receiver got %v", received)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+type wakeNotifier chan struct{}
+
+func (n wakeNotifier) Subscribe(string) (<-chan struct{}, func()) { return n, func() {} }
+
+func TestFollowWalletEventsService(t *testing.T) {
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+	eventRows := func(ids ...int64) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"id", "event_id", "type", "wallet_id", "payload", "created_at", "attempts"})
+		for _, id := range ids {
+			rows.AddRow(id, "0b8f2a4c-4d1e-4b7a-9d3c-6f5e2a1b0c9d", models.EventBalanceChanged, walletID,
+				[]byte(`{"balance": 1500}`), time.Now(), 0)
+		}
+		return rows
+	}
+
+	t.Run("Test 1: Sends the backlog, then new events when woken", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		q := "SELECT id, event_id, type, wallet_id, payload, created_at, attempts FROM outbox_events WHERE wallet_id = \\$1 AND id > \\$2"
+		mock.ExpectQuery(q).WithArgs(walletID, int64(4), 100).WillReturnRows(eventRows(5, 6))
+		mock.ExpectQuery(q).WithArgs(walletID, int64(6), 100).WillReturnRows(eventRows(7))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		notifier := make(wakeNotifier, 1)
+
+		var sent []int64
+		err := service.FollowWalletEventsService(ctx, db, notifier, walletID, 4, time.Hour,
+			func(events []models.OutboxEvent) error {
+				for _, event := range events {
+					sent = append(sent, event.Id)
+				}
+				if len(sent) == 2 {
+					notifier <- struct{}{}
+				} else {
+					cancel()
+				}
+				return nil
+			})
+
+		if err != nil {
+			t.Fatalf("FollowWalletEventsService: %v", err)
+		}
+		if len(sent) != 3 || sent[0] != 5 || sent[2] != 7 {
+			t.Errorf("sent events %v, want [5 6 7]", sent)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Heartbeat with nothing new", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM outbox_events").WillReturnRows(eventRows())
+		mock.ExpectQuery("SELECT .* FROM outbox_events").WillReturnRows(eventRows())
+
+		stop := errors.New("stop")
+		beats := 0
+		err := service.FollowWalletEventsService(context.Background(), db, make(wakeNotifier), walletID, 0,
+			10*time.Millisecond, func(events []models.OutboxEvent) error {
+				if len(events) == 0 {
+					beats++
+				}
+				return stop
+			})
+
+		if !errors.Is(err, stop) || beats != 1 {
+			t.Errorf("got %v after %d heartbeats, want the send error after 1", err, beats)
+		}
+	})
+
+	t.Run("Test 3: Error DataBase", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM outbox_events").WillReturnError(sql.ErrConnDone)
+
+		err := service.FollowWalletEventsService(context.Background(), db, make(wakeNotifier), walletID, 0,
+			time.Hour, func([]models.OutboxEvent) error { return nil })
+
+		if !errors.Is(err, utils.ErrDatabase) {
+			t.Errorf("got %v, want %v", err, utils.ErrDatabase)
+		}
+	})
+}
+
+func TestValidateOperationRequest(t *testing.T) {
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+	tests := []struct {
+		name    string
+		request models.WalletOperationRequest
+		want    error
+	}{
+		{"Valid", models.WalletOperationRequest{WalletID: walletID, OperationType: service.DEPOSIT, Amount: 100, Currency: "RUB"}, nil},
+		{"Zero amount before invalid id", models.WalletOperationRequest{WalletID: "x", OperationType: service.DEPOSIT, Currency: "RUB"}, utils.ErrNegativeBalance},
+		{"Invalid id", models.WalletOperationRequest{WalletID: "x", OperationType: service.DEPOSIT, Amount: 100, Currency: "RUB"}, utils.ErrInvalidRequest},
+		{"Lowercase type", models.WalletOperationRequest{WalletID: walletID, OperationType: "deposit", Amount: 100, Currency: "RUB"}, utils.ErrInvalidRequest},
+		{"Missing currency", models.WalletOperationRequest{WalletID: walletID, OperationType: service.WITHDRAW, Amount: 100}, utils.ErrInvalidRequest},
+		{"Unsupported currency", models.WalletOperationRequest{WalletID: walletID, OperationType: service.WITHDRAW, Amount: 100, Currency: "XYZ"}, utils.ErrUnsupportedCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.ValidateOperationRequest(tt.request); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportWalletService(t *testing.T) {
+	testWalletID := "f4c863ec-0300-495d-852d-c115e197390b"
+
+	expectImport := func(mock sqlmock.Sqlmock, balance int64) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO wallets").
+			WithArgs(testWalletID, "EUR", "legacy-17", "", []byte("{}")).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+		expectLedgerPosting(mock, "OPENING")
+		mock.ExpectExec("UPDATE wallets SET balance = balance \\+ \\$1").
+			WithArgs(balance, testWalletID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	t.Run("Test 1: Frozen wallet with an opening balance", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectImport(mock, 1500)
+		mock.ExpectExec("UPDATE wallets SET status").
+			WithArgs("FROZEN", true, testWalletID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO wallet_status_changes").
+			WithArgs(testWalletID, "ACTIVE", "FROZEN", service.ImportStatusReason).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		wallet, err := service.ImportWalletService(context.Background(), db, models.WalletImport{
+			Id: strings.ToUpper(testWalletID), Currency: "eur", Balance: 1500, Status: "frozen", DepositsBlocked: true,
+			Owner: "legacy-17", OperationDetails: models.OperationDetails{Reference: "ACC-17"},
+		}, false)
+		if err != nil {
+			t.Fatalf("ImportWalletService: got %v, want nil", err)
+		}
+		if wallet.Id != testWalletID || wallet.Balance != 1500 || wallet.Status != models.WalletFrozen || !wallet.DepositsBlocked {
+			t.Errorf("unexpected wallet: %+v", wallet)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("Test 2: Dry run rolls back", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		expectImport(mock, 200)
+		mock.ExpectRollback()
+
+		_, err := service.ImportWalletService(context.Background(), db, models.WalletImport{
+			Id: testWalletID, Currency: "EUR", Balance: 200, Owner: "legacy-17",
+		}, true)
+		if err != nil {
+			t.Fatalf("ImportWalletService: got %v, want nil", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("Test 3: Existing wallet", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO wallets").
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "wallets_pkey"})
+		mock.ExpectRollback()
+
+		_, err := service.ImportWalletService(context.Background(), db, models.WalletImport{
+			Id: testWalletID, Currency: "EUR", Balance: 200,
+		}, false)
+		if !errors.Is(err, utils.ErrWalletExists) {
+			t.Errorf("ImportWalletService: got %v, want %v", err, utils.ErrWalletExists)
+		}
+	})
+
+	for _, tc := range []struct {
+		name   string
+		record models.WalletImport
+		want   error
+	}{
+		{"invalid id", models.WalletImport{Id: "ACC-17", Currency: "EUR"}, utils.ErrInvalidRequest},
+		{"missing currency", models.WalletImport{Balance: 1}, utils.ErrInvalidRequest},
+		{"unsupported currency", models.WalletImport{Currency: "XYZ"}, utils.ErrUnsupportedCurrency},
+		{"unknown status", models.WalletImport{Currency: "EUR", Status: "DORMANT"}, utils.ErrInvalidRequest},
+		{"active with deposits blocked", models.WalletImport{Currency: "EUR", DepositsBlocked: true}, utils.ErrInvalidRequest},
+		{"closed with a balance", models.WalletImport{Currency: "EUR", Balance: 1, Status: "CLOSED"}, utils.ErrWalletNotEmpty},
+		{"balance overflow", models.WalletImport{Currency: "EUR", Balance: math.MaxInt64 + 1}, utils.ErrAmountOverflow},
+	} {
+		t.Run("Test 4: Rejected "+tc.name, func(t *testing.T) {
+			db, _, _ := sqlmock.New()
+			defer db.Close()
+
+			_, err := service.ImportWalletService(context.Background(), db, tc.record, false)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("ImportWalletService: got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+// timeArg matches a time argument equal to the given time.
+type timeArg time.Time
+
+func (a timeArg) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	return ok && t.Equal(time.Time(a))
+}
+
+func TestCreateScheduledOperationService(t *testing.T) {
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+	start := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	end := start.AddDate(1, 0, 0)
+	valid := models.CreateScheduledOperationRequest{
+		WalletID: walletID, OperationType: service.WITHDRAW, Amount: 1000, Currency: "RUB",
+		Description: " Subscription ", StartAt: start, Schedule: "monthly", EndAt: &end, MaxRetries: 3,
+	}
+	walletRows := func(status string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(walletID, 0, "RUB", status, false, "", "", "{}", time.Now(), time.Now())
+	}
+
+	t.Run("Test 1: First occurrence at the start", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM wallets WHERE id = \\$1").WithArgs(walletID).WillReturnRows(walletRows("ACTIVE"))
+		mock.ExpectQuery("INSERT INTO scheduled_operations").
+			WithArgs(sqlmock.AnyArg(), walletID, service.WITHDRAW, int64(1000), "RUB", "Subscription", []byte("{}"),
+				"monthly", timeArg(start), timeArg(end), 3, "ACTIVE", timeArg(start), 0, timeArg(start)).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(time.Now(), time.Now()))
+
+		operation, err := service.CreateScheduledOperationService(db, valid)
+		if err != nil {
+			t.Fatalf("CreateScheduledOperationService: got %v, want nil", err)
+		}
+		if operation.Status != models.ScheduleActive || !operation.NextRunTime.Equal(start) {
+			t.Errorf("unexpected operation: %+v", operation)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Test 2: Closed wallet", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT .* FROM wallets WHERE id = \\$1").WithArgs(walletID).WillReturnRows(walletRows("CLOSED"))
+
+		if _, err := service.CreateScheduledOperationService(db, valid); !errors.Is(err, utils.ErrWalletClosed) {
+			t.Errorf("CreateScheduledOperationService: got %v, want %v", err, utils.ErrWalletClosed)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		modify func(r *models.CreateScheduledOperationRequest)
+	}{
+		{"Unknown operation", func(r *models.CreateScheduledOperationRequest) { r.OperationType = "REFUND" }},
+		{"Invalid schedule", func(r *models.CreateScheduledOperationRequest) { r.Schedule = "hourly" }},
+		{"Start in the past", func(r *models.CreateScheduledOperationRequest) { r.StartAt = start.AddDate(0, 0, -1) }},
+		{"End before start", func(r *models.CreateScheduledOperationRequest) { r.StartAt = end.Add(time.Hour) }},
+		{"End of a one-off", func(r *models.CreateScheduledOperationRequest) { r.Schedule = "" }},
+		{"Too many retries", func(r *models.CreateScheduledOperationRequest) { r.MaxRetries = 11 }},
+		{"No occurrence before the end", func(r *models.CreateScheduledOperationRequest) {
+			r.Schedule = "0 0 29 2 *"
+			r.EndAt = &r.StartAt
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _, _ := sqlmock.New()
+			defer db.Close()
+
+			request := valid
+			tt.modify(&request)
+			if _, err := service.CreateScheduledOperationService(db, request); !errors.Is(err, utils.ErrInvalidRequest) {
+				t.Errorf("CreateScheduledOperationService: got %v, want %v", err, utils.ErrInvalidRequest)
+			}
+		})
+	}
+}
+
+func TestRunScheduledOperationsService(t *testing.T) {
+	const walletID = "f4c863ec-0300-495d-852d-c115e197390b"
+	start := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	columns := []string{"id", "wallet_id", "operation_type", "amount", "currency", "description", "metadata",
+		"schedule", "start_at", "end_at", "max_retries", "status", "next_run_at", "attempts", "next_attempt_at",
+		"created_at", "updated_at"}
+	row := func(rows *sqlmock.Rows, id, operationType, schedule string, maxRetries, attempts int) *sqlmock.Rows {
+		return rows.AddRow(id, walletID, operationType, 500, "RUB", "", []byte("{}"), schedule, start, nil,
+			maxRetries, "ACTIVE", start, attempts, time.Now().Add(5*time.Minute), start, start)
+	}
+	expectWallet := func(mock sqlmock.Sqlmock, id, status string) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+			WithArgs(walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+				AddRow(walletID, 100, "RUB", status, false, "", "", "{}", time.Now(), time.Now()))
+		expectReferenceCheck(mock, walletID, "schedule:"+id+":1751360400", false)
+	}
+	// expectRecord expects the run and the progress of an operation to be
+	// recorded in a transaction of their own.
+	expectRecord := func(mock sqlmock.Sqlmock, id, status, errorCode string, attempt int, progress ...driver.Value) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO scheduled_operation_runs").
+			WithArgs(id, timeArg(start), attempt, status, "schedule:"+id+":1751360400", errorCode).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "executed_at"}).AddRow(1, time.Now()))
+		mock.ExpectExec("UPDATE scheduled_operations SET status = \\$2").
+			WithArgs(append(append([]driver.Value{id}, progress...), timeArg(start))...).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	rows := sqlmock.NewRows(columns)
+	row(rows, "11111111-1111-4111-8111-111111111111", service.DEPOSIT, "daily", 0, 0)
+	row(rows, "22222222-2222-4222-8222-222222222222", service.WITHDRAW, "weekly", 2, 0)
+	row(rows, "33333333-3333-4333-8333-333333333333", service.WITHDRAW, "", 2, 2)
+	row(rows, "44444444-4444-4444-8444-444444444444", service.WITHDRAW, "monthly", 2, 0)
+	row(rows, "55555555-5555-4555-8555-555555555555", service.DEPOSIT, "daily", 0, 0)
+	// The due operations are leased in a statement of their own.
+	mock.ExpectQuery("WITH due AS \\(.* FOR UPDATE SKIP LOCKED\\s*\\)\\s*UPDATE scheduled_operations SET next_attempt_at = \\$2").
+		WithArgs(10, sqlmock.AnyArg()).
+		WillReturnRows(rows)
+
+	// A successful deposit moves on to the next day.
+	expectWallet(mock, "11111111-1111-4111-8111-111111111111", "ACTIVE")
+	mock.ExpectExec("UPDATE wallets SET balance").WithArgs(int64(500), walletID).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectLedgerPosting(mock, "CASH_IN")
+	expectOutboxEvent(mock, walletID)
+	mock.ExpectCommit()
+	next := start.AddDate(0, 0, 1)
+	expectRecord(mock, "11111111-1111-4111-8111-111111111111", "SUCCEEDED", "", 1,
+		"ACTIVE", timeArg(next), 0, timeArg(next))
+
+	// Insufficient funds is retried later at the same occurrence.
+	expectWallet(mock, "22222222-2222-4222-8222-222222222222", "ACTIVE")
+	mock.ExpectRollback()
+	expectRecord(mock, "22222222-2222-4222-8222-222222222222", "FAILED", "negative_amount", 1,
+		"ACTIVE", timeArg(start), 1, sqlmock.AnyArg())
+
+	// Out of retries, a one-off operation completes.
+	expectWallet(mock, "33333333-3333-4333-8333-333333333333", "ACTIVE")
+	mock.ExpectRollback()
+	expectRecord(mock, "33333333-3333-4333-8333-333333333333", "FAILED", "negative_amount", 3,
+		"COMPLETED", nil, 2, nil)
+
+	// An operation on a closed wallet is cancelled.
+	expectWallet(mock, "44444444-4444-4444-8444-444444444444", "CLOSED")
+	mock.ExpectRollback()
+	expectRecord(mock, "44444444-4444-4444-8444-444444444444", "FAILED", "wallet_closed", 1,
+		"CANCELLED", nil, 0, nil)
+
+	// A database failure is not recorded; the operation is retried when its lease expires.
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").WithArgs(walletID).WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	executed, attempted, err := service.RunScheduledOperationsService(context.Background(), db, nil, 10, time.Hour)
+	if !errors.Is(err, utils.ErrDatabase) {
+		t.Errorf("RunScheduledOperationsService: got %v, want %v", err, utils.ErrDatabase)
+	}
+	if executed != 1 || attempted != 5 {
+		t.Errorf("RunScheduledOperationsService: got %d executed of %d, want 1 of 5", executed, attempted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunScheduledOperationsService_AlreadyExecuted(t *testing.T) {
+	const (
+		walletID   = "f4c863ec-0300-495d-852d-c115e197390b"
+		scheduleID = "11111111-1111-4111-8111-111111111111"
+	)
+	start := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	mock.ExpectQuery("WITH due AS").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "wallet_id", "operation_type", "amount", "currency", "description",
+			"metadata", "schedule", "start_at", "end_at", "max_retries", "status", "next_run_at", "attempts",
+			"next_attempt_at", "created_at", "updated_at"}).
+			AddRow(scheduleID, walletID, service.WITHDRAW, 500, "RUB", "", []byte("{}"), "", start, nil, 3, "ACTIVE",
+				start, 0, start, start, start))
+	// The withdrawal was executed by an earlier run that was not recorded,
+	// and the balance is gone since: it still counts as executed.
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, balance.*FOR UPDATE").
+		WithArgs(walletID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "deposits_blocked", "owner", "name", "labels", "created_at", "updated_at"}).
+			AddRow(walletID, 0, "RUB", "ACTIVE", false, "", "", "{}", time.Now(), time.Now()))
+	expectReferenceCheck(mock, walletID, "schedule:"+scheduleID+":1751360400", true)
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO scheduled_operation_runs").
+		WithArgs(scheduleID, timeArg(start), 1, "SUCCEEDED", "schedule:"+scheduleID+":1751360400", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "executed_at"}).AddRow(1, time.Now()))
+	mock.ExpectExec("UPDATE scheduled_operations SET status = \\$2").
+		WithArgs(scheduleID, "COMPLETED", nil, 0, nil, timeArg(start)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	executed, _, err := service.RunScheduledOperationsService(context.Background(), db, nil, 10, time.Hour)
+	if err != nil || executed != 1 {
+		t.Errorf("RunScheduledOperationsService: got %d executed, %v, want 1, nil", executed, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
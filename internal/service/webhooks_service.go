@@ -0,0 +1,279 @@
+package service
+
+import (
+	"JavaCode/internal/models"
+	"JavaCode/internal/repositories"
+	"JavaCode/internal/webhooks"
+	"JavaCode/utils"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Webhook endpoint limits.
+const (
+	MaxClientIdLength = 255
+	MaxWebhookURL     = 2048
+)
+
+// DefaultDeliveryLimit is the number of deliveries returned when no limit is given.
+const DefaultDeliveryLimit = 100
+
+// MaxDeliveryLimit caps the number of deliveries returned at once.
+const MaxDeliveryLimit = 1000
+
+// CreateWebhookService registers a webhook endpoint with a new signing secret.
+//
+// Events must be a non-empty subset of models.WebhookEvents. A positive
+// low balance threshold is required with, and only allowed with, the
+// low_balance event.
+//
+// It returns:
+//   - the endpoint, including its secret;
+//   - utils.ErrInvalidRequest if the request is invalid;
+//   - utils.ErrWalletNotFound if the wallet does not exist;
+//   - utils.ErrDatabase on any other failure.
+func CreateWebhookService(db *sql.DB, request models.CreateWebhookRequest) (*models.WebhookSubscription, error) {
+	subscription := &models.WebhookSubscription{
+		Id:                  uuid.NewString(),
+		ClientId:            strings.TrimSpace(request.ClientId),
+		URL:                 strings.TrimSpace(request.URL),
+		WalletId:            request.WalletId,
+		LowBalanceThreshold: request.LowBalanceThreshold,
+		Active:              true,
+	}
+	if subscription.ClientId == "" || len(subscription.ClientId) > MaxClientIdLength {
+		return nil, utils.ErrInvalidRequest
+	}
+	if len(subscription.URL) > MaxWebhookURL {
+		return nil, utils.ErrInvalidRequest
+	}
+	if u, err := url.Parse(subscription.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, utils.ErrInvalidRequest
+	}
+
+	for _, event := range request.Events {
+		if !slices.Contains(models.WebhookEvents, event) {
+			return nil, utils.ErrInvalidRequest
+		}
+		if !slices.Contains(subscription.Events, event) {
+			subscription.Events = append(subscription.Events, event)
+		}
+	}
+	if len(subscription.Events) == 0 {
+		return nil, utils.ErrInvalidRequest
+	}
+	lowBalance := slices.Contains(subscription.Events, models.WebhookLowBalance)
+	if lowBalance != (subscription.LowBalanceThreshold != nil) {
+		return nil, utils.ErrInvalidRequest
+	}
+	if subscription.LowBalanceThreshold != nil && *subscription.LowBalanceThreshold <= 0 {
+		return nil, utils.ErrInvalidRequest
+	}
+
+	if subscription.WalletId != "" {
+		if err := uuid.Validate(subscription.WalletId); err != nil {
+			return nil, utils.ErrInvalidRequest
+		}
+		if _, err := GetWalletsService(db, subscription.WalletId); err != nil {
+			return nil, err
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generate webhook secret: %w", err)
+	}
+	subscription.Secret = "whsec_" + hex.EncodeToString(secret)
+
+	if err := repositories.CreateWebhookSubscription(db, subscription); err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return subscription, nil
+}
+
+// GetWebhookService returns a webhook endpoint by id.
+//
+// It returns:
+//   - the endpoint;
+//   - utils.ErrWebhookNotFound if it does not exist;
+//   - utils.ErrDatabase on any other failure.
+func GetWebhookService(db *sql.DB, id string) (*models.WebhookSubscription, error) {
+	subscription, err := repositories.GetWebhookSubscription(db, id)
+	if err != nil {
+		if errors.Is(err, utils.ErrWebhookNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return subscription, nil
+}
+
+// ListWebhooksService returns the webhook endpoints of a client, or of all
+// clients if clientID is empty, oldest first.
+//
+// It returns:
+//   - the endpoints, empty if there are none;
+//   - utils.ErrDatabase on failure.
+func ListWebhooksService(db *sql.DB, clientID string) ([]models.WebhookSubscription, error) {
+	subscriptions, err := repositories.ListWebhookSubscriptions(db, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if subscriptions == nil {
+		subscriptions = []models.WebhookSubscription{}
+	}
+	return subscriptions, nil
+}
+
+// DeleteWebhookService deactivates a webhook endpoint. Its pending
+// deliveries are dead-lettered and its delivery log is kept.
+//
+// It returns:
+//   - utils.ErrWebhookNotFound if the endpoint does not exist;
+//   - utils.ErrDatabase on any other failure.
+func DeleteWebhookService(db *sql.DB, id string) error {
+	if err := repositories.DeactivateWebhookSubscription(db, id); err != nil {
+		if errors.Is(err, utils.ErrWebhookNotFound) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveriesService returns the most recent deliveries of a
+// webhook endpoint, newest first. A limit of 0 means DefaultDeliveryLimit;
+// larger limits are capped at MaxDeliveryLimit.
+//
+// It returns:
+//   - the deliveries, empty if there are none;
+//   - utils.ErrInvalidRequest if status is not a delivery status;
+//   - utils.ErrWebhookNotFound if the endpoint does not exist;
+//   - utils.ErrDatabase on any other failure.
+func ListWebhookDeliveriesService(db *sql.DB, id, status string, limit int) ([]models.WebhookDelivery, error) {
+	status = strings.ToUpper(status)
+	if status != "" && status != models.DeliveryPending && status != models.DeliveryDelivered && status != models.DeliveryDead {
+		return nil, utils.ErrInvalidRequest
+	}
+	if limit <= 0 {
+		limit = DefaultDeliveryLimit
+	}
+	if limit > MaxDeliveryLimit {
+		limit = MaxDeliveryLimit
+	}
+
+	if _, err := GetWebhookService(db, id); err != nil {
+		return nil, err
+	}
+	deliveries, err := repositories.ListWebhookDeliveries(db, id, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDeliveryService requeues a dead-lettered delivery of an
+// active webhook endpoint with a fresh set of attempts.
+//
+// It returns:
+//   - utils.ErrWebhookNotFound if the endpoint does not exist;
+//   - utils.ErrDeliveryNotFound if the endpoint has no such dead delivery or is inactive;
+//   - utils.ErrDatabase on any other failure.
+func RetryWebhookDeliveryService(db *sql.DB, id string, deliveryID int64) error {
+	if _, err := GetWebhookService(db, id); err != nil {
+		return err
+	}
+	requeued, err := repositories.RequeueWebhookDelivery(db, id, deliveryID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+	if !requeued {
+		return utils.ErrDeliveryNotFound
+	}
+	return nil
+}
+
+// DeliverWebhooksService sends up to limit due webhook deliveries.
+//
+// The deliveries are leased in a statement of their own, so several
+// instances can work in parallel and no transaction is held while the
+// endpoints respond. Each outcome is recorded on its own; a delivery whose
+// outcome is not recorded is sent again once its lease expires, so the
+// lease must outlast sending the whole batch and receivers should drop
+// repeated event ids. A failed delivery is retried with exponential backoff
+// capped at maxBackoff, and dead-lettered after maxAttempts attempts.
+//
+// It returns:
+//   - the number of deliveries sent successfully;
+//   - the number of deliveries attempted, which equals limit if more may be due;
+//   - utils.ErrDatabase if the deliveries could not be leased or an outcome
+//     could not be recorded; the other deliveries are still sent.
+func DeliverWebhooksService(ctx context.Context, db *sql.DB, sender *webhooks.Sender, limit, maxAttempts int,
+	maxBackoff, lease time.Duration) (int, int, error) {
+	deliveries, err := repositories.LeaseWebhookDeliveries(db, limit, time.Now().Add(lease))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+	}
+
+	delivered := 0
+	var failure error
+	for _, delivery := range deliveries {
+		ok, err := deliverWebhook(ctx, db, sender, delivery, maxAttempts, maxBackoff)
+		if err != nil {
+			utils.Logger.WithError(err).Errorf("webhook delivery %d outcome not recorded", delivery.Id)
+			if failure == nil {
+				failure = fmt.Errorf("%w: %v", utils.ErrDatabase, err)
+			}
+			continue
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, len(deliveries), failure
+}
+
+// deliverWebhook sends a leased delivery and records the outcome.
+// A delivery whose payload cannot be encoded is dead-lettered at once.
+//
+// It returns:
+//   - whether the delivery was sent successfully;
+//   - the error of recording the outcome.
+func deliverWebhook(ctx context.Context, db *sql.DB, sender *webhooks.Sender, delivery models.PendingWebhookDelivery,
+	maxAttempts int, maxBackoff time.Duration) (bool, error) {
+	status := 0
+	dead := false
+	body, err := json.Marshal(models.WebhookPayload{
+		Id:        delivery.EventId,
+		Type:      delivery.EventType,
+		CreatedAt: delivery.CreatedTime.UTC(),
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		dead = true
+	} else if status, err = sender.Send(ctx, delivery.URL, delivery.Secret, delivery.EventId, delivery.EventType, body); err == nil {
+		return true, repositories.MarkWebhookDelivered(db, delivery.Id, status)
+	}
+
+	dead = dead || delivery.Attempts+1 >= maxAttempts
+	if dead {
+		utils.Logger.WithError(err).Errorf("webhook delivery %d dead-lettered after %d attempts", delivery.Id, delivery.Attempts+1)
+	} else {
+		utils.Logger.WithError(err).Warnf("webhook delivery %d failed (attempt %d)", delivery.Id, delivery.Attempts+1)
+	}
+	next := time.Now().Add(retryBackoff(delivery.Attempts, maxBackoff))
+	return false, repositories.MarkWebhookDeliveryFailed(db, delivery.Id, status, err.Error(), next, dead)
+}
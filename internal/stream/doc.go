@@ -0,0 +1,8 @@
+// Package stream wakes up the clients following a wallet's events.
+//
+// Every event written to the outbox raises a NOTIFY on Channel when its
+// transaction commits, carrying the wallet id. A Hub LISTENs on it and wakes
+// the subscribers of that wallet, which then read the new events from the
+// outbox themselves, so notifications may be lost or merged without losing
+// events. A Poller wakes subscribers on a timer where LISTEN is unavailable.
+package stream
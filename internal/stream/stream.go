@@ -0,0 +1,140 @@
+package stream
+
+import (
+	"context"
+	"github.com/lib/pq"
+	"sync"
+	"time"
+)
+
+// Channel is the notification channel raised for every outbox event; the
+// payload is the wallet id.
+const Channel = "wallet_events"
+
+// pingInterval is how often an idle listener checks its connection.
+const pingInterval = 90 * time.Second
+
+// Notifier wakes the followers of a wallet when it may have new events.
+type Notifier interface {
+	// Subscribe returns a channel that receives a value after new events of
+	// walletID may have been committed, and a function to cancel it.
+	Subscribe(walletID string) (<-chan struct{}, func())
+}
+
+// Listener is the part of *pq.Listener a Hub uses.
+type Listener interface {
+	NotificationChannel() <-chan *pq.Notification
+	Ping() error
+}
+
+// Hub fans the notifications of a Listener out to the subscribers of each wallet.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+}
+
+// NewHub returns a Hub without subscribers.
+func NewHub() *Hub {
+	return &Hub{subscribers: map[string]map[chan struct{}]struct{}{}}
+}
+
+// Subscribe implements Notifier.
+func (h *Hub) Subscribe(walletID string) (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	if h.subscribers[walletID] == nil {
+		h.subscribers[walletID] = map[chan struct{}]struct{}{}
+	}
+	h.subscribers[walletID][wake] = struct{}{}
+	h.mu.Unlock()
+
+	return wake, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers[walletID], wake)
+		if len(h.subscribers[walletID]) == 0 {
+			delete(h.subscribers, walletID)
+		}
+	}
+}
+
+// Notify wakes the subscribers of walletID. Subscribers that have not
+// consumed an earlier wake-up are not woken twice.
+func (h *Hub) Notify(walletID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for wake := range h.subscribers[walletID] {
+		signal(wake)
+	}
+}
+
+// NotifyAll wakes every subscriber.
+func (h *Hub) NotifyAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subscribers := range h.subscribers {
+		for wake := range subscribers {
+			signal(wake)
+		}
+	}
+}
+
+// Run delivers the notifications of listener until ctx is done or its
+// notification channel is closed. After a reconnection, when notifications
+// may have been missed, every subscriber is woken.
+func (h *Hub) Run(ctx context.Context, listener Listener) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n, ok := <-listener.NotificationChannel():
+			if !ok {
+				return
+			}
+			if n == nil {
+				h.NotifyAll()
+				continue
+			}
+			h.Notify(n.Extra)
+		case <-ticker.C:
+			// A failed ping makes the listener reconnect.
+			_ = listener.Ping()
+		}
+	}
+}
+
+// Poller wakes every subscriber each Interval, without LISTEN.
+type Poller struct {
+	Interval time.Duration
+}
+
+// Subscribe implements Notifier.
+func (p Poller) Subscribe(string) (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(p.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				signal(wake)
+			}
+		}
+	}()
+	var once sync.Once
+	return wake, func() { once.Do(func() { close(done) }) }
+}
+
+// signal wakes a subscriber unless a wake-up is already pending.
+func signal(wake chan struct{}) {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
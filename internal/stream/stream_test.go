@@ -0,0 +1,98 @@
+package stream_test
+
+import (
+	"JavaCode/internal/stream"
+	"context"
+	"github.com/lib/pq"
+	"testing"
+	"time"
+)
+
+type fakeListener struct {
+	notifications chan *pq.Notification
+}
+
+func (l *fakeListener) NotificationChannel() <-chan *pq.Notification { return l.notifications }
+
+func (l *fakeListener) Ping() error { return nil }
+
+func woken(wake <-chan struct{}) bool {
+	select {
+	case <-wake:
+		return true
+	case <-time.After(100 * time.Millisecond):
+		return false
+	}
+}
+
+func TestHub(t *testing.T) {
+	const (
+		walletA = "f4c863ec-0300-495d-852d-c115e197390b"
+		walletB = "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f"
+	)
+
+	t.Run("Test 1: Notify wakes the wallet's subscribers only", func(t *testing.T) {
+		hub := stream.NewHub()
+		wakeA, cancelA := hub.Subscribe(walletA)
+		defer cancelA()
+		wakeB, cancelB := hub.Subscribe(walletB)
+		defer cancelB()
+
+		hub.Notify(walletA)
+		hub.Notify(walletA)
+
+		if !woken(wakeA) {
+			t.Errorf("subscriber of %s not woken", walletA)
+		}
+		if woken(wakeA) {
+			t.Errorf("pending wake-ups not merged")
+		}
+		if woken(wakeB) {
+			t.Errorf("subscriber of %s woken", walletB)
+		}
+	})
+
+	t.Run("Test 2: Cancelled subscriptions are not woken", func(t *testing.T) {
+		hub := stream.NewHub()
+		wake, cancel := hub.Subscribe(walletA)
+		cancel()
+
+		hub.Notify(walletA)
+
+		if woken(wake) {
+			t.Errorf("cancelled subscriber woken")
+		}
+	})
+
+	t.Run("Test 3: Run wakes everyone after a reconnection", func(t *testing.T) {
+		hub := stream.NewHub()
+		wakeA, cancelA := hub.Subscribe(walletA)
+		defer cancelA()
+		wakeB, cancelB := hub.Subscribe(walletB)
+		defer cancelB()
+
+		listener := &fakeListener{notifications: make(chan *pq.Notification)}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go hub.Run(ctx, listener)
+
+		listener.notifications <- &pq.Notification{Channel: stream.Channel, Extra: walletB}
+		if !woken(wakeB) || woken(wakeA) {
+			t.Fatalf("notification for %s not routed to its subscriber", walletB)
+		}
+
+		listener.notifications <- nil
+		if !woken(wakeA) || !woken(wakeB) {
+			t.Errorf("reconnection did not wake every subscriber")
+		}
+	})
+}
+
+func TestPoller(t *testing.T) {
+	wake, cancel := stream.Poller{Interval: 10 * time.Millisecond}.Subscribe("f4c863ec-0300-495d-852d-c115e197390b")
+	if !woken(wake) {
+		t.Errorf("poller did not wake the subscriber")
+	}
+	cancel()
+	cancel()
+}
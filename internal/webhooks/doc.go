@@ -0,0 +1,6 @@
+// Package webhooks signs and sends webhook deliveries.
+//
+// Every delivery is a JSON POST carrying an HMAC-SHA256 signature of its
+// timestamp and body in the X-Webhook-Signature header, in the form
+// "t=<unix seconds>,v1=<hex digest>". Receivers check it with Verify.
+package webhooks
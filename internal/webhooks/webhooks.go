@@ -0,0 +1,104 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Delivery headers.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	IdHeader        = "X-Webhook-Id"
+	EventHeader     = "X-Webhook-Event"
+)
+
+// ErrInvalidSignature is returned by Verify for a missing, malformed,
+// expired or wrong signature.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns the signature header value of body sent at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + ",v1=" + digest(secret, ts, body)
+}
+
+// Verify checks a signature header value against body. Signatures older
+// than tolerance are rejected to limit replays; a zero tolerance disables
+// the check.
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var ts, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signature = value
+		}
+	}
+	seconds, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || signature == "" {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && time.Since(time.Unix(seconds, 0)) > tolerance {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(digest(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func digest(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sender POSTs signed deliveries.
+type Sender struct {
+	Client *http.Client
+}
+
+// Send POSTs body to url, signed with secret.
+//
+// It returns the response status, 0 if no response was received, and an
+// error unless the status is 2xx.
+func (s *Sender) Send(ctx context.Context, url, secret, eventID, eventType string, body []byte) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(IdHeader, eventID)
+	request.Header.Set(EventHeader, eventType)
+	request.Header.Set(SignatureHeader, Sign(secret, time.Now(), body))
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("endpoint responded with status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
@@ -0,0 +1,82 @@
+package webhooks_test
+
+import (
+	"JavaCode/internal/webhooks"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const secret = "whsec_test"
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"e1"}`)
+
+	t.Run("Test 1: Valid signature", func(t *testing.T) {
+		header := webhooks.Sign(secret, time.Now(), body)
+		if err := webhooks.Verify(secret, header, body, 5*time.Minute); err != nil {
+			t.Errorf("Verify: got %v, want nil", err)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		body   []byte
+	}{
+		{"Tampered body", webhooks.Sign(secret, time.Now(), body), []byte(`{"id":"e2"}`)},
+		{"Other secret", webhooks.Sign("whsec_other", time.Now(), body), body},
+		{"Expired", webhooks.Sign(secret, time.Now().Add(-time.Hour), body), body},
+		{"Malformed", "v1=abc", body},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhooks.Verify(secret, tt.header, tt.body, 5*time.Minute)
+			if !errors.Is(err, webhooks.ErrInvalidSignature) {
+				t.Errorf("Verify: got %v, want %v", err, webhooks.ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestSender(t *testing.T) {
+	t.Run("Test 1: Signed delivery accepted", func(t *testing.T) {
+		var verifyErr error
+		var event string
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			verifyErr = webhooks.Verify(secret, r.Header.Get(webhooks.SignatureHeader), body, time.Minute)
+			event = r.Header.Get(webhooks.EventHeader)
+		}))
+		defer receiver.Close()
+
+		sender := &webhooks.Sender{}
+		status, err := sender.Send(context.Background(), receiver.URL, secret, "e1", "deposit", []byte(`{"id":"e1"}`))
+		if err != nil || status != http.StatusOK {
+			t.Fatalf("Send: got %d, %v, want 200, nil", status, err)
+		}
+		if verifyErr != nil {
+			t.Errorf("receiver could not verify the signature: %v", verifyErr)
+		}
+		if event != "deposit" {
+			t.Errorf("event header: got %q, want deposit", event)
+		}
+	})
+
+	t.Run("Test 2: Error status reported", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer receiver.Close()
+
+		sender := &webhooks.Sender{}
+		status, err := sender.Send(context.Background(), receiver.URL, secret, "e1", "deposit", []byte(`{}`))
+		if err == nil || status != http.StatusBadGateway {
+			t.Errorf("Send: got %d, %v, want 502 and an error", status, err)
+		}
+	})
+}
@@ -1,3 +0,0 @@
-request = function()
-  return wrk.format("GET", "/api/v1/wallets/1c63a43f-aacd-47b0-bc3b-535e69c6ed4c")
-end
\ No newline at end of file
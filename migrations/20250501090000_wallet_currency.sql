@@ -0,0 +1,25 @@
+-- +goose Up
+-- Existing wallets predate currencies and are assigned the default one.
+ALTER TABLE wallets ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB'
+    CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE wallets ALTER COLUMN currency DROP DEFAULT;
+
+-- +goose StatementBegin
+CREATE FUNCTION wallets_currency_immutable() RETURNS trigger AS $$
+BEGIN
+    IF NEW.currency <> OLD.currency THEN
+        RAISE EXCEPTION 'wallet currency is immutable' USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER wallets_currency_immutable
+    BEFORE UPDATE OF currency ON wallets
+    FOR EACH ROW EXECUTE FUNCTION wallets_currency_immutable();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS wallets_currency_immutable ON wallets;
+DROP FUNCTION IF EXISTS wallets_currency_immutable();
+ALTER TABLE wallets DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,42 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id BIGSERIAL PRIMARY KEY,
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    -- The rate is rate_units / 10^precision units of quote_currency per unit of base_currency.
+    rate_units BIGINT NOT NULL CHECK (rate_units > 0),
+    precision SMALLINT NOT NULL CHECK (precision BETWEEN 0 AND 12),
+    rounding_mode TEXT NOT NULL CHECK (rounding_mode IN ('HALF_UP', 'HALF_EVEN', 'DOWN', 'UP')),
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (base_currency <> quote_currency),
+    CHECK (valid_to IS NULL OR valid_to > valid_from)
+    );
+
+CREATE INDEX IF NOT EXISTS exchange_rates_pair_idx
+    ON exchange_rates (base_currency, quote_currency, valid_from DESC);
+
+CREATE TABLE IF NOT EXISTS transfers (
+    id UUID PRIMARY KEY,
+    from_wallet_id UUID NOT NULL REFERENCES wallets (id),
+    to_wallet_id UUID NOT NULL REFERENCES wallets (id),
+    source_amount BIGINT NOT NULL CHECK (source_amount > 0),
+    source_currency CHAR(3) NOT NULL,
+    target_amount BIGINT NOT NULL CHECK (target_amount > 0),
+    target_currency CHAR(3) NOT NULL,
+    -- Rate details are copied so that history stays reproducible if the rate row changes.
+    rate_id BIGINT REFERENCES exchange_rates (id),
+    rate_units BIGINT,
+    rate_precision SMALLINT,
+    rounding_mode TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (from_wallet_id <> to_wallet_id)
+    );
+
+CREATE INDEX IF NOT EXISTS transfers_from_wallet_idx ON transfers (from_wallet_id, created_at);
+CREATE INDEX IF NOT EXISTS transfers_to_wallet_idx ON transfers (to_wallet_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS transfers;
+DROP TABLE IF EXISTS exchange_rates;
@@ -0,0 +1,118 @@
+-- +goose Up
+-- Double-entry ledger. Every balance change is a ledger transaction whose
+-- entries debit and credit accounts by the same total in each currency.
+-- wallets.balance is kept as a projection of the wallet account's entries
+-- (credits minus debits).
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id UUID PRIMARY KEY,
+    type TEXT NOT NULL CHECK (type IN ('WALLET', 'CASH_IN', 'CASH_OUT', 'FEES', 'FX', 'OPENING')),
+    currency CHAR(3) NOT NULL,
+    -- Wallet accounts share their wallet's id.
+    wallet_id UUID UNIQUE REFERENCES wallets(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK ((type = 'WALLET') = (wallet_id IS NOT NULL)),
+    CHECK (wallet_id IS NULL OR wallet_id = id)
+);
+
+-- One system account of each type per currency.
+CREATE UNIQUE INDEX IF NOT EXISTS ledger_accounts_system_idx
+    ON ledger_accounts (type, currency) WHERE wallet_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id UUID PRIMARY KEY,
+    type TEXT NOT NULL CHECK (type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER', 'OPENING')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id UUID NOT NULL REFERENCES ledger_transactions(id),
+    account_id UUID NOT NULL REFERENCES ledger_accounts(id),
+    direction TEXT NOT NULL CHECK (direction IN ('DEBIT', 'CREDIT')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_account_idx ON ledger_entries (account_id, id);
+CREATE INDEX IF NOT EXISTS ledger_entries_transaction_idx ON ledger_entries (transaction_id);
+
+-- +goose StatementBegin
+CREATE FUNCTION ledger_transaction_balanced() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM ledger_entries
+        WHERE transaction_id = NEW.transaction_id
+        GROUP BY currency
+        HAVING SUM(CASE direction WHEN 'CREDIT' THEN amount ELSE -amount END) <> 0
+    ) THEN
+        RAISE EXCEPTION 'ledger transaction % is not balanced', NEW.transaction_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Checked at commit, once every entry of the transaction is inserted.
+CREATE CONSTRAINT TRIGGER ledger_transaction_balanced
+    AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_transaction_balanced();
+
+-- +goose StatementBegin
+CREATE FUNCTION ledger_entries_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger entries are append-only' USING ERRCODE = 'check_violation';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER ledger_entries_immutable
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_entries_immutable();
+
+-- +goose StatementBegin
+CREATE FUNCTION wallets_ledger_account() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO ledger_accounts (id, type, currency, wallet_id)
+    VALUES (NEW.id, 'WALLET', NEW.currency, NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER wallets_ledger_account
+    AFTER INSERT ON wallets
+    FOR EACH ROW EXECUTE FUNCTION wallets_ledger_account();
+
+-- Existing wallets get an account and an opening entry for their current balance.
+INSERT INTO ledger_accounts (id, type, currency, wallet_id)
+SELECT id, 'WALLET', currency, id FROM wallets;
+
+INSERT INTO ledger_accounts (id, type, currency)
+SELECT gen_random_uuid(), 'OPENING', currency FROM wallets WHERE balance > 0 GROUP BY currency;
+
+CREATE TEMPORARY TABLE opening_balances ON COMMIT DROP AS
+SELECT id AS wallet_id, balance, currency, gen_random_uuid() AS transaction_id
+FROM wallets WHERE balance > 0;
+
+INSERT INTO ledger_transactions (id, type)
+SELECT transaction_id, 'OPENING' FROM opening_balances;
+
+INSERT INTO ledger_entries (transaction_id, account_id, direction, amount, currency)
+SELECT o.transaction_id, a.id, 'DEBIT', o.balance, o.currency
+FROM opening_balances o
+JOIN ledger_accounts a ON a.type = 'OPENING' AND a.currency = o.currency AND a.wallet_id IS NULL
+UNION ALL
+SELECT o.transaction_id, o.wallet_id, 'CREDIT', o.balance, o.currency
+FROM opening_balances o;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS wallets_ledger_account ON wallets;
+DROP FUNCTION IF EXISTS wallets_ledger_account();
+DROP TABLE IF EXISTS ledger_entries;
+DROP FUNCTION IF EXISTS ledger_entries_immutable();
+DROP FUNCTION IF EXISTS ledger_transaction_balanced();
+DROP TABLE IF EXISTS ledger_transactions;
+DROP TABLE IF EXISTS ledger_accounts;
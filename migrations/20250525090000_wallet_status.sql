@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE wallets ADD COLUMN status TEXT NOT NULL DEFAULT 'ACTIVE'
+    CHECK (status IN ('ACTIVE', 'FROZEN'));
+
+-- Frozen wallets can still receive funds, but nothing can be taken out of them.
+-- +goose StatementBegin
+CREATE FUNCTION wallets_frozen_debit() RETURNS trigger AS $$
+BEGIN
+    IF OLD.status = 'FROZEN' AND NEW.balance < OLD.balance THEN
+        RAISE EXCEPTION 'wallet % is frozen', OLD.id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'wallets_frozen';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER wallets_frozen_debit
+    BEFORE UPDATE OF balance ON wallets
+    FOR EACH ROW EXECUTE FUNCTION wallets_frozen_debit();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS wallets_frozen_debit ON wallets;
+DROP FUNCTION IF EXISTS wallets_frozen_debit();
+ALTER TABLE wallets DROP COLUMN IF EXISTS status;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Wallet balances as of a point in time, so that historical balances only
+-- need the ledger entries posted after the latest earlier snapshot.
+-- balance is the sum of the wallet's entries created at or before taken_at.
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    taken_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    balance BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (wallet_id, taken_at)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_account_created_idx ON ledger_entries (account_id, created_at);
+CREATE INDEX IF NOT EXISTS ledger_entries_created_idx ON ledger_entries (created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS ledger_entries_created_idx;
+DROP INDEX IF EXISTS ledger_entries_account_created_idx;
+DROP TABLE IF EXISTS balance_snapshots;
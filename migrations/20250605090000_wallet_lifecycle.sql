@@ -0,0 +1,60 @@
+-- +goose Up
+ALTER TABLE wallets DROP CONSTRAINT wallets_status_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_status_check
+    CHECK (status IN ('ACTIVE', 'FROZEN', 'CLOSED'));
+
+-- A frozen wallet may additionally refuse deposits.
+ALTER TABLE wallets ADD COLUMN deposits_blocked BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE wallets ADD CONSTRAINT wallets_deposits_blocked_check
+    CHECK (NOT deposits_blocked OR status = 'FROZEN');
+
+CREATE TABLE wallet_status_changes (
+    id          BIGSERIAL PRIMARY KEY,
+    wallet_id   UUID        NOT NULL REFERENCES wallets (id),
+    from_status TEXT        NOT NULL,
+    to_status   TEXT        NOT NULL,
+    reason      TEXT        NOT NULL,
+    changed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX wallet_status_changes_wallet_id_idx ON wallet_status_changes (wallet_id, changed_at);
+
+-- The service checks the status before every balance change; the trigger
+-- keeps writers that bypass it from moving funds through a frozen or closed wallet.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION wallets_frozen_debit() RETURNS trigger AS $$
+BEGIN
+    IF OLD.status = 'CLOSED' AND NEW.balance <> OLD.balance THEN
+        RAISE EXCEPTION 'wallet % is closed', OLD.id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'wallets_closed';
+    END IF;
+    IF OLD.status = 'FROZEN' AND (NEW.balance < OLD.balance
+        OR (OLD.deposits_blocked AND NEW.balance > OLD.balance)) THEN
+        RAISE EXCEPTION 'wallet % is frozen', OLD.id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'wallets_frozen';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION wallets_frozen_debit() RETURNS trigger AS $$
+BEGIN
+    IF OLD.status = 'FROZEN' AND NEW.balance < OLD.balance THEN
+        RAISE EXCEPTION 'wallet % is frozen', OLD.id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'wallets_frozen';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TABLE IF EXISTS wallet_status_changes;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_deposits_blocked_check;
+ALTER TABLE wallets DROP COLUMN IF EXISTS deposits_blocked;
+UPDATE wallets SET status = 'FROZEN' WHERE status = 'CLOSED';
+ALTER TABLE wallets DROP CONSTRAINT wallets_status_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_status_check
+    CHECK (status IN ('ACTIVE', 'FROZEN'));
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE wallets ADD COLUMN owner TEXT;
+
+-- Keyset pagination orders by (sort column, id).
+CREATE INDEX wallets_created_at_id_idx ON wallets (created_at, id);
+CREATE INDEX wallets_balance_id_idx ON wallets (balance, id);
+CREATE INDEX wallets_owner_created_at_idx ON wallets (owner, created_at, id) WHERE owner IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS wallets_owner_created_at_idx;
+DROP INDEX IF EXISTS wallets_balance_id_idx;
+DROP INDEX IF EXISTS wallets_created_at_id_idx;
+ALTER TABLE wallets DROP COLUMN IF EXISTS owner;
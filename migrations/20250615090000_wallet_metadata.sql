@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE wallets ADD COLUMN name TEXT;
+ALTER TABLE wallets ADD COLUMN labels JSONB NOT NULL DEFAULT '{}'
+    CHECK (jsonb_typeof(labels) = 'object');
+
+CREATE INDEX wallets_labels_idx ON wallets USING GIN (labels jsonb_path_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS wallets_labels_idx;
+ALTER TABLE wallets DROP COLUMN IF EXISTS labels;
+ALTER TABLE wallets DROP COLUMN IF EXISTS name;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Deposits and withdrawals record the wallet they were made on, so that
+-- external references can be unique per wallet.
+ALTER TABLE ledger_transactions ADD COLUMN wallet_id UUID REFERENCES wallets(id);
+ALTER TABLE ledger_transactions ADD COLUMN description TEXT;
+ALTER TABLE ledger_transactions ADD COLUMN reference TEXT;
+ALTER TABLE ledger_transactions ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}'
+    CHECK (jsonb_typeof(metadata) = 'object');
+
+CREATE UNIQUE INDEX ledger_transactions_wallet_reference_idx
+    ON ledger_transactions (wallet_id, reference) WHERE reference IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS ledger_transactions_wallet_reference_idx;
+ALTER TABLE ledger_transactions DROP COLUMN IF EXISTS metadata;
+ALTER TABLE ledger_transactions DROP COLUMN IF EXISTS reference;
+ALTER TABLE ledger_transactions DROP COLUMN IF EXISTS description;
+ALTER TABLE ledger_transactions DROP COLUMN IF EXISTS wallet_id;
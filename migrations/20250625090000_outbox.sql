@@ -0,0 +1,22 @@
+-- +goose Up
+-- Events are written in the same transaction as the change they describe and
+-- delivered afterwards by the dispatcher, at least once and in id order per wallet.
+CREATE TABLE outbox_events (
+    id              BIGSERIAL PRIMARY KEY,
+    event_id        UUID        NOT NULL UNIQUE,
+    type            TEXT        NOT NULL,
+    wallet_id       UUID        NOT NULL REFERENCES wallets (id),
+    payload         JSONB       NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempts        INT         NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at    TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_events_pending_idx ON outbox_events (id) WHERE published_at IS NULL;
+CREATE INDEX outbox_events_wallet_id_idx ON outbox_events (wallet_id, id);
+CREATE INDEX outbox_events_published_at_idx ON outbox_events (published_at) WHERE published_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox_events;
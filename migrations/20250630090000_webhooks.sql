@@ -0,0 +1,44 @@
+-- +goose Up
+CREATE TABLE webhook_subscriptions (
+    id                    UUID PRIMARY KEY,
+    client_id             TEXT        NOT NULL,
+    url                   TEXT        NOT NULL,
+    -- Deliveries are signed with the secret, so it is stored as is.
+    secret                TEXT        NOT NULL,
+    events                TEXT[]      NOT NULL CHECK (cardinality(events) > 0),
+    wallet_id             UUID REFERENCES wallets (id),
+    low_balance_threshold BIGINT CHECK (low_balance_threshold > 0),
+    active                BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT webhook_subscriptions_low_balance_check
+        CHECK (NOT 'low_balance' = ANY(events) OR low_balance_threshold IS NOT NULL)
+);
+
+CREATE INDEX webhook_subscriptions_client_id_idx ON webhook_subscriptions (client_id, created_at);
+
+-- Deliveries are created in the transaction of the change they report, like
+-- outbox events, and worked off by the delivery worker.
+CREATE TABLE webhook_deliveries (
+    id               BIGSERIAL PRIMARY KEY,
+    subscription_id  UUID        NOT NULL REFERENCES webhook_subscriptions (id),
+    event_id         UUID        NOT NULL,
+    event_type       TEXT        NOT NULL,
+    payload          JSONB       NOT NULL,
+    status           TEXT        NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts         INT         NOT NULL DEFAULT 0,
+    last_status_code INT,
+    last_error       TEXT,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at     TIMESTAMPTZ,
+    UNIQUE (subscription_id, event_id, event_type)
+);
+
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX webhook_deliveries_subscription_id_idx ON webhook_deliveries (subscription_id, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Wakes the balance streams of every instance when a wallet's event commits;
+-- the streams then read the new events from outbox_events.
+-- +goose StatementBegin
+CREATE FUNCTION outbox_events_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('wallet_events', NEW.wallet_id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER outbox_events_notify
+    AFTER INSERT ON outbox_events
+    FOR EACH ROW EXECUTE FUNCTION outbox_events_notify();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS outbox_events_notify ON outbox_events;
+DROP FUNCTION IF EXISTS outbox_events_notify();
@@ -0,0 +1,48 @@
+-- +goose Up
+-- Deposits and withdrawals to be executed later, once or on a recurrence,
+-- by the scheduler.
+CREATE TABLE scheduled_operations (
+    id              UUID PRIMARY KEY,
+    wallet_id       UUID        NOT NULL REFERENCES wallets (id),
+    operation_type  TEXT        NOT NULL CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW')),
+    amount          BIGINT      NOT NULL CHECK (amount > 0),
+    currency        TEXT        NOT NULL,
+    description     TEXT,
+    metadata        JSONB       NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(metadata) = 'object'),
+    -- Empty for a one-off operation; otherwise daily, weekly, monthly or a cron expression.
+    schedule        TEXT        NOT NULL DEFAULT '',
+    start_at        TIMESTAMPTZ NOT NULL,
+    end_at          TIMESTAMPTZ CHECK (end_at >= start_at),
+    max_retries     INT         NOT NULL DEFAULT 0 CHECK (max_retries >= 0),
+    status          TEXT        NOT NULL DEFAULT 'ACTIVE'
+        CHECK (status IN ('ACTIVE', 'COMPLETED', 'CANCELLED')),
+    -- The occurrence being executed and the failed attempts at it so far.
+    next_run_at     TIMESTAMPTZ,
+    attempts        INT         NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (status <> 'ACTIVE' OR (next_run_at IS NOT NULL AND next_attempt_at IS NOT NULL))
+);
+
+CREATE INDEX scheduled_operations_due_idx ON scheduled_operations (next_attempt_at) WHERE status = 'ACTIVE';
+CREATE INDEX scheduled_operations_wallet_id_idx ON scheduled_operations (wallet_id, created_at);
+
+-- Every attempt to execute an occurrence, successful or not.
+CREATE TABLE scheduled_operation_runs (
+    id           BIGSERIAL PRIMARY KEY,
+    schedule_id  UUID        NOT NULL REFERENCES scheduled_operations (id),
+    run_at       TIMESTAMPTZ NOT NULL,
+    attempt      INT         NOT NULL,
+    status       TEXT        NOT NULL CHECK (status IN ('SUCCEEDED', 'FAILED')),
+    -- The ledger reference of the operation, the same for every attempt at an occurrence.
+    reference    TEXT        NOT NULL,
+    error        TEXT,
+    executed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX scheduled_operation_runs_schedule_id_idx ON scheduled_operation_runs (schedule_id, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS scheduled_operation_runs;
+DROP TABLE IF EXISTS scheduled_operations;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Backs the dispatcher's check for an event of the same wallet waiting for a
+-- retry, and the removal of events by age when no dispatcher delivers them.
+CREATE INDEX outbox_events_retry_idx ON outbox_events (wallet_id, next_attempt_at) WHERE published_at IS NULL;
+CREATE INDEX outbox_events_created_at_idx ON outbox_events (created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS outbox_events_created_at_idx;
+DROP INDEX IF EXISTS outbox_events_retry_idx;
@@ -0,0 +1,12 @@
+// Package migrations embeds the SQL schema migrations into the binary.
+//
+// Files follow the goose format ("<version>_<name>.sql" with
+// "-- +goose Up" and "-- +goose Down" sections) and are applied by pkg/migrate.
+package migrations
+
+import "embed"
+
+// FS holds every *.sql migration in this directory.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,126 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RoundingMode selects how a converted amount is rounded to a whole minor unit.
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "HALF_UP"   // 0.5 rounds away from zero
+	RoundHalfEven RoundingMode = "HALF_EVEN" // 0.5 rounds to the nearest even unit (banker's rounding)
+	RoundDown     RoundingMode = "DOWN"      // truncate towards zero
+	RoundUp       RoundingMode = "UP"        // any remainder rounds away from zero
+)
+
+// MaxRatePrecision is the largest number of decimal places a rate may have.
+const MaxRatePrecision = 12
+
+// ErrOverflow is returned when a converted amount does not fit in an int64.
+var ErrOverflow = errors.New("amount overflows")
+
+// IsRoundingMode reports whether mode is a supported rounding mode.
+func IsRoundingMode(mode RoundingMode) bool {
+	switch mode {
+	case RoundHalfUp, RoundHalfEven, RoundDown, RoundUp:
+		return true
+	}
+	return false
+}
+
+// ParseRate parses a positive decimal rate such as "1.0834" into an integer
+// scaled by 10^precision.
+//
+// It returns an error if the rate is malformed, not positive, or has more
+// decimal places than precision.
+func ParseRate(rate string, precision int) (int64, error) {
+	if precision < 0 || precision > MaxRatePrecision {
+		return 0, fmt.Errorf("precision must be between 0 and %d", MaxRatePrecision)
+	}
+
+	whole, frac, _ := strings.Cut(strings.TrimSpace(rate), ".")
+	if whole == "" || !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("rate %q is not a positive decimal number", rate)
+	}
+	trimmed := strings.TrimRight(frac, "0")
+	if len(trimmed) > precision {
+		return 0, fmt.Errorf("rate %q has more than %d decimal places", rate, precision)
+	}
+
+	units, ok := new(big.Int).SetString(whole+trimmed+strings.Repeat("0", precision-len(trimmed)), 10)
+	if !ok || !units.IsInt64() {
+		return 0, fmt.Errorf("rate %q is too large", rate)
+	}
+	if units.Sign() <= 0 {
+		return 0, fmt.Errorf("rate %q must be greater than 0", rate)
+	}
+	return units.Int64(), nil
+}
+
+// FormatRate formats a rate scaled by 10^precision as a decimal string.
+func FormatRate(units int64, precision int) string {
+	s := fmt.Sprintf("%0*d", precision+1, units)
+	if precision == 0 {
+		return s
+	}
+	return s[:len(s)-precision] + "." + s[len(s)-precision:]
+}
+
+// Convert converts a non-negative amount in minor units of one currency into
+// minor units of another.
+//
+// The rate is rateUnits / 10^precision units of the target currency per one
+// unit of the source currency; fromExp and toExp are the currencies' exponents.
+// The exact result is rounded to a whole minor unit using mode.
+func Convert(amount int64, fromExp, toExp int, rateUnits int64, precision int, mode RoundingMode) (int64, error) {
+	if amount < 0 {
+		return 0, errors.New("amount must not be negative")
+	}
+	if !IsRoundingMode(mode) {
+		return 0, fmt.Errorf("unknown rounding mode %q", mode)
+	}
+
+	// amount * rate * 10^toExp / 10^fromExp, with rate = rateUnits / 10^precision.
+	num := new(big.Int).Mul(big.NewInt(amount), big.NewInt(rateUnits))
+	num.Mul(num, pow10(toExp))
+	den := new(big.Int).Mul(pow10(precision), pow10(fromExp))
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		twice := new(big.Int).Mul(rem, big.NewInt(2))
+		switch mode {
+		case RoundUp:
+			quo.Add(quo, big.NewInt(1))
+		case RoundHalfUp:
+			if twice.Cmp(den) >= 0 {
+				quo.Add(quo, big.NewInt(1))
+			}
+		case RoundHalfEven:
+			if c := twice.Cmp(den); c > 0 || (c == 0 && quo.Bit(0) == 1) {
+				quo.Add(quo, big.NewInt(1))
+			}
+		}
+	}
+
+	if !quo.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return quo.Int64(), nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+// Package currency provides the ISO 4217 currencies supported by the wallet service.
+//
+// Amounts are always stored as integers in the currency's minor unit
+// (e.g. cents for EUR); the exponent tells how many decimal places that is.
+package currency
+
+import "strings"
+
+// exponents maps ISO 4217 alphabetic codes to their minor-unit exponent.
+var exponents = map[string]int{
+	// Zero decimal places.
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+
+	// Three decimal places.
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+
+	// Two decimal places.
+	"AED": 2, "AMD": 2, "ARS": 2, "AUD": 2, "AZN": 2, "BGN": 2, "BRL": 2, "BYN": 2,
+	"CAD": 2, "CHF": 2, "CNY": 2, "COP": 2, "CZK": 2, "DKK": 2, "EGP": 2, "EUR": 2,
+	"GBP": 2, "GEL": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "KGS": 2,
+	"KZT": 2, "MAD": 2, "MDL": 2, "MXN": 2, "MYR": 2, "NGN": 2, "NOK": 2, "NZD": 2,
+	"PHP": 2, "PKR": 2, "PLN": 2, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2, "SAR": 2,
+	"SEK": 2, "SGD": 2, "THB": 2, "TJS": 2, "TMT": 2, "TRY": 2, "TWD": 2, "UAH": 2,
+	"USD": 2, "UZS": 2, "ZAR": 2,
+}
+
+// Default is the currency assigned to wallets created before currencies existed.
+const Default = "RUB"
+
+// Normalize returns the canonical (upper-case, trimmed) form of a currency code.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Exponent returns the number of minor-unit decimal places of a currency.
+//
+// It returns false if the code is not a supported ISO 4217 currency.
+func Exponent(code string) (int, bool) {
+	exponent, ok := exponents[Normalize(code)]
+	return exponent, ok
+}
+
+// IsSupported reports whether code is a supported ISO 4217 currency.
+func IsSupported(code string) bool {
+	_, ok := Exponent(code)
+	return ok
+}
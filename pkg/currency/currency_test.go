@@ -0,0 +1,103 @@
+package currency_test
+
+import (
+	"JavaCode/pkg/currency"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestExponent(t *testing.T) {
+	tests := []struct {
+		code     string
+		exponent int
+		ok       bool
+	}{
+		{"EUR", 2, true},
+		{"jpy", 0, true},
+		{"KWD", 3, true},
+		{"XYZ", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			exponent, ok := currency.Exponent(tt.code)
+			if exponent != tt.exponent || ok != tt.ok {
+				t.Errorf("Exponent(%q) = (%d, %v), want (%d, %v)", tt.code, exponent, ok, tt.exponent, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		rate      string
+		precision int
+		want      int64
+		wantErr   bool
+	}{
+		{"Exact", "1.0834", 4, 10834, false},
+		{"Padded", "1.08", 6, 1080000, false},
+		{"Trailing zeros", "1.50000", 1, 15, false},
+		{"Integer", "95", 2, 9500, false},
+		{"Too precise", "1.08345", 4, 0, true},
+		{"Zero", "0.0", 2, 0, true},
+		{"Negative", "-1.2", 2, 0, true},
+		{"Garbage", "1,2", 2, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := currency.ParseRate(tt.rate, tt.precision)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseRate(%q, %d) = (%d, %v), want %d", tt.rate, tt.precision, got, err, tt.want)
+			}
+		})
+	}
+
+	if got := currency.FormatRate(10834, 4); got != "1.0834" {
+		t.Errorf("FormatRate = %q, want 1.0834", got)
+	}
+	if got := currency.FormatRate(5, 3); got != "0.005" {
+		t.Errorf("FormatRate = %q, want 0.005", got)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    int64
+		fromExp   int
+		toExp     int
+		rate      int64
+		precision int
+		mode      currency.RoundingMode
+		want      int64
+	}{
+		// 10.00 EUR * 1.0834 = 10.834 USD
+		{"Half up", 1000, 2, 2, 10834, 4, currency.RoundHalfUp, 1083},
+		{"Up", 1000, 2, 2, 10834, 4, currency.RoundUp, 1084},
+		{"Down", 1000, 2, 2, 10834, 4, currency.RoundDown, 1083},
+		// 0.25 * 1.0 = 0.25 -> 0 or 1 unit at exponent 1
+		{"Half even rounds to even", 25, 2, 1, 10, 1, currency.RoundHalfEven, 2},
+		{"Half even rounds up odd", 35, 2, 1, 10, 1, currency.RoundHalfEven, 4},
+		// 100.00 USD -> JPY at 151.37 = 15137 JPY
+		{"To zero exponent", 10000, 2, 0, 15137, 2, currency.RoundHalfUp, 15137},
+		// 1.000 KWD -> EUR at 3.0 = 3.00 EUR
+		{"From three exponent", 1000, 3, 2, 3, 0, currency.RoundHalfUp, 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := currency.Convert(tt.amount, tt.fromExp, tt.toExp, tt.rate, tt.precision, tt.mode)
+			if err != nil || got != tt.want {
+				t.Errorf("Convert() = (%d, %v), want %d", got, err, tt.want)
+			}
+		})
+	}
+
+	t.Run("Overflow", func(t *testing.T) {
+		_, err := currency.Convert(math.MaxInt64, 0, 2, 100, 0, currency.RoundDown)
+		if !errors.Is(err, currency.ErrOverflow) {
+			t.Errorf("expected ErrOverflow, got %v", err)
+		}
+	})
+}
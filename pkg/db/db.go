@@ -1,27 +1,119 @@
 // Package db provides functionality for initializing a PostgreSQL database connection.
 //
-// It wraps sql.Open and sql.Ping into a reusable helper for consistent database setup.
+// It wraps sql.Open and sql.Ping into a reusable helper for consistent database setup,
+// with connection pool tuning, retries while the server starts, and DSN redaction for logs.
 package db
 
 import (
 	"database/sql"
 	"fmt"
+	"time"
 )
 
+// PoolOptions configures the connection pool of a *sql.DB.
+// Zero values keep the database/sql defaults.
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// RetryOptions configures how the initial ping is retried.
+type RetryOptions struct {
+	// Attempts is the total number of pings; values below 1 mean a single ping.
+	Attempts int
+	// InitialBackoff is the wait after the first failure; it doubles on each retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts.
+	MaxBackoff time.Duration
+	// OnRetry, if set, is called before each wait.
+	OnRetry func(attempt int, wait time.Duration, err error)
+}
+
 // InitDB initializes and verifies a database connection using the given DSN and driver name.
 //
 // It returns:
 //   - a valid *sql.DB instance on success;
 //   - an error if connection or ping fails.
 func InitDB(dsn string, driverName string) (*sql.DB, error) {
+	return Connect(dsn, driverName, PoolOptions{}, RetryOptions{})
+}
+
+// Connect opens a database, applies pool options and pings it,
+// retrying the ping with exponential backoff while the server is not ready.
+//
+// It returns:
+//   - a valid *sql.DB instance on success;
+//   - an error if the driver rejects the DSN or every ping fails.
+func Connect(dsn string, driverName string, pool PoolOptions, retry RetryOptions) (*sql.DB, error) {
 	db, err := sql.Open(driverName, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("sql.Open error: %w", err)
 	}
 
-	if err = db.Ping(); err != nil {
+	ConfigurePool(db, pool)
+
+	if err = pingWithRetry(db, retry); err != nil {
+		_ = db.Close()
 		return nil, fmt.Errorf("db.Ping error: %w", err)
 	}
 
 	return db, nil
 }
+
+// ConfigurePool applies the non-zero pool options to db.
+func ConfigurePool(db *sql.DB, pool PoolOptions) {
+	if pool.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+	if pool.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	}
+	if pool.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	}
+}
+
+func pingWithRetry(db *sql.DB, retry RetryOptions) error {
+	wait := retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := db.Ping()
+		if err == nil || attempt >= retry.Attempts {
+			return err
+		}
+
+		if retry.OnRetry != nil {
+			retry.OnRetry(attempt, wait, err)
+		}
+		time.Sleep(wait)
+
+		wait *= 2
+		if retry.MaxBackoff > 0 && wait > retry.MaxBackoff {
+			wait = retry.MaxBackoff
+		}
+	}
+}
+
+// InitReplicas initializes connections to every read replica in dsns.
+//
+// It returns:
+//   - the opened replicas in the same order as dsns;
+//   - an error if any replica fails to connect (already opened ones are closed).
+func InitReplicas(dsns []string, driverName string, pool PoolOptions, retry RetryOptions) ([]*sql.DB, error) {
+	replicas := make([]*sql.DB, 0, len(dsns))
+	for i, dsn := range dsns {
+		replica, err := Connect(dsn, driverName, pool, retry)
+		if err != nil {
+			for _, opened := range replicas {
+				_ = opened.Close()
+			}
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas, nil
+}
@@ -0,0 +1,121 @@
+package db_test
+
+import (
+	"JavaCode/pkg/db"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactDSN(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			"Key value",
+			"host=db port=5432 user=admin password=secret dbname=wallet sslmode=disable",
+			"host=db port=5432 user=admin password=xxxxx dbname=wallet sslmode=disable",
+		},
+		{
+			"Quoted key value",
+			`host=db password='se cr\'et' dbname=wallet`,
+			"host=db password=xxxxx dbname=wallet",
+		},
+		{
+			"URL",
+			"postgres://admin:secret@db:5432/wallet?sslmode=disable",
+			"postgres://admin:xxxxx@db:5432/wallet?sslmode=disable",
+		},
+		{
+			"URL with password parameter",
+			"postgres://admin@db/wallet?password=secret",
+			"postgres://admin@db/wallet?password=xxxxx",
+		},
+		{
+			"Without password",
+			"host=db user=admin",
+			"host=db user=admin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := db.RedactDSN(tt.input)
+			if got != tt.want {
+				t.Errorf("RedactDSN() = %q, want %q", got, tt.want)
+			}
+			if strings.Contains(got, "secret") {
+				t.Errorf("RedactDSN() leaked the password: %q", got)
+			}
+		})
+	}
+}
+
+func TestQuoteDSNValue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"wallet", "wallet"},
+		{"", "''"},
+		{"my app", "'my app'"},
+		{`it's`, `'it\'s'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := db.QuoteDSNValue(tt.input); got != tt.want {
+				t.Errorf("QuoteDSNValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	t.Run("Test 1: Retries until ping succeeds", func(t *testing.T) {
+		_, mock, _ := sqlmock.NewWithDSN("retry-ok", sqlmock.MonitorPingsOption(true))
+		mock.ExpectPing().WillReturnError(errors.New("the database system is starting up"))
+		mock.ExpectPing().WillReturnError(errors.New("the database system is starting up"))
+		mock.ExpectPing()
+
+		var retries []int
+		conn, err := db.Connect("retry-ok", "sqlmock", db.PoolOptions{MaxOpenConns: 5}, db.RetryOptions{
+			Attempts:       5,
+			InitialBackoff: time.Millisecond,
+			OnRetry:        func(attempt int, _ time.Duration, _ error) { retries = append(retries, attempt) },
+		})
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		defer conn.Close()
+
+		if len(retries) != 2 {
+			t.Errorf("expected 2 retries, got %v", retries)
+		}
+		if conn.Stats().MaxOpenConnections != 5 {
+			t.Errorf("pool options not applied: %+v", conn.Stats())
+		}
+	})
+
+	t.Run("Test 2: Gives up after all attempts", func(t *testing.T) {
+		_, mock, _ := sqlmock.NewWithDSN("retry-fail", sqlmock.MonitorPingsOption(true))
+		for i := 0; i < 3; i++ {
+			mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		}
+
+		_, err := db.Connect("retry-fail", "sqlmock", db.PoolOptions{}, db.RetryOptions{
+			Attempts:       3,
+			InitialBackoff: time.Millisecond,
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
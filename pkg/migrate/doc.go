@@ -0,0 +1,5 @@
+// Package migrate applies goose-formatted SQL migrations from an fs.FS.
+//
+// It records applied versions in the goose_db_version table, so databases
+// previously migrated with the goose CLI are picked up where they left off.
+package migrate
@@ -0,0 +1,262 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"io/fs"
+	"time"
+)
+
+// advisoryLockID serializes concurrent migrators (e.g. several replicas
+// started with --migrate-on-start) on the same database.
+const advisoryLockID = 7246_1701
+
+// ErrNoMigration is returned by Down when there is nothing to roll back.
+var ErrNoMigration = errors.New("no migration to roll back")
+
+// Status describes whether a migration has been applied.
+type Status struct {
+	Migration Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies migrations to a database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New loads the migrations in fsys for use against db.
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the highest known migration version, or 0 if there are none.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration in version order.
+//
+// It returns the migrations applied by this call.
+func (m *Migrator) Up() ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(func(conn *sql.Conn) error {
+		versions, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			if err := run(conn, migration, migration.Up,
+				"INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, TRUE)"); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the most recently applied migration.
+//
+// It returns:
+//   - the migration rolled back;
+//   - ErrNoMigration if no known migration is applied.
+func (m *Migrator) Down() (*Migration, error) {
+	var rolledBack *Migration
+	err := m.locked(func(conn *sql.Conn) error {
+		versions, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+			if err := run(conn, migration, migration.Down,
+				"DELETE FROM goose_db_version WHERE version_id = $1"); err != nil {
+				return err
+			}
+			rolledBack = &migration
+			return nil
+		}
+		return ErrNoMigration
+	})
+	return rolledBack, err
+}
+
+// Status reports every known migration and whether it is applied.
+func (m *Migrator) Status() ([]Status, error) {
+	var statuses []Status
+	err := m.locked(func(conn *sql.Conn) error {
+		versions, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			appliedAt, ok := versions[migration.Version]
+			statuses = append(statuses, Status{Migration: migration, Applied: ok, AppliedAt: appliedAt})
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Version returns the highest applied migration version without
+// creating the version table.
+//
+// It returns 0 if no migration has been applied yet.
+func Version(db *sql.DB) (int64, error) {
+	const query = `SELECT COALESCE(MAX(version_id), 0) FROM (
+		SELECT DISTINCT ON (version_id) version_id, is_applied
+		FROM goose_db_version ORDER BY version_id, id DESC
+	) latest WHERE is_applied`
+
+	var version int64
+	err := db.QueryRow(query).Scan(&version)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "42P01" { // undefined_table
+		return 0, nil
+	}
+	return version, err
+}
+
+// locked runs fn on a dedicated connection holding the migration advisory lock,
+// after making sure the version table exists.
+func (m *Migrator) locked(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() { _, _ = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", advisoryLockID) }()
+
+	if err := ensureVersionTable(conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// ensureVersionTable creates goose_db_version with goose's layout if it is missing.
+func ensureVersionTable(conn *sql.Conn) error {
+	ctx := context.Background()
+	var exists bool
+	if err := conn.QueryRowContext(ctx, "SELECT to_regclass('goose_db_version') IS NOT NULL").Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`CREATE TABLE goose_db_version (
+		id SERIAL PRIMARY KEY,
+		version_id BIGINT NOT NULL,
+		is_applied BOOLEAN NOT NULL,
+		tstamp TIMESTAMP DEFAULT NOW()
+	)`); err != nil {
+		return fmt.Errorf("create version table: %w", err)
+	}
+	if _, err := tx.Exec("INSERT INTO goose_db_version (version_id, is_applied) VALUES (0, TRUE)"); err != nil {
+		return fmt.Errorf("create version table: %w", err)
+	}
+	return tx.Commit()
+}
+
+// appliedVersions returns the applied migration versions with their apply time.
+// For each version only its most recent row counts, as in goose.
+func appliedVersions(conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(context.Background(),
+		"SELECT version_id, is_applied, tstamp FROM goose_db_version ORDER BY id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := map[int64]bool{}
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var (
+			version   int64
+			isApplied bool
+			tstamp    sql.NullTime
+		)
+		if err := rows.Scan(&version, &isApplied, &tstamp); err != nil {
+			return nil, err
+		}
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		if isApplied {
+			applied[version] = tstamp.Time
+		}
+	}
+	return applied, rows.Err()
+}
+
+// run executes statements and records the version change, in one
+// transaction unless the migration opts out.
+func run(conn *sql.Conn, migration Migration, statements []string, record string) error {
+	ctx := context.Background()
+	wrap := func(err error) error {
+		return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if migration.NoTx {
+		for _, stmt := range statements {
+			if _, err := conn.ExecContext(ctx, stmt); err != nil {
+				return wrap(err)
+			}
+		}
+		if _, err := conn.ExecContext(ctx, record, migration.Version); err != nil {
+			return wrap(err)
+		}
+		return nil
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return wrap(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return wrap(err)
+		}
+	}
+	if _, err := tx.Exec(record, migration.Version); err != nil {
+		return wrap(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package migrate_test
+
+import (
+	"JavaCode/migrations"
+	"JavaCode/pkg/migrate"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("Test 1: Embedded migrations", func(t *testing.T) {
+		loaded, err := migrate.Load(migrations.FS)
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if len(loaded) < 2 || loaded[0].Name != "init" || loaded[0].Version != 20250417120934 {
+			t.Fatalf("unexpected migrations: %+v", loaded)
+		}
+		for _, m := range loaded {
+			if len(m.Up) == 0 || len(m.Down) == 0 {
+				t.Errorf("migration %d_%s has empty up or down", m.Version, m.Name)
+			}
+		}
+	})
+
+	t.Run("Test 2: Statement splitting", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"2_second.sql": {Data: []byte(`-- +goose Up
+-- +goose StatementBegin
+CREATE FUNCTION f() RETURNS INT AS $$
+BEGIN
+    RETURN 1;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+-- +goose Down
+DROP FUNCTION f;
+`)},
+			"1_first.sql": {Data: []byte(`-- comment
+-- +goose Up
+CREATE TABLE a (id INT);
+INSERT INTO a
+VALUES (1);
+
+-- +goose Down
+DROP TABLE a;
+`)},
+		}
+
+		loaded, err := migrate.Load(fsys)
+		if err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		if loaded[0].Version != 1 || loaded[1].Version != 2 {
+			t.Fatalf("migrations not sorted: %+v", loaded)
+		}
+		if len(loaded[0].Up) != 2 || len(loaded[0].Down) != 1 {
+			t.Errorf("unexpected statements in first: %q / %q", loaded[0].Up, loaded[0].Down)
+		}
+		if len(loaded[1].Up) != 1 {
+			t.Errorf("StatementBegin block was split: %q", loaded[1].Up)
+		}
+	})
+
+	t.Run("Test 3: Invalid files", func(t *testing.T) {
+		tests := []struct {
+			name string
+			fsys fstest.MapFS
+		}{
+			{"No version", fstest.MapFS{"init.sql": {Data: []byte("-- +goose Up\nSELECT 1;\n")}}},
+			{"Duplicate version", fstest.MapFS{
+				"1_a.sql": {Data: []byte("-- +goose Up\nSELECT 1;\n")},
+				"1_b.sql": {Data: []byte("-- +goose Up\nSELECT 1;\n")},
+			}},
+			{"Missing Up", fstest.MapFS{"1_a.sql": {Data: []byte("SELECT 1;\n")}}},
+			{"Unclosed block", fstest.MapFS{"1_a.sql": {Data: []byte("-- +goose Up\n-- +goose StatementBegin\nSELECT 1;\n")}}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if _, err := migrate.Load(tt.fsys); err == nil {
+					t.Error("expected error, got nil")
+				}
+			})
+		}
+	})
+}
+
+func TestMigrator_Up(t *testing.T) {
+	fsys := fstest.MapFS{
+		"1_first.sql":  {Data: []byte("-- +goose Up\nCREATE TABLE a (id INT);\n-- +goose Down\nDROP TABLE a;\n")},
+		"2_second.sql": {Data: []byte("-- +goose Up\nCREATE TABLE b (id INT);\n-- +goose Down\nDROP TABLE b;\n")},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	mock.ExpectExec("SELECT pg_advisory_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT to_regclass").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT version_id, is_applied, tstamp FROM goose_db_version ORDER BY id DESC").
+		WillReturnRows(sqlmock.NewRows([]string{"version_id", "is_applied", "tstamp"}).
+			AddRow(1, true, time.Now()).
+			AddRow(0, true, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE b").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO goose_db_version").WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT pg_advisory_unlock").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	migrator, err := migrate.New(db, fsys)
+	if err != nil {
+		t.Fatalf("expected nil, got error: %v", err)
+	}
+
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("expected nil, got error: %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 2 {
+		t.Errorf("unexpected applied migrations: %+v", applied)
+	}
+	if migrator.Latest() != 2 {
+		t.Errorf("Latest: got %d, want 2", migrator.Latest())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMigrator_Down(t *testing.T) {
+	fsys := fstest.MapFS{
+		"1_first.sql": {Data: []byte("-- +goose Up\nCREATE TABLE a (id INT);\n-- +goose Down\nDROP TABLE a;\n")},
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	mock.ExpectExec("SELECT pg_advisory_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT to_regclass").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT version_id, is_applied, tstamp FROM goose_db_version").
+		WillReturnRows(sqlmock.NewRows([]string{"version_id", "is_applied", "tstamp"}).AddRow(0, true, time.Now()))
+	mock.ExpectExec("SELECT pg_advisory_unlock").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	migrator, _ := migrate.New(db, fsys)
+	if _, err := migrator.Down(); !errors.Is(err, migrate.ErrNoMigration) {
+		t.Errorf("expected ErrNoMigration, got: %v", err)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	t.Run("Test 1: Applied version", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT COALESCE\\(MAX\\(version_id\\), 0\\)").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(20250417135508))
+
+		version, err := migrate.Version(db)
+		if err != nil || version != 20250417135508 {
+			t.Errorf("Version: got (%d, %v)", version, err)
+		}
+	})
+
+	t.Run("Test 2: Missing version table", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT COALESCE\\(MAX\\(version_id\\), 0\\)").
+			WillReturnError(&pq.Error{Code: "42P01"})
+
+		version, err := migrate.Version(db)
+		if err != nil || version != 0 {
+			t.Errorf("Version: got (%d, %v), want (0, nil)", version, err)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package migrate
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      []string
+	Down    []string
+	// NoTx is set by "-- +goose NO TRANSACTION" and runs the statements outside a transaction.
+	NoTx bool
+}
+
+// Load reads every *.sql migration in the root of fsys, sorted by version.
+//
+// It returns an error if a file name has no numeric version prefix,
+// two files share a version, or a file cannot be parsed.
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[int64]string{}
+	migrations := make([]Migration, 0, len(names))
+	for _, name := range names {
+		prefix, rest, ok := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: file name must start with a positive version, e.g. 20250417120934_init.sql", name)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migration %s: version %d already used by %s", name, version, other)
+		}
+		seen[version] = name
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		m, err := parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", name, err)
+		}
+		m.Version = version
+		m.Name = strings.TrimSuffix(rest, path.Ext(rest))
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parse splits a goose-formatted file into up and down statements.
+//
+// Statements end with a line ending in ";" unless they are wrapped in
+// "-- +goose StatementBegin" / "-- +goose StatementEnd".
+func parse(content string) (Migration, error) {
+	var (
+		m         Migration
+		target    *[]string
+		buf       strings.Builder
+		inBlock   bool
+		lineNo    int
+		sawUpMark bool
+	)
+
+	flush := func() {
+		if stmt := strings.TrimSpace(buf.String()); stmt != "" && target != nil {
+			*target = append(*target, stmt)
+		}
+		buf.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if directive, ok := strings.CutPrefix(trimmed, "-- +goose "); ok {
+			switch strings.ToUpper(strings.TrimSpace(directive)) {
+			case "UP":
+				flush()
+				target, sawUpMark = &m.Up, true
+			case "DOWN":
+				flush()
+				target = &m.Down
+			case "STATEMENTBEGIN":
+				flush()
+				inBlock = true
+			case "STATEMENTEND":
+				if !inBlock {
+					return m, fmt.Errorf("line %d: StatementEnd without StatementBegin", lineNo)
+				}
+				inBlock = false
+				flush()
+			case "NO TRANSACTION":
+				m.NoTx = true
+			default:
+				return m, fmt.Errorf("line %d: unknown directive %q", lineNo, trimmed)
+			}
+			continue
+		}
+
+		if target == nil {
+			if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				return m, fmt.Errorf("line %d: statement before -- +goose Up", lineNo)
+			}
+			continue
+		}
+		if !inBlock && buf.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return m, err
+	}
+	if inBlock {
+		return m, fmt.Errorf("missing StatementEnd")
+	}
+	flush()
+
+	if !sawUpMark {
+		return m, fmt.Errorf("missing -- +goose Up")
+	}
+	return m, nil
+}
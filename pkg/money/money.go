@@ -0,0 +1,194 @@
+// Package money represents amounts of a currency as integer minor units
+// and provides overflow-checked arithmetic and the decimal text format
+// ("12.34 EUR") used by the v2 API.
+package money
+
+import (
+	"JavaCode/pkg/currency"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	// ErrFormat is returned when an amount is not a decimal number followed by a currency code.
+	ErrFormat = errors.New("malformed amount")
+	// ErrPrecision is returned when an amount has more decimal places than its currency allows.
+	ErrPrecision = errors.New("amount has more decimal places than the currency allows")
+	// ErrOverflow is returned when an amount or a result does not fit in 64 bits.
+	ErrOverflow = errors.New("amount overflows")
+	// ErrCurrency is returned for unsupported currencies and for arithmetic across currencies.
+	ErrCurrency = errors.New("unsupported or mismatched currency")
+)
+
+// Money is an amount in minor units of an ISO 4217 currency.
+type Money struct {
+	Units    int64
+	Currency string
+}
+
+// New returns units minor units of the given currency.
+func New(units int64, code string) Money {
+	return Money{Units: units, Currency: currency.Normalize(code)}
+}
+
+// FromUnsigned returns an amount stored as an unsigned balance.
+//
+// It returns ErrOverflow if units does not fit in an int64.
+func FromUnsigned(units uint64, code string) (Money, error) {
+	if units > math.MaxInt64 {
+		return Money{}, ErrOverflow
+	}
+	return New(int64(units), code), nil
+}
+
+// Parse parses an amount in the form "<decimal> <currency>", e.g. "12.34 EUR"
+// or "-5 JPY".
+//
+// It returns:
+//   - ErrFormat if the text is malformed;
+//   - ErrCurrency if the currency is not supported;
+//   - ErrPrecision if the amount has more significant decimal places than the currency's exponent;
+//   - ErrOverflow if the amount does not fit in an int64 of minor units.
+func Parse(s string) (Money, error) {
+	parts := strings.Fields(s)
+	if len(parts) != 2 {
+		return Money{}, fmt.Errorf("%w: %q (want e.g. \"12.34 EUR\")", ErrFormat, s)
+	}
+	return ParseAmount(parts[0], parts[1])
+}
+
+// ParseAmount parses a decimal amount such as "12.34" in the given currency.
+//
+// It returns the same errors as Parse.
+func ParseAmount(amount, code string) (Money, error) {
+	code = currency.Normalize(code)
+	exponent, ok := currency.Exponent(code)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrCurrency, code)
+	}
+
+	// At most one sign; isDigits rejects a second one.
+	negative := strings.HasPrefix(amount, "-")
+	digits := amount
+	if negative || strings.HasPrefix(amount, "+") {
+		digits = amount[1:]
+	}
+	whole, frac, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || !isDigits(whole) || !isDigits(frac) || (hasPoint && frac == "") {
+		return Money{}, fmt.Errorf("%w: %q", ErrFormat, amount)
+	}
+
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > exponent {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places in %s", ErrPrecision, amount, exponent, code)
+	}
+	frac += strings.Repeat("0", exponent-len(frac))
+
+	var units int64
+	for _, r := range whole + frac {
+		var err error
+		if units, err = Mul(units, 10); err != nil {
+			return Money{}, err
+		}
+		if units, err = Add(units, int64(r-'0')); err != nil {
+			return Money{}, err
+		}
+	}
+	if negative {
+		units = -units
+	}
+	return Money{Units: units, Currency: code}, nil
+}
+
+// String formats the amount as "<decimal> <currency>", e.g. "12.34 EUR".
+func (m Money) String() string {
+	exponent, _ := currency.Exponent(m.Currency)
+
+	sign := ""
+	magnitude := uint64(m.Units)
+	if m.Units < 0 {
+		sign = "-"
+		magnitude = uint64(-(m.Units + 1)) + 1 // avoids overflow for math.MinInt64
+	}
+
+	digits := fmt.Sprintf("%0*d", exponent+1, magnitude)
+	if exponent == 0 {
+		return sign + digits + " " + m.Currency
+	}
+	split := len(digits) - exponent
+	return sign + digits[:split] + "." + digits[split:] + " " + m.Currency
+}
+
+// IsPositive reports whether the amount is greater than zero.
+func (m Money) IsPositive() bool {
+	return m.Units > 0
+}
+
+// Add returns m + other.
+//
+// It returns ErrCurrency if the currencies differ and ErrOverflow if the sum overflows.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrency
+	}
+	units, err := Add(m.Units, other.Units)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Units: units, Currency: m.Currency}, nil
+}
+
+// Sub returns m - other.
+//
+// It returns ErrCurrency if the currencies differ and ErrOverflow if the difference overflows.
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrency
+	}
+	units, err := Sub(m.Units, other.Units)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Units: units, Currency: m.Currency}, nil
+}
+
+// Add returns a + b, or ErrOverflow if the result does not fit in an int64.
+func Add(a, b int64) (int64, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, ErrOverflow
+	}
+	return sum, nil
+}
+
+// Sub returns a - b, or ErrOverflow if the result does not fit in an int64.
+func Sub(a, b int64) (int64, error) {
+	diff := a - b
+	if (b > 0 && diff > a) || (b < 0 && diff < a) {
+		return 0, ErrOverflow
+	}
+	return diff, nil
+}
+
+// Mul returns a * b, or ErrOverflow if the result does not fit in an int64.
+func Mul(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, ErrOverflow
+	}
+	return product, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
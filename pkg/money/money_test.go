@@ -0,0 +1,99 @@
+package money_test
+
+import (
+	"JavaCode/pkg/money"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    money.Money
+		wantErr error
+	}{
+		{"12.34 EUR", money.Money{Units: 1234, Currency: "EUR"}, nil},
+		{"12 eur", money.Money{Units: 1200, Currency: "EUR"}, nil},
+		{"0.5 EUR", money.Money{Units: 50, Currency: "EUR"}, nil},
+		{"12.340 EUR", money.Money{Units: 1234, Currency: "EUR"}, nil},
+		{"-1.5 USD", money.Money{Units: -150, Currency: "USD"}, nil},
+		{"1000 JPY", money.Money{Units: 1000, Currency: "JPY"}, nil},
+		{"1.001 KWD", money.Money{Units: 1001, Currency: "KWD"}, nil},
+		{"92233720368547758.07 EUR", money.Money{Units: math.MaxInt64, Currency: "EUR"}, nil},
+		{"12.345 EUR", money.Money{}, money.ErrPrecision},
+		{"1.5 JPY", money.Money{}, money.ErrPrecision},
+		{"92233720368547758.08 EUR", money.Money{}, money.ErrOverflow},
+		{"99999999999999999999 JPY", money.Money{}, money.ErrOverflow},
+		{"12.34", money.Money{}, money.ErrFormat},
+		{"12. EUR", money.Money{}, money.ErrFormat},
+		{".5 EUR", money.Money{}, money.ErrFormat},
+		{"1e3 EUR", money.Money{}, money.ErrFormat},
+		{"+5 EUR", money.Money{Units: 500, Currency: "EUR"}, nil},
+		{"-+5 EUR", money.Money{}, money.ErrFormat},
+		{"+-5 EUR", money.Money{}, money.ErrFormat},
+		{"--5 EUR", money.Money{}, money.ErrFormat},
+		{"12.34 XYZ", money.Money{}, money.ErrCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := money.Parse(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		money money.Money
+		want  string
+	}{
+		{money.New(1234, "EUR"), "12.34 EUR"},
+		{money.New(5, "EUR"), "0.05 EUR"},
+		{money.New(-150, "usd"), "-1.50 USD"},
+		{money.New(1000, "JPY"), "1000 JPY"},
+		{money.New(1, "KWD"), "0.001 KWD"},
+		{money.New(math.MinInt64, "JPY"), "-9223372036854775808 JPY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.money.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckedArithmetic(t *testing.T) {
+	if _, err := money.Add(math.MaxInt64, 1); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("Add overflow: got %v", err)
+	}
+	if _, err := money.Sub(math.MinInt64, 1); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("Sub overflow: got %v", err)
+	}
+	if _, err := money.Mul(math.MaxInt64/2+1, 2); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("Mul overflow: got %v", err)
+	}
+	if _, err := money.Mul(-1, math.MinInt64); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("Mul overflow: got %v", err)
+	}
+	if sum, err := money.Add(-5, 3); err != nil || sum != -2 {
+		t.Errorf("Add(-5, 3) = %d, %v", sum, err)
+	}
+
+	if _, err := money.New(1, "EUR").Add(money.New(1, "USD")); !errors.Is(err, money.ErrCurrency) {
+		t.Errorf("Add across currencies: got %v", err)
+	}
+	if _, err := money.FromUnsigned(math.MaxUint64, "EUR"); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("FromUnsigned overflow: got %v", err)
+	}
+	diff, err := money.New(1000, "EUR").Sub(money.New(1500, "EUR"))
+	if err != nil || diff.Units != -500 {
+		t.Errorf("Sub = %+v, %v", diff, err)
+	}
+}
@@ -0,0 +1,144 @@
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField is the range of a cron field.
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// cron is a parsed five-field cron expression. Each field is a bit set of
+// the allowed values.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record a "*" day field: as in cron, when both day
+	// fields are restricted a day matching either one qualifies.
+	domAny, dowAny bool
+}
+
+// parseCron parses "minute hour day-of-month month day-of-week". Fields are
+// "*", numbers, ranges "a-b", steps "*/n" or "a-b/n", and lists of these.
+// Day of week 0 and 7 are Sunday.
+func parseCron(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%w %q: want daily, weekly, monthly or a cron expression with 5 fields", ErrInvalidSchedule, spec)
+	}
+
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidSchedule, spec, err)
+		}
+		sets[i] = set
+	}
+	c := &cron{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+// parseCronField parses a field into the set of its values.
+func parseCronField(field string, f cronField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if before, after, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(after)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s: invalid step %q", f.name, after)
+			}
+			rangePart, step = before, n
+		}
+
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			before, after, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(before); err != nil {
+				return 0, fmt.Errorf("%s: invalid value %q", f.name, before)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(after); err != nil {
+					return 0, fmt.Errorf("%s: invalid value %q", f.name, after)
+				}
+			} else if step > 1 {
+				high = f.max
+			}
+		}
+		if low < f.min || high > f.max || low > high {
+			return 0, fmt.Errorf("%s: %q is out of range %d-%d", f.name, rangePart, f.min, f.max)
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// Next returns the first matching minute after t.
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if c.hour&(1<<t.Hour()) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if c.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay reports whether the day of t matches the day fields.
+func (c *cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	}
+	return dom || dow
+}
@@ -0,0 +1,85 @@
+// Package recurrence computes the occurrences of recurring schedules:
+// "daily", "weekly" and "monthly" repeat the start time, a five-field cron
+// expression ("minute hour day-of-month month day-of-week") gives the times
+// explicitly. All times are in UTC.
+package recurrence
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Schedule keywords.
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+// ErrInvalidSchedule is returned for a schedule that cannot be parsed.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule gives the occurrences of a recurring operation.
+type Schedule interface {
+	// Next returns the first occurrence strictly after t, or the zero time
+	// if there is none within the next five years.
+	Next(t time.Time) time.Time
+}
+
+// Parse parses a schedule spec. The keywords repeat start, which is also
+// the first occurrence; a cron expression ignores it.
+func Parse(spec string, start time.Time) (Schedule, error) {
+	start = start.UTC().Truncate(time.Second)
+	switch strings.ToLower(strings.TrimSpace(spec)) {
+	case Daily:
+		return interval{start: start, days: 1}, nil
+	case Weekly:
+		return interval{start: start, days: 7}, nil
+	case Monthly:
+		return monthly{start: start}, nil
+	}
+	return parseCron(spec)
+}
+
+// interval repeats start every given number of days.
+type interval struct {
+	start time.Time
+	days  int
+}
+
+func (s interval) Next(t time.Time) time.Time {
+	t = t.UTC()
+	if t.Before(s.start) {
+		return s.start
+	}
+	n := int(t.Sub(s.start)/(time.Duration(s.days)*24*time.Hour)) + 1
+	return s.start.AddDate(0, 0, n*s.days)
+}
+
+// monthly repeats start on the same day of every month, or on the last day
+// of shorter months.
+type monthly struct {
+	start time.Time
+}
+
+func (s monthly) Next(t time.Time) time.Time {
+	t = t.UTC()
+	if t.Before(s.start) {
+		return s.start
+	}
+	n := (t.Year()-s.start.Year())*12 + int(t.Month()-s.start.Month())
+	for ; ; n++ {
+		if next := s.occurrence(n); next.After(t) {
+			return next
+		}
+	}
+}
+
+// occurrence returns the n-th occurrence after start.
+func (s monthly) occurrence(n int) time.Time {
+	first := time.Date(s.start.Year(), s.start.Month()+time.Month(n), 1,
+		s.start.Hour(), s.start.Minute(), s.start.Second(), 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(s.start.Day(), lastDay)-1)
+}
@@ -0,0 +1,71 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNext(t *testing.T) {
+	for _, tc := range []struct {
+		spec  string
+		start string
+		after string
+		want  string
+	}{
+		{"daily", "2025-07-01T09:30:00Z", "2025-06-01T00:00:00Z", "2025-07-01T09:30:00Z"},
+		{"daily", "2025-07-01T09:30:00Z", "2025-07-01T09:30:00Z", "2025-07-02T09:30:00Z"},
+		{"daily", "2025-07-01T09:30:00Z", "2025-07-10T12:00:00Z", "2025-07-11T09:30:00Z"},
+		{"weekly", "2025-07-01T09:30:00Z", "2025-07-08T09:29:59Z", "2025-07-08T09:30:00Z"},
+		{"Monthly", "2025-01-31T00:00:00Z", "2025-01-31T00:00:00Z", "2025-02-28T00:00:00Z"},
+		{"monthly", "2025-01-31T00:00:00Z", "2025-02-28T00:00:00Z", "2025-03-31T00:00:00Z"},
+		{"monthly", "2024-01-31T00:00:00Z", "2024-02-01T00:00:00Z", "2024-02-29T00:00:00Z"},
+		{"monthly", "2025-01-15T08:00:00Z", "2025-12-20T00:00:00Z", "2026-01-15T08:00:00Z"},
+		{"*/15 * * * *", "", "2025-07-01T09:31:10Z", "2025-07-01T09:45:00Z"},
+		{"0 9 * * 1-5", "", "2025-07-04T09:00:00Z", "2025-07-07T09:00:00Z"},
+		{"0 0 1 * *", "", "2025-07-01T00:00:00Z", "2025-08-01T00:00:00Z"},
+		{"30 6 29 2 *", "", "2025-03-01T00:00:00Z", "2028-02-29T06:30:00Z"},
+		{"0 12 13 * 5", "", "2025-07-01T00:00:00Z", "2025-07-04T12:00:00Z"},
+		{"0 0 * * 7", "", "2025-07-01T00:00:00Z", "2025-07-06T00:00:00Z"},
+		{"5,10 8-9 * 12 *", "", "2025-12-31T09:10:00Z", "2026-12-01T08:05:00Z"},
+	} {
+		start := time.Time{}
+		if tc.start != "" {
+			start = date(tc.start)
+		}
+		schedule, err := Parse(tc.spec, start)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tc.spec, err)
+			continue
+		}
+		if got := schedule.Next(date(tc.after)); !got.Equal(date(tc.want)) {
+			t.Errorf("%q from %s: Next(%s) = %s, want %s", tc.spec, tc.start, tc.after, got.Format(time.RFC3339), tc.want)
+		}
+	}
+}
+
+func TestNext_Never(t *testing.T) {
+	schedule, err := Parse("0 0 31 2 *", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := schedule.Next(date("2025-01-01T00:00:00Z")); !got.IsZero() {
+		t.Errorf("Next = %v, want none", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "hourly", "* * * *", "60 * * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := Parse(spec, time.Now()); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidSchedule", spec, err)
+		}
+	}
+}
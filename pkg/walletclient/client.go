@@ -0,0 +1,243 @@
+package walletclient
+
+import (
+	"JavaCode/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Defaults of the retry policy.
+const (
+	DefaultRetries    = 3
+	DefaultBackoff    = 200 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// maxErrorBody caps how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+// Client calls the wallet REST API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	userAgent  string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests, e.g. to configure
+// timeouts or TLS. The default is http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithRetries sets how many times a failed request is retried; 0 disables
+// retries.
+func WithRetries(retries int) Option {
+	return func(c *Client) { c.retries = max(retries, 0) }
+}
+
+// WithBackoff sets the wait before the first retry and its cap. The wait
+// doubles with every retry and is randomized by up to a half.
+func WithBackoff(initial, maximum time.Duration) Option {
+	return func(c *Client) { c.backoff, c.maxBackoff = initial, max(initial, maximum) }
+}
+
+// WithUserAgent sets the User-Agent header of requests.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// New returns a client of the API served at baseURL, e.g.
+// "http://localhost:8080".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("walletclient: invalid base url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("walletclient: invalid base url %q: must be an http:// or https:// URL", baseURL)
+	}
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		retries:    DefaultRetries,
+		backoff:    DefaultBackoff,
+		maxBackoff: DefaultMaxBackoff,
+		userAgent:  "walletclient",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// request describes a call of the API.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   any
+	// idempotent requests are retried after any transient failure, others
+	// only when they were never sent.
+	idempotent bool
+	// duplicateOK treats ErrDuplicateReference as success on a retry: the
+	// earlier attempt was applied.
+	duplicateOK bool
+}
+
+// Do calls an endpoint that has no dedicated method. path is relative to
+// the base URL, e.g. "/api/v1/webhooks". body, if not nil, is sent as JSON
+// and a successful response is decoded into out, if not nil. GET, PUT and
+// DELETE requests are retried.
+//
+// It returns the response headers, or *Error for error responses.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, out any) (http.Header, error) {
+	idempotent := method == http.MethodGet || method == http.MethodHead ||
+		method == http.MethodPut || method == http.MethodDelete
+	return c.do(ctx, request{method: method, path: path, query: query, body: body, idempotent: idempotent}, out)
+}
+
+func (c *Client) do(ctx context.Context, req request, out any) (http.Header, error) {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		if payload, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("walletclient: encode request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		header, retryAfter, err := c.send(ctx, req, payload, out)
+		if err == nil {
+			return header, nil
+		}
+		if attempt > 0 && req.duplicateOK && errors.Is(err, ErrDuplicateReference) {
+			return header, nil
+		}
+		if attempt >= c.retries || !retryable(err, req.idempotent) {
+			return header, err
+		}
+
+		timer := time.NewTimer(max(c.retryBackoff(attempt), retryAfter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send makes one attempt of req. A Retry-After header of an error response
+// is returned with the error.
+func (c *Client) send(ctx context.Context, req request, payload []byte, out any) (http.Header, time.Duration, error) {
+	u := c.baseURL.JoinPath(req.path)
+	if len(req.query) > 0 {
+		u.RawQuery = req.query.Encode()
+	}
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("walletclient: %w", err)
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return resp.Header, parseRetryAfter(resp.Header.Get("Retry-After")), decodeError(resp)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.Header, 0, fmt.Errorf("walletclient: decode response: %w", err)
+		}
+	}
+	return resp.Header, 0, nil
+}
+
+// decodeError reads the ErrorResponse of a failed request.
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var body utils.ErrorResponse
+	if err := json.Unmarshal(data, &body); err == nil && body.Error != "" {
+		return &Error{StatusCode: resp.StatusCode, Code: body.Error, Message: body.Message}
+	}
+	message := strings.TrimSpace(string(data))
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	return &Error{StatusCode: resp.StatusCode, Message: message}
+}
+
+// retryable reports whether a request that failed with err may be repeated.
+func retryable(err error, idempotent bool) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests:
+			// Rejected before it was handled.
+			return true
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return idempotent
+		}
+		return false
+	}
+	// A refused connection means the request was never sent.
+	return idempotent || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// retryBackoff returns the randomized wait before retry attempt+1.
+func (c *Client) retryBackoff(attempt int) time.Duration {
+	backoff := c.backoff
+	for i := 0; i < attempt && backoff < c.maxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, c.maxBackoff)
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds.
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,18 @@
+// Package walletclient is a Go client of the wallet REST API.
+//
+// Requests and responses are the types of the API itself. Error responses
+// are returned as *Error, which matches the errors the server reports with
+// errors.Is:
+//
+//	_, err := client.Withdraw(ctx, walletID, 500, "RUB")
+//	if errors.Is(err, walletclient.ErrNegativeBalance) {
+//		// insufficient funds
+//	}
+//
+// Failed requests are retried with exponential backoff when repeating them
+// is safe: reads, and deposits and withdrawals, which are made idempotent
+// with their reference. An operation without a reference gets a random one,
+// so that a retry of an operation that was applied is rejected by the
+// server as a duplicate and reported as a success. Creating wallets and
+// transfers are only retried when the request was never sent.
+package walletclient
@@ -0,0 +1,72 @@
+package walletclient
+
+import (
+	"JavaCode/utils"
+	"fmt"
+)
+
+// Errors reported by the API. They are the errors of the server, so the
+// same errors.Is checks work against the client and the service layer.
+var (
+	ErrInvalidRequest      = utils.ErrInvalidRequest
+	ErrInvalidAmount       = utils.ErrInvalidAmount
+	ErrNegativeBalance     = utils.ErrNegativeBalance
+	ErrAmountPrecision     = utils.ErrAmountPrecision
+	ErrAmountOverflow      = utils.ErrAmountOverflow
+	ErrWalletNotFound      = utils.ErrWalletNotFound
+	ErrWalletExists        = utils.ErrWalletExists
+	ErrWalletFrozen        = utils.ErrWalletFrozen
+	ErrWalletClosed        = utils.ErrWalletClosed
+	ErrWalletNotEmpty      = utils.ErrWalletNotEmpty
+	ErrInvalidStatus       = utils.ErrInvalidStatus
+	ErrDatabase            = utils.ErrDatabase
+	ErrHistoryUnavailable  = utils.ErrHistoryUnavailable
+	ErrNotReady            = utils.ErrNotReady
+	ErrUnsupportedCurrency = utils.ErrUnsupportedCurrency
+	ErrCurrencyMismatch    = utils.ErrCurrencyMismatch
+	ErrRateNotFound        = utils.ErrRateNotFound
+	ErrStaleRate           = utils.ErrStaleRate
+	ErrTransferNotFound    = utils.ErrTransferNotFound
+	ErrDuplicateReference  = utils.ErrDuplicateReference
+	ErrWebhookNotFound     = utils.ErrWebhookNotFound
+	ErrDeliveryNotFound    = utils.ErrDeliveryNotFound
+	ErrScheduleNotFound    = utils.ErrScheduleNotFound
+	ErrScheduleNotActive   = utils.ErrScheduleNotActive
+)
+
+// errorsByCode maps the error codes of ErrorResponse to the errors above.
+var errorsByCode = func() map[string]error {
+	byCode := map[string]error{}
+	for _, err := range []error{
+		ErrInvalidRequest, ErrInvalidAmount, ErrNegativeBalance, ErrAmountPrecision, ErrAmountOverflow,
+		ErrWalletNotFound, ErrWalletExists, ErrWalletFrozen, ErrWalletClosed, ErrWalletNotEmpty,
+		ErrInvalidStatus, ErrDatabase, ErrNotReady, ErrUnsupportedCurrency, ErrCurrencyMismatch,
+		ErrRateNotFound, ErrStaleRate, ErrTransferNotFound, ErrDuplicateReference, ErrWebhookNotFound,
+		ErrDeliveryNotFound, ErrScheduleNotFound, ErrScheduleNotActive, ErrHistoryUnavailable,
+	} {
+		byCode[utils.NewErrorResponse(err).Error] = err
+	}
+	return byCode
+}()
+
+// Error is an error response of the API.
+type Error struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Code is the error code of the response, e.g. "wallet_not_found"; it is
+	// empty if the response was not an ErrorResponse, e.g. from a proxy.
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("wallet api: status %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("wallet api: %s (status %d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// Unwrap returns the error matching Code, or nil for unknown codes.
+func (e *Error) Unwrap() error {
+	return errorsByCode[e.Code]
+}
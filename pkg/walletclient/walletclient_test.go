@@ -0,0 +1,177 @@
+package walletclient
+
+import (
+	"JavaCode/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := New(server.URL, WithBackoff(0, 0))
+	assert.NoError(t, err)
+	return client
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	response := utils.NewErrorResponse(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.Code)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func TestClient_Errors(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/wallets/missing" {
+			writeError(w, utils.ErrWalletNotFound)
+			return
+		}
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	})
+
+	_, err := client.GetBalance(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+	var apiErr *Error
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "wallet_not_found", apiErr.Code)
+	}
+
+	_, err = client.GetTransfer(context.Background(), "any")
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+		assert.Empty(t, apiErr.Code)
+		assert.Equal(t, "bad gateway", apiErr.Message)
+		assert.Nil(t, apiErr.Unwrap())
+	}
+}
+
+func TestClient_ApplyOperationRetry(t *testing.T) {
+	var attempts atomic.Int32
+	references := make(chan string, 3)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req WalletOperationRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		references <- req.Reference
+		switch attempts.Add(1) {
+		case 1:
+			writeError(w, utils.ErrNotReady)
+		case 2:
+			// The first attempt was applied after all.
+			writeError(w, utils.ErrDuplicateReference)
+		}
+	})
+
+	result, err := client.Deposit(context.Background(), "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f", 100, "RUB")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), attempts.Load())
+	first, second := <-references, <-references
+	assert.NotEmpty(t, first)
+	assert.Equal(t, first, second)
+	assert.Equal(t, first, result.Reference)
+}
+
+func TestClient_ApplyOperationDuplicate(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, utils.ErrDuplicateReference)
+	})
+
+	_, err := client.ApplyOperation(context.Background(), WalletOperationRequest{
+		WalletID:         "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f",
+		OperationType:    Withdraw,
+		Amount:           100,
+		Currency:         "RUB",
+		OperationDetails: OperationDetails{Reference: "order-1001"},
+	})
+	assert.ErrorIs(t, err, ErrDuplicateReference)
+}
+
+func TestClient_ApplyOperationConsistencyToken(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.Header().Set(consistencyHeader, "0/16B3748")
+			_, _ = w.Write([]byte(`{"message":"operation successful"}`))
+		case http.MethodGet:
+			assert.Equal(t, "0/16B3748", r.Header.Get(consistencyHeader))
+			_, _ = w.Write([]byte(`{"uuid":"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f","balance":100,"currency":"RUB"}`))
+		}
+	})
+
+	result, err := client.Deposit(context.Background(), "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f", 100, "RUB")
+	assert.NoError(t, err)
+	assert.Equal(t, "0/16B3748", result.ConsistencyToken)
+
+	wallet, err := client.GetBalance(context.Background(), "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f", ReadAfter(result.ConsistencyToken))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), wallet.Balance)
+}
+
+func TestClient_TransferNotRetried(t *testing.T) {
+	var attempts atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "gateway timeout", http.StatusGatewayTimeout)
+	})
+
+	_, err := client.Transfer(context.Background(), TransferRequest{
+		FromWalletID: "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f",
+		ToWalletID:   "1c63a43f-aacd-47b0-bc3b-535e69c6ed4c",
+		Amount:       100,
+		Currency:     "RUB",
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestClient_GetRetriesUntilExhausted(t *testing.T) {
+	var attempts atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		writeError(w, utils.ErrNotReady)
+	})
+
+	_, err := client.GetBalance(context.Background(), "c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f")
+	assert.ErrorIs(t, err, ErrNotReady)
+	assert.Equal(t, int32(DefaultRetries+1), attempts.Load())
+}
+
+func TestClient_ListWalletsQuery(t *testing.T) {
+	minBalance := uint64(500)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "customer-1842", query.Get("owner"))
+		assert.Equal(t, []string{"region:eu", "tier:gold"}, query["label"])
+		assert.Equal(t, "500", query.Get("minBalance"))
+		assert.Equal(t, "-balance", query.Get("sort"))
+		assert.Equal(t, "abc", query.Get("cursor"))
+		assert.Equal(t, "10", query.Get("limit"))
+		_, _ = w.Write([]byte(`{"wallets":[{"uuid":"c3a8cb84-03f2-4fb9-982a-9ee2cfb50b9f","balance":700}],"nextCursor":"def"}`))
+	})
+
+	page, err := client.ListWallets(context.Background(), ListWalletsRequest{
+		Owner:      "customer-1842",
+		Labels:     map[string]string{"tier": "gold", "region": "eu"},
+		MinBalance: &minBalance,
+		Sort:       "balance",
+		Desc:       true,
+		Cursor:     "abc",
+		Limit:      10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, page.Wallets, 1)
+	assert.Equal(t, "def", page.NextCursor)
+}
+
+func TestNew_InvalidURL(t *testing.T) {
+	_, err := New("localhost:8080")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidRequest))
+}
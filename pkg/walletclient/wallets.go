@@ -0,0 +1,324 @@
+package walletclient
+
+import (
+	"JavaCode/internal/models"
+	"context"
+	"github.com/google/uuid"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Requests and responses of the API.
+type (
+	CreateWalletRequest    = models.CreateWalletRequest
+	UpdateWalletRequest    = models.UpdateWalletRequest
+	BalanceResponse        = models.BalanceResponse
+	WalletListResponse     = models.WalletListResponse
+	WalletOperationRequest = models.WalletOperationRequest
+	OperationDetails       = models.OperationDetails
+	TransferRequest        = models.TransferRequest
+	TransferResponse       = models.TransferResponse
+	FreezeWalletRequest    = models.FreezeWalletRequest
+	WalletStatusRequest    = models.WalletStatusRequest
+	LedgerEntryResponse    = models.LedgerEntryResponse
+	BalanceVerification    = models.BalanceVerification
+	WalletStatusChange     = models.WalletStatusChange
+)
+
+// Operation types and wallet statuses.
+const (
+	Deposit  = models.LedgerDeposit
+	Withdraw = models.LedgerWithdraw
+
+	WalletActive = models.WalletActive
+	WalletFrozen = models.WalletFrozen
+	WalletClosed = models.WalletClosed
+)
+
+const consistencyHeader = "X-Consistency-Token"
+
+// ListWalletsRequest selects a page of wallets. Zero fields do not filter.
+type ListWalletsRequest struct {
+	Owner string
+	// Name matches wallets whose name contains it, ignoring case.
+	Name string
+	// Labels matches wallets that have all of these labels.
+	Labels     map[string]string
+	Status     string
+	Currency   string
+	MinBalance *uint64
+	MaxBalance *uint64
+	// CreatedFrom is inclusive, CreatedTo exclusive.
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// Sort is "createdAt" or "balance"; Desc reverses the order.
+	Sort string
+	Desc bool
+	// Cursor is the NextCursor of the previous page.
+	Cursor string
+	Limit  int
+}
+
+// OperationResult is the outcome of a deposit or withdrawal.
+type OperationResult struct {
+	// Reference identifies the operation; generated if none was given.
+	Reference string
+	// ConsistencyToken makes a GetBalance with ReadAfter observe the operation.
+	ConsistencyToken string
+}
+
+// ReadOption configures a balance read.
+type ReadOption func(*request)
+
+// ReadAfter makes the read observe the operation that returned token.
+func ReadAfter(token string) ReadOption {
+	return func(r *request) {
+		if token != "" {
+			r.header.Set(consistencyHeader, token)
+		}
+	}
+}
+
+// ReadStrong makes the primary database serve the read.
+func ReadStrong() ReadOption {
+	return func(r *request) { r.query.Set("consistency", "strong") }
+}
+
+// ReadAt reads the balance as of t.
+func ReadAt(t time.Time) ReadOption {
+	return func(r *request) { r.query.Set("at", t.UTC().Format(time.RFC3339)) }
+}
+
+// CreateWallet creates a wallet. It is only retried if the request was not sent.
+func (c *Client) CreateWallet(ctx context.Context, req CreateWalletRequest) (*BalanceResponse, error) {
+	var wallet BalanceResponse
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/wallets", body: req}, &wallet); err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// GetBalance returns a wallet.
+func (c *Client) GetBalance(ctx context.Context, walletID string, opts ...ReadOption) (*BalanceResponse, error) {
+	req := request{
+		method:     http.MethodGet,
+		path:       "/api/v1/wallets/" + url.PathEscape(walletID),
+		query:      url.Values{},
+		header:     http.Header{},
+		idempotent: true,
+	}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	var wallet BalanceResponse
+	if _, err := c.do(ctx, req, &wallet); err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// ListWallets returns a page of wallets.
+func (c *Client) ListWallets(ctx context.Context, req ListWalletsRequest) (*WalletListResponse, error) {
+	query := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	set("owner", req.Owner)
+	set("name", req.Name)
+	set("status", req.Status)
+	set("currency", req.Currency)
+	set("cursor", req.Cursor)
+	keys := make([]string, 0, len(req.Labels))
+	for key := range req.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		query.Add("label", key+":"+req.Labels[key])
+	}
+	if req.MinBalance != nil {
+		set("minBalance", strconv.FormatUint(*req.MinBalance, 10))
+	}
+	if req.MaxBalance != nil {
+		set("maxBalance", strconv.FormatUint(*req.MaxBalance, 10))
+	}
+	if req.CreatedFrom != nil {
+		set("createdFrom", req.CreatedFrom.UTC().Format(time.RFC3339))
+	}
+	if req.CreatedTo != nil {
+		set("createdTo", req.CreatedTo.UTC().Format(time.RFC3339))
+	}
+	if req.Sort != "" {
+		if req.Desc {
+			set("sort", "-"+req.Sort)
+		} else {
+			set("sort", req.Sort)
+		}
+	}
+	if req.Limit > 0 {
+		set("limit", strconv.Itoa(req.Limit))
+	}
+
+	var page WalletListResponse
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/wallets", query: query, idempotent: true}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// UpdateWallet changes a wallet's name, owner or labels.
+func (c *Client) UpdateWallet(ctx context.Context, walletID string, req UpdateWalletRequest) (*BalanceResponse, error) {
+	var wallet BalanceResponse
+	_, err := c.do(ctx, request{
+		method:     http.MethodPatch,
+		path:       "/api/v1/wallets/" + url.PathEscape(walletID),
+		body:       req,
+		idempotent: true,
+	}, &wallet)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// ApplyOperation deposits to or withdraws from a wallet.
+//
+// An empty Reference is filled in with a random one, which makes the
+// operation safe to retry: a retry of an applied operation is rejected as
+// a duplicate, which is reported as success. A duplicate reference on the
+// first attempt is returned as ErrDuplicateReference.
+func (c *Client) ApplyOperation(ctx context.Context, req WalletOperationRequest) (*OperationResult, error) {
+	if req.Reference == "" {
+		req.Reference = uuid.NewString()
+	}
+	header, err := c.do(ctx, request{
+		method:      http.MethodPost,
+		path:        "/api/v1/wallet",
+		body:        req,
+		idempotent:  true,
+		duplicateOK: true,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &OperationResult{Reference: req.Reference, ConsistencyToken: header.Get(consistencyHeader)}, nil
+}
+
+// Deposit credits amount, in minor units of currency, to a wallet.
+func (c *Client) Deposit(ctx context.Context, walletID string, amount int64, currency string) (*OperationResult, error) {
+	return c.ApplyOperation(ctx, WalletOperationRequest{
+		WalletID: walletID, OperationType: Deposit, Amount: amount, Currency: currency,
+	})
+}
+
+// Withdraw debits amount, in minor units of currency, from a wallet.
+func (c *Client) Withdraw(ctx context.Context, walletID string, amount int64, currency string) (*OperationResult, error) {
+	return c.ApplyOperation(ctx, WalletOperationRequest{
+		WalletID: walletID, OperationType: Withdraw, Amount: amount, Currency: currency,
+	})
+}
+
+// Transfer moves funds between wallets. It is only retried if the request
+// was not sent.
+func (c *Client) Transfer(ctx context.Context, req TransferRequest) (*TransferResponse, error) {
+	var transfer TransferResponse
+	if _, err := c.do(ctx, request{method: http.MethodPost, path: "/api/v1/transfers", body: req}, &transfer); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// GetTransfer returns a transfer.
+func (c *Client) GetTransfer(ctx context.Context, transferID string) (*TransferResponse, error) {
+	var transfer TransferResponse
+	_, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/api/v1/transfers/" + url.PathEscape(transferID),
+		idempotent: true,
+	}, &transfer)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// FreezeWallet freezes a wallet.
+func (c *Client) FreezeWallet(ctx context.Context, walletID string, req FreezeWalletRequest) (*BalanceResponse, error) {
+	return c.changeStatus(ctx, walletID, "freeze", req)
+}
+
+// UnfreezeWallet makes a frozen wallet active again.
+func (c *Client) UnfreezeWallet(ctx context.Context, walletID, reason string) (*BalanceResponse, error) {
+	return c.changeStatus(ctx, walletID, "unfreeze", WalletStatusRequest{Reason: reason})
+}
+
+// CloseWallet closes a wallet with a zero balance.
+func (c *Client) CloseWallet(ctx context.Context, walletID, reason string) (*BalanceResponse, error) {
+	return c.changeStatus(ctx, walletID, "close", WalletStatusRequest{Reason: reason})
+}
+
+// changeStatus calls a status transition. Transitions are not retried: a
+// repeated one fails with ErrInvalidStatus.
+func (c *Client) changeStatus(ctx context.Context, walletID, action string, body any) (*BalanceResponse, error) {
+	var wallet BalanceResponse
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/admin/wallets/" + url.PathEscape(walletID) + "/" + action,
+		body:   body,
+	}, &wallet)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// ListLedgerEntries returns the latest ledger entries of a wallet, newest
+// first, optionally only those of the operation with reference.
+func (c *Client) ListLedgerEntries(ctx context.Context, walletID, reference string, limit int) ([]LedgerEntryResponse, error) {
+	query := url.Values{}
+	if reference != "" {
+		query.Set("reference", reference)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var entries []LedgerEntryResponse
+	_, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/api/v1/admin/wallets/" + url.PathEscape(walletID) + "/entries",
+		query:      query,
+		idempotent: true,
+	}, &entries)
+	return entries, err
+}
+
+// VerifyWalletBalance compares a wallet's balance with its ledger.
+func (c *Client) VerifyWalletBalance(ctx context.Context, walletID string) (*BalanceVerification, error) {
+	var verification BalanceVerification
+	_, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/api/v1/admin/wallets/" + url.PathEscape(walletID) + "/verify",
+		idempotent: true,
+	}, &verification)
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// ListWalletStatusChanges returns a wallet's status history.
+func (c *Client) ListWalletStatusChanges(ctx context.Context, walletID string) ([]WalletStatusChange, error) {
+	var changes []WalletStatusChange
+	_, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/api/v1/admin/wallets/" + url.PathEscape(walletID) + "/status-changes",
+		idempotent: true,
+	}, &changes)
+	return changes, err
+}
@@ -0,0 +1,5 @@
+// Package walletpb holds the generated gRPC client and server code of
+// api/proto/wallet/v1/wallet.proto.
+package walletpb
+
+//go:generate protoc -I ../../api/proto --go_out=. --go_opt=module=JavaCode/pkg/walletpb --go-grpc_out=. --go-grpc_opt=module=JavaCode/pkg/walletpb wallet/v1/wallet.proto
@@ -3,7 +3,6 @@ package utils
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
-	"net/http"
 )
 
 // ErrorResponse defines the standard error response format returned by the API.
@@ -19,7 +18,33 @@ var (
 	ErrInvalidAmount   = errors.New("amount must be greater than 0")
 	ErrNegativeBalance = errors.New("the amount cannot be negative")
 	ErrWalletNotFound  = errors.New("wallet not found")
+	ErrWalletExists    = errors.New("wallet already exists")
+	ErrWalletFrozen    = errors.New("wallet is frozen")
+	ErrWalletClosed    = errors.New("wallet is closed")
+	ErrWalletNotEmpty  = errors.New("wallet balance is not zero")
+	ErrInvalidStatus   = errors.New("wallet status does not allow this transition")
 	ErrDatabase        = errors.New("database error")
+	ErrNotReady        = errors.New("service not ready")
+
+	ErrHistoryUnavailable = errors.New("wallet history is not available before its opening balance")
+
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrCurrencyMismatch    = errors.New("currency does not match the wallet currency")
+
+	ErrAmountPrecision = errors.New("amount has more decimal places than the currency allows")
+	ErrAmountOverflow  = errors.New("amount overflows")
+
+	ErrRateNotFound     = errors.New("exchange rate not found")
+	ErrStaleRate        = errors.New("exchange rate is stale")
+	ErrTransferNotFound = errors.New("transfer not found")
+
+	ErrDuplicateReference = errors.New("operation reference already used for this wallet")
+
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+	ErrScheduleNotFound  = errors.New("scheduled operation not found")
+	ErrScheduleNotActive = errors.New("scheduled operation is not active")
 )
 
 // HandleError maps internal errors to appropriate HTTP responses and sends them via Gin.
@@ -27,42 +52,164 @@ var (
 // It supports specific error types like ErrInvalidRequest, ErrWalletNotFound, etc.,
 // and defaults to 500 Internal Server Error if the error is unknown.
 func HandleError(c *gin.Context, err error) {
+	response := NewErrorResponse(err)
+	c.JSON(response.Code, response)
+}
+
+// NewErrorResponse describes an error as returned by the API: a stable
+// error code, a message and the HTTP status in Code. Other transports
+// reuse the code and message.
+func NewErrorResponse(err error) ErrorResponse {
 	switch {
 	case errors.Is(err, ErrInvalidRequest):
-		c.JSON(http.StatusBadRequest, ErrorResponse{
+		return ErrorResponse{
 			Error:   "invalid_request",
 			Message: "Request is invalid or missing required fields",
 			Code:    400,
-		})
+		}
 	case errors.Is(err, ErrInvalidAmount):
-		c.JSON(http.StatusBadRequest, ErrorResponse{
+		return ErrorResponse{
 			Error:   "invalid_amount",
 			Message: "Amount must be greater than zero",
 			Code:    400,
-		})
+		}
+	case errors.Is(err, ErrAmountPrecision):
+		return ErrorResponse{
+			Error:   "excess_precision",
+			Message: "Amount has more decimal places than the currency allows",
+			Code:    400,
+		}
+	case errors.Is(err, ErrAmountOverflow):
+		return ErrorResponse{
+			Error:   "amount_overflow",
+			Message: "Amount or resulting balance is out of range",
+			Code:    422,
+		}
 	case errors.Is(err, ErrNegativeBalance):
-		c.JSON(http.StatusBadRequest, ErrorResponse{
+		return ErrorResponse{
 			Error:   "negative_amount",
 			Message: "The amount cannot be negative.",
 			Code:    400,
-		})
+		}
 	case errors.Is(err, ErrWalletNotFound):
-		c.JSON(http.StatusNotFound, ErrorResponse{
+		return ErrorResponse{
 			Error:   "wallet_not_found",
 			Message: "Wallet not found by uuid",
 			Code:    404,
-		})
+		}
+	case errors.Is(err, ErrWalletExists):
+		return ErrorResponse{
+			Error:   "wallet_exists",
+			Message: "A wallet with this uuid already exists",
+			Code:    409,
+		}
+	case errors.Is(err, ErrWalletFrozen):
+		return ErrorResponse{
+			Error:   "wallet_frozen",
+			Message: "Wallet is frozen and does not accept this operation",
+			Code:    409,
+		}
+	case errors.Is(err, ErrWalletClosed):
+		return ErrorResponse{
+			Error:   "wallet_closed",
+			Message: "Wallet is closed",
+			Code:    409,
+		}
+	case errors.Is(err, ErrWalletNotEmpty):
+		return ErrorResponse{
+			Error:   "wallet_not_empty",
+			Message: "Only a wallet with a zero balance can be closed",
+			Code:    409,
+		}
+	case errors.Is(err, ErrInvalidStatus):
+		return ErrorResponse{
+			Error:   "invalid_status_transition",
+			Message: "Wallet status does not allow this transition",
+			Code:    409,
+		}
 	case errors.Is(err, ErrDatabase):
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		return ErrorResponse{
 			Error:   "database_error",
 			Message: "Database operation failed",
 			Code:    500,
-		})
+		}
+	case errors.Is(err, ErrHistoryUnavailable):
+		return ErrorResponse{
+			Error:   "history_unavailable",
+			Message: "The ledger has no history of the wallet before its opening balance",
+			Code:    422,
+		}
+	case errors.Is(err, ErrUnsupportedCurrency):
+		return ErrorResponse{
+			Error:   "unsupported_currency",
+			Message: "Currency must be a supported ISO 4217 code",
+			Code:    400,
+		}
+	case errors.Is(err, ErrCurrencyMismatch):
+		return ErrorResponse{
+			Error:   "currency_mismatch",
+			Message: "Operation currency does not match the wallet currency",
+			Code:    422,
+		}
+	case errors.Is(err, ErrRateNotFound):
+		return ErrorResponse{
+			Error:   "rate_not_found",
+			Message: "No exchange rate is available for the currency pair",
+			Code:    422,
+		}
+	case errors.Is(err, ErrStaleRate):
+		return ErrorResponse{
+			Error:   "stale_rate",
+			Message: "The latest exchange rate for the currency pair has expired",
+			Code:    422,
+		}
+	case errors.Is(err, ErrTransferNotFound):
+		return ErrorResponse{
+			Error:   "transfer_not_found",
+			Message: "Transfer not found by uuid",
+			Code:    404,
+		}
+	case errors.Is(err, ErrDuplicateReference):
+		return ErrorResponse{
+			Error:   "duplicate_reference",
+			Message: "An operation with this reference already exists for the wallet",
+			Code:    409,
+		}
+	case errors.Is(err, ErrWebhookNotFound):
+		return ErrorResponse{
+			Error:   "webhook_not_found",
+			Message: "Webhook not found by id",
+			Code:    404,
+		}
+	case errors.Is(err, ErrDeliveryNotFound):
+		return ErrorResponse{
+			Error:   "delivery_not_found",
+			Message: "Webhook delivery not found",
+			Code:    404,
+		}
+	case errors.Is(err, ErrScheduleNotFound):
+		return ErrorResponse{
+			Error:   "schedule_not_found",
+			Message: "Scheduled operation not found by id",
+			Code:    404,
+		}
+	case errors.Is(err, ErrScheduleNotActive):
+		return ErrorResponse{
+			Error:   "schedule_not_active",
+			Message: "Scheduled operation is already completed or cancelled",
+			Code:    409,
+		}
+	case errors.Is(err, ErrNotReady):
+		return ErrorResponse{
+			Error:   "not_ready",
+			Message: err.Error(),
+			Code:    503,
+		}
 	default:
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		return ErrorResponse{
 			Error:   "internal_server_error",
 			Message: "An unexpected error occurred",
 			Code:    500,
-		})
+		}
 	}
 }